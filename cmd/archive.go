@@ -0,0 +1,150 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package cmd
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"github.com/mdhender/fhcms/cms/store/jsondb"
+	"github.com/mdhender/fhcms/internal/archive"
+	"github.com/mdhender/fhcms/internal/convert"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+var archivePath string
+var archiveSaveInputPath string
+var archiveSaveJSON bool
+var archiveSaveReportPath string
+var archiveSpeciesNo int
+var archiveSpeciesTurn int
+
+func init() {
+	rootCmd.AddCommand(archiveCmd)
+	archiveCmd.PersistentFlags().StringVar(&archivePath, "archive", "", "path to the turn archive (defaults to files.path/archive)")
+	archiveCmd.AddCommand(archiveListCmd)
+	archiveCmd.AddCommand(archiveSaveCmd)
+	archiveSaveCmd.Flags().StringVar(&archiveSaveInputPath, "input", "", "path to data files and orders for the turn (defaults to files.path)")
+	archiveSaveCmd.Flags().BoolVar(&archiveSaveJSON, "json", false, "load galaxy.json instead of the binary data files")
+	archiveSaveCmd.Flags().StringVar(&archiveSaveReportPath, "reports", "", "path to the turn reports (defaults to input)")
+	archiveCmd.AddCommand(archiveSpeciesCmd)
+	archiveSpeciesCmd.Flags().IntVar(&archiveSpeciesNo, "species", 0, "number of the species")
+	archiveSpeciesCmd.Flags().IntVar(&archiveSpeciesTurn, "turn", -1, "turn number (defaults to the latest)")
+	_ = archiveSpeciesCmd.MarkFlagRequired("species")
+}
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive",
+	Short: "Manage the turn archive",
+	Long: `The turn archive keeps a compressed snapshot of galaxy.json and the
+orders and reports for every turn. Archived turns are never changed.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if archivePath == "" {
+			archivePath = filepath.Join(viper.GetString("files.path"), "archive")
+		}
+	},
+}
+
+var archiveListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the archived turns",
+	Run: func(cmd *cobra.Command, args []string) {
+		turns, err := archive.Open(archivePath).Turns()
+		cobra.CheckErr(err)
+		for _, turn := range turns {
+			fmt.Println(turn)
+		}
+	},
+}
+
+var archiveSaveCmd = &cobra.Command{
+	Use:   "save",
+	Short: "Add the current turn to the archive",
+	Long: `Add the current turn to the archive. Use this to archive turns that
+were run without "fh turn run --archive".`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if archiveSaveInputPath == "" {
+			archiveSaveInputPath = viper.GetString("files.path")
+		}
+		if archiveSaveReportPath == "" {
+			archiveSaveReportPath = archiveSaveInputPath
+		}
+		var ds *jsondb.Store
+		var err error
+		if archiveSaveJSON {
+			ds, err = jsondb.Read(filepath.Join(archiveSaveInputPath, "galaxy.json"))
+		} else {
+			var endian binary.ByteOrder = binary.LittleEndian
+			if viper.GetBool("files.big_endian") {
+				endian = binary.BigEndian
+			}
+			ds, err = convert.FromDat32(archiveSaveInputPath, endian)
+		}
+		cobra.CheckErr(err)
+
+		// without the engine, the orders on disk are the best record of what was executed
+		orders := make(map[int][]byte)
+		for spNo := 1; spNo <= len(ds.Species); spNo++ {
+			b, err := ioutil.ReadFile(filepath.Join(archiveSaveInputPath, fmt.Sprintf("sp%02d.ord", spNo)))
+			if err == nil {
+				orders[spNo] = b
+			} else if !os.IsNotExist(err) {
+				cobra.CheckErr(err)
+			}
+		}
+		files := turnArchiveFiles(archiveSaveReportPath, ds.Galaxy.TurnNumber, len(ds.Species))
+		cobra.CheckErr(archive.Open(archivePath).Save(ds, orders, files))
+		log.Printf("[archive] saved turn %d with %d orders and %d files to %q\n", ds.Galaxy.TurnNumber, len(orders), len(files), archivePath)
+	},
+}
+
+var archiveSpeciesCmd = &cobra.Command{
+	Use:   "species",
+	Short: "Print a species as of a turn",
+	Run: func(cmd *cobra.Command, args []string) {
+		a := archive.Open(archivePath)
+		if archiveSpeciesTurn < 0 {
+			var err error
+			archiveSpeciesTurn, err = a.Latest()
+			cobra.CheckErr(err)
+		}
+		sp, err := a.Species(archiveSpeciesNo, archiveSpeciesTurn)
+		cobra.CheckErr(err)
+		b, err := json.MarshalIndent(sp, "", "  ")
+		cobra.CheckErr(err)
+		fmt.Println(string(b))
+	},
+}
+
+// turnArchiveFiles returns the reports for a turn that exist,
+// keyed by their name in the archive.
+func turnArchiveFiles(reportPath string, turn, numSpecies int) map[string]string {
+	files := make(map[string]string)
+	for _, name := range turnReportFiles(turn, numSpecies) {
+		if _, err := os.Stat(filepath.Join(reportPath, name)); err == nil {
+			files[name] = filepath.Join(reportPath, name)
+		}
+	}
+	return files
+}
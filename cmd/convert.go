@@ -0,0 +1,109 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package cmd
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"github.com/mdhender/fhcms/cms/store/jsondb"
+	"github.com/mdhender/fhcms/internal/convert"
+	"github.com/mdhender/fhcms/internal/repos/cdb"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strings"
+)
+
+var convertFrom, convertTo string
+var convertInputPath, convertOutputPath string
+var convertGalaxyName string
+var convertTurn int
+
+func init() {
+	rootCmd.AddCommand(convertCmd)
+	formats := strings.Join(convert.Formats(), ", ")
+	convertCmd.Flags().StringVar(&convertFrom, "from", "", "format of the input ("+formats+")")
+	convertCmd.Flags().StringVar(&convertTo, "to", "", "format of the output ("+formats+")")
+	convertCmd.Flags().StringVar(&convertInputPath, "input", "", "input file, or directory for dat32")
+	convertCmd.Flags().StringVar(&convertOutputPath, "output", "", "output file, or directory for dat32")
+	convertCmd.Flags().StringVar(&convertGalaxyName, "galaxy", "", "name of the galaxy in the database")
+	convertCmd.Flags().IntVar(&convertTurn, "turn", 0, "turn to read from the database (defaults to the latest)")
+	_ = convertCmd.MarkFlagRequired("from")
+	_ = convertCmd.MarkFlagRequired("to")
+}
+
+var convertCmd = &cobra.Command{
+	Use:   "convert",
+	Short: "Convert game data between formats",
+	Long: `Convert game data between the binary data files (dat32), galaxy.json
+(jsondb), cluster.json (cluster), and the database staging tables (postgres).
+The --input and --output flags name the files, or the directory for dat32.
+The postgres format requires --galaxy and uses database.json from files.path.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var endian binary.ByteOrder = binary.LittleEndian
+		if viper.GetBool("files.big_endian") {
+			endian = binary.BigEndian
+		}
+
+		var db *cdb.DB
+		ctx := context.Background()
+		if convertFrom == convert.POSTGRES || convertTo == convert.POSTGRES {
+			if convertGalaxyName == "" {
+				cobra.CheckErr(fmt.Errorf("the postgres format requires --galaxy"))
+			}
+			dataPath := viper.GetString("files.path")
+			dbConfig := &cdb.DBConfig{}
+			log.Printf("[convert] loading database configuration %q\n", filepath.Join(dataPath, "database.json"))
+			data, err := ioutil.ReadFile(filepath.Join(dataPath, "database.json"))
+			cobra.CheckErr(err)
+			cobra.CheckErr(json.Unmarshal(data, dbConfig))
+			db, err = cdb.New(ctx, dbConfig)
+			cobra.CheckErr(err)
+			defer db.Close()
+		}
+		if convertFrom != convert.POSTGRES && convertInputPath == "" {
+			cobra.CheckErr(fmt.Errorf("missing --input"))
+		} else if convertTo != convert.POSTGRES && convertOutputPath == "" {
+			cobra.CheckErr(fmt.Errorf("missing --output"))
+		}
+
+		var ds *jsondb.Store
+		var err error
+		log.Printf("[convert] reading %s %q\n", convertFrom, convertInputPath)
+		if convertFrom == convert.POSTGRES {
+			ds, err = db.ReadStore(ctx, convertGalaxyName, convertTurn)
+		} else {
+			ds, err = convert.Read(convertFrom, convertInputPath, endian)
+		}
+		cobra.CheckErr(err)
+
+		ds.Version = version
+		log.Printf("[convert] writing %s %q\n", convertTo, convertOutputPath)
+		if convertTo == convert.POSTGRES {
+			err = db.StageStore(ctx, convertGalaxyName, ds)
+		} else {
+			err = convert.Write(ds, convertTo, convertOutputPath, endian)
+		}
+		cobra.CheckErr(err)
+	},
+}
@@ -0,0 +1,69 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package cmd
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/mdhender/fhcms/internal/convert"
+	"github.com/mdhender/fhcms/internal/doctor"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"strings"
+)
+
+var doctorFrom, doctorInputPath string
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().StringVar(&doctorFrom, "from", convert.DAT32, "format of the input ("+strings.Join([]string{convert.DAT32, convert.JSONDB, convert.CLUSTER}, ", ")+")")
+	doctorCmd.Flags().StringVar(&doctorInputPath, "input", "", "input file, or directory for dat32 (defaults to files.path)")
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the game data for problems",
+	Long: `Check the game data for damage from hand-edits before running a turn.
+Reports ships outside the cluster, named planets that point at the wrong
+planet, negative inventories, record counts that don't match, broken
+wormholes, duplicate ship names, and relations with missing species.
+Each problem is listed with a suggested fix. Nothing is changed.
+Fixes use the galaxy.json field names; use "fh convert" to edit the
+binary data files as galaxy.json and convert them back.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if doctorInputPath == "" {
+			doctorInputPath = viper.GetString("files.path")
+		}
+		var endian binary.ByteOrder = binary.LittleEndian
+		if viper.GetBool("files.big_endian") {
+			endian = binary.BigEndian
+		}
+		ds, err := convert.Read(doctorFrom, doctorInputPath, endian)
+		cobra.CheckErr(err)
+
+		findings := doctor.Check(ds)
+		for _, f := range findings {
+			fmt.Println(f)
+		}
+		if len(findings) != 0 {
+			cobra.CheckErr(fmt.Errorf("found %d problems", len(findings)))
+		}
+		fmt.Println("no problems found")
+	},
+}
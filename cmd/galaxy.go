@@ -0,0 +1,85 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/mdhender/fhcms/internal/galaxy"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var galaxyNewForce bool
+var galaxyNewOutputPath string
+var galaxyNewRadius int
+var galaxyNewSeed uint64
+var galaxyNewSpecies int
+
+func init() {
+	rootCmd.AddCommand(galaxyCmd)
+	galaxyCmd.AddCommand(galaxyNewCmd)
+	galaxyNewCmd.Flags().BoolVar(&galaxyNewForce, "force", false, "overwrite an existing galaxy.json")
+	galaxyNewCmd.Flags().StringVar(&galaxyNewOutputPath, "output", "", "path to write galaxy.json (defaults to files.path)")
+	galaxyNewCmd.Flags().IntVar(&galaxyNewRadius, "radius", 0, "radius of the galaxy in parsecs (defaults to a size that suits the number of species)")
+	galaxyNewCmd.Flags().Uint64Var(&galaxyNewSeed, "seed", 0, "seed for the random number generator (defaults to the clock)")
+	galaxyNewCmd.Flags().IntVar(&galaxyNewSpecies, "species", 0, "number of species the galaxy is designed for")
+	_ = galaxyNewCmd.MarkFlagRequired("species")
+}
+
+var galaxyCmd = &cobra.Command{
+	Use:   "galaxy",
+	Short: "Create galaxies",
+}
+
+var galaxyNewCmd = &cobra.Command{
+	Use:   "new",
+	Short: "Create the stars and planets for a new game",
+	Long: `Create a new galaxy and write it to galaxy.json in the jsondb format.
+Stars are placed at random with their types, colors, planets, and
+wormholes, and one home system is built for each species. Species are
+added later. The same settings and seed always create the same galaxy.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if galaxyNewOutputPath == "" {
+			galaxyNewOutputPath = viper.GetString("files.path")
+		}
+		if galaxyNewSeed == 0 {
+			galaxyNewSeed = uint64(time.Now().UnixNano())
+		}
+		outputFile := filepath.Join(galaxyNewOutputPath, "galaxy.json")
+		if _, err := os.Stat(outputFile); err == nil && !galaxyNewForce {
+			cobra.CheckErr(fmt.Errorf("%s already exists, use --force to overwrite it", outputFile))
+		}
+
+		ds, err := galaxy.New(galaxy.Config{Species: galaxyNewSpecies, Radius: galaxyNewRadius, Seed: galaxyNewSeed})
+		cobra.CheckErr(err)
+		ds.Version = version
+
+		homes := 0
+		for _, star := range ds.Stars {
+			homes += star.HomeSystem
+		}
+		log.Printf("[galaxy] seed %d: radius %d: %d stars: %d planets: %d home systems\n", galaxyNewSeed, ds.Galaxy.Radius, len(ds.Stars), len(ds.Planets), homes)
+		cobra.CheckErr(ds.Write(outputFile))
+		log.Printf("[galaxy] wrote %q\n", outputFile)
+	},
+}
@@ -0,0 +1,343 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/mdhender/fhcms/internal/orders"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ordersCheckFormat string
+var ordersCheckInputPath string
+var ordersCheckSpeciesNo int
+var ordersConvertTo string
+var ordersDiffFormat string
+var ordersDiffInputPath string
+var ordersDiffSpeciesNo int
+var ordersExpandWrite bool
+var ordersFmtInputPath string
+var ordersFmtNames bool
+var ordersFmtSpeciesNo int
+var ordersFmtWrite bool
+
+func init() {
+	rootCmd.AddCommand(ordersCmd)
+	ordersCmd.AddCommand(ordersCheckCmd)
+	ordersCheckCmd.Flags().StringVar(&ordersCheckFormat, "format", "text", "output format, text or json")
+	ordersCheckCmd.Flags().StringVar(&ordersCheckInputPath, "input", "", "path to data files for turn (defaults to files.path)")
+	ordersCheckCmd.Flags().IntVar(&ordersCheckSpeciesNo, "species-no", 0, "species number (defaults to the number in the file name)")
+	ordersCmd.AddCommand(ordersConvertCmd)
+	ordersConvertCmd.Flags().StringVar(&ordersConvertTo, "to", "json", "output format, text, json, or yaml")
+	ordersCmd.AddCommand(ordersDiffCmd)
+	ordersDiffCmd.Flags().StringVar(&ordersDiffFormat, "format", "text", "output format, text or json")
+	ordersDiffCmd.Flags().StringVar(&ordersDiffInputPath, "input", "", "path to data files for turn (defaults to files.path)")
+	ordersDiffCmd.Flags().IntVar(&ordersDiffSpeciesNo, "species-no", 0, "species number (defaults to the number in the file name)")
+	ordersCmd.AddCommand(ordersExpandCmd)
+	ordersExpandCmd.Flags().BoolVarP(&ordersExpandWrite, "write", "w", false, "write result to spNN.expanded.ord next to the order file instead of stdout")
+	ordersCmd.AddCommand(ordersFmtCmd)
+	ordersFmtCmd.Flags().StringVar(&ordersFmtInputPath, "input", "", "path to data files for turn (defaults to files.path)")
+	ordersFmtCmd.Flags().BoolVar(&ordersFmtNames, "names", false, "spell names the way the game data does")
+	ordersFmtCmd.Flags().IntVar(&ordersFmtSpeciesNo, "species-no", 0, "species number (defaults to the number in the file name)")
+	ordersFmtCmd.Flags().BoolVarP(&ordersFmtWrite, "write", "w", false, "write result to the order file instead of stdout")
+}
+
+var ordersCmd = &cobra.Command{
+	Use:   "orders",
+	Short: "Work with order files",
+}
+
+var ordersCheckCmd = &cobra.Command{
+	Use:   "check spNN.ord",
+	Short: "Check an order file against the current game",
+	Long: `Parse an order file and check it against the current game data.
+Reports parse errors, orders in the wrong section, and unknown ships,
+planets, and species, with suggestions for names that look misspelled.
+Fleets and macros are expanded before the orders are checked, and
+line numbers refer to the lines that the orders were expanded from.
+JSON and YAML files are converted to text first, so line numbers refer
+to the converted text. Exits with status 1 if any errors were found.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if ordersCheckFormat != "text" && ordersCheckFormat != "json" {
+			cobra.CheckErr(fmt.Errorf("format must be text or json"))
+		}
+		ordersFile := args[0]
+		if ordersCheckSpeciesNo == 0 {
+			ordersCheckSpeciesNo = speciesNoFromFile(ordersFile)
+		}
+		if ordersCheckInputPath == "" {
+			ordersCheckInputPath = viper.GetString("files.path")
+		}
+
+		b, err := ioutil.ReadFile(ordersFile)
+		cobra.CheckErr(err)
+		b, err = orders.ToText(b)
+		cobra.CheckErr(err)
+		ds, err := loader(ordersCheckInputPath, viper.GetBool("files.big_endian"))
+		cobra.CheckErr(err)
+		sp, ok := ds.Species[fmt.Sprintf("SP%02d", ordersCheckSpeciesNo)]
+		if !ok {
+			cobra.CheckErr(fmt.Errorf("species-no must be in range 1..%d", len(ds.Species)))
+		}
+
+		diagnostics := orders.Expand(b).Check(ds.OrderNames(sp))
+
+		if ordersCheckFormat == "json" {
+			out := struct {
+				File        string               `json:"file"`
+				SpeciesNo   int                  `json:"species_no"`
+				Turn        int                  `json:"turn"`
+				Diagnostics []*orders.Diagnostic `json:"diagnostics"`
+			}{File: ordersFile, SpeciesNo: sp.No, Turn: ds.Turn, Diagnostics: diagnostics}
+			if out.Diagnostics == nil {
+				out.Diagnostics = []*orders.Diagnostic{}
+			}
+			buf, err := json.MarshalIndent(out, "", "  ")
+			cobra.CheckErr(err)
+			fmt.Println(string(buf))
+		} else {
+			for _, d := range diagnostics {
+				fmt.Printf("%s:%s\n", ordersFile, d)
+			}
+		}
+
+		for _, d := range diagnostics {
+			if d.Severity == "error" {
+				os.Exit(1)
+			}
+		}
+	},
+}
+
+var ordersConvertCmd = &cobra.Command{
+	Use:   "convert spNN.ord",
+	Short: "Convert an order file between the text, JSON, and YAML formats",
+	Long: `Convert an order file between the text, JSON, and YAML formats.
+The format of the input is detected from its contents. Every order,
+comment, and message is kept; text is written in canonical form.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		b, err := ioutil.ReadFile(args[0])
+		cobra.CheckErr(err)
+		d, err := orders.Decode(b)
+		cobra.CheckErr(err)
+		var out []byte
+		switch ordersConvertTo {
+		case "text":
+			out = d.Text()
+		case "json":
+			out, err = d.JSON()
+		case "yaml":
+			out, err = d.YAML()
+		default:
+			err = fmt.Errorf("to must be text, json, or yaml")
+		}
+		cobra.CheckErr(err)
+		fmt.Print(string(out))
+	},
+}
+
+var ordersDiffCmd = &cobra.Command{
+	Use:   "diff spNN.ord",
+	Short: "Compare an order file with the default orders for the turn",
+	Long: `Compare an order file with the default orders that default-orders
+generates for the same species and turn. Orders that were added, removed,
+or changed are listed section by section, and the PRODUCTION section is
+compared one production center at a time, so a colony that is missing
+its PRODUCTION block is easy to spot.
+
+Fleets and macros are expanded first, and line numbers refer to the
+lines that the orders were expanded from. Exits with status 1 if the
+orders differ from the defaults.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if ordersDiffFormat != "text" && ordersDiffFormat != "json" {
+			cobra.CheckErr(fmt.Errorf("format must be text or json"))
+		}
+		ordersFile := args[0]
+		if ordersDiffSpeciesNo == 0 {
+			ordersDiffSpeciesNo = speciesNoFromFile(ordersFile)
+		}
+		if ordersDiffInputPath == "" {
+			ordersDiffInputPath = viper.GetString("files.path")
+		}
+
+		b, err := ioutil.ReadFile(ordersFile)
+		cobra.CheckErr(err)
+		b, err = orders.ToText(b)
+		cobra.CheckErr(err)
+		ds, err := loader(ordersDiffInputPath, viper.GetBool("files.big_endian"))
+		cobra.CheckErr(err)
+		sp, ok := ds.Species[fmt.Sprintf("SP%02d", ordersDiffSpeciesNo)]
+		if !ok {
+			cobra.CheckErr(fmt.Errorf("species-no must be in range 1..%d", len(ds.Species)))
+		}
+
+		// the names must be taken before the defaults are generated, since that updates the store
+		names := ds.OrderNames(sp)
+		defaults := &bytes.Buffer{}
+		writeDefaultOrders(defaults, ds, sp, time.Now().UTC())
+
+		x := orders.Expand(b)
+		changes := orders.Diff(orders.Parse(defaults.Bytes()), orders.Parse(x.Text), names)
+		for _, c := range changes {
+			c.Line = x.OriginalLine(c.Line)
+		}
+
+		if ordersDiffFormat == "json" {
+			out := struct {
+				File      string           `json:"file"`
+				SpeciesNo int              `json:"species_no"`
+				Turn      int              `json:"turn"`
+				Changes   []*orders.Change `json:"changes"`
+			}{File: ordersFile, SpeciesNo: sp.No, Turn: ds.Turn, Changes: changes}
+			if out.Changes == nil {
+				out.Changes = []*orders.Change{}
+			}
+			buf, err := json.MarshalIndent(out, "", "  ")
+			cobra.CheckErr(err)
+			fmt.Println(string(buf))
+		} else {
+			var section, center string
+			for _, c := range changes {
+				if c.Section != section || c.Center != center {
+					section, center = c.Section, c.Center
+					if center == "" {
+						fmt.Printf("%s:%s\n", ordersFile, section)
+					} else {
+						fmt.Printf("%s:%s PL %s\n", ordersFile, section, center)
+					}
+				}
+				fmt.Printf("\t%s\n", c)
+			}
+		}
+
+		if len(changes) != 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+var ordersExpandCmd = &cobra.Command{
+	Use:   "expand spNN.ord",
+	Short: "Expand the fleets and macros in an order file",
+	Long: `Replace the FLEET, FOR, and MACRO directives in an order file with
+the plain orders that the engine will run. Errors in the directives are
+reported with the line numbers of the original file.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ordersFile := args[0]
+		b, err := ioutil.ReadFile(ordersFile)
+		cobra.CheckErr(err)
+		b, err = orders.ToText(b)
+		cobra.CheckErr(err)
+
+		x := orders.Expand(b)
+		for _, err := range x.Errors {
+			fmt.Fprintf(os.Stderr, "%s:%v\n", ordersFile, err)
+		}
+		if !ordersExpandWrite {
+			fmt.Print(string(x.Text))
+		} else {
+			expandedFile := strings.TrimSuffix(ordersFile, filepath.Ext(ordersFile)) + ".expanded.ord"
+			cobra.CheckErr(ioutil.WriteFile(expandedFile, x.Text, 0644))
+		}
+		if len(x.Errors) != 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+var ordersFmtCmd = &cobra.Command{
+	Use:   "fmt spNN.ord...",
+	Short: "Rewrite order files in canonical form",
+	Long: `Rewrite order files in canonical form.
+Command names are expanded, arguments are separated by tabs, and every
+section is a START ... END block. Comments and message text are kept,
+and lines with errors are left as entered. Formatting a file twice
+gives the same result. JSON and YAML files are formatted and written
+back in their own format.
+
+With --names, ship, planet, and species names are spelled the way
+the game data spells them.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if ordersFmtInputPath == "" {
+			ordersFmtInputPath = viper.GetString("files.path")
+		}
+		for _, ordersFile := range args {
+			b, err := ioutil.ReadFile(ordersFile)
+			cobra.CheckErr(err)
+			text, err := orders.ToText(b)
+			cobra.CheckErr(err)
+
+			var names *orders.Names
+			if ordersFmtNames {
+				speciesNo := ordersFmtSpeciesNo
+				if speciesNo == 0 {
+					speciesNo = speciesNoFromFile(ordersFile)
+				}
+				ds, err := loader(ordersFmtInputPath, viper.GetBool("files.big_endian"))
+				cobra.CheckErr(err)
+				sp, ok := ds.Species[fmt.Sprintf("SP%02d", speciesNo)]
+				if !ok {
+					cobra.CheckErr(fmt.Errorf("%s: species-no must be in range 1..%d", ordersFile, len(ds.Species)))
+				}
+				names = ds.OrderNames(sp)
+			}
+
+			formatted := orders.Format(text, names)
+			switch orders.FileFormat(b) {
+			case "json":
+				formatted, err = orders.FromText(formatted).JSON()
+			case "yaml":
+				formatted, err = orders.FromText(formatted).YAML()
+			}
+			cobra.CheckErr(err)
+			if !ordersFmtWrite {
+				fmt.Print(string(formatted))
+				continue
+			}
+			fi, err := os.Stat(ordersFile)
+			cobra.CheckErr(err)
+			cobra.CheckErr(ioutil.WriteFile(ordersFile, formatted, fi.Mode()))
+		}
+	},
+}
+
+// speciesNoFromFile returns the species number from an order file name like sp18.ord, or 0.
+func speciesNoFromFile(name string) int {
+	m := regexp.MustCompile(`sp(\d+)`).FindStringSubmatch(filepath.Base(name))
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n
+}
@@ -24,23 +24,30 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"log"
+	"path/filepath"
 )
 
 var processFilePrefix string
 var processInputPath string
+var processJSON bool
+var processOutputPath string
 var processPromptGM bool
+var processReportPath string
 
 func init() {
 	rootCmd.AddCommand(processCmd)
 	processCmd.Flags().StringVar(&processFilePrefix, "prefix", "", "prefix for turn-based files")
 	processCmd.Flags().StringVar(&processInputPath, "input", "", "path to data files for turn")
+	processCmd.Flags().BoolVar(&processJSON, "json", false, "load and save galaxy.json instead of the binary data files")
+	processCmd.Flags().StringVar(&processOutputPath, "output", "", "path to write updated data files (defaults to input)")
+	processCmd.Flags().StringVar(&processReportPath, "reports", "", "path to write turn reports (defaults to output)")
 	processCmd.Flags().BoolVar(&processPromptGM, "prompt-gm", false, "prompt gm and log to stdout")
 }
 
 var processCmd = &cobra.Command{
 	Use:   "process",
 	Short: "Process the current turn",
-	Long:  `Load orders and process the current turn.`,
+	Long:  `Load orders, process the current turn, write the turn reports, and save the updated data files.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		e := engine.New(processPromptGM)
 		var endian binary.ByteOrder
@@ -50,8 +57,26 @@ var processCmd = &cobra.Command{
 			endian = binary.LittleEndian
 		}
 		log.Printf("[engine] input path is %q\n", processInputPath)
-		cobra.CheckErr(e.LoadBinary(processInputPath, processFilePrefix, endian))
+		if processJSON {
+			cobra.CheckErr(e.LoadJSON(filepath.Join(processInputPath, processFilePrefix+"galaxy.json")))
+		} else {
+			cobra.CheckErr(e.LoadBinary(processInputPath, processFilePrefix, endian))
+		}
 		cobra.CheckErr(e.LoadOrders(processInputPath, processFilePrefix))
+		if processOutputPath == "" {
+			processOutputPath = processInputPath
+		}
+		if processReportPath == "" {
+			processReportPath = processOutputPath
+		}
+		log.Printf("[engine] report path is %q\n", processReportPath)
+		e.SetReportPath(processReportPath)
 		cobra.CheckErr(e.Run())
+		log.Printf("[engine] output path is %q\n", processOutputPath)
+		if processJSON {
+			cobra.CheckErr(e.SaveJSON(filepath.Join(processOutputPath, processFilePrefix+"galaxy.json")))
+		} else {
+			cobra.CheckErr(e.SaveBinary(processOutputPath, processFilePrefix, endian))
+		}
 	},
 }
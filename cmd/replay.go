@@ -0,0 +1,270 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/mdhender/fhcms/internal/engine"
+	"github.com/spf13/cobra"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+var turnReplayInputPath string
+var turnReplayManifestFile string
+var turnReplayWorkPath string
+
+func init() {
+	turnCmd.AddCommand(turnReplayCmd)
+	turnReplayCmd.Flags().StringVar(&turnReplayInputPath, "input", "", "path to the data and order files the turn was run from (defaults to the copy named in the manifest)")
+	turnReplayCmd.Flags().StringVar(&turnReplayManifestFile, "manifest", "", "replay manifest written by turn run")
+	turnReplayCmd.Flags().StringVar(&turnReplayWorkPath, "work", "", "path to write the replayed files (defaults to a new temporary directory)")
+	_ = turnReplayCmd.MarkFlagRequired("manifest")
+}
+
+// replayManifest records everything needed to run a turn again and
+// get the same results: the inputs, the seed at the start of each
+// phase, and the outputs. Files are recorded by name and SHA-256 hash.
+// Turn is the number of the turn that the run produces.
+type replayManifest struct {
+	Version   string            `json:"version"`
+	Turn      int               `json:"turn"`
+	InputPath string            `json:"input_path,omitempty"` // copy of the input files
+	Prefix    string            `json:"prefix,omitempty"`
+	JSON      bool              `json:"json"`
+	BigEndian bool              `json:"big_endian"`
+	Seed      uint64            `json:"seed"`
+	Phases    []replayPhase     `json:"phases"`
+	Inputs    map[string]string `json:"inputs"`
+	Outputs   map[string]string `json:"outputs,omitempty"`
+}
+
+type replayPhase struct {
+	Phase string `json:"phase"`
+	Seed  uint64 `json:"seed"` // seed at the start of the phase
+}
+
+func readReplayManifest(name string) (*replayManifest, error) {
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	var m replayManifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (m *replayManifest) write(name string) error {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(name, b, 0644)
+}
+
+// turnInputFiles returns the names of the files that a turn is run from.
+// Orders are optional, so only the order files found in path are included.
+func turnInputFiles(path, prefix string, useJSON bool, numSpecies int) []string {
+	var names []string
+	if useJSON {
+		names = append(names, prefix+"galaxy.json")
+	} else {
+		names = append(names, prefix+"galaxy.dat", prefix+"stars.dat", prefix+"planets.dat", prefix+"locations.dat")
+		for spNo := 1; spNo <= numSpecies; spNo++ {
+			names = append(names, prefix+fmt.Sprintf("sp%02d.dat", spNo))
+		}
+	}
+	for spNo := 1; spNo <= numSpecies; spNo++ {
+		name := prefix + fmt.Sprintf("sp%02d.ord", spNo)
+		if _, err := os.Stat(filepath.Join(path, name)); err == nil {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// turnDataFiles returns the names of the data files that a turn saves.
+func turnDataFiles(prefix string, useJSON bool, numSpecies int) []string {
+	if useJSON {
+		return []string{prefix + "galaxy.json"}
+	}
+	names := []string{prefix + "galaxy.dat", prefix + "stars.dat", prefix + "planets.dat", prefix + "locations.dat"}
+	for spNo := 1; spNo <= numSpecies; spNo++ {
+		names = append(names, prefix+fmt.Sprintf("sp%02d.dat", spNo))
+	}
+	return names
+}
+
+// turnReportFiles returns the names of the reports that a turn writes.
+func turnReportFiles(turn, numSpecies int) []string {
+	var names []string
+	for spNo := 1; spNo <= numSpecies; spNo++ {
+		names = append(names, fmt.Sprintf("sp%02d.rpt.t%d", spNo, turn))
+	}
+	return append(names, fmt.Sprintf("stats.t%d", turn))
+}
+
+// copyFiles copies the named files from one path to another.
+func copyFiles(from, to string, names []string) error {
+	if err := os.MkdirAll(to, 0755); err != nil {
+		return err
+	}
+	for _, name := range names {
+		b, err := ioutil.ReadFile(filepath.Join(from, name))
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(to, name), b, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hashFiles adds the SHA-256 hash of each file to hashes.
+func hashFiles(hashes map[string]string, path string, names []string) error {
+	for _, name := range names {
+		b, err := ioutil.ReadFile(filepath.Join(path, name))
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(b)
+		hashes[name] = hex.EncodeToString(sum[:])
+	}
+	return nil
+}
+
+// compareHashes returns a line for each file that is missing from
+// or different in got.
+func compareHashes(want, got map[string]string) []string {
+	var names []string
+	for name := range want {
+		names = append(names, name)
+	}
+	for name := range got {
+		if _, ok := want[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var diffs []string
+	for _, name := range names {
+		if w, ok := want[name]; !ok {
+			diffs = append(diffs, fmt.Sprintf("%s: not in manifest", name))
+		} else if g, ok := got[name]; !ok {
+			diffs = append(diffs, fmt.Sprintf("%s: missing", name))
+		} else if w != g {
+			diffs = append(diffs, fmt.Sprintf("%s: hash is %s, want %s", name, g, w))
+		}
+	}
+	return diffs
+}
+
+var turnReplayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Run a turn again from its replay manifest",
+	Long: `Check the input files against the replay manifest, run the turn
+again with the recorded seed, and prove that the random number generator
+and every output file match the original run. The input files are read
+from the copy that turn run saved unless --input is given.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		m, err := readReplayManifest(turnReplayManifestFile)
+		cobra.CheckErr(err)
+		if m.Version != version {
+			log.Printf("[replay] manifest was written by version %s, this is version %s\n", m.Version, version)
+		}
+		if turnReplayInputPath == "" {
+			if m.InputPath == "" {
+				cobra.CheckErr(fmt.Errorf("manifest does not name a copy of the inputs, use --input"))
+			}
+			turnReplayInputPath = m.InputPath
+		}
+		log.Printf("[replay] input path is %q\n", turnReplayInputPath)
+
+		// the inputs must be exactly the files the turn was run from
+		e := engine.New(false)
+		var endian binary.ByteOrder
+		if m.BigEndian {
+			endian = binary.BigEndian
+		} else {
+			endian = binary.LittleEndian
+		}
+		if m.JSON {
+			cobra.CheckErr(e.LoadJSON(filepath.Join(turnReplayInputPath, m.Prefix+"galaxy.json")))
+		} else {
+			cobra.CheckErr(e.LoadBinary(turnReplayInputPath, m.Prefix, endian))
+		}
+		inputs := make(map[string]string)
+		cobra.CheckErr(hashFiles(inputs, turnReplayInputPath, turnInputFiles(turnReplayInputPath, m.Prefix, m.JSON, e.NumSpecies())))
+		if diffs := compareHashes(m.Inputs, inputs); len(diffs) != 0 {
+			for _, diff := range diffs {
+				fmt.Printf("input  %s\n", diff)
+			}
+			cobra.CheckErr(fmt.Errorf("input files do not match the manifest"))
+		}
+		cobra.CheckErr(e.LoadOrders(turnReplayInputPath, m.Prefix))
+
+		if turnReplayWorkPath == "" {
+			turnReplayWorkPath, err = ioutil.TempDir("", "fh-replay-")
+			cobra.CheckErr(err)
+		} else {
+			cobra.CheckErr(os.MkdirAll(turnReplayWorkPath, 0755))
+		}
+		log.Printf("[replay] work path is %q\n", turnReplayWorkPath)
+		e.SetReportPath(turnReplayWorkPath)
+		e.SetSeed(m.Seed)
+
+		var diffs []string
+		for i, phase := range engine.Phases() {
+			if i >= len(m.Phases) || m.Phases[i].Phase != phase {
+				cobra.CheckErr(fmt.Errorf("manifest does not record phase %s", phase))
+			} else if seed := e.Seed(); seed != m.Phases[i].Seed {
+				diffs = append(diffs, fmt.Sprintf("%s: seed is %d, want %d", phase, seed, m.Phases[i].Seed))
+			}
+			cobra.CheckErr(e.RunPhase(phase))
+		}
+
+		if m.JSON {
+			cobra.CheckErr(e.SaveJSON(filepath.Join(turnReplayWorkPath, m.Prefix+"galaxy.json")))
+		} else {
+			cobra.CheckErr(e.SaveBinary(turnReplayWorkPath, m.Prefix, endian))
+		}
+		outputs := make(map[string]string)
+		cobra.CheckErr(hashFiles(outputs, turnReplayWorkPath, turnDataFiles(m.Prefix, m.JSON, e.NumSpecies())))
+		cobra.CheckErr(hashFiles(outputs, turnReplayWorkPath, turnReportFiles(e.TurnNumber(), e.NumSpecies())))
+		diffs = append(diffs, compareHashes(m.Outputs, outputs)...)
+
+		if len(diffs) != 0 {
+			for _, diff := range diffs {
+				fmt.Printf("output %s\n", diff)
+			}
+			cobra.CheckErr(fmt.Errorf("replay of turn %d does not match the manifest", m.Turn))
+		}
+		fmt.Printf("replay of turn %d matches: %d phases, %d inputs, %d outputs\n", m.Turn, len(m.Phases), len(m.Inputs), len(m.Outputs))
+	},
+}
@@ -0,0 +1,84 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package cmd
+
+import (
+	"github.com/mdhender/fhcms/cms/store/jsondb"
+	"github.com/mdhender/fhcms/internal/galaxy"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"log"
+	"path/filepath"
+	"time"
+)
+
+var speciesAddConfig galaxy.SpeciesConfig
+var speciesAddInputPath string
+
+func init() {
+	rootCmd.AddCommand(speciesCmd)
+	speciesCmd.AddCommand(speciesAddCmd)
+	speciesAddCmd.Flags().StringVar(&speciesAddInputPath, "input", "", "path to galaxy.json (defaults to files.path)")
+	speciesAddCmd.Flags().StringVar(&speciesAddConfig.Name, "name", "", "name of the species")
+	speciesAddCmd.Flags().StringVar(&speciesAddConfig.GovtName, "govt-name", "", "name of the government")
+	speciesAddCmd.Flags().StringVar(&speciesAddConfig.GovtType, "govt-type", "", "type of the government")
+	speciesAddCmd.Flags().StringVar(&speciesAddConfig.HomePlanet, "home-planet", "", "name of the home planet")
+	speciesAddCmd.Flags().IntVar(&speciesAddConfig.ML, "ml", 0, "initial Military tech level")
+	speciesAddCmd.Flags().IntVar(&speciesAddConfig.GV, "gv", 0, "initial Gravitics tech level")
+	speciesAddCmd.Flags().IntVar(&speciesAddConfig.LS, "ls", 0, "initial Life Support tech level")
+	speciesAddCmd.Flags().IntVar(&speciesAddConfig.BI, "bi", 0, "initial Biology tech level")
+	speciesAddCmd.Flags().Uint64Var(&speciesAddConfig.Seed, "seed", 0, "seed for the random number generator (defaults to the clock)")
+	for _, name := range []string{"name", "govt-name", "govt-type", "home-planet"} {
+		_ = speciesAddCmd.MarkFlagRequired(name)
+	}
+}
+
+var speciesCmd = &cobra.Command{
+	Use:   "species",
+	Short: "Manage the species in a game",
+}
+
+var speciesAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add a new player's species to the galaxy",
+	Long: `Add a species to galaxy.json from a player's set-up form.
+The species is given an unused home system, its gases are set from the
+atmosphere of the home planet, and the home planet is populated.
+ML, GV, LS, and BI must add up to 15. MI and MA always start at 10.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if speciesAddInputPath == "" {
+			speciesAddInputPath = viper.GetString("files.path")
+		}
+		if speciesAddConfig.Seed == 0 {
+			speciesAddConfig.Seed = uint64(time.Now().UnixNano())
+		}
+		galaxyFile := filepath.Join(speciesAddInputPath, "galaxy.json")
+		ds, err := jsondb.Read(galaxyFile)
+		cobra.CheckErr(err)
+
+		sp, err := galaxy.AddSpecies(ds, speciesAddConfig)
+		cobra.CheckErr(err)
+		home := sp.Namplas[0]
+		log.Printf("[species] added SP%02d %q on PL %q at %d %d %d %d\n", sp.Id, sp.Name, home.Name, home.X, home.Y, home.Z, home.Pn)
+
+		ds.Version = version
+		cobra.CheckErr(ds.Write(galaxyFile))
+		log.Printf("[species] wrote %q\n", galaxyFile)
+	},
+}
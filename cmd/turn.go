@@ -19,13 +19,42 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 package cmd
 
 import (
+	"encoding/binary"
 	"fmt"
+	"github.com/mdhender/fhcms/internal/archive"
+	"github.com/mdhender/fhcms/internal/engine"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
 )
 
+var turnRunArchivePath string
+var turnRunCheckpointPath string
+var turnRunFilePrefix string
+var turnRunFromPhase string
+var turnRunInputPath string
+var turnRunJSON bool
+var turnRunOutputPath string
+var turnRunPromptGM bool
+var turnRunReportPath string
+var turnRunSeed uint64
+
 func init() {
 	rootCmd.AddCommand(turnCmd)
+	turnCmd.AddCommand(turnRunCmd)
+	turnRunCmd.Flags().StringVar(&turnRunArchivePath, "archive", "", "path to the turn archive (the turn is not archived if empty)")
+	turnRunCmd.Flags().StringVar(&turnRunCheckpointPath, "checkpoints", "", "path to write phase checkpoints (defaults to output/checkpoints)")
+	turnRunCmd.Flags().StringVar(&turnRunFilePrefix, "prefix", "", "prefix for turn-based files")
+	turnRunCmd.Flags().StringVar(&turnRunFromPhase, "from-phase", "", "restart the turn at this phase using the checkpoint from the phase before it")
+	turnRunCmd.Flags().StringVar(&turnRunInputPath, "input", "", "path to data files for turn")
+	turnRunCmd.Flags().BoolVar(&turnRunJSON, "json", false, "load and save galaxy.json instead of the binary data files")
+	turnRunCmd.Flags().StringVar(&turnRunOutputPath, "output", "", "path to write updated data files (defaults to input)")
+	turnRunCmd.Flags().BoolVar(&turnRunPromptGM, "prompt-gm", false, "prompt gm and log to stdout")
+	turnRunCmd.Flags().StringVar(&turnRunReportPath, "reports", "", "path to write turn reports (defaults to output)")
+	turnRunCmd.Flags().Uint64Var(&turnRunSeed, "seed", 0, "seed for the random number generator (defaults to the engine seed)")
 }
 
 var turnCmd = &cobra.Command{
@@ -38,3 +67,121 @@ var turnCmd = &cobra.Command{
 		fmt.Printf("%d\n", ds.Turn)
 	},
 }
+
+var turnRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run every phase of the current turn",
+	Long: `Load orders and run every phase of the current turn, from Locations
+through Stats. The engine state is saved after each phase so that a failed
+turn can be restarted with --from-phase. The input files are copied to
+inputs.tN in the checkpoint path before the turn is run, and a replay
+manifest, replay.tN.json, is written to the output path so that the turn
+can be checked with replay even when the output overwrites the input. N is
+the number of the turn that the run produces, the same as for the reports.
+With --archive, a snapshot of the turn is added to the turn archive along
+with the orders that were executed, including any generated by NoOrders.
+
+Phases: ` + strings.Join(engine.Phases(), ", "),
+	Run: func(cmd *cobra.Command, args []string) {
+		phases := engine.Phases()
+		fromPhase := 0
+		if turnRunFromPhase != "" {
+			if fromPhase = engine.PhaseIndex(turnRunFromPhase); fromPhase == -1 {
+				cobra.CheckErr(fmt.Errorf("from-phase must be one of %s", strings.Join(phases, ", ")))
+			}
+		}
+		if turnRunOutputPath == "" {
+			turnRunOutputPath = turnRunInputPath
+		}
+		if turnRunReportPath == "" {
+			turnRunReportPath = turnRunOutputPath
+		}
+		if turnRunCheckpointPath == "" {
+			turnRunCheckpointPath = filepath.Join(turnRunOutputPath, "checkpoints")
+		}
+		cobra.CheckErr(os.MkdirAll(turnRunCheckpointPath, 0755))
+		checkpointFile := func(phase int) string {
+			return filepath.Join(turnRunCheckpointPath, fmt.Sprintf("%s%02d-%s.json", turnRunFilePrefix, phase+1, phases[phase]))
+		}
+
+		manifestFile := filepath.Join(turnRunCheckpointPath, turnRunFilePrefix+"replay.json")
+
+		e := engine.New(turnRunPromptGM)
+		var endian binary.ByteOrder
+		if viper.GetBool("files.big_endian") {
+			endian = binary.BigEndian
+		} else {
+			endian = binary.LittleEndian
+		}
+		var manifest *replayManifest
+		if fromPhase == 0 {
+			log.Printf("[engine] input path is %q\n", turnRunInputPath)
+			if turnRunJSON {
+				cobra.CheckErr(e.LoadJSON(filepath.Join(turnRunInputPath, turnRunFilePrefix+"galaxy.json")))
+			} else {
+				cobra.CheckErr(e.LoadBinary(turnRunInputPath, turnRunFilePrefix, endian))
+			}
+			cobra.CheckErr(e.LoadOrders(turnRunInputPath, turnRunFilePrefix))
+			if turnRunSeed != 0 {
+				e.SetSeed(turnRunSeed)
+			}
+			manifest = &replayManifest{
+				Version:   version,
+				Turn:      e.TurnNumber() + 1, // Finish advances the turn number
+				Prefix:    turnRunFilePrefix,
+				JSON:      turnRunJSON,
+				BigEndian: viper.GetBool("files.big_endian"),
+				Seed:      e.Seed(),
+				Inputs:    make(map[string]string),
+			}
+			inputFiles := turnInputFiles(turnRunInputPath, turnRunFilePrefix, turnRunJSON, e.NumSpecies())
+			cobra.CheckErr(hashFiles(manifest.Inputs, turnRunInputPath, inputFiles))
+			// keep a copy of the inputs, since the output may overwrite them
+			inputCopy, err := filepath.Abs(filepath.Join(turnRunCheckpointPath, turnRunFilePrefix+fmt.Sprintf("inputs.t%d", manifest.Turn)))
+			cobra.CheckErr(err)
+			cobra.CheckErr(copyFiles(turnRunInputPath, inputCopy, inputFiles))
+			manifest.InputPath = inputCopy
+		} else {
+			_, err := e.LoadCheckpoint(checkpointFile(fromPhase - 1))
+			cobra.CheckErr(err)
+			manifest, err = readReplayManifest(manifestFile)
+			cobra.CheckErr(err)
+			if len(manifest.Phases) < fromPhase {
+				cobra.CheckErr(fmt.Errorf("replay manifest %q does not record phase %s", manifestFile, phases[fromPhase-1]))
+			}
+			manifest.Phases, manifest.Outputs = manifest.Phases[:fromPhase], nil
+		}
+		log.Printf("[engine] report path is %q\n", turnRunReportPath)
+		e.SetReportPath(turnRunReportPath)
+
+		for phase := fromPhase; phase < len(phases); phase++ {
+			manifest.Phases = append(manifest.Phases, replayPhase{Phase: phases[phase], Seed: e.Seed()})
+			if err := e.RunPhase(phases[phase]); err != nil {
+				log.Printf("[engine] phase %s failed, restart with --from-phase %s\n", phases[phase], phases[phase])
+				cobra.CheckErr(err)
+			}
+			cobra.CheckErr(e.SaveCheckpoint(checkpointFile(phase), phases[phase]))
+			cobra.CheckErr(manifest.write(manifestFile))
+		}
+
+		log.Printf("[engine] output path is %q\n", turnRunOutputPath)
+		if turnRunJSON {
+			cobra.CheckErr(e.SaveJSON(filepath.Join(turnRunOutputPath, turnRunFilePrefix+"galaxy.json")))
+		} else {
+			cobra.CheckErr(e.SaveBinary(turnRunOutputPath, turnRunFilePrefix, endian))
+		}
+
+		manifest.Outputs = make(map[string]string)
+		cobra.CheckErr(hashFiles(manifest.Outputs, turnRunOutputPath, turnDataFiles(turnRunFilePrefix, turnRunJSON, e.NumSpecies())))
+		cobra.CheckErr(hashFiles(manifest.Outputs, turnRunReportPath, turnReportFiles(e.TurnNumber(), e.NumSpecies())))
+		cobra.CheckErr(manifest.write(manifestFile))
+		replayFile := filepath.Join(turnRunOutputPath, turnRunFilePrefix+fmt.Sprintf("replay.t%d.json", manifest.Turn))
+		cobra.CheckErr(manifest.write(replayFile))
+		log.Printf("[engine] replay manifest is %q\n", replayFile)
+
+		if turnRunArchivePath != "" {
+			files := turnArchiveFiles(turnRunReportPath, e.TurnNumber(), e.NumSpecies())
+			cobra.CheckErr(e.SaveArchive(archive.Open(turnRunArchivePath), files))
+		}
+	},
+}
@@ -0,0 +1,116 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package cmd
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"github.com/mdhender/fhcms/internal/engine"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var ordersWhatIfFilePrefix string
+var ordersWhatIfFormat string
+var ordersWhatIfInputPath string
+var ordersWhatIfJSON bool
+var ordersWhatIfSpeciesNo int
+
+func init() {
+	ordersCmd.AddCommand(ordersWhatIfCmd)
+	ordersWhatIfCmd.Flags().StringVar(&ordersWhatIfFilePrefix, "prefix", "", "prefix for turn-based files")
+	ordersWhatIfCmd.Flags().StringVar(&ordersWhatIfFormat, "format", "text", "output format, text or json")
+	ordersWhatIfCmd.Flags().StringVar(&ordersWhatIfInputPath, "input", "", "path to data files for turn (defaults to files.path)")
+	ordersWhatIfCmd.Flags().BoolVar(&ordersWhatIfJSON, "json", false, "load galaxy.json instead of the binary data files")
+	ordersWhatIfCmd.Flags().IntVar(&ordersWhatIfSpeciesNo, "species-no", 0, "species number (defaults to the number in the file name)")
+}
+
+var ordersWhatIfCmd = &cobra.Command{
+	Use:   "whatif spNN.ord",
+	Short: "Dry run the orders for a single species",
+	Long: `Run the pre-departure, jump, and production orders for a single species
+on a copy of the game data, ignoring every other species, and print the
+log and the projected ships and colonies. Use it to see whether a BUILD
+fits the budget, a ship reaches its destination, or a transport has room.
+Nothing is saved.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if ordersWhatIfFormat != "text" && ordersWhatIfFormat != "json" {
+			cobra.CheckErr(fmt.Errorf("format must be text or json"))
+		}
+		ordersFile := args[0]
+		if ordersWhatIfSpeciesNo == 0 {
+			ordersWhatIfSpeciesNo = speciesNoFromFile(ordersFile)
+		}
+		if ordersWhatIfInputPath == "" {
+			ordersWhatIfInputPath = viper.GetString("files.path")
+		}
+		b, err := ioutil.ReadFile(ordersFile)
+		cobra.CheckErr(err)
+
+		e := engine.New(false)
+		if ordersWhatIfJSON {
+			cobra.CheckErr(e.LoadJSON(filepath.Join(ordersWhatIfInputPath, ordersWhatIfFilePrefix+"galaxy.json")))
+		} else {
+			var endian binary.ByteOrder = binary.LittleEndian
+			if viper.GetBool("files.big_endian") {
+				endian = binary.BigEndian
+			}
+			cobra.CheckErr(e.LoadBinary(ordersWhatIfInputPath, ordersWhatIfFilePrefix, endian))
+		}
+		w, err := e.WhatIf(ordersWhatIfSpeciesNo, b)
+		cobra.CheckErr(err)
+
+		if ordersWhatIfFormat == "json" {
+			buf, err := json.MarshalIndent(w, "", "  ")
+			cobra.CheckErr(err)
+			fmt.Println(string(buf))
+			return
+		}
+
+		for _, err := range w.Errors {
+			fmt.Printf("error: %s\n", err)
+		}
+		fmt.Printf("%s\n", strings.TrimSpace(w.Log))
+		fmt.Printf("\nProjected economic units: %d\n", w.EconUnits)
+		fmt.Printf("\nProjected ships:\n")
+		for _, ship := range w.Ships {
+			fmt.Printf("  %-24s %3d %3d %3d #%d  %-18s %s\n", ship.Name, ship.X, ship.Y, ship.Z, ship.Orbit, ship.Status, whatIfInventory(ship.Inventory))
+		}
+		fmt.Printf("\nProjected colonies:\n")
+		for _, colony := range w.Colonies {
+			fmt.Printf("  PL %-21s %3d %3d %3d #%d  %s\n", colony.Name, colony.X, colony.Y, colony.Z, colony.Orbit, whatIfInventory(colony.Inventory))
+		}
+	},
+}
+
+// whatIfInventory returns an inventory as a sorted list like "CU 5, IU 10".
+func whatIfInventory(inventory map[string]int) string {
+	var list []string
+	for abbr, n := range inventory {
+		list = append(list, fmt.Sprintf("%s %d", abbr, n))
+	}
+	sort.Strings(list)
+	return strings.Join(list, ", ")
+}
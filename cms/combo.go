@@ -24,7 +24,6 @@ import (
 	"github.com/mdhender/fhcms/cms/agrep"
 	"github.com/mdhender/fhcms/cms/config"
 	"github.com/mdhender/fhcms/cms/orders"
-	"github.com/mdhender/fhcms/cms/parser"
 	"github.com/mdhender/fhcms/cms/prng"
 	"github.com/mdhender/fhcms/cms/store/jsondb"
 	"io/ioutil"
@@ -10899,16 +10898,12 @@ func (s *species_data) getOrders(verbose, debug bool) []error {
 	if verbose {
 		log.Printf("orders: loading orders file %q\n", filepath.Base(s.orders.filename))
 	}
-	// parser returns a raw parse tree. we will evaluate it later, maybe.
-	if tree, err := parser.Parse(s.orders.filename, debug); err != nil {
+	b, err := ioutil.ReadFile(s.orders.filename)
+	if err != nil {
 		s.orders.errors = append(s.orders.errors, err)
 		return s.orders.errors
-	} else if tree == nil {
-		s.orders.errors = append(s.orders.errors, fmt.Errorf("parser failed to return orders"))
-		return s.orders.errors
-	} else {
-		s.orders.data = tree
 	}
+	s.orders.data = orders.Parse(b)
 	//if verbose_mode {
 	//	fmt.Printf(";; SP%02d TURN %3d\n", s.id, __jdb.Galaxy.TurnNumber)
 	//	fmt.Println("START COMBAT")
@@ -19,7 +19,6 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 package main
 
 import (
-	"github.com/mdhender/fhcms/cms/parser"
 	"log"
 )
 
@@ -44,12 +43,8 @@ func (g *globals) executeJumpOrders() []error {
 
 		// skip if this species has no jump orders
 		var hasJumpOrders bool
-		if g.species.orders.data != nil {
-			for _, o := range g.species.orders.data.Children {
-				if o.Token.Type == parser.Jump {
-					hasJumpOrders = len(o.Children) != 0
-				}
-			}
+		if section := g.species.orders.data.Section("JUMPS"); section != nil {
+			hasJumpOrders = len(section.Orders) != 0
 		}
 		if !hasJumpOrders {
 			log.Printf("jump: species %02d has no jump orders\n", species_number)
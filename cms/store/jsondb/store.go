@@ -31,24 +31,7 @@ type Store struct {
 	Locations []Location     `json:"locations"`
 	Planets   []*PlanetData  `json:"planets"`
 	Species   []*SpeciesData `json:"species"`
-	Stars     []struct {
-		Id          int   `json:"id"`
-		Color       int   `json:"color"`
-		HomeSystem  int   `json:"home_system"`
-		Message     int   `json:"message"`
-		NumPlanets  int   `json:"num_planets"`
-		PlanetIndex int   `json:"planet_index"`
-		Size        int   `json:"size"`
-		Type        int   `json:"type"`
-		VisitedBy   []int `json:"visited_by"`
-		WormHere    int   `json:"worm_here"`
-		WormX       int   `json:"worm_x"`
-		WormY       int   `json:"worm_y"`
-		WormZ       int   `json:"worm_z"`
-		X           int   `json:"x"`
-		Y           int   `json:"y"`
-		Z           int   `json:"z"`
-	} `json:"stars"`
+	Stars     []*StarData    `json:"stars"`
 }
 
 type Location struct {
@@ -101,6 +84,30 @@ type PlanetData struct {
 	TemperatureClass int    `json:"temperature_class"`
 }
 
+type ShipData struct {
+	Id                 int    `json:"id"`
+	Age                int    `json:"age"`
+	ArrivedViaWormhole int    `json:"arrived_via_wormhole"`
+	Class              int    `json:"class"`
+	DestX              int    `json:"dest_x"`
+	DestY              int    `json:"dest_y"`
+	DestZ              int    `json:"dest_z"`
+	JustJumped         int    `json:"just_jumped"`
+	ItemQuantity       []int  `json:"item_quantity"`
+	LoadingPoint       int    `json:"loading_point"`
+	Name               string `json:"name"`
+	Pn                 int    `json:"pn"`
+	RemainingCost      int    `json:"remaining_cost"`
+	Special            int    `json:"special"`
+	Status             int    `json:"status"`
+	Tonnage            int    `json:"tonnage"`
+	Type               int    `json:"type"`
+	UnloadingPoint     int    `json:"unloading_point"`
+	X                  int    `json:"x"`
+	Y                  int    `json:"y"`
+	Z                  int    `json:"z"`
+}
+
 type SpeciesData struct {
 	Id               int                `json:"id"`
 	Ally             []int              `json:"ally"`
@@ -124,35 +131,32 @@ type SpeciesData struct {
 	RequiredGas      int                `json:"required_gas"`
 	RequiredGasMax   int                `json:"required_gas_max"`
 	RequiredGasMin   int                `json:"required_gas_min"`
-	Ships            []struct {
-		Id                 int    `json:"id"`
-		Age                int    `json:"age"`
-		ArrivedViaWormhole int    `json:"arrived_via_wormhole"`
-		Class              int    `json:"class"`
-		DestX              int    `json:"dest_x"`
-		DestY              int    `json:"dest_y"`
-		DestZ              int    `json:"dest_z"`
-		JustJumped         int    `json:"just_jumped"`
-		ItemQuantity       []int  `json:"item_quantity"`
-		LoadingPoint       int    `json:"loading_point"`
-		Name               string `json:"name"`
-		Pn                 int    `json:"pn"`
-		RemainingCost      int    `json:"remaining_cost"`
-		Special            int    `json:"special"`
-		Status             int    `json:"status"`
-		Tonnage            int    `json:"tonnage"`
-		Type               int    `json:"type"`
-		UnloadingPoint     int    `json:"unloading_point"`
-		X                  int    `json:"x"`
-		Y                  int    `json:"y"`
-		Z                  int    `json:"z"`
-	} `json:"ships"`
-	TechEps       [6]int `json:"tech_eps"`
-	TechKnowledge [6]int `json:"tech_knowledge"`
-	TechLevel     [6]int `json:"tech_level"`
-	X             int    `json:"x"`
-	Y             int    `json:"y"`
-	Z             int    `json:"z"`
+	Ships            []*ShipData        `json:"ships"`
+	TechEps          [6]int             `json:"tech_eps"`
+	TechKnowledge    [6]int             `json:"tech_knowledge"`
+	TechLevel        [6]int             `json:"tech_level"`
+	X                int                `json:"x"`
+	Y                int                `json:"y"`
+	Z                int                `json:"z"`
+}
+
+type StarData struct {
+	Id          int   `json:"id"`
+	Color       int   `json:"color"`
+	HomeSystem  int   `json:"home_system"`
+	Message     int   `json:"message"`
+	NumPlanets  int   `json:"num_planets"`
+	PlanetIndex int   `json:"planet_index"`
+	Size        int   `json:"size"`
+	Type        int   `json:"type"`
+	VisitedBy   []int `json:"visited_by"`
+	WormHere    int   `json:"worm_here"`
+	WormX       int   `json:"worm_x"`
+	WormY       int   `json:"worm_y"`
+	WormZ       int   `json:"worm_z"`
+	X           int   `json:"x"`
+	Y           int   `json:"y"`
+	Z           int   `json:"z"`
 }
 
 /* Status codes for named planets. These are logically ORed together. */
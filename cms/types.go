@@ -20,7 +20,7 @@ package main
 
 import (
 	"bytes"
-	"github.com/mdhender/fhcms/cms/parser"
+	"github.com/mdhender/fhcms/cms/orders"
 )
 
 type action_data struct {
@@ -184,7 +184,7 @@ type species_data struct {
 	id      int
 	namplas []*nampla_data
 	orders  struct {
-		data     *parser.Node
+		data     *orders.Orders
 		filename string
 		errors   []error
 	}
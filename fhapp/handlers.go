@@ -21,10 +21,13 @@ package main
 
 import (
 	"bytes"
+	"encoding/binary"
+	"encoding/json"
 	"fmt"
-	"github.com/mdhender/fhcms/cms/parser"
 	"github.com/mdhender/fhcms/internal/cluster"
+	"github.com/mdhender/fhcms/internal/engine"
 	"github.com/mdhender/fhcms/internal/flist"
+	"github.com/mdhender/fhcms/internal/orders"
 	"github.com/mdhender/fhcms/internal/way"
 	"html/template"
 	"io/ioutil"
@@ -235,6 +238,7 @@ func (s *Server) handleTurnOrders(files string) http.HandlerFunc {
 			TurnNumber int
 			Date       string
 			Orders     string
+			Lines      []*orderLine
 		}{
 			Engine:     s.data.Engine,
 			User:       u,
@@ -270,6 +274,7 @@ func (s *Server) handleTurnOrders(files string) http.HandlerFunc {
 						log.Printf("server: %s %q: handleTurnOrders: %+v\n", r.Method, r.URL.Path, err)
 					} else {
 						data.Orders = string(b)
+						data.Lines = annotateOrders(data.Orders, s.checkOrders(u.Species, data.Orders))
 					}
 				}
 				break
@@ -398,73 +403,87 @@ func (s *Server) handleTurnUpload(uploads string) http.HandlerFunc {
 			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 			return
 		}
-		data := struct {
-			Engine *Engine
-			Semver string
-			Site   struct {
-				Title     string
-				Slug      string
-				Copyright struct {
-					Year   int
-					Author string
-				}
-			}
-			Game struct {
-				Title     string
-				Turn      int
-				LastTurn  int
-				OrdersDue string
-			}
-			User   UserData
-			Player struct {
-				Name            string
-				Data            string // folder on web server containing this player's data
-				IsAdmin         bool
-				IsAuthenticated bool
-				Species         *cluster.Species
-			}
-			Stats      *StatsData
-			TurnNumber int
-			OrdersFile string
-			Report     string
-		}{
-			Engine:     s.data.Engine,
-			User:       u,
-			Stats:      s.data.Stats[u.SpeciesId],
-			TurnNumber: turnNumber,
-		}
-		data.Semver = s.data.Store.Semver
-		data.Game.Title = "Raven's Beta"
-		data.Game.Turn = s.data.Store.Turn
-		if data.Game.Turn > 1 {
-			data.Game.LastTurn = s.data.Store.Turn - 1
-		}
-		data.Game.OrdersDue = fmt.Sprintf("%s by %s. %s", s.data.Turn.Due, s.data.Turn.By, s.data.Turn.TimeZone)
-		data.Player.Name = u.Player
-		for _, p := range s.data.Players {
-			if p.SpeciesId == u.SpeciesId {
-				data.Player.Data = strings.ToLower(u.SpeciesId) + "-" + p.Key
+		s.renderTurnUpload(w, r, u, turnNumber, "", nil)
+	}
+}
+
+// renderTurnUpload writes the upload form.
+// When orders are given, the form shows them along with the problems found on each line.
+func (s *Server) renderTurnUpload(w http.ResponseWriter, r *http.Request, u UserData, turnNumber int, ordersText string, diagnostics []*orders.Diagnostic) {
+	data := struct {
+		Engine *Engine
+		Semver string
+		Site   struct {
+			Title     string
+			Slug      string
+			Copyright struct {
+				Year   int
+				Author string
 			}
 		}
-		data.Player.IsAuthenticated = u.IsAuthenticated
-		data.Player.IsAdmin = u.IsAuthenticated && u.IsAdmin
-		data.Player.Species = u.Species
-		data.OrdersFile = fmt.Sprintf("sp%02d.t%d.orders.txt", u.Species.No, s.data.Store.Turn)
-		data.Site.Title = s.data.Site.Title
-		data.Site.Slug = s.data.Site.Slug
-		data.Site.Copyright.Year = s.data.Site.Copyright.Year
-		data.Site.Copyright.Author = s.data.Site.Copyright.Author
-		b, err := s.render("turnUpload", data)
-		if err != nil {
-			log.Printf("server: %s %q: handleTurnReport: %+v\n", r.Method, r.URL.Path, err)
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
-			return
+		Game struct {
+			Title     string
+			Turn      int
+			LastTurn  int
+			OrdersDue string
+		}
+		User   UserData
+		Player struct {
+			Name            string
+			Data            string // folder on web server containing this player's data
+			IsAdmin         bool
+			IsAuthenticated bool
+			Species         *cluster.Species
+		}
+		Stats       *StatsData
+		TurnNumber  int
+		OrdersFile  string
+		Report      string
+		Orders      string
+		Lines       []*orderLine
+		Diagnostics []*orders.Diagnostic
+	}{
+		Engine:      s.data.Engine,
+		User:        u,
+		Stats:       s.data.Stats[u.SpeciesId],
+		TurnNumber:  turnNumber,
+		Orders:      ordersText,
+		Diagnostics: diagnostics,
+	}
+	if len(diagnostics) != 0 {
+		data.Lines = annotateOrders(ordersText, diagnostics)
+	}
+	data.Semver = s.data.Store.Semver
+	data.Game.Title = "Raven's Beta"
+	data.Game.Turn = s.data.Store.Turn
+	if data.Game.Turn > 1 {
+		data.Game.LastTurn = s.data.Store.Turn - 1
+	}
+	data.Game.OrdersDue = fmt.Sprintf("%s by %s. %s", s.data.Turn.Due, s.data.Turn.By, s.data.Turn.TimeZone)
+	data.Player.Name = u.Player
+	for _, p := range s.data.Players {
+		if p.SpeciesId == u.SpeciesId {
+			data.Player.Data = strings.ToLower(u.SpeciesId) + "-" + p.Key
 		}
-		w.Header().Set("Content-Type", "text/html")
-		w.Header().Set("Far-Horizons", s.data.Store.Semver)
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write(b)
 	}
+	data.Player.IsAuthenticated = u.IsAuthenticated
+	data.Player.IsAdmin = u.IsAuthenticated && u.IsAdmin
+	data.Player.Species = u.Species
+	data.OrdersFile = fmt.Sprintf("sp%02d.t%d.orders.txt", u.Species.No, s.data.Store.Turn)
+	data.Site.Title = s.data.Site.Title
+	data.Site.Slug = s.data.Site.Slug
+	data.Site.Copyright.Year = s.data.Site.Copyright.Year
+	data.Site.Copyright.Author = s.data.Site.Copyright.Author
+	b, err := s.render("turnUpload", data)
+	if err != nil {
+		log.Printf("server: %s %q: renderTurnUpload: %+v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html")
+	w.Header().Set("Far-Horizons", s.data.Store.Semver)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(b)
 }
 
 func (s *Server) handleUI() http.HandlerFunc {
@@ -602,23 +621,36 @@ func (s *Server) postTurnOrders(uploads string) http.HandlerFunc {
 			return
 		}
 
-		if err := r.ParseForm(); err != nil {
-			log.Printf("server: %s %q: %+v\n", r.Method, r.URL.Path, err)
-			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
-			return
-		}
-		//log.Printf("server: %s %q: %v\n", r.Method, r.URL.Path, r.PostForm)
 		var input struct {
 			orders string
+			force  bool // save even if the orders have errors
 		}
-		for k, v := range r.Form {
-			switch k {
-			case "orders":
-				if len(v) != 1 || !utf8.ValidString(v[0]) || len(v[0]) < 1 || len(v[0]) > 64*1024 {
-					http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
-					return
+		if body, ok, err := readOrdersDocument(r); ok {
+			// bots may post a JSON or YAML orders document as the body of the request
+			if err != nil {
+				log.Printf("server: %s %q: %+v\n", r.Method, r.URL.Path, err)
+				http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+				return
+			}
+			input.orders, input.force = body, r.URL.Query().Get("force") == "true"
+		} else {
+			if err := r.ParseForm(); err != nil {
+				log.Printf("server: %s %q: %+v\n", r.Method, r.URL.Path, err)
+				http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+				return
+			}
+			//log.Printf("server: %s %q: %v\n", r.Method, r.URL.Path, r.PostForm)
+			for k, v := range r.Form {
+				switch k {
+				case "force":
+					input.force = len(v) == 1 && v[0] == "true"
+				case "orders":
+					if len(v) != 1 || !utf8.ValidString(v[0]) || len(v[0]) < 1 || len(v[0]) > 64*1024 {
+						http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+						return
+					}
+					input.orders = v[0]
 				}
-				input.orders = v[0]
 			}
 		}
 
@@ -627,6 +659,32 @@ func (s *Server) postTurnOrders(uploads string) http.HandlerFunc {
 			return
 		}
 
+		// orders in the JSON or YAML format are checked and saved as text
+		text, err := orders.ToText([]byte(input.orders))
+		if err != nil {
+			diagnostics := []*orders.Diagnostic{{Severity: "error", Message: err.Error()}}
+			if wantsJSON(r) {
+				writeOrdersResponse(w, http.StatusUnprocessableEntity, false, "", diagnostics)
+				return
+			}
+			s.renderTurnUpload(w, r, u, turnNumber, input.orders, diagnostics)
+			return
+		}
+		input.orders = string(text)
+
+		// check the orders before saving them. line numbers are for the orders as text.
+		// only errors block the save; warnings are returned with the saved orders.
+		diagnostics := s.checkOrders(u.Species, input.orders)
+		if numErrors := countErrors(diagnostics); numErrors != 0 && !input.force {
+			log.Printf("server: %s %q: species %s turn %d orders not saved: %d errors\n", r.Method, r.URL.Path, u.SpeciesId, turnNumber, numErrors)
+			if wantsJSON(r) {
+				writeOrdersResponse(w, http.StatusUnprocessableEntity, false, "", diagnostics)
+				return
+			}
+			s.renderTurnUpload(w, r, u, turnNumber, input.orders, diagnostics)
+			return
+		}
+
 		date := time.Now().UTC().Format(time.RFC3339)
 		input.orders = fmt.Sprintf(";; %s T%d %s\n\n", u.SpeciesId, turnNumber, date) + input.orders
 
@@ -639,22 +697,31 @@ func (s *Server) postTurnOrders(uploads string) http.HandlerFunc {
 			return
 		}
 
+		// fleets and macros are expanded into a second file so the GM can see exactly what will run
+		expanded := orders.Expand([]byte(input.orders))
+		if expanded.Expanded {
+			expandedFile := fmt.Sprintf("sp%02d.t%d.orders.expanded.txt", u.Species.No, s.data.Store.Turn)
+			log.Printf("server: %s %q: species %s turn %d expanded orders %s\n", r.Method, r.URL.Path, u.SpeciesId, turnNumber, expandedFile)
+			if err := ioutil.WriteFile(filepath.Join(uploads, expandedFile), expanded.Text, 0644); err != nil {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+		}
+
 		log.Printf("orders: loading orders file %q\n", ordersFile)
 
-		// parser returns a raw parse tree. we will evaluate it later, maybe.
+		// the report lists the parsed orders and any problems found in the saved file
 		var report bytes.Buffer
-		var errs []error
-		tree, err := parser.Parse(fullOrdersFile, false)
-		if err != nil {
-			errs = append(errs, err)
-		} else if tree == nil {
-			errs = append(errs, fmt.Errorf("parser failed to return orders"))
-		}
-		for _, err := range errs {
-			report.WriteString(fmt.Sprintf("parser: %+v\n", err))
+		o := orders.Parse(expanded.Text)
+		for _, section := range o.Sections {
+			report.WriteString(fmt.Sprintf("START %s ; line %d\n", section.Name, expanded.OriginalLine(section.Line)))
+			for _, order := range section.Orders {
+				report.WriteString(fmt.Sprintf("%6d: %-12s %s\n", expanded.OriginalLine(order.LineNo()), order.Command(), order.Input()))
+			}
+			report.WriteString("END\n\n")
 		}
-		if tree != nil {
-			report.WriteString(fmt.Sprintf("%v\n", *tree))
+		for _, d := range expanded.Check(s.data.Store.OrderNames(u.Species)) {
+			report.WriteString(fmt.Sprintf("check: %s\n", d))
 		}
 
 		reportFile := fmt.Sprintf("sp%02d.t%d.report.txt", u.Species.No, s.data.Store.Turn)
@@ -688,6 +755,10 @@ func (s *Server) postTurnOrders(uploads string) http.HandlerFunc {
 			})
 		}
 
+		if wantsJSON(r) {
+			writeOrdersResponse(w, http.StatusOK, true, ordersFile, diagnostics)
+			return
+		}
 		http.Redirect(w, r, "/", http.StatusFound)
 	}
 }
@@ -724,3 +795,59 @@ func (s *Server) handleHomePage(uploads string) http.HandlerFunc {
 		}
 	}
 }
+
+// postTurnWhatIf dry runs the posted orders for the player's species and
+// returns the log and the projected ships and colonies as JSON.
+// The game data is loaded again for every request and is never saved.
+func (s *Server) postTurnWhatIf() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := currentUser(r)
+		if !u.IsAuthenticated {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		turnNumber, err := strconv.Atoi(way.Param(r.Context(), "turn"))
+		if err != nil || turnNumber != s.data.Store.Turn {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+
+		text, ok, err := readOrdersDocument(r)
+		if !ok {
+			if err = r.ParseForm(); err == nil {
+				text = r.PostForm.Get("orders")
+			}
+		}
+		if err != nil || len(text) < 1 || len(text) > 64*1024 || !utf8.ValidString(text) {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+
+		endian := binary.ByteOrder(binary.LittleEndian)
+		if s.data.BigEndian {
+			endian = binary.BigEndian
+		}
+		e := engine.New(false)
+		if err := e.LoadBinary(s.data.Path, "", endian); err != nil {
+			log.Printf("server: %s %q: %+v\n", r.Method, r.URL.Path, err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		result, err := e.WhatIf(u.Species.No, []byte(text))
+		if err != nil {
+			log.Printf("server: %s %q: species %s turn %d whatif: %+v\n", r.Method, r.URL.Path, u.SpeciesId, turnNumber, err)
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		b, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(b)
+	}
+}
@@ -81,7 +81,7 @@ func run(cfg *config.Config) (errs []error) {
 	if ds, err := datLoader(cfg.Data.Path, cfg.Data.BigEndian); err != nil {
 		return append(errs, err)
 	} else {
-		s.data.Store = ds
+		s.data.Store, s.data.Path, s.data.BigEndian = ds, cfg.Data.Path, cfg.Data.BigEndian
 	}
 	xlatNo := make(map[int]*cluster.Species)
 	for _, sp := range s.data.Store.Species {
@@ -0,0 +1,120 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mdhender/fhcms/internal/cluster"
+	"github.com/mdhender/fhcms/internal/orders"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// orderLine is a line from an orders file along with the problems found on it.
+type orderLine struct {
+	No          int
+	Text        string
+	Diagnostics []*orders.Diagnostic
+}
+
+// checkOrders expands and parses the orders and checks them against the species' data.
+// Line numbers are for the orders as the player entered them.
+func (s *Server) checkOrders(sp *cluster.Species, text string) []*orders.Diagnostic {
+	if sp == nil {
+		return nil
+	}
+	return orders.Expand([]byte(text)).Check(s.data.Store.OrderNames(sp))
+}
+
+// annotateOrders splits the orders into lines and attaches every diagnostic to its line.
+func annotateOrders(text string, diagnostics []*orders.Diagnostic) []*orderLine {
+	var lines []*orderLine
+	for i, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		lines = append(lines, &orderLine{No: i + 1, Text: line})
+	}
+	for _, d := range diagnostics {
+		i := d.Line - 1
+		if i < 0 {
+			i = 0
+		} else if i >= len(lines) {
+			i = len(lines) - 1
+		}
+		lines[i].Diagnostics = append(lines[i].Diagnostics, d)
+	}
+	return lines
+}
+
+// readOrdersDocument returns the body of a request that posts a JSON or YAML orders document.
+// Returns false if the request is not such a post, for example a form post.
+func readOrdersDocument(r *http.Request) (string, bool, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json", "application/yaml", "application/x-yaml", "text/yaml":
+	default:
+		return "", false, nil
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(r.Body, 64*1024+1))
+	if err != nil {
+		return "", true, err
+	} else if len(b) < 1 || len(b) > 64*1024 || !utf8.Valid(b) {
+		return "", true, fmt.Errorf("invalid orders document")
+	}
+	return string(b), true, nil
+}
+
+// wantsJSON returns true if the client asked for a JSON response.
+func wantsJSON(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
+// countErrors returns the number of diagnostics that are errors.
+func countErrors(diagnostics []*orders.Diagnostic) int {
+	n := 0
+	for _, d := range diagnostics {
+		if d.Severity == "error" {
+			n++
+		}
+	}
+	return n
+}
+
+// writeOrdersResponse writes the result of an orders upload as JSON.
+func writeOrdersResponse(w http.ResponseWriter, status int, saved bool, ordersFile string, diagnostics []*orders.Diagnostic) {
+	response := struct {
+		Saved       bool                 `json:"saved"`
+		OrdersFile  string               `json:"orders_file,omitempty"`
+		Diagnostics []*orders.Diagnostic `json:"diagnostics"`
+	}{Saved: saved, OrdersFile: ordersFile, Diagnostics: diagnostics}
+	if response.Diagnostics == nil {
+		response.Diagnostics = []*orders.Diagnostic{}
+	}
+	b, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(b)
+}
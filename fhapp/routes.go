@@ -42,6 +42,7 @@ func (s *Server) routes(reports, uploads string) {
 
 	s.router.HandleFunc("POST", "/api/authenticate", s.handleAuthenticate())
 	s.router.HandleFunc("POST", "/api/turn/:turn/orders", s.postTurnOrders(uploads))
+	s.router.HandleFunc("POST", "/api/turn/:turn/whatif", s.postTurnWhatIf())
 
 	//s.router.HandleFunc("GET", "/api/get-cookie", s.handleGetCookie())
 	//s.router.HandleFunc("GET", "/api/set-cookie", s.handleSetCookie())
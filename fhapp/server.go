@@ -32,13 +32,15 @@ type Server struct {
 	http.Server
 	router *way.Router
 	data   struct {
-		Store   *cluster.Store
-		Engine  *Engine
-		Site    *Site
-		Players []*PlayerData
-		Files   map[string][]*FileData // key is species id
-		Stats   map[string]*StatsData  // key is species id
-		Turn    struct {
+		Store     *cluster.Store
+		Path      string // path to the binary data files that Store was loaded from
+		BigEndian bool
+		Engine    *Engine
+		Site      *Site
+		Players   []*PlayerData
+		Files     map[string][]*FileData // key is species id
+		Stats     map[string]*StatsData  // key is species id
+		Turn      struct {
 			Due      string `json:"due"`
 			By       string `json:"by"`
 			TimeZone string `json:"tmz"`
@@ -22,6 +22,7 @@ import (
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"github.com/mdhender/fhcms/internal/convert"
 	"github.com/mdhender/fhcms/internal/dat32"
 	"io/ioutil"
 	"log"
@@ -77,6 +78,11 @@ func run(root, gFile, sFile, pFile string, bigendian bool) error {
 		species = append(species, sp)
 	}
 
+	locations, err := dat32.ReadLocations(filepath.Join(root, "locations.dat"), bo)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
 	if err := write(filepath.Join(root, "galaxy.json"), galaxy); err != nil {
 		return err
 	}
@@ -92,7 +98,11 @@ func run(root, gFile, sFile, pFile string, bigendian bool) error {
 		}
 	}
 
-	return err
+	cluster, err := convert.ToCluster(convert.FromDat32Records(galaxy, stars, planets, species, locations))
+	if err != nil {
+		return err
+	}
+	return cluster.Write(filepath.Join(root, "cluster.json"))
 }
 
 func write(name string, data interface{}) error {
@@ -26,6 +26,7 @@ import (
 	"github.com/go-chi/jwtauth/v5"
 	"github.com/go-chi/oauth"
 	"github.com/mdhender/fhcms/internal/cluster"
+	"github.com/mdhender/fhcms/internal/orders"
 	"github.com/spf13/viper"
 	"log"
 	"net/http"
@@ -45,6 +46,8 @@ func Router() http.Handler {
 	r.Get("/game/{gameId}", notImplemented)
 	r.Get("/game/{gameId}/turn", apiGetTurn)
 
+	r.Post("/orders/complete", apiCompleteOrder)
+
 	r.Get("/widgets", apiGetWidgets)
 	r.Post("/widgets", apiCreateWidget)
 	r.Post("/widgets/{slug}", apiUpdateWidget)
@@ -225,6 +228,60 @@ func apiGetTurn(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// apiCompleteOrder returns the completions for the word at the cursor in a line of orders.
+// The section is the section that the line is in, or empty if it is not in a section.
+func apiCompleteOrder(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		SpeciesNo int    `json:"species_no"`
+		Section   string `json:"section,omitempty"`
+		Line      string `json:"line"`
+		Cursor    int    `json:"cursor"`
+	}
+	type response struct {
+		Completions []*orders.Completion `json:"completions"`
+	}
+
+	if contentType := r.Header.Get("Content-type"); contentType != "application/json" {
+		http.Error(w, http.StatusText(http.StatusUnsupportedMediaType), http.StatusUnsupportedMediaType)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, 4096)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&input); err != nil {
+		log.Printf("api: %s %q: decode %+v\n", r.Method, r.URL.Path, err)
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	// players may only ask about their own species
+	if _, claims, _ := jwtauth.FromContext(r.Context()); claims != nil {
+		isAdmin, _ := claims["admin"].(bool)
+		if speciesNo, ok := claims["species"].(float64); ok && !isAdmin && int(speciesNo) != input.SpeciesNo {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+	}
+
+	ds, err := loader(viper.GetString("files.path"), viper.GetBool("files.big_endian"))
+	if err != nil {
+		log.Printf("error: %+v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	sp, ok := ds.Species[fmt.Sprintf("SP%02d", input.SpeciesNo)]
+	if !ok {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	rsp := response{Completions: orders.Complete(input.Line, input.Cursor, input.Section, ds.OrderNames(sp))}
+	if rsp.Completions == nil {
+		rsp.Completions = []*orders.Completion{}
+	}
+	renderJSON(w, rsp, http.StatusOK)
+}
+
 func apiGetWidgets(w http.ResponseWriter, r *http.Request) {
 	_, claims, _ := jwtauth.FromContext(r.Context())
 	_, _ = fmt.Fprintf(w, "apiGetWidgets: claims %v\n", claims["species"])
@@ -0,0 +1,202 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+// Package archive keeps a snapshot of every turn. Each turn is saved in its
+// own directory, tNNNN, holding gzip-compressed copies of galaxy.json and the
+// orders and reports for the turn. A turn is never changed once it is saved.
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"github.com/mdhender/fhcms/cms/store/jsondb"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type Archive struct {
+	path string
+}
+
+// Open returns the archive in path. The directory is created by the first Save.
+func Open(path string) *Archive {
+	return &Archive{path: path}
+}
+
+// Save archives the store for its turn along with the orders, which are keyed
+// by species number, and the files, which map the name in the archive (for
+// example "sp18.rpt.t5") to the file to read.
+// It returns an error if the turn has already been saved.
+func (a *Archive) Save(ds *jsondb.Store, orders map[int][]byte, files map[string]string) error {
+	turn := ds.Galaxy.TurnNumber
+	dir := a.turnPath(turn)
+	if _, err := os.Stat(dir); err == nil {
+		return fmt.Errorf("turn %d is already archived", turn)
+	}
+
+	// build the turn in a scratch directory so that a failed save leaves nothing behind
+	tmp := dir + ".tmp"
+	if err := os.RemoveAll(tmp); err != nil {
+		return err
+	} else if err = os.MkdirAll(tmp, 0755); err != nil {
+		return err
+	}
+	b, err := json.Marshal(ds)
+	if err != nil {
+		return err
+	} else if err = writeGzip(filepath.Join(tmp, "galaxy.json.gz"), b); err != nil {
+		return err
+	}
+	for spNo, b := range orders {
+		if err = writeGzip(filepath.Join(tmp, ordersName(spNo)+".gz"), b); err != nil {
+			return err
+		}
+	}
+	for name, file := range files {
+		if name == "galaxy.json" || strings.ContainsAny(name, `/\`) {
+			return fmt.Errorf("invalid archive name %q", name)
+		}
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		} else if err = writeGzip(filepath.Join(tmp, name+".gz"), b); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmp, dir)
+}
+
+// Turns returns the archived turns in order.
+func (a *Archive) Turns() ([]int, error) {
+	entries, err := os.ReadDir(a.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var turns []int
+	for _, entry := range entries {
+		var turn int
+		if !entry.IsDir() || len(entry.Name()) != 5 {
+			continue
+		} else if _, err := fmt.Sscanf(entry.Name(), "t%04d", &turn); err == nil {
+			turns = append(turns, turn)
+		}
+	}
+	sort.Ints(turns)
+	return turns, nil
+}
+
+// HasTurn returns true if the turn has been saved.
+func (a *Archive) HasTurn(turn int) bool {
+	info, err := os.Stat(a.turnPath(turn))
+	return err == nil && info.IsDir()
+}
+
+// Latest returns the most recent archived turn.
+func (a *Archive) Latest() (int, error) {
+	turns, err := a.Turns()
+	if err != nil {
+		return 0, err
+	} else if len(turns) == 0 {
+		return 0, fmt.Errorf("archive is empty")
+	}
+	return turns[len(turns)-1], nil
+}
+
+// Load returns the store as of the turn.
+func (a *Archive) Load(turn int) (*jsondb.Store, error) {
+	b, err := a.File(turn, "galaxy.json")
+	if err != nil {
+		return nil, err
+	}
+	var ds jsondb.Store
+	if err = json.Unmarshal(b, &ds); err != nil {
+		return nil, err
+	}
+	return &ds, nil
+}
+
+// Species returns the species as of the turn.
+func (a *Archive) Species(spNo, turn int) (*jsondb.SpeciesData, error) {
+	ds, err := a.Load(turn)
+	if err != nil {
+		return nil, err
+	}
+	for _, sp := range ds.Species {
+		if sp.Id == spNo {
+			return sp, nil
+		}
+	}
+	return nil, fmt.Errorf("turn %d: no such species %d", ds.Galaxy.TurnNumber, spNo)
+}
+
+// Orders returns the orders that were executed for the species for the turn.
+// These include the orders that the engine generated for a species that did
+// not submit any.
+func (a *Archive) Orders(spNo, turn int) ([]byte, error) {
+	return a.File(turn, ordersName(spNo))
+}
+
+// Report returns the report for the species for the turn.
+func (a *Archive) Report(spNo, turn int) ([]byte, error) {
+	return a.File(turn, fmt.Sprintf("sp%02d.rpt.t%d", spNo, turn))
+}
+
+// File returns the uncompressed contents of a file saved with the turn.
+func (a *Archive) File(turn int, name string) ([]byte, error) {
+	r, err := os.Open(filepath.Join(a.turnPath(turn), name+".gz"))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return ioutil.ReadAll(zr)
+}
+
+// ordersName returns the name of the orders for a species in the archive.
+func ordersName(spNo int) string {
+	return fmt.Sprintf("sp%02d.ord", spNo)
+}
+
+func (a *Archive) turnPath(turn int) string {
+	return filepath.Join(a.path, fmt.Sprintf("t%04d", turn))
+}
+
+// writeGzip writes a compressed, read-only file.
+func writeGzip(name string, b []byte) error {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Name = strings.TrimSuffix(filepath.Base(name), ".gz")
+	if _, err := zw.Write(b); err != nil {
+		return err
+	} else if err = zw.Close(); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(name, buf.Bytes(), 0444)
+}
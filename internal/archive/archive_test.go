@@ -0,0 +1,96 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package archive
+
+import (
+	"github.com/mdhender/fhcms/cms/store/jsondb"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestArchive saves two turns and reads them back.
+func TestArchive(t *testing.T) {
+	dir := t.TempDir()
+	a := Open(filepath.Join(dir, "archive"))
+
+	if turns, err := a.Turns(); err != nil || len(turns) != 0 {
+		t.Errorf("empty: turns: got %v %v, want none", turns, err)
+	}
+	if _, err := a.Latest(); err == nil || err.Error() != "archive is empty" {
+		t.Errorf("empty: latest: got %v, want %q", err, "archive is empty")
+	}
+
+	report := filepath.Join(dir, "sp01.rpt.t5")
+	if err := ioutil.WriteFile(report, []byte("report for turn 5\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ds := &jsondb.Store{Species: []*jsondb.SpeciesData{{Id: 1, Name: "Alpha"}}}
+	for _, turn := range []int{5, 4} {
+		ds.Galaxy.TurnNumber = turn
+		files := map[string]string{}
+		if turn == 5 {
+			files["sp01.rpt.t5"] = report
+		}
+		if err := a.Save(ds, map[int][]byte{1: []byte("START PRODUCTION\nEND\n")}, files); err != nil {
+			t.Fatalf("turn %d: save: %v", turn, err)
+		}
+	}
+
+	ds.Galaxy.TurnNumber, ds.Species[0].Name = 5, "Changed"
+	if err := a.Save(ds, nil, nil); err == nil || err.Error() != "turn 5 is already archived" {
+		t.Errorf("duplicate turn: got %v, want %q", err, "turn 5 is already archived")
+	}
+
+	if turns, err := a.Turns(); err != nil || len(turns) != 2 || turns[0] != 4 || turns[1] != 5 {
+		t.Errorf("turns: got %v %v, want [4 5]", turns, err)
+	}
+	if latest, err := a.Latest(); err != nil || latest != 5 {
+		t.Errorf("latest: got %d %v, want 5", latest, err)
+	}
+	if !a.HasTurn(4) || a.HasTurn(6) {
+		t.Errorf("has turn: got %v for 4 and %v for 6", a.HasTurn(4), a.HasTurn(6))
+	}
+
+	loaded, err := a.Load(5)
+	if err != nil {
+		t.Fatal(err)
+	} else if loaded.Galaxy.TurnNumber != 5 || len(loaded.Species) != 1 || loaded.Species[0].Name != "Alpha" {
+		t.Errorf("load: got turn %d with %d species", loaded.Galaxy.TurnNumber, len(loaded.Species))
+	}
+	if sp, err := a.Species(1, 4); err != nil || sp.Name != "Alpha" {
+		t.Errorf("species: got %v %v, want Alpha", sp, err)
+	}
+	if _, err := a.Species(2, 4); err == nil || !strings.Contains(err.Error(), "no such species 2") {
+		t.Errorf("missing species: got %v", err)
+	}
+	if b, err := a.Orders(1, 4); err != nil || string(b) != "START PRODUCTION\nEND\n" {
+		t.Errorf("orders: got %q %v", b, err)
+	}
+	if b, err := a.Report(1, 5); err != nil || string(b) != "report for turn 5\n" {
+		t.Errorf("report: got %q %v", b, err)
+	}
+	if _, err := a.Report(1, 4); err == nil {
+		t.Errorf("missing report: got no error")
+	}
+	if _, err := a.Load(6); err == nil {
+		t.Errorf("missing turn: got no error")
+	}
+}
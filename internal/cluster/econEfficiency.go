@@ -19,22 +19,24 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 package cluster
 
 // UpdateEconEfficiency will recalculate the economic efficiencies of all planets.
+// Home planets do not count towards the total economic base of a planet,
+// and planets without any colonies are reset to 100.
 func (ds *Store) UpdateEconEfficiency() {
 	// calculate the total economic base for each planet from named planet data
 	totalEconBase := make(map[string]int)
 	for _, sp := range ds.Species {
 		for _, np := range sp.NamedPlanets.ById {
-			if np.Colony != nil {
+			if np.Colony != nil && !np.Colony.Is.HomePlanet {
 				totalEconBase[np.Planet.Id] = totalEconBase[np.Planet.Id] + np.Colony.Mining.Base + np.Colony.Manufacturing.Base
 			}
 		}
 	}
 	// recalculate economic efficiencies of all planets
-	for id, base := range totalEconBase {
+	for id, planet := range ds.Planets {
 		econEfficiency := 100
-		if base > 2000 {
+		if base := totalEconBase[id]; base > 2000 {
 			econEfficiency = (100 * ((base-2000)/20 + 2000)) / base
 		}
-		ds.Planets[id].EconEfficiency = econEfficiency
+		planet.EconEfficiency = econEfficiency
 	}
 }
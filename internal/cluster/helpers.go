@@ -212,3 +212,11 @@ func power(tonnage int) int {
 	t2 := tonnage - t1
 	return 1149 * (power(t1) + power(t2)) / 1000
 }
+
+func sameOrbit(a, b *Coords) bool {
+	return a != nil && b != nil && a.X == b.X && a.Y == b.Y && a.Z == b.Z && a.Orbit == b.Orbit
+}
+
+func sameSystem(a, b *Coords) bool {
+	return a != nil && b != nil && a.X == b.X && a.Y == b.Y && a.Z == b.Z
+}
@@ -69,6 +69,16 @@ func FromDat32(path string, bigEndian bool) (*Store, error) {
 		speciesData = append(speciesData, sp)
 	}
 
+	ld, err := dat32.ReadLocations(locationDataFile, bo)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromDat32Records(galaxyData, starData, planetData, speciesData, ld)
+}
+
+// FromDat32Records builds the store from records that are already in memory.
+func FromDat32Records(galaxyData *dat32.Galaxy, starData *dat32.Stars, planetData *dat32.Planets, speciesData []*dat32.Species, ld []dat32.SpLocData) (*Store, error) {
 	ds := &Store{
 		Systems:     make(map[string]*System),
 		Planets:     make(map[string]*Planet),
@@ -377,10 +387,6 @@ func FromDat32(path string, bigEndian bool) (*Store, error) {
 		sp.Scanned = append(sp.Scanned, sp.Visited...)
 	}
 
-	ld, err := dat32.ReadLocations(locationDataFile, bo)
-	if err != nil {
-		return nil, err
-	}
 	ds.Locations = make([]SpeciesLocationData, len(ld), len(ld))
 	for i, loc := range ld {
 		ds.Locations[i].Species = ds.SpeciesBase[loc.S]
@@ -1,6 +1,6 @@
 /*******************************************************************************
 Far Horizons Engine
-Copyright (C) 2021  Michael D Henderson
+Copyright (C) 2022  Michael D Henderson
 
 This program is free software: you can redistribute it and/or modify
 it under the terms of the GNU Affero General Public License as published
@@ -16,42 +16,29 @@ You should have received a copy of the GNU Affero General Public License
 along with this program.  If not, see <https://www.gnu.org/licenses/>.
 ******************************************************************************/
 
-package parser
+package cluster
 
-type Node struct {
-	Parent   *Node
-	Children []*Node
-	Token    *Token
-}
+import "github.com/mdhender/fhcms/internal/orders"
 
-func (n *Node) firstChildOfType(t TokenType) *Node {
-	if n != nil {
-		for _, c := range n.Children {
-			if c.Token.Type == t {
-				return c
-			}
-		}
+// OrderNames returns the names needed to check the orders for a species.
+func (ds *Store) OrderNames(sp *Species) *orders.Names {
+	names := &orders.Names{}
+	for _, ship := range sp.Fleet.Base {
+		names.Ships = append(names.Ships, ship.Name)
+		names.ShipIds = append(names.ShipIds, ship.Display.Name)
 	}
-	return nil
-}
-
-func (n *Node) String() string {
-	return n.prettyPrint(1)
-}
-
-func (n *Node) prettyPrint(l int) string {
-	var s string
-	for i := 0; i < l; i++ {
-		s += " "
-	}
-	if n.Token != nil {
-		s += n.Token.String()
+	for _, np := range sp.NamedPlanets.Base {
+		if np != nil {
+			names.Planets = append(names.Planets, np.Display.Name)
+		}
 	}
-	if n.Children != nil {
-		s += "\n"
-		for _, c := range n.Children {
-			s += c.prettyPrint(l + 3)
+	for _, o := range ds.SpeciesBase {
+		if o != nil {
+			names.Species = append(names.Species, o.Name)
 		}
 	}
-	return s + "\n"
+	for _, o := range sp.Contact {
+		names.Contacts = append(names.Contacts, o.Name)
+	}
+	return names
 }
@@ -18,6 +18,11 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 
 package cluster
 
+import (
+	"fmt"
+	"io"
+)
+
 type TechLevel struct {
 	Name, Code      string
 	Total, Min, Max int
@@ -329,3 +334,52 @@ func (ds *Store) Stats() *GameStats {
 
 	return gameStats
 }
+
+// Write prints the statistics in the format of the original Stats program.
+func (game *GameStats) Write(w io.Writer) {
+	if game.TotalSpecies == 0 {
+		return
+	}
+
+	// m attempts to compensate for rounding integer values down
+	m := game.TotalSpecies / 2
+
+	fmt.Fprintf(w, "SP Species               Tech Levels        Total  Num Num  Num  Offen.  Defen.  Econ\n")
+	fmt.Fprintf(w, " # Name             MI  MA  ML  GV  LS  BI  Prod.  Pls Shps Yrds  Power   Power  Units\n")
+	fmt.Fprintf(w, "----------------------------------------------------------------------------------------\n")
+	for _, sp := range game.Stats {
+		fmt.Fprintf(w, "%2d %-15.15s%4d%4d%4d%4d%4d%4d%7.0f%4.0f%5.0f%5.0f%8.0f%8.0f%9.0f\n", sp.No, sp.Name, sp.MI, sp.MA, sp.ML, sp.GV, sp.LS, sp.BI, sp.Production, sp.PopulatedPlanets, sp.Ships, sp.Shipyards, sp.OffensivePower, sp.DefensivePower, sp.BankedEconUnits)
+	}
+	fmt.Fprintln(w, "")
+	fmt.Fprintf(w, "Average %-13s tech level = %3d (min = %3d, max = %3d)\n", game.MI.Name, (game.MI.Total+m)/game.TotalSpecies, game.MI.Min, game.MI.Max)
+	fmt.Fprintf(w, "Average %-13s tech level = %3d (min = %3d, max = %3d)\n", game.MA.Name, (game.MA.Total+m)/game.TotalSpecies, game.MA.Min, game.MA.Max)
+	fmt.Fprintf(w, "Average %-13s tech level = %3d (min = %3d, max = %3d)\n", game.ML.Name, (game.ML.Total+m)/game.TotalSpecies, game.ML.Min, game.ML.Max)
+	fmt.Fprintf(w, "Average %-13s tech level = %3d (min = %3d, max = %3d)\n", game.GV.Name, (game.GV.Total+m)/game.TotalSpecies, game.GV.Min, game.GV.Max)
+	fmt.Fprintf(w, "Average %-13s tech level = %3d (min = %3d, max = %3d)\n", game.LS.Name, (game.LS.Total+m)/game.TotalSpecies, game.LS.Min, game.LS.Max)
+	fmt.Fprintf(w, "Average %-13s tech level = %3d (min = %3d, max = %3d)\n", game.BI.Name, (game.BI.Total+m)/game.TotalSpecies, game.BI.Min, game.BI.Max)
+	fmt.Fprintln(w, "")
+	fmt.Fprintf(w, "Average number of warships per species          %9.1f (min = %6.0f max = %6.0f)\n", game.Warships.Average, game.Warships.Min, game.Warships.Max)
+	fmt.Fprintf(w, "Average total warship tonnage per species       %9s tons\n", commas(int(game.WarshipTonnage.Average)))
+	fmt.Fprintf(w, "Average warship size                            %9s tons\n", commas(averageSize(game.WarshipTonnage.Total, game.Warships.Total)))
+	fmt.Fprintln(w, "")
+	fmt.Fprintf(w, "Average number of starbases per species         %9.1f (min = %6.0f max = %6.0f)\n", game.Starbases.Average, game.Starbases.Min, game.Starbases.Max)
+	fmt.Fprintf(w, "Average total starbase tonnage per species      %9s tons\n", commas(int(game.StarbaseTonnage.Average)))
+	fmt.Fprintf(w, "Average starbase size                           %9s tons\n", commas(averageSize(game.StarbaseTonnage.Total, game.Starbases.Total)))
+	fmt.Fprintln(w, "")
+	fmt.Fprintf(w, "Average number of transports per species        %9.1f (min = %6.0f max = %6.0f)\n", game.Transports.Average, game.Transports.Min, game.Transports.Max)
+	fmt.Fprintf(w, "Average total transport tonnage per species     %9s tons\n", commas(int(game.TransportTonnage.Average)))
+	fmt.Fprintf(w, "Average transport size                          %9s tons\n", commas(averageSize(game.TransportTonnage.Total, game.Transports.Total)))
+	fmt.Fprintln(w, "")
+	fmt.Fprintf(w, "Average number of shipyards per species         %9.1f (min = %6.0f max = %6.0f)\n", game.Shipyards.Average, game.Shipyards.Min, game.Shipyards.Max)
+	fmt.Fprintf(w, "Average number of populated planets per species %9.1f (min = %6.0f max = %6.0f)\n", game.PopulatedPlanets.Average, game.PopulatedPlanets.Min, game.PopulatedPlanets.Max)
+	fmt.Fprintf(w, "Average total production per species            %9.1f (min = %6.0f max = %6.0f)\n", game.Production.Average, game.Production.Min, game.Production.Max)
+	fmt.Fprintf(w, "Average banked economic units per species       %9.1f (min = %6.0f max = %6.0f)\n", game.BankedEconUnits.Average, game.BankedEconUnits.Min, game.BankedEconUnits.Max)
+}
+
+// averageSize returns the average tonnage per ship, or zero if there are no ships.
+func averageSize(tonnage, ships float64) int {
+	if ships == 0 {
+		return 0
+	}
+	return int(tonnage / ships)
+}
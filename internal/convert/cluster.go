@@ -0,0 +1,797 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mdhender/fhcms/cms/store/jsondb"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+// Cluster is the cluster.json format. It merges the data files into a
+// tree of systems, planets, and colonies, along with the species and their
+// named planets and ships, so that clients don't have to follow indexes.
+// The indexes are kept so that the cluster converts back without loss.
+type Cluster struct {
+	Semver      string                  `json:"semver,omitempty"`
+	Turn        int                     `json:"turn"`          // current turn number
+	Radius      int                     `json:"radius"`        // radius of the cluster
+	DNumSpecies int                     `json:"d_num_species"` // number of species the cluster was designed for
+	Systems     map[string]*SystemData  `json:"systems"`       // key is coordinates of the system
+	Planets     map[string]*PlanetData  `json:"planets"`       // key is coordinates of the planet
+	Species     map[string]*SpeciesData `json:"species"`       // key is species id
+	Locations   []jsondb.Location       `json:"locations"`
+}
+
+type SystemData struct {
+	Index  int     `json:"index"`  // index of the star in stars.dat
+	Coords *Coords `json:"coords"` // location of the star within the cluster
+	Color  struct {
+		DisplayCode string `json:"display_code"`
+		Description string `json:"description"`
+	} `json:"color"`
+	Message             int      `json:"message,omitempty"`
+	PlanetIndex         int      `json:"planet_index"`                    // index of the first planet in planets.dat
+	Planets             []string `json:"planets"`                         // list of identifiers for each planet, ordered by orbit
+	PotentialHomeSystem bool     `json:"potential_home_system,omitempty"` // true if the system is a good potential home system
+	Size                int      `json:"size"`
+	Type                struct {
+		DisplayCode string `json:"display_code"`
+		Description string `json:"description"`
+	} `json:"type"`
+	VisitedBy []string `json:"visited_by"`         // list of identifiers for every species that has visited the system
+	Wormhole  *Coords  `json:"wormhole,omitempty"` // coordinates of other end of wormhole, nil if not a wormhole
+}
+
+type PlanetData struct {
+	Index                    int                    `json:"index"`  // index of the planet in planets.dat
+	Coords                   *Coords                `json:"coords"` // location of the planet within the cluster
+	System                   string                 `json:"system"` // identifier for system containing the planet
+	Atmosphere               []GasType              `json:"atmosphere"`
+	Colonies                 map[string]*ColonyData `json:"colonies"` // key is species id
+	Diameter                 int                    `json:"diameter"`
+	EconEfficiency           int                    `json:"econ_efficiency"`
+	Gravity                  int                    `json:"gravity"`
+	Message                  int                    `json:"message"`
+	MiningDifficulty         int                    `json:"mining_difficulty"`
+	MiningDifficultyIncrease int                    `json:"mining_difficulty_increase"`
+	PressureClass            int                    `json:"pressure_class"`
+	TemperatureClass         int                    `json:"temperature_class"`
+	Special                  string                 `json:"special,omitempty"`
+}
+
+type GasType struct {
+	Code       string `json:"code"`
+	Percentage int    `json:"percentage"`
+}
+
+type SpeciesData struct {
+	Name       string `json:"name"` // name of the species
+	Government struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+	} `json:"government"`
+	HomeWorld  string   `json:"home_world"` // coordinates of the home world
+	Ally       []string `json:"ally"`
+	AutoOrders bool     `json:"auto_orders,omitempty"`
+	Colonies   []string `json:"colonies"` // coordinates of the planet containing the colony
+	Contact    []string `json:"contact"`
+	EconUnits  int      `json:"econ_units"`
+	Enemy      []string `json:"enemy"`
+	Fleet      struct {
+		Cost           int `json:"cost"`
+		MaintenancePct int `json:"maintenance_pct"` // percentage of production applied to fleet maintenance
+	} `json:"fleet"`
+	Gases struct {
+		Required struct {
+			Code   string `json:"code"`
+			MinPct int    `json:"min_pct"`
+			MaxPct int    `json:"max_pct"`
+		} `json:"required"`
+		Neutral []string `json:"neutral"`
+		Poison  []string `json:"poison"`
+	} `json:"gases"`
+	HPOriginalBase  int                         `json:"hp_original_base"`
+	NamedPlanets    map[string]*NamedPlanetData `json:"named_planets"`     // key is name of planet, converted to upper case
+	NumNamedPlanets int                         `json:"num_named_planets"` // number of named planet records, including deleted ones
+	NumShips        int                         `json:"num_ships"`         // number of ship records, including deleted ones
+	Scanned         []string                    `json:"scanned"`           // coordinates of all systems that have been scanned
+	Ships           map[string]*ShipData        `json:"ships"`             // key is name of ship, converted to upper case
+	Tech            struct {
+		MI TechLevelData `json:"mi"`
+		MA TechLevelData `json:"ma"`
+		ML TechLevelData `json:"ml"`
+		GV TechLevelData `json:"gv"`
+		LS TechLevelData `json:"ls"`
+		BI TechLevelData `json:"bi"`
+	} `json:"tech"`
+	Visited []string `json:"visited"` // coordinates of all systems that have been visited
+}
+
+type NamedPlanetData struct {
+	Index       int    `json:"index"`        // index of the record in the species data file
+	Planet      string `json:"planet"`       // coordinates of the planet being named
+	DisplayName string `json:"display_name"` // original name of the planet
+}
+
+type ColonyData struct {
+	Status struct {
+		Colony          bool `json:"colony"`
+		DisbandedColony bool `json:"disbanded_colony,omitempty"`
+		Hiding          bool `json:"hiding,omitempty"`
+		Hidden          bool `json:"hidden,omitempty"`
+		HomePlanet      bool `json:"home_planet,omitempty"`
+		MiningColony    bool `json:"mining_colony,omitempty"`
+		Populated       bool `json:"populated,omitempty"`
+		ResortColony    bool `json:"resort_colony,omitempty"`
+	} `json:"status"`
+	Inventory   map[string]int `json:"inventory"` // key is item code, value is quantity
+	MaBase      int            `json:"ma_base"`
+	Message     int            `json:"message"`
+	MiBase      int            `json:"mi_base"`
+	PlanetIndex int            `json:"planet_index"`
+	PopUnits    int            `json:"pop_units"`
+	SiegeEff    int            `json:"siege_eff"`
+	Shipyards   int            `json:"shipyards"`
+	Special     int            `json:"special,omitempty"`
+	Units       struct {
+		Manufacturing UnitsData `json:"manufacturing"`
+		Mining        UnitsData `json:"mining"`
+	} `json:"units"`
+	UseOnAmbush int `json:"use_on_ambush"`
+}
+
+type UnitsData struct {
+	Auto               int `json:"auto,omitempty"` // number of units to install automatically
+	AvailableToInstall int `json:"available_to_install,omitempty"`
+	Needed             int `json:"needed,omitempty"`
+}
+
+type ShipData struct {
+	Index       int    `json:"index"`        // index of the record in the species data file
+	DisplayName string `json:"display_name"` // original name of the ship
+	Class       struct {
+		Code        string `json:"code"`
+		Description string `json:"description"`
+		Tonnage     int    `json:"tonnage"` // tonnage divided by 10,000
+		Cost        int    `json:"cost"`
+	} `json:"class"`
+	Type               string         `json:"type"` // FTL, SUB_LIGHT, or STARBASE
+	Age                int            `json:"age"`
+	Location           *Coords        `json:"location,omitempty"`
+	ArrivedViaWormhole bool           `json:"arrived_via_wormhole,omitempty"`
+	Destination        *Coords        `json:"destination,omitempty"`
+	Inventory          map[string]int `json:"inventory"` // key is item code, value is quantity
+	JustJumped         bool           `json:"just_jumped,omitempty"`
+	LoadingPoint       int            `json:"loading_point,omitempty"`
+	UnloadingPoint     int            `json:"unloading_point,omitempty"`
+	RemainingCost      int            `json:"remaining_cost,omitempty"`
+	Special            int            `json:"special,omitempty"`
+	Status             string         `json:"status"`
+}
+
+type TechLevelData struct {
+	Code             string `json:"code"`
+	Level            int    `json:"level"`             // current level
+	InitLevel        int    `json:"init_level"`        // level at the start of the turn
+	KnowledgeLevel   int    `json:"knowledge_level"`   // un-applied tech level knowledge
+	ExperiencePoints int    `json:"experience_points"` // experience points for tech levels
+}
+
+type Coords struct {
+	X     int `json:"x"`
+	Y     int `json:"y"`
+	Z     int `json:"z"`
+	Orbit int `json:"orbit,omitempty"`
+}
+
+func (c *Coords) Id() string {
+	if c.Orbit != 0 {
+		return fmt.Sprintf("%d.%d.%d.%d", c.X, c.Y, c.Z, c.Orbit)
+	}
+	return fmt.Sprintf("%d.%d.%d", c.X, c.Y, c.Z)
+}
+
+// parseCoords returns the coordinates for an identifier.
+// It is the inverse of Coords.Id.
+func parseCoords(id string) (*Coords, error) {
+	var c Coords
+	if n, _ := fmt.Sscanf(id, "%d.%d.%d.%d", &c.X, &c.Y, &c.Z, &c.Orbit); n < 3 {
+		return nil, fmt.Errorf("invalid coordinates %q", id)
+	}
+	return &c, nil
+}
+
+var shipTypes = []string{"FTL", "SUB_LIGHT", "STARBASE"}
+var shipStatuses = []string{"UNDER_CONSTRUCTION", "ON_SURFACE", "IN_ORBIT", "IN_DEEP_SPACE", "JUMPED_IN_COMBAT", "FORCED_JUMP"}
+
+// isDeleted returns true if the engine has deleted the named planet or ship.
+func isDeleted(name string, pn int) bool {
+	return pn == 99 && strings.EqualFold(name, "Unused")
+}
+
+// ReadCluster loads a cluster from a JSON file.
+func ReadCluster(filename string) (*Cluster, error) {
+	var c Cluster
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	} else if err = json.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Write saves the cluster as a JSON file.
+func (c *Cluster) Write(filename string) error {
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if filename == "*stdout*" {
+		fmt.Println(string(b))
+		return nil
+	}
+	return ioutil.WriteFile(filename, b, 0644)
+}
+
+// ToCluster converts a store to a cluster.
+// It returns an error if a record can't be placed in the tree, for example
+// a named planet that isn't in a system or two ships with the same name.
+func ToCluster(ds *jsondb.Store) (*Cluster, error) {
+	c := &Cluster{
+		Semver:      ds.Version,
+		Turn:        ds.Galaxy.TurnNumber,
+		Radius:      ds.Galaxy.Radius,
+		DNumSpecies: ds.Galaxy.DNumSpecies,
+		Systems:     make(map[string]*SystemData),
+		Planets:     make(map[string]*PlanetData),
+		Species:     make(map[string]*SpeciesData),
+		Locations:   append([]jsondb.Location{}, ds.Locations...),
+	}
+
+	for i, star := range ds.Stars {
+		cs := &SystemData{
+			Index:               i,
+			Coords:              &Coords{X: star.X, Y: star.Y, Z: star.Z},
+			Message:             star.Message,
+			PlanetIndex:         star.PlanetIndex,
+			Planets:             []string{},
+			PotentialHomeSystem: star.HomeSystem != 0,
+			Size:                star.Size,
+			VisitedBy:           []string{},
+		}
+		cs.Color.DisplayCode = toCode(starColorCodes, "color", star.Color)
+		cs.Color.Description = toCode(starColorNames, "color", star.Color)
+		cs.Type.DisplayCode = toCode(starTypeCodes, "type", star.Type)
+		cs.Type.Description = toCode(starTypeNames, "type", star.Type)
+		if star.WormHere != 0 {
+			cs.Wormhole = &Coords{X: star.WormX, Y: star.WormY, Z: star.WormZ}
+		}
+		for _, spNo := range star.VisitedBy {
+			cs.VisitedBy = append(cs.VisitedBy, speciesId(spNo))
+		}
+		if _, ok := c.Systems[cs.Coords.Id()]; ok {
+			return nil, fmt.Errorf("star %d: duplicate system %s", i, cs.Coords.Id())
+		}
+		c.Systems[cs.Coords.Id()] = cs
+
+		for orbit := 1; orbit <= star.NumPlanets; orbit++ {
+			index := star.PlanetIndex + orbit - 1
+			if index < 0 || index >= len(ds.Planets) {
+				return nil, fmt.Errorf("star %d: planet index %d out of range", i, index)
+			}
+			planet := ds.Planets[index]
+			cp := &PlanetData{
+				Index:                    index,
+				Coords:                   &Coords{X: star.X, Y: star.Y, Z: star.Z, Orbit: orbit},
+				System:                   cs.Coords.Id(),
+				Atmosphere:               []GasType{},
+				Colonies:                 make(map[string]*ColonyData),
+				Diameter:                 planet.Diameter,
+				EconEfficiency:           planet.EconEfficiency,
+				Gravity:                  planet.Gravity,
+				Message:                  planet.Message,
+				MiningDifficulty:         planet.MiningDifficulty,
+				MiningDifficultyIncrease: planet.MdIncrease,
+				PressureClass:            planet.PressureClass,
+				TemperatureClass:         planet.TemperatureClass,
+				Special:                  toCode(specialCodes, "special", planet.Special),
+			}
+			for g := range planet.Gas {
+				if planet.Gas[g] != 0 || planet.GasPercent[g] != 0 {
+					cp.Atmosphere = append(cp.Atmosphere, GasType{Code: toCode(gasCodes, "gas", planet.Gas[g]), Percentage: planet.GasPercent[g]})
+				}
+			}
+			c.Planets[cp.Coords.Id()] = cp
+			cs.Planets = append(cs.Planets, cp.Coords.Id())
+		}
+	}
+	if len(c.Planets) != len(ds.Planets) {
+		return nil, fmt.Errorf("stars hold %d planets but the store has %d", len(c.Planets), len(ds.Planets))
+	}
+
+	for _, sp := range ds.Species {
+		id := speciesId(sp.Id)
+		cs := &SpeciesData{
+			Name:            sp.Name,
+			HomeWorld:       (&Coords{X: sp.X, Y: sp.Y, Z: sp.Z, Orbit: sp.Pn}).Id(),
+			Ally:            []string{},
+			AutoOrders:      sp.AutoOrders != 0,
+			Colonies:        []string{},
+			Contact:         []string{},
+			EconUnits:       sp.EconUnits,
+			Enemy:           []string{},
+			HPOriginalBase:  sp.HpOriginalBase,
+			NamedPlanets:    make(map[string]*NamedPlanetData),
+			NumNamedPlanets: len(sp.Namplas),
+			NumShips:        len(sp.Ships),
+			Scanned:         []string{},
+			Ships:           make(map[string]*ShipData),
+			Visited:         []string{},
+		}
+		cs.Government.Name = sp.GovtName
+		cs.Government.Type = sp.GovtType
+		for _, spNo := range sp.Ally {
+			cs.Ally = append(cs.Ally, speciesId(spNo))
+		}
+		for _, spNo := range sp.Contact {
+			cs.Contact = append(cs.Contact, speciesId(spNo))
+		}
+		for _, spNo := range sp.Enemy {
+			cs.Enemy = append(cs.Enemy, speciesId(spNo))
+		}
+		cs.Fleet.Cost = sp.FleetCost
+		cs.Fleet.MaintenancePct = sp.FleetPercentCost
+		cs.Gases.Required.Code = toCode(gasCodes, "gas", sp.RequiredGas)
+		cs.Gases.Required.MinPct = sp.RequiredGasMin
+		cs.Gases.Required.MaxPct = sp.RequiredGasMax
+		cs.Gases.Neutral, cs.Gases.Poison = []string{}, []string{}
+		for _, gas := range sp.NeutralGas {
+			if gas != 0 {
+				cs.Gases.Neutral = append(cs.Gases.Neutral, toCode(gasCodes, "gas", gas))
+			}
+		}
+		for _, gas := range sp.PoisonGas {
+			if gas != 0 {
+				cs.Gases.Poison = append(cs.Gases.Poison, toCode(gasCodes, "gas", gas))
+			}
+		}
+		for tech, t := range []*TechLevelData{&cs.Tech.MI, &cs.Tech.MA, &cs.Tech.ML, &cs.Tech.GV, &cs.Tech.LS, &cs.Tech.BI} {
+			*t = TechLevelData{
+				Code:             techCodes[tech],
+				Level:            sp.TechLevel[tech],
+				InitLevel:        sp.InitTechLevel[tech],
+				KnowledgeLevel:   sp.TechKnowledge[tech],
+				ExperiencePoints: sp.TechEps[tech],
+			}
+		}
+
+		for n, np := range sp.Namplas {
+			if isDeleted(np.Name, np.Pn) {
+				continue
+			}
+			coords := &Coords{X: np.X, Y: np.Y, Z: np.Z, Orbit: np.Pn}
+			planet, ok := c.Planets[coords.Id()]
+			if !ok {
+				return nil, fmt.Errorf("%s: named planet %q: %s is not a planet", id, np.Name, coords.Id())
+			} else if _, ok = planet.Colonies[id]; ok {
+				return nil, fmt.Errorf("%s: named planet %q: planet %s is already named", id, np.Name, coords.Id())
+			}
+			key := strings.ToUpper(np.Name)
+			if _, ok = cs.NamedPlanets[key]; ok {
+				return nil, fmt.Errorf("%s: duplicate named planet %q", id, np.Name)
+			}
+			cs.NamedPlanets[key] = &NamedPlanetData{Index: n, Planet: coords.Id(), DisplayName: np.Name}
+
+			cc := &ColonyData{
+				Inventory:   inventory(np.ItemQuantity),
+				MaBase:      np.MaBase,
+				Message:     np.Message,
+				MiBase:      np.MiBase,
+				PlanetIndex: np.PlanetIndex,
+				PopUnits:    np.PopUnits,
+				SiegeEff:    np.SiegeEff,
+				Shipyards:   np.Shipyards,
+				Special:     np.Special,
+				UseOnAmbush: np.UseOnAmbush,
+			}
+			cc.Status.HomePlanet = (np.Status & jsondb.HOME_PLANET) != 0
+			cc.Status.Colony = (np.Status & jsondb.COLONY) != 0
+			cc.Status.Populated = (np.Status & jsondb.POPULATED) != 0
+			cc.Status.MiningColony = (np.Status & jsondb.MINING_COLONY) != 0
+			cc.Status.ResortColony = (np.Status & jsondb.RESORT_COLONY) != 0
+			cc.Status.DisbandedColony = (np.Status & jsondb.DISBANDED_COLONY) != 0
+			cc.Status.Hiding = np.Hiding != 0
+			cc.Status.Hidden = np.Hidden != 0
+			cc.Units.Manufacturing = UnitsData{Auto: np.AutoAUs, AvailableToInstall: np.AUsToInstall, Needed: np.AUsNeeded}
+			cc.Units.Mining = UnitsData{Auto: np.AutoIUs, AvailableToInstall: np.IUsToInstall, Needed: np.IUsNeeded}
+			planet.Colonies[id] = cc
+			if cc.Status.Colony || cc.Status.HomePlanet {
+				cs.Colonies = append(cs.Colonies, coords.Id())
+			}
+		}
+
+		for n, ship := range sp.Ships {
+			if isDeleted(ship.Name, ship.Pn) {
+				continue
+			}
+			key := strings.ToUpper(ship.Name)
+			if _, ok := cs.Ships[key]; ok {
+				return nil, fmt.Errorf("%s: duplicate ship %q", id, ship.Name)
+			}
+			cc := &ShipData{
+				Index:              n,
+				DisplayName:        ship.Name,
+				Type:               toCode(shipTypes, "type", ship.Type),
+				Age:                ship.Age,
+				Location:           &Coords{X: ship.X, Y: ship.Y, Z: ship.Z, Orbit: ship.Pn},
+				ArrivedViaWormhole: ship.ArrivedViaWormhole != 0,
+				Inventory:          inventory(ship.ItemQuantity),
+				JustJumped:         ship.JustJumped != 0,
+				LoadingPoint:       ship.LoadingPoint,
+				UnloadingPoint:     ship.UnloadingPoint,
+				RemainingCost:      ship.RemainingCost,
+				Special:            ship.Special,
+				Status:             toCode(shipStatuses, "status", ship.Status),
+			}
+			cc.Class.Code = toCode(shipClassCodes, "class", ship.Class)
+			cc.Class.Description = toCode(shipClassNames, "class", ship.Class)
+			cc.Class.Tonnage = ship.Tonnage
+			if ship.Type == 1 { // sub-light ships are cheaper
+				cc.Class.Cost = ship.Tonnage * 75
+			} else {
+				cc.Class.Cost = ship.Tonnage * 100
+			}
+			if ship.DestX != 0 || ship.DestY != 0 || ship.DestZ != 0 {
+				cc.Destination = &Coords{X: ship.DestX, Y: ship.DestY, Z: ship.DestZ}
+			}
+			cs.Ships[key] = cc
+		}
+
+		c.Species[id] = cs
+	}
+
+	// we don't have data for scanned systems, so just pretend that visited === scanned
+	for _, star := range ds.Stars {
+		coords := &Coords{X: star.X, Y: star.Y, Z: star.Z}
+		for _, spNo := range star.VisitedBy {
+			if sp, ok := c.Species[speciesId(spNo)]; ok {
+				sp.Visited = append(sp.Visited, coords.Id())
+				sp.Scanned = append(sp.Scanned, coords.Id())
+			}
+		}
+	}
+	for _, sp := range c.Species {
+		sort.Strings(sp.Colonies)
+		sort.Strings(sp.Scanned)
+		sort.Strings(sp.Visited)
+	}
+
+	return c, nil
+}
+
+// FromCluster converts a cluster to a store. It is the inverse of ToCluster.
+func FromCluster(c *Cluster) (*jsondb.Store, error) {
+	ds := &jsondb.Store{Version: c.Semver}
+	ds.Galaxy.DNumSpecies = c.DNumSpecies
+	ds.Galaxy.NumSpecies = len(c.Species)
+	ds.Galaxy.Radius = c.Radius
+	ds.Galaxy.TurnNumber = c.Turn
+	ds.Locations = append([]jsondb.Location{}, c.Locations...)
+
+	ds.Stars = make([]*jsondb.StarData, len(c.Systems), len(c.Systems))
+	for id, cs := range c.Systems {
+		if cs.Index < 0 || cs.Index >= len(ds.Stars) || ds.Stars[cs.Index] != nil {
+			return nil, fmt.Errorf("system %s: invalid index %d", id, cs.Index)
+		} else if cs.Coords == nil {
+			return nil, fmt.Errorf("system %s: missing coordinates", id)
+		}
+		star := &jsondb.StarData{
+			Id:          cs.Index,
+			Message:     cs.Message,
+			NumPlanets:  len(cs.Planets),
+			PlanetIndex: cs.PlanetIndex,
+			Size:        cs.Size,
+			X:           cs.Coords.X,
+			Y:           cs.Coords.Y,
+			Z:           cs.Coords.Z,
+		}
+		var err error
+		if star.Color, err = fromCode(starColorNames, "color", cs.Color.Description); err != nil {
+			return nil, fmt.Errorf("system %s: %w", id, err)
+		} else if star.Type, err = fromCode(starTypeNames, "type", cs.Type.Description); err != nil {
+			return nil, fmt.Errorf("system %s: %w", id, err)
+		}
+		if cs.PotentialHomeSystem {
+			star.HomeSystem = 1
+		}
+		if cs.Wormhole != nil {
+			star.WormHere, star.WormX, star.WormY, star.WormZ = 1, cs.Wormhole.X, cs.Wormhole.Y, cs.Wormhole.Z
+		}
+		for _, spId := range cs.VisitedBy {
+			spNo, err := speciesNo(spId)
+			if err != nil {
+				return nil, fmt.Errorf("system %s: %w", id, err)
+			}
+			star.VisitedBy = append(star.VisitedBy, spNo)
+		}
+		ds.Stars[cs.Index] = star
+	}
+
+	ds.Planets = make([]*jsondb.PlanetData, len(c.Planets), len(c.Planets))
+	for id, cp := range c.Planets {
+		if cp.Index < 0 || cp.Index >= len(ds.Planets) || ds.Planets[cp.Index] != nil {
+			return nil, fmt.Errorf("planet %s: invalid index %d", id, cp.Index)
+		} else if len(cp.Atmosphere) > 4 {
+			return nil, fmt.Errorf("planet %s: more than 4 gases", id)
+		}
+		planet := &jsondb.PlanetData{
+			Id:               cp.Index,
+			Diameter:         cp.Diameter,
+			EconEfficiency:   cp.EconEfficiency,
+			Gravity:          cp.Gravity,
+			MdIncrease:       cp.MiningDifficultyIncrease,
+			Message:          cp.Message,
+			MiningDifficulty: cp.MiningDifficulty,
+			PressureClass:    cp.PressureClass,
+			TemperatureClass: cp.TemperatureClass,
+		}
+		var err error
+		if planet.Special, err = fromCode(specialCodes, "special", cp.Special); err != nil {
+			return nil, fmt.Errorf("planet %s: %w", id, err)
+		}
+		for g, gas := range cp.Atmosphere {
+			if planet.Gas[g], err = fromCode(gasCodes, "gas", gas.Code); err != nil {
+				return nil, fmt.Errorf("planet %s: %w", id, err)
+			}
+			planet.GasPercent[g] = gas.Percentage
+		}
+		ds.Planets[cp.Index] = planet
+	}
+
+	ds.Species = make([]*jsondb.SpeciesData, len(c.Species), len(c.Species))
+	for id, cs := range c.Species {
+		spNo, err := speciesNo(id)
+		if err != nil {
+			return nil, err
+		} else if spNo > len(ds.Species) || ds.Species[spNo-1] != nil {
+			return nil, fmt.Errorf("%s: species are not numbered 1..%d", id, len(ds.Species))
+		}
+		sp, err := fromClusterSpecies(c, id, spNo, cs)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+		ds.Species[spNo-1] = sp
+	}
+
+	return ds, nil
+}
+
+// fromClusterSpecies converts a species and its named planets and ships.
+func fromClusterSpecies(c *Cluster, id string, spNo int, cs *SpeciesData) (*jsondb.SpeciesData, error) {
+	home, err := parseCoords(cs.HomeWorld)
+	if err != nil {
+		return nil, err
+	}
+	sp := &jsondb.SpeciesData{
+		Id:               spNo,
+		EconUnits:        cs.EconUnits,
+		FleetCost:        cs.Fleet.Cost,
+		FleetPercentCost: cs.Fleet.MaintenancePct,
+		GovtName:         cs.Government.Name,
+		GovtType:         cs.Government.Type,
+		HpOriginalBase:   cs.HPOriginalBase,
+		Name:             cs.Name,
+		NumNamplas:       cs.NumNamedPlanets,
+		NumShips:         cs.NumShips,
+		Pn:               home.Orbit,
+		RequiredGasMax:   cs.Gases.Required.MaxPct,
+		RequiredGasMin:   cs.Gases.Required.MinPct,
+		X:                home.X,
+		Y:                home.Y,
+		Z:                home.Z,
+	}
+	if cs.AutoOrders {
+		sp.AutoOrders = 1
+	}
+	for _, list := range []struct {
+		ids []string
+		nos *[]int
+	}{{cs.Ally, &sp.Ally}, {cs.Contact, &sp.Contact}, {cs.Enemy, &sp.Enemy}} {
+		for _, alienId := range list.ids {
+			alienNo, err := speciesNo(alienId)
+			if err != nil {
+				return nil, err
+			}
+			*list.nos = append(*list.nos, alienNo)
+		}
+	}
+	if sp.RequiredGas, err = fromCode(gasCodes, "gas", cs.Gases.Required.Code); err != nil {
+		return nil, err
+	} else if len(cs.Gases.Neutral) > len(sp.NeutralGas) || len(cs.Gases.Poison) > len(sp.PoisonGas) {
+		return nil, fmt.Errorf("more than %d neutral or poison gases", len(sp.NeutralGas))
+	}
+	for i, code := range cs.Gases.Neutral {
+		if sp.NeutralGas[i], err = fromCode(gasCodes, "gas", code); err != nil {
+			return nil, err
+		}
+	}
+	for i, code := range cs.Gases.Poison {
+		if sp.PoisonGas[i], err = fromCode(gasCodes, "gas", code); err != nil {
+			return nil, err
+		}
+	}
+	for tech, t := range []TechLevelData{cs.Tech.MI, cs.Tech.MA, cs.Tech.ML, cs.Tech.GV, cs.Tech.LS, cs.Tech.BI} {
+		sp.TechLevel[tech], sp.InitTechLevel[tech] = t.Level, t.InitLevel
+		sp.TechKnowledge[tech], sp.TechEps[tech] = t.KnowledgeLevel, t.ExperiencePoints
+	}
+
+	// records that aren't in the cluster were deleted by the engine
+	sp.Namplas = make([]*jsondb.NamedPlanetData, cs.NumNamedPlanets, cs.NumNamedPlanets)
+	for _, cn := range cs.NamedPlanets {
+		if cn.Index < 0 || cn.Index >= len(sp.Namplas) || sp.Namplas[cn.Index] != nil {
+			return nil, fmt.Errorf("named planet %q: invalid index %d", cn.DisplayName, cn.Index)
+		}
+		coords, err := parseCoords(cn.Planet)
+		if err != nil {
+			return nil, err
+		}
+		planet, ok := c.Planets[cn.Planet]
+		if !ok {
+			return nil, fmt.Errorf("named planet %q: %s is not a planet", cn.DisplayName, cn.Planet)
+		}
+		cc, ok := planet.Colonies[id]
+		if !ok {
+			return nil, fmt.Errorf("named planet %q: missing from planet %s", cn.DisplayName, cn.Planet)
+		}
+		np := &jsondb.NamedPlanetData{
+			Id:           cn.Index,
+			AUsNeeded:    cc.Units.Manufacturing.Needed,
+			AUsToInstall: cc.Units.Manufacturing.AvailableToInstall,
+			AutoAUs:      cc.Units.Manufacturing.Auto,
+			AutoIUs:      cc.Units.Mining.Auto,
+			IUsNeeded:    cc.Units.Mining.Needed,
+			IUsToInstall: cc.Units.Mining.AvailableToInstall,
+			Name:         cn.DisplayName,
+			PlanetIndex:  cc.PlanetIndex,
+			Pn:           coords.Orbit,
+			PopUnits:     cc.PopUnits,
+			MaBase:       cc.MaBase,
+			Message:      cc.Message,
+			MiBase:       cc.MiBase,
+			Shipyards:    cc.Shipyards,
+			SiegeEff:     cc.SiegeEff,
+			Special:      cc.Special,
+			UseOnAmbush:  cc.UseOnAmbush,
+			X:            coords.X,
+			Y:            coords.Y,
+			Z:            coords.Z,
+		}
+		if np.ItemQuantity, err = quantities(cc.Inventory); err != nil {
+			return nil, fmt.Errorf("named planet %q: %w", cn.DisplayName, err)
+		}
+		for _, status := range []struct {
+			set  bool
+			code int
+		}{
+			{cc.Status.HomePlanet, jsondb.HOME_PLANET},
+			{cc.Status.Colony, jsondb.COLONY},
+			{cc.Status.Populated, jsondb.POPULATED},
+			{cc.Status.MiningColony, jsondb.MINING_COLONY},
+			{cc.Status.ResortColony, jsondb.RESORT_COLONY},
+			{cc.Status.DisbandedColony, jsondb.DISBANDED_COLONY},
+		} {
+			if status.set {
+				np.Status |= status.code
+			}
+		}
+		if cc.Status.Hiding {
+			np.Hiding = 1
+		}
+		if cc.Status.Hidden {
+			np.Hidden = 1
+		}
+		sp.Namplas[cn.Index] = np
+	}
+	for n, np := range sp.Namplas {
+		if np == nil {
+			sp.Namplas[n] = &jsondb.NamedPlanetData{Id: n, Name: "Unused", Pn: 99, ItemQuantity: make([]int, len(itemCodes), len(itemCodes))}
+		}
+	}
+
+	sp.Ships = make([]*jsondb.ShipData, cs.NumShips, cs.NumShips)
+	for _, cc := range cs.Ships {
+		if cc.Index < 0 || cc.Index >= len(sp.Ships) || sp.Ships[cc.Index] != nil {
+			return nil, fmt.Errorf("ship %q: invalid index %d", cc.DisplayName, cc.Index)
+		} else if cc.Location == nil {
+			return nil, fmt.Errorf("ship %q: missing location", cc.DisplayName)
+		}
+		ship := &jsondb.ShipData{
+			Id:             cc.Index,
+			Age:            cc.Age,
+			LoadingPoint:   cc.LoadingPoint,
+			Name:           cc.DisplayName,
+			Pn:             cc.Location.Orbit,
+			RemainingCost:  cc.RemainingCost,
+			Special:        cc.Special,
+			Tonnage:        cc.Class.Tonnage,
+			UnloadingPoint: cc.UnloadingPoint,
+			X:              cc.Location.X,
+			Y:              cc.Location.Y,
+			Z:              cc.Location.Z,
+		}
+		if cc.Destination != nil {
+			ship.DestX, ship.DestY, ship.DestZ = cc.Destination.X, cc.Destination.Y, cc.Destination.Z
+		}
+		if cc.ArrivedViaWormhole {
+			ship.ArrivedViaWormhole = 1
+		}
+		if cc.JustJumped {
+			ship.JustJumped = 1
+		}
+		if ship.Class, err = fromCode(shipClassCodes, "class", cc.Class.Code); err != nil {
+			return nil, fmt.Errorf("ship %q: %w", cc.DisplayName, err)
+		} else if ship.Type, err = fromCode(shipTypes, "type", cc.Type); err != nil {
+			return nil, fmt.Errorf("ship %q: %w", cc.DisplayName, err)
+		} else if ship.Status, err = fromCode(shipStatuses, "status", cc.Status); err != nil {
+			return nil, fmt.Errorf("ship %q: %w", cc.DisplayName, err)
+		} else if ship.ItemQuantity, err = quantities(cc.Inventory); err != nil {
+			return nil, fmt.Errorf("ship %q: %w", cc.DisplayName, err)
+		}
+		sp.Ships[cc.Index] = ship
+	}
+	for n, ship := range sp.Ships {
+		if ship == nil {
+			sp.Ships[n] = &jsondb.ShipData{Id: n, Name: "Unused", Pn: 99, ItemQuantity: make([]int, len(itemCodes), len(itemCodes))}
+		}
+	}
+
+	return sp, nil
+}
+
+// inventory returns the non-zero quantities keyed by item code.
+func inventory(quantities []int) map[string]int {
+	items := make(map[string]int)
+	for item, qty := range quantities {
+		if qty != 0 {
+			items[toCode(itemCodes, "item", item)] = qty
+		}
+	}
+	return items
+}
+
+// quantities returns the quantity of every item in an inventory.
+// It is the inverse of inventory.
+func quantities(items map[string]int) ([]int, error) {
+	qty := make([]int, len(itemCodes), len(itemCodes))
+	for code, n := range items {
+		item, err := fromCode(itemCodes, "item", code)
+		if err != nil {
+			return nil, err
+		} else if item >= len(qty) {
+			return nil, fmt.Errorf("item %q is out of range", code)
+		}
+		qty[item] = n
+	}
+	return qty, nil
+}
@@ -0,0 +1,80 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package convert
+
+import (
+	"fmt"
+)
+
+// codes are the display codes for the values stored in the data files.
+// Values outside the tables are written as "name(n)" so that nothing is lost.
+var (
+	gasCodes       = []string{"", "H2", "CH4", "He", "NH3", "N2", "CO2", "O2", "HCl", "Cl2", "F2", "H2O", "SO2", "H2S"}
+	itemCodes      = []string{"RM", "PD", "SU", "DR", "CU", "IU", "AU", "FS", "JP", "FM", "FJ", "GT", "FD", "TP", "GW", "SG1", "SG2", "SG3", "SG4", "SG5", "SG6", "SG7", "SG8", "SG9", "GU1", "GU2", "GU3", "GU4", "GU5", "GU6", "GU7", "GU8", "GU9", "X1", "X2", "X3", "X4", "X5"}
+	shipClassCodes = []string{"PB", "CT", "ES", "FF", "DD", "CL", "CS", "CA", "CC", "BC", "BS", "DN", "SD", "BM", "BW", "BR", "BA", "TR"}
+	shipClassNames = []string{"Picketboat", "Corvette", "Escort", "Frigate", "Destroyer", "Light Cruiser", "Strike Cruiser", "Heavy Cruiser", "Command Cruiser", "Battlecruiser", "Battleship", "Dreadnought", "Super Dreadnought", "Battlemoon", "Battleworld", "Battlestar", "Starbase", "Transport"}
+	specialCodes   = []string{"", "Ideal Home Planet", "Ideal Colony Planet", "Radioactive Hellhole"}
+	starColorCodes = []string{"", "O", "B", "A", "F", "G", "K", "M"}
+	starColorNames = []string{"", "BLUE", "BLUE_WHITE", "WHITE", "YELLOW_WHITE", "YELLOW", "ORANGE", "RED"}
+	starTypeCodes  = []string{"", "d", "D", " ", "G"}
+	starTypeNames  = []string{"", "DWARF", "DEGENERATE", "MAIN_SEQUENCE", "GIANT"}
+	techCodes      = []string{"MI", "MA", "ML", "GV", "LS", "BI"}
+)
+
+// toCode returns the code for the value.
+func toCode(codes []string, name string, value int) string {
+	if 0 <= value && value < len(codes) {
+		return codes[value]
+	}
+	return fmt.Sprintf("%s(%d)", name, value)
+}
+
+// fromCode returns the value for the code. It is the inverse of toCode.
+func fromCode(codes []string, name, code string) (int, error) {
+	for value, c := range codes {
+		if c == code {
+			return value, nil
+		}
+	}
+	var value int
+	if _, err := fmt.Sscanf(code, name+"(%d)", &value); err != nil {
+		return 0, fmt.Errorf("unknown %s %q", name, code)
+	}
+	return value, nil
+}
+
+// speciesId returns the identifier for the species number.
+func speciesId(spNo int) string {
+	return fmt.Sprintf("SP%02d", spNo)
+}
+
+// speciesNo returns the species number for the identifier.
+// It is the inverse of speciesId.
+func speciesNo(id string) (int, error) {
+	var spNo int
+	if _, err := fmt.Sscanf(id, "SP%d", &spNo); err != nil || spNo < 1 {
+		return 0, fmt.Errorf("invalid species id %q", id)
+	}
+	return spNo, nil
+}
+
+// ItemCode returns the display code for an item, for example "RM".
+func ItemCode(item int) string {
+	return toCode(itemCodes, "item", item)
+}
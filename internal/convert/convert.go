@@ -0,0 +1,77 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+// Package convert translates game data between the dat32, jsondb, and
+// cluster.json formats. The Postgres schema is loaded from and saved to
+// a jsondb.Store by the cdb package.
+package convert
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/mdhender/fhcms/cms/store/jsondb"
+)
+
+// Data formats
+const (
+	DAT32    = "dat32"    // binary data files in a directory
+	JSONDB   = "jsondb"   // galaxy.json file
+	CLUSTER  = "cluster"  // cluster.json file
+	POSTGRES = "postgres" // staging tables in the database
+)
+
+// Formats returns the names of the supported data formats.
+func Formats() []string {
+	return []string{DAT32, JSONDB, CLUSTER, POSTGRES}
+}
+
+// Read loads a store from a file-based format.
+// For DAT32, path is the directory containing the data files.
+func Read(format, path string, endian binary.ByteOrder) (*jsondb.Store, error) {
+	switch format {
+	case DAT32:
+		return FromDat32(path, endian)
+	case JSONDB:
+		return jsondb.Read(path)
+	case CLUSTER:
+		c, err := ReadCluster(path)
+		if err != nil {
+			return nil, err
+		}
+		return FromCluster(c)
+	}
+	return nil, fmt.Errorf("can't read format %q", format)
+}
+
+// Write saves a store to a file-based format.
+// For DAT32, path is the directory that will hold the data files.
+func Write(ds *jsondb.Store, format, path string, endian binary.ByteOrder) error {
+	switch format {
+	case DAT32:
+		return ToDat32(ds, path, endian)
+	case JSONDB:
+		return ds.Write(path)
+	case CLUSTER:
+		c, err := ToCluster(ds)
+		if err != nil {
+			return err
+		}
+		return c.Write(path)
+	}
+	return fmt.Errorf("can't write format %q", format)
+}
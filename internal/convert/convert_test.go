@@ -0,0 +1,171 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package convert_test
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"github.com/mdhender/fhcms/cms/store/jsondb"
+	"github.com/mdhender/fhcms/internal/convert"
+	"github.com/mdhender/fhcms/internal/galaxy"
+	"path/filepath"
+	"testing"
+)
+
+// testStore returns a small galaxy with colonies, ships, and diplomacy
+// so that every field that the formats translate has something in it.
+func testStore(t *testing.T) *jsondb.Store {
+	ds, err := galaxy.New(galaxy.Config{Species: 3, Seed: 0x5eed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, name := range []string{"Alpha", "Bravo", "Charlie"} {
+		if _, err := galaxy.AddSpecies(ds, galaxy.SpeciesConfig{
+			Name:       name,
+			GovtName:   name + " Government",
+			GovtType:   "Monarchy",
+			HomePlanet: name + " Prime",
+			ML:         4, GV: 4, LS: 4, BI: 3,
+			Seed: uint64(i + 1),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ds.Galaxy.TurnNumber = 7
+
+	alpha, bravo := ds.Species[0], ds.Species[1]
+	alpha.Contact, alpha.Ally, alpha.Enemy = []int{2, 3}, []int{2}, []int{3}
+	bravo.Contact, bravo.Ally = []int{1}, []int{1}
+	alpha.EconUnits, alpha.FleetCost, alpha.FleetPercentCost, alpha.HpOriginalBase = 1234, 56, 78, 9012
+	alpha.TechEps, alpha.TechKnowledge = [6]int{1, 2, 3, 4, 5, 6}, [6]int{10, 11, 12, 13, 14, 15}
+
+	// a colony on the next planet out from the home planet
+	home := alpha.Namplas[0]
+	home.Shipyards, home.Message, home.UseOnAmbush = 2, 3, 40
+	home.ItemQuantity[0], home.ItemQuantity[5] = 100, 25
+	colony := &jsondb.NamedPlanetData{
+		Id:           1,
+		Name:         "Second",
+		X:            home.X,
+		Y:            home.Y,
+		Z:            home.Z,
+		Pn:           home.Pn + 1,
+		PlanetIndex:  home.PlanetIndex + 1,
+		Status:       jsondb.COLONY | jsondb.POPULATED | jsondb.MINING_COLONY,
+		Hiding:       1,
+		MiBase:       150,
+		MaBase:       20,
+		PopUnits:     12,
+		AutoIUs:      3,
+		IUsNeeded:    4,
+		IUsToInstall: 5,
+		SiegeEff:     6,
+		ItemQuantity: make([]int, len(home.ItemQuantity)),
+	}
+	colony.ItemQuantity[1] = 7
+	alpha.Namplas = append(alpha.Namplas, colony)
+	alpha.NumNamplas = len(alpha.Namplas)
+
+	// a ship in orbit, one under construction, and one in deep space
+	for i, ship := range []*jsondb.ShipData{
+		{Name: "Seeker", Class: 19, Tonnage: 1, Status: jsondb.IN_ORBIT, Pn: home.Pn, Age: 3, JustJumped: 1},
+		{Name: "Hauler", Class: 19, Tonnage: 5, Type: 1, Status: jsondb.UNDER_CONSTRUCTION, Pn: home.Pn, RemainingCost: 80},
+		{Name: "Wanderer", Class: 1, Tonnage: 2, Status: jsondb.IN_DEEP_SPACE, LoadingPoint: 9999, UnloadingPoint: 1, ArrivedViaWormhole: 1, Special: 4},
+	} {
+		ship.Id, ship.X, ship.Y, ship.Z = i, home.X, home.Y, home.Z
+		ship.ItemQuantity = make([]int, len(home.ItemQuantity))
+		ship.ItemQuantity[i] = 10 * (i + 1)
+		alpha.Ships = append(alpha.Ships, ship)
+	}
+	alpha.NumShips = len(alpha.Ships)
+
+	ds.Planets[home.PlanetIndex].Message = 17
+	ds.Stars[0].Message = 18
+	ds.Stars[0].VisitedBy = append(ds.Stars[0].VisitedBy, 2)
+	ds.SetLocations()
+	return ds
+}
+
+// format is a data format along with the byte order for dat32.
+type format struct {
+	name   string
+	format string
+	endian binary.ByteOrder
+}
+
+var fileFormats = []format{
+	{"dat32-le", convert.DAT32, binary.LittleEndian},
+	{"dat32-be", convert.DAT32, binary.BigEndian},
+	{"jsondb", convert.JSONDB, nil},
+	{"cluster", convert.CLUSTER, nil},
+}
+
+// path returns the file or directory to read and write the format in.
+func (f format) path(t *testing.T) string {
+	dir := t.TempDir()
+	if f.format == convert.DAT32 {
+		return dir
+	}
+	return filepath.Join(dir, f.name+".json")
+}
+
+// equalStores fails the test if the stores do not have the same JSON.
+func equalStores(t *testing.T, got, want *jsondb.Store) {
+	t.Helper()
+	g, err := json.MarshalIndent(got, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := json.MarshalIndent(want, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(g) != string(w) {
+		t.Errorf("store changed\ngot:\n%s\nwant:\n%s", g, w)
+	}
+}
+
+// TestRoundTrip checks that writing a store in one format, reading it,
+// writing it in another format, and reading that returns the same store.
+func TestRoundTrip(t *testing.T) {
+	want := testStore(t)
+	for _, from := range fileFormats {
+		for _, to := range fileFormats {
+			t.Run(from.name+"-"+to.name, func(t *testing.T) {
+				path := from.path(t)
+				if err := convert.Write(want, from.format, path, from.endian); err != nil {
+					t.Fatal(err)
+				}
+				ds, err := convert.Read(from.format, path, from.endian)
+				if err != nil {
+					t.Fatal(err)
+				}
+				path = to.path(t)
+				if err := convert.Write(ds, to.format, path, to.endian); err != nil {
+					t.Fatal(err)
+				}
+				got, err := convert.Read(to.format, path, to.endian)
+				if err != nil {
+					t.Fatal(err)
+				}
+				equalStores(t, got, want)
+			})
+		}
+	}
+}
@@ -0,0 +1,382 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package convert
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/mdhender/fhcms/cms/store/jsondb"
+	"github.com/mdhender/fhcms/internal/dat32"
+	"os"
+	"path/filepath"
+)
+
+// FromDat32 reads the binary data files from the path.
+// A missing locations.dat is treated as an empty list.
+func FromDat32(path string, endian binary.ByteOrder) (*jsondb.Store, error) {
+	galaxy, err := dat32.ReadGalaxy(filepath.Join(path, "galaxy.dat"), endian)
+	if err != nil {
+		return nil, err
+	}
+	stars, err := dat32.ReadStars(filepath.Join(path, "stars.dat"), endian)
+	if err != nil {
+		return nil, err
+	}
+	planets, err := dat32.ReadPlanets(filepath.Join(path, "planets.dat"), endian)
+	if err != nil {
+		return nil, err
+	}
+	var species []*dat32.Species
+	for spNo := 1; spNo <= galaxy.NumSpecies; spNo++ {
+		sp, err := dat32.ReadSpecies(filepath.Join(path, fmt.Sprintf("sp%02d.dat", spNo)), spNo, endian)
+		if err != nil {
+			return nil, err
+		}
+		species = append(species, sp)
+	}
+	locations, err := dat32.ReadLocations(filepath.Join(path, "locations.dat"), endian)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return FromDat32Records(galaxy, stars, planets, species, locations), nil
+}
+
+// ToDat32 writes the store to the binary data files in the path.
+func ToDat32(ds *jsondb.Store, path string, endian binary.ByteOrder) error {
+	galaxy, stars, planets, species, locations := ToDat32Records(ds)
+	if err := dat32.WriteGalaxy(filepath.Join(path, "galaxy.dat"), galaxy, endian); err != nil {
+		return err
+	} else if err = dat32.WriteStars(filepath.Join(path, "stars.dat"), stars, endian); err != nil {
+		return err
+	} else if err = dat32.WritePlanets(filepath.Join(path, "planets.dat"), planets, endian); err != nil {
+		return err
+	}
+	for _, sp := range species {
+		if err := dat32.WriteSpecies(filepath.Join(path, fmt.Sprintf("sp%02d.dat", sp.Id)), sp, endian); err != nil {
+			return err
+		}
+	}
+	return dat32.WriteLocations(filepath.Join(path, "locations.dat"), locations, endian)
+}
+
+// FromDat32Records converts records read from the binary data files to a store.
+func FromDat32Records(galaxy *dat32.Galaxy, stars *dat32.Stars, planets *dat32.Planets, species []*dat32.Species, locations []dat32.SpLocData) *jsondb.Store {
+	ds := &jsondb.Store{}
+	ds.Galaxy.DNumSpecies = galaxy.DNumSpecies
+	ds.Galaxy.NumSpecies = galaxy.NumSpecies
+	ds.Galaxy.Radius = galaxy.Radius
+	ds.Galaxy.TurnNumber = galaxy.TurnNumber
+
+	for i, star := range stars.Stars {
+		ds.Stars = append(ds.Stars, &jsondb.StarData{
+			Id:          i,
+			Color:       star.Color,
+			HomeSystem:  star.HomeSystem,
+			Message:     star.Message,
+			NumPlanets:  star.NumPlanets,
+			PlanetIndex: star.PlanetIndex,
+			Size:        star.Size,
+			Type:        star.Type,
+			VisitedBy:   append([]int(nil), star.VisitedBy...),
+			WormHere:    star.WormHere,
+			WormX:       star.WormX,
+			WormY:       star.WormY,
+			WormZ:       star.WormZ,
+			X:           star.X,
+			Y:           star.Y,
+			Z:           star.Z,
+		})
+	}
+
+	for i, planet := range planets.Planets {
+		ds.Planets = append(ds.Planets, &jsondb.PlanetData{
+			Id:               i,
+			Diameter:         planet.Diameter,
+			EconEfficiency:   planet.EconEfficiency,
+			Gas:              planet.Gas,
+			GasPercent:       planet.GasPercent,
+			Gravity:          planet.Gravity,
+			MdIncrease:       planet.MDIncrease,
+			Message:          planet.Message,
+			MiningDifficulty: planet.MiningDifficulty,
+			PressureClass:    planet.PressureClass,
+			Special:          planet.Special,
+			TemperatureClass: planet.TemperatureClass,
+		})
+	}
+
+	for _, sp := range species {
+		sd := &jsondb.SpeciesData{
+			Id:               sp.Id,
+			Ally:             append([]int(nil), sp.Ally...),
+			Contact:          append([]int(nil), sp.Contact...),
+			EconUnits:        sp.EconUnits,
+			Enemy:            append([]int(nil), sp.Enemy...),
+			FleetCost:        sp.FleetCost,
+			FleetPercentCost: sp.FleetPercentCost,
+			GovtName:         sp.GovtName,
+			GovtType:         sp.GovtType,
+			HpOriginalBase:   sp.HPOriginalBase,
+			InitTechLevel:    sp.InitTechLevel,
+			Name:             sp.Name,
+			NumNamplas:       len(sp.NamplaBase),
+			NumShips:         len(sp.ShipBase),
+			Pn:               sp.PN,
+			RequiredGas:      sp.RequiredGas,
+			RequiredGasMax:   sp.RequiredGasMax,
+			RequiredGasMin:   sp.RequiredGasMin,
+			Ships:            []*jsondb.ShipData{},
+			TechEps:          sp.TechEps,
+			TechKnowledge:    sp.TechKnowledge,
+			TechLevel:        sp.TechLevel,
+			X:                sp.X,
+			Y:                sp.Y,
+			Z:                sp.Z,
+		}
+		if sp.AutoOrders {
+			sd.AutoOrders = 1
+		}
+		copy(sd.NeutralGas[:], sp.NeutralGas)
+		copy(sd.PoisonGas[:], sp.PoisonGas)
+		for j, np := range sp.NamplaBase {
+			nampla := &jsondb.NamedPlanetData{
+				Id:           j,
+				AUsNeeded:    np.AUsNeeded,
+				AUsToInstall: np.AUsToInstall,
+				AutoAUs:      np.AutoAUs,
+				AutoIUs:      np.AutoIUs,
+				ItemQuantity: append([]int{}, np.ItemQuantity[:]...),
+				IUsNeeded:    np.IUsNeeded,
+				IUsToInstall: np.IUsToInstall,
+				Name:         np.Name,
+				PlanetIndex:  np.PlanetIndex,
+				Pn:           np.PN,
+				PopUnits:     np.PopUnits,
+				MaBase:       np.MaBase,
+				Message:      np.Message,
+				MiBase:       np.MiBase,
+				Shipyards:    np.Shipyards,
+				SiegeEff:     np.SiegeEff,
+				Status:       np.Status,
+				Special:      np.Special,
+				UseOnAmbush:  np.UseOnAmbush,
+				X:            np.X,
+				Y:            np.Y,
+				Z:            np.Z,
+			}
+			if np.Hidden {
+				nampla.Hidden = 1
+			}
+			if np.Hiding {
+				nampla.Hiding = 1
+			}
+			sd.Namplas = append(sd.Namplas, nampla)
+		}
+		for j, sh := range sp.ShipBase {
+			ship := &jsondb.ShipData{
+				Id:             j,
+				Age:            sh.Age,
+				Class:          sh.Class,
+				DestX:          sh.DestX,
+				DestY:          sh.DestY,
+				DestZ:          sh.DestZ,
+				ItemQuantity:   append([]int{}, sh.ItemQuantity[:]...),
+				LoadingPoint:   sh.LoadingPoint,
+				Name:           sh.Name,
+				Pn:             sh.PN,
+				RemainingCost:  sh.RemainingCost,
+				Special:        sh.Special,
+				Status:         sh.Status,
+				Tonnage:        sh.Tonnage,
+				Type:           sh.Type,
+				UnloadingPoint: sh.UnloadingPoint,
+				X:              sh.X,
+				Y:              sh.Y,
+				Z:              sh.Z,
+			}
+			if sh.JustJumped {
+				ship.JustJumped = 1
+			}
+			if sh.ArrivedViaWormhole {
+				ship.ArrivedViaWormhole = 1
+			}
+			sd.Ships = append(sd.Ships, ship)
+		}
+		ds.Species = append(ds.Species, sd)
+	}
+
+	for _, loc := range locations {
+		ds.Locations = append(ds.Locations, jsondb.Location{S: loc.S, X: loc.X, Y: loc.Y, Z: loc.Z})
+	}
+
+	return ds
+}
+
+// ToDat32Records converts a store to the records stored in the binary data files.
+// It is the inverse of FromDat32Records.
+func ToDat32Records(ds *jsondb.Store) (*dat32.Galaxy, *dat32.Stars, *dat32.Planets, []*dat32.Species, []dat32.SpLocData) {
+	galaxy := &dat32.Galaxy{
+		DNumSpecies: ds.Galaxy.DNumSpecies,
+		NumSpecies:  ds.Galaxy.NumSpecies,
+		Radius:      ds.Galaxy.Radius,
+		TurnNumber:  ds.Galaxy.TurnNumber,
+	}
+
+	stars := &dat32.Stars{NumStars: len(ds.Stars)}
+	for _, star := range ds.Stars {
+		stars.Stars = append(stars.Stars, dat32.Star{
+			X:           star.X,
+			Y:           star.Y,
+			Z:           star.Z,
+			Type:        star.Type,
+			Color:       star.Color,
+			Size:        star.Size,
+			NumPlanets:  star.NumPlanets,
+			HomeSystem:  star.HomeSystem,
+			WormHere:    star.WormHere,
+			WormX:       star.WormX,
+			WormY:       star.WormY,
+			WormZ:       star.WormZ,
+			PlanetIndex: star.PlanetIndex,
+			Message:     star.Message,
+			VisitedBy:   append([]int(nil), star.VisitedBy...),
+		})
+	}
+
+	planets := &dat32.Planets{NumPlanets: len(ds.Planets)}
+	for i, planet := range ds.Planets {
+		planets.Planets = append(planets.Planets, dat32.Planet{
+			Id:               i,
+			TemperatureClass: planet.TemperatureClass,
+			PressureClass:    planet.PressureClass,
+			Special:          planet.Special,
+			Gas:              planet.Gas,
+			GasPercent:       planet.GasPercent,
+			Diameter:         planet.Diameter,
+			Gravity:          planet.Gravity,
+			MiningDifficulty: planet.MiningDifficulty,
+			EconEfficiency:   planet.EconEfficiency,
+			MDIncrease:       planet.MdIncrease,
+			Message:          planet.Message,
+		})
+	}
+
+	var species []*dat32.Species
+	for _, sd := range ds.Species {
+		sp := &dat32.Species{
+			Id:               sd.Id,
+			Name:             sd.Name,
+			GovtName:         sd.GovtName,
+			GovtType:         sd.GovtType,
+			X:                sd.X,
+			Y:                sd.Y,
+			Z:                sd.Z,
+			PN:               sd.Pn,
+			RequiredGas:      sd.RequiredGas,
+			RequiredGasMin:   sd.RequiredGasMin,
+			RequiredGasMax:   sd.RequiredGasMax,
+			AutoOrders:       sd.AutoOrders != 0,
+			TechLevel:        sd.TechLevel,
+			InitTechLevel:    sd.InitTechLevel,
+			TechKnowledge:    sd.TechKnowledge,
+			NumNamplas:       len(sd.Namplas),
+			NumShips:         len(sd.Ships),
+			TechEps:          sd.TechEps,
+			HPOriginalBase:   sd.HpOriginalBase,
+			EconUnits:        sd.EconUnits,
+			FleetCost:        sd.FleetCost,
+			FleetPercentCost: sd.FleetPercentCost,
+			Contact:          append([]int(nil), sd.Contact...),
+			Ally:             append([]int(nil), sd.Ally...),
+			Enemy:            append([]int(nil), sd.Enemy...),
+		}
+		for _, gas := range sd.NeutralGas {
+			if gas != 0 {
+				sp.NeutralGas = append(sp.NeutralGas, gas)
+			}
+		}
+		for _, gas := range sd.PoisonGas {
+			if gas != 0 {
+				sp.PoisonGas = append(sp.PoisonGas, gas)
+			}
+		}
+		for _, np := range sd.Namplas {
+			nampla := dat32.NamedPlanet{
+				Name:         np.Name,
+				X:            np.X,
+				Y:            np.Y,
+				Z:            np.Z,
+				PN:           np.Pn,
+				Status:       np.Status,
+				Hiding:       np.Hiding != 0,
+				Hidden:       np.Hidden != 0,
+				PlanetIndex:  np.PlanetIndex,
+				SiegeEff:     np.SiegeEff,
+				Shipyards:    np.Shipyards,
+				IUsNeeded:    np.IUsNeeded,
+				AUsNeeded:    np.AUsNeeded,
+				AutoIUs:      np.AutoIUs,
+				AutoAUs:      np.AutoAUs,
+				IUsToInstall: np.IUsToInstall,
+				AUsToInstall: np.AUsToInstall,
+				MiBase:       np.MiBase,
+				MaBase:       np.MaBase,
+				PopUnits:     np.PopUnits,
+				UseOnAmbush:  np.UseOnAmbush,
+				Message:      np.Message,
+				Special:      np.Special,
+			}
+			copy(nampla.ItemQuantity[:], np.ItemQuantity)
+			sp.NamplaBase = append(sp.NamplaBase, nampla)
+		}
+		for _, sh := range sd.Ships {
+			ship := dat32.Ship{
+				Name:               sh.Name,
+				X:                  sh.X,
+				Y:                  sh.Y,
+				Z:                  sh.Z,
+				PN:                 sh.Pn,
+				Status:             sh.Status,
+				Type:               sh.Type,
+				DestX:              sh.DestX,
+				DestY:              sh.DestY,
+				DestZ:              sh.DestZ,
+				JustJumped:         sh.JustJumped != 0,
+				ArrivedViaWormhole: sh.ArrivedViaWormhole != 0,
+				Class:              sh.Class,
+				Tonnage:            sh.Tonnage,
+				Age:                sh.Age,
+				RemainingCost:      sh.RemainingCost,
+				LoadingPoint:       sh.LoadingPoint,
+				UnloadingPoint:     sh.UnloadingPoint,
+				Special:            sh.Special,
+			}
+			copy(ship.ItemQuantity[:], sh.ItemQuantity)
+			sp.ShipBase = append(sp.ShipBase, ship)
+		}
+		species = append(species, sp)
+	}
+
+	var locations []dat32.SpLocData
+	for _, loc := range ds.Locations {
+		locations = append(locations, dat32.SpLocData{S: loc.S, X: loc.X, Y: loc.Y, Z: loc.Z})
+	}
+
+	return galaxy, stars, planets, species, locations
+}
@@ -0,0 +1,84 @@
+//go:build postgres
+// +build postgres
+
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package convert_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/mdhender/fhcms/internal/convert"
+	"github.com/mdhender/fhcms/internal/repos/cdb"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// TestPostgresRoundTrip checks that a store read from each file format
+// can be staged in the database and read back without loss.
+//
+// It needs a database, so it is only built with the postgres tag:
+//
+//	FH_TEST_DATABASE=database.json FH_TEST_GALAXY=name go test -tags postgres ./internal/convert
+//
+// FH_TEST_DATABASE is the path to a database.json file and FH_TEST_GALAXY
+// is the name of a galaxy in that database with at least three species.
+func TestPostgresRoundTrip(t *testing.T) {
+	dbPath, name := os.Getenv("FH_TEST_DATABASE"), os.Getenv("FH_TEST_GALAXY")
+	if dbPath == "" || name == "" {
+		t.Skip("FH_TEST_DATABASE and FH_TEST_GALAXY are not set")
+	}
+	data, err := ioutil.ReadFile(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &cdb.DBConfig{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	db, err := cdb.New(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	want := testStore(t)
+	for _, from := range fileFormats {
+		t.Run(from.name, func(t *testing.T) {
+			path := from.path(t)
+			if err := convert.Write(want, from.format, path, from.endian); err != nil {
+				t.Fatal(err)
+			}
+			ds, err := convert.Read(from.format, path, from.endian)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := db.StageStore(ctx, name, ds); err != nil {
+				t.Fatal(err)
+			}
+			got, err := db.ReadStore(ctx, name, want.Galaxy.TurnNumber)
+			if err != nil {
+				t.Fatal(err)
+			}
+			equalStores(t, got, want)
+		})
+	}
+}
@@ -58,3 +58,14 @@ func ReadGalaxy(name string, bo binary.ByteOrder) (*Galaxy, error) {
 
 	return &g, nil
 }
+
+// WriteGalaxy writes the galaxy to a file using the same layout as ReadGalaxy.
+func WriteGalaxy(name string, g *Galaxy, bo binary.ByteOrder) error {
+	gd := galaxy_data{
+		DNumSpecies: int32(g.DNumSpecies),
+		NumSpecies:  int32(g.NumSpecies),
+		Radius:      int32(g.Radius),
+		TurnNumber:  int32(g.TurnNumber),
+	}
+	return writeFile(name, bo, &gd)
+}
@@ -18,6 +18,12 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 
 package dat32
 
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+)
+
 func nameToString(name [32]uint8) string {
 	var b []byte
 	for _, ch := range name {
@@ -37,3 +43,29 @@ func nameToString(name [32]uint8) string {
 func speciesBitIsSet(set [2]uint64, sp int) bool {
 	return (set[0] & (1 << (sp + 15))) != 0
 }
+
+func stringToName(s string) [32]uint8 {
+	var name [32]uint8
+	for i := 0; i < len(s) && i < len(name); i++ {
+		name[i] = s[i]
+	}
+	return name
+}
+
+// setSpeciesBit sets the bit for the species.
+// It is the inverse of speciesBitIsSet.
+// note: the species number must be 1 based!
+func setSpeciesBit(set *[2]uint64, sp int) {
+	set[0] |= 1 << (sp + 15)
+}
+
+// writeFile writes the binary representation of each value to the file.
+func writeFile(name string, bo binary.ByteOrder, data ...interface{}) error {
+	w := &bytes.Buffer{}
+	for _, v := range data {
+		if err := binary.Write(w, bo, v); err != nil {
+			return err
+		}
+	}
+	return ioutil.WriteFile(name, w.Bytes(), 0644)
+}
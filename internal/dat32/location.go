@@ -50,3 +50,12 @@ func ReadLocations(name string, bo binary.ByteOrder) ([]SpLocData, error) {
 	}
 	return ld, nil
 }
+
+// WriteLocations writes the locations to a file using the same layout as ReadLocations.
+func WriteLocations(name string, ld []SpLocData, bo binary.ByteOrder) error {
+	data := make([]sp_loc_data, len(ld), len(ld))
+	for i := range ld {
+		data[i] = sp_loc_data{S: uint8(ld[i].S), X: uint8(ld[i].X), Y: uint8(ld[i].Y), Z: uint8(ld[i].Z)}
+	}
+	return writeFile(name, bo, data)
+}
@@ -30,16 +30,22 @@ type NamedPlanet struct {
 	PN int `json:"pn"`
 	/* Status of planet. */
 	Status int `json:"status"`
+	/* Reserved for future use. Zero for now. */
+	Reserved1 int `json:"-"`
 	/* HIDE order given. */
 	Hiding bool `json:"hiding"`
 	/* Colony is hidden. */
 	Hidden bool `json:"hidden"`
+	/* Reserved for future use. Zero for now. */
+	Reserved2 int `json:"-"`
 	/* Index (starting at zero) into the file "planets.dat" of this planet. */
 	PlanetIndex int `json:"planet_index"`
 	/* Siege effectiveness - a percentage between 0 and 99. */
 	SiegeEff int `json:"siege_eff"`
 	/* Number of shipyards on planet. */
 	Shipyards int `json:"shipyards"`
+	/* Reserved for future use. Zero for now. */
+	Reserved4 int `json:"-"`
 	/* Incoming ship with only CUs on board. */
 	IUsNeeded int `json:"ius_needed"`
 	/* Incoming ship with only CUs on board. */
@@ -48,6 +54,8 @@ type NamedPlanet struct {
 	AutoIUs int `json:"auto_ius"`
 	/* Number of AUs to be automatically installed. */
 	AutoAUs int `json:"auto_aus"`
+	/* Reserved for future use. Zero for now. */
+	Reserved5 int `json:"-"`
 	/* Colonial mining units to be installed. */
 	IUsToInstall int `json:"ius_to_install"`
 	/* Colonial manufacturing units to be installed. */
@@ -60,10 +68,14 @@ type NamedPlanet struct {
 	PopUnits int `json:"pop_units"`
 	/* Quantity of each item available. */
 	ItemQuantity [MAX_ITEMS]int `json:"item_quantity"`
+	/* Reserved for future use. Zero for now. */
+	Reserved6 int `json:"-"`
 	/* Amount to use on ambush. */
 	UseOnAmbush int `json:"use_on_ambush"`
 	/* Message associated with this planet, if any. */
 	Message int `json:"message"`
 	/* Different for each application. */
 	Special int `json:"special"`
+	/* Use for expansion. Initialized to all zeroes. */
+	Padding [28]int `json:"-"`
 }
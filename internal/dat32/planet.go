@@ -38,9 +38,13 @@ type Planet struct {
 	/* Pressure class, 0-29. */
 	PressureClass int `json:"pressure_class"`
 	/* 0 = not special, 1 = ideal home planet, 2 = ideal colony planet, 3 = radioactive hellhole. */
-	Special    int    `json:"special"`
+	Special int `json:"special"`
+	/* Reserved for future use. Zero for now. */
+	Reserved1  int    `json:"-"`
 	Gas        [4]int `json:"gas"`
 	GasPercent [4]int `json:"gas_percent"`
+	/* Reserved for future use. Zero for now. */
+	Reserved2 int `json:"-"`
 	/* Diameter in thousands of kilometers. */
 	Diameter int `json:"diameter"`
 	/* Surface gravity. Multiple of Earth gravity times 100. */
@@ -53,6 +57,10 @@ type Planet struct {
 	MDIncrease int `json:"md_increase"`
 	/* Message associated with this planet, if any. */
 	Message int `json:"message"`
+	/* Reserved for future use. Zero for now. */
+	Reserved3 int `json:"-"`
+	Reserved4 int `json:"-"`
+	Reserved5 int `json:"-"`
 }
 
 // ReadPlanets returns either an initialized set of planets or an error
@@ -93,7 +101,41 @@ func ReadPlanets(name string, bo binary.ByteOrder) (*Planets, error) {
 		planets.Planets[n].EconEfficiency = int(pd.PlanetBase[n].EconEfficiency)
 		planets.Planets[n].MDIncrease = int(pd.PlanetBase[n].MDIncrease)
 		planets.Planets[n].Message = int(pd.PlanetBase[n].Message)
+		planets.Planets[n].Reserved1 = int(pd.PlanetBase[n].Reserved1)
+		planets.Planets[n].Reserved2 = int(pd.PlanetBase[n].Reserved2)
+		planets.Planets[n].Reserved3 = int(pd.PlanetBase[n].Reserved3)
+		planets.Planets[n].Reserved4 = int(pd.PlanetBase[n].Reserved4)
+		planets.Planets[n].Reserved5 = int(pd.PlanetBase[n].Reserved5)
 	}
 
 	return &planets, nil
 }
+
+// WritePlanets writes the planets to a file using the same layout as ReadPlanets.
+func WritePlanets(name string, planets *Planets, bo binary.ByteOrder) error {
+	var pd planet_file_t
+	pd.NumPlanets = int32(len(planets.Planets))
+	pd.PlanetBase = make([]planet_data, len(planets.Planets), len(planets.Planets))
+	for n := 0; n < len(planets.Planets); n++ {
+		pd.PlanetBase[n].TemperatureClass = int8(planets.Planets[n].TemperatureClass)
+		pd.PlanetBase[n].PressureClass = int8(planets.Planets[n].PressureClass)
+		pd.PlanetBase[n].Special = int8(planets.Planets[n].Special)
+		for i := 0; i < len(pd.PlanetBase[n].Gas); i++ {
+			pd.PlanetBase[n].Gas[i] = int8(planets.Planets[n].Gas[i])
+			pd.PlanetBase[n].GasPercent[i] = int8(planets.Planets[n].GasPercent[i])
+		}
+		pd.PlanetBase[n].Diameter = int16(planets.Planets[n].Diameter)
+		pd.PlanetBase[n].Gravity = int16(planets.Planets[n].Gravity)
+		pd.PlanetBase[n].MiningDifficulty = int16(planets.Planets[n].MiningDifficulty)
+		pd.PlanetBase[n].EconEfficiency = int16(planets.Planets[n].EconEfficiency)
+		pd.PlanetBase[n].MDIncrease = int16(planets.Planets[n].MDIncrease)
+		pd.PlanetBase[n].Message = int32(planets.Planets[n].Message)
+		pd.PlanetBase[n].Reserved1 = int8(planets.Planets[n].Reserved1)
+		pd.PlanetBase[n].Reserved2 = int16(planets.Planets[n].Reserved2)
+		pd.PlanetBase[n].Reserved3 = int32(planets.Planets[n].Reserved3)
+		pd.PlanetBase[n].Reserved4 = int32(planets.Planets[n].Reserved4)
+		pd.PlanetBase[n].Reserved5 = int32(planets.Planets[n].Reserved5)
+	}
+
+	return writeFile(name, bo, pd.NumPlanets, pd.PlanetBase)
+}
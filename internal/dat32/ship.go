@@ -39,6 +39,10 @@ type Ship struct {
 	JustJumped bool `json:"just_jumped"`
 	/* Ship arrived via wormhole in the PREVIOUS turn. */
 	ArrivedViaWormhole bool `json:"arrived_via_wormhole"`
+	/* Reserved for future use. Zero for now. */
+	Reserved1 int `json:"-"`
+	Reserved2 int `json:"-"`
+	Reserved3 int `json:"-"`
 	/* Ship class. */
 	Class int `json:"class"`
 	/* Ship tonnage divided by 10,000. */
@@ -49,10 +53,16 @@ type Ship struct {
 	Age int `json:"age"`
 	/* The cost needed to complete the ship if still under construction. */
 	RemainingCost int `json:"remaining_cost"`
+	/* Reserved for future use. Zero for now. */
+	Reserved4 int `json:"-"`
 	/* NamedPlanet index for planet where ship was last loaded with CUs. Zero = none. Use 9999 for home planet. */
 	LoadingPoint int `json:"loading_point"`
 	/* NamedPlanet index for planet that ship should be given orders to jump to where it will unload. Zero = none. Use 9999 for home planet. */
 	UnloadingPoint int `json:"unloading_point"`
 	/* Different for each application. */
 	Special int `json:"special"`
+	/* Use for expansion. Initialized to all zeroes. */
+	Padding [28]int `json:"-"`
+	// padding to make Go struct same size as C
+	MorePadding [2]int `json:"-"`
 }
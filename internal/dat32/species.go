@@ -46,12 +46,17 @@ type Species struct {
 	RequiredGasMin int `json:"required_gas_min"`
 	/* Maximum allowed percentage. */
 	RequiredGasMax int `json:"required_gas_max"`
+	/* Reserved for future use. Zero for now. */
+	Reserved5 int `json:"-"`
 	/* Gases neutral to species. */
 	NeutralGas []int `json:"neutral_gas"`
 	/* Gases poisonous to species. */
 	PoisonGas []int `json:"poison_gas"`
 	/* AUTO command was issued. */
 	AutoOrders bool `json:"auto_orders"`
+	/* Reserved for future use. Zero for now. */
+	Reserved3 int `json:"-"`
+	Reserved4 int `json:"-"`
 	/* Actual tech levels. */
 	TechLevel [6]int `json:"tech_level"`
 	/* Tech levels at start of turn. */
@@ -78,6 +83,8 @@ type Species struct {
 	Ally []int `json:"ally"`
 	/* A bit is set if corresponding species is considered an enemy. */
 	Enemy []int `json:"enemy"`
+	/* Use for expansion. Initialized to all zeroes. */
+	Padding [12]int `json:"-"`
 	// All named planets (home planet and colonies)
 	NamplaBase []NamedPlanet `json:"nampla_base"`
 	// All ships, plus some slots tagged as UNUSED
@@ -140,6 +147,12 @@ func ReadSpecies(name string, no int, bo binary.ByteOrder) (*Species, error) {
 		}
 	}
 	species.AutoOrders = sd.Species.AutoOrders != 0
+	species.Reserved3 = int(sd.Species.Reserved3)
+	species.Reserved4 = int(sd.Species.Reserved4)
+	species.Reserved5 = int(sd.Species.Reserved5)
+	for n := 0; n < len(sd.Species.Padding); n++ {
+		species.Padding[n] = int(sd.Species.Padding[n])
+	}
 	for i := 0; i < 6; i++ {
 		species.TechLevel[i] = int(sd.Species.TechLevel[i])
 		species.InitTechLevel[i] = int(sd.Species.InitTechLevel[i])
@@ -192,6 +205,14 @@ func ReadSpecies(name string, no int, bo binary.ByteOrder) (*Species, error) {
 		species.NamplaBase[i].UseOnAmbush = int(sd.NampData[i].UseOnAmbush)
 		species.NamplaBase[i].Message = int(sd.NampData[i].Message)
 		species.NamplaBase[i].Special = int(sd.NampData[i].Special)
+		species.NamplaBase[i].Reserved1 = int(sd.NampData[i].Reserved1)
+		species.NamplaBase[i].Reserved2 = int(sd.NampData[i].Reserved2)
+		species.NamplaBase[i].Reserved4 = int(sd.NampData[i].Reserved4)
+		species.NamplaBase[i].Reserved5 = int(sd.NampData[i].Reserved5)
+		species.NamplaBase[i].Reserved6 = int(sd.NampData[i].Reserved6)
+		for n := 0; n < len(sd.NampData[i].Padding); n++ {
+			species.NamplaBase[i].Padding[n] = int(sd.NampData[i].Padding[n])
+		}
 	}
 	species.ShipBase = make([]Ship, species.NumShips, species.NumShips)
 	for i := 0; i < species.NumShips; i++ {
@@ -217,7 +238,159 @@ func ReadSpecies(name string, no int, bo binary.ByteOrder) (*Species, error) {
 		species.ShipBase[i].LoadingPoint = int(sd.ShipData[i].LoadingPoint)
 		species.ShipBase[i].UnloadingPoint = int(sd.ShipData[i].UnloadingPoint)
 		species.ShipBase[i].Special = int(sd.ShipData[i].Special)
+		species.ShipBase[i].Reserved1 = int(sd.ShipData[i].Reserved1)
+		species.ShipBase[i].Reserved2 = int(sd.ShipData[i].Reserved2)
+		species.ShipBase[i].Reserved3 = int(sd.ShipData[i].Reserved3)
+		species.ShipBase[i].Reserved4 = int(sd.ShipData[i].Reserved4)
+		for n := 0; n < len(sd.ShipData[i].Padding); n++ {
+			species.ShipBase[i].Padding[n] = int(sd.ShipData[i].Padding[n])
+		}
+		for n := 0; n < len(sd.ShipData[i].MorePadding); n++ {
+			species.ShipBase[i].MorePadding[n] = int(sd.ShipData[i].MorePadding[n])
+		}
 	}
 
 	return &species, nil
 }
+
+// WriteSpecies writes the species, along with its named planets and ships,
+// to a file using the same layout as ReadSpecies.
+func WriteSpecies(name string, species *Species, bo binary.ByteOrder) error {
+	var sd species_file_t
+	sd.Species.Name = stringToName(species.Name)
+	sd.Species.GovtName = stringToName(species.GovtName)
+	sd.Species.GovtType = stringToName(species.GovtType)
+	sd.Species.X = uint8(species.X)
+	sd.Species.Y = uint8(species.Y)
+	sd.Species.Z = uint8(species.Z)
+	sd.Species.PN = uint8(species.PN)
+	sd.Species.RequiredGas = uint8(species.RequiredGas)
+	sd.Species.RequiredGasMin = uint8(species.RequiredGasMin)
+	sd.Species.RequiredGasMax = uint8(species.RequiredGasMax)
+	for i := 0; i < len(species.NeutralGas) && i < len(sd.Species.NeutralGas); i++ {
+		sd.Species.NeutralGas[i] = uint8(species.NeutralGas[i])
+	}
+	for i := 0; i < len(species.PoisonGas) && i < len(sd.Species.PoisonGas); i++ {
+		sd.Species.PoisonGas[i] = uint8(species.PoisonGas[i])
+	}
+	if species.AutoOrders {
+		sd.Species.AutoOrders = 1
+	}
+	sd.Species.Reserved3 = uint8(species.Reserved3)
+	sd.Species.Reserved4 = int16(species.Reserved4)
+	sd.Species.Reserved5 = uint8(species.Reserved5)
+	for n := 0; n < len(sd.Species.Padding); n++ {
+		sd.Species.Padding[n] = uint8(species.Padding[n])
+	}
+	for i := 0; i < 6; i++ {
+		sd.Species.TechLevel[i] = int16(species.TechLevel[i])
+		sd.Species.InitTechLevel[i] = int16(species.InitTechLevel[i])
+		sd.Species.TechKnowledge[i] = int16(species.TechKnowledge[i])
+		sd.Species.TechEps[i] = int32(species.TechEps[i])
+	}
+	sd.Species.NumNamplas = int32(len(species.NamplaBase))
+	sd.Species.NumShips = int32(len(species.ShipBase))
+	sd.Species.HPOriginalBase = int32(species.HPOriginalBase)
+	sd.Species.EconUnits = int32(species.EconUnits)
+	sd.Species.FleetCost = int32(species.FleetCost)
+	sd.Species.FleetPercentCost = int32(species.FleetPercentCost)
+	for _, spNo := range species.Contact {
+		if spIndex := spNo - 1; 0 <= spIndex && spIndex < 63 {
+			sd.Species.Contact[0] |= 1 << spIndex
+		}
+	}
+	for _, spNo := range species.Ally {
+		if spIndex := spNo - 1; 0 <= spIndex && spIndex < 63 {
+			sd.Species.Ally[0] |= 1 << spIndex
+		}
+	}
+	for _, spNo := range species.Enemy {
+		if spIndex := spNo - 1; 0 <= spIndex && spIndex < 63 {
+			sd.Species.Enemy[0] |= 1 << spIndex
+		}
+	}
+
+	sd.NampData = make([]nampla_data, len(species.NamplaBase), len(species.NamplaBase))
+	for i := 0; i < len(species.NamplaBase); i++ {
+		sd.NampData[i].Name = stringToName(species.NamplaBase[i].Name)
+		sd.NampData[i].X = uint8(species.NamplaBase[i].X)
+		sd.NampData[i].Y = uint8(species.NamplaBase[i].Y)
+		sd.NampData[i].Z = uint8(species.NamplaBase[i].Z)
+		sd.NampData[i].PN = uint8(species.NamplaBase[i].PN)
+		sd.NampData[i].Status = uint8(species.NamplaBase[i].Status)
+		if species.NamplaBase[i].Hiding {
+			sd.NampData[i].Hiding = 1
+		}
+		if species.NamplaBase[i].Hidden {
+			sd.NampData[i].Hidden = 1
+		}
+		sd.NampData[i].PlanetIndex = int16(species.NamplaBase[i].PlanetIndex)
+		sd.NampData[i].SiegeEff = int16(species.NamplaBase[i].SiegeEff)
+		sd.NampData[i].Shipyards = int16(species.NamplaBase[i].Shipyards)
+		sd.NampData[i].IUsNeeded = int32(species.NamplaBase[i].IUsNeeded)
+		sd.NampData[i].AUsNeeded = int32(species.NamplaBase[i].AUsNeeded)
+		sd.NampData[i].AutoIUs = int32(species.NamplaBase[i].AutoIUs)
+		sd.NampData[i].AutoAUs = int32(species.NamplaBase[i].AutoAUs)
+		sd.NampData[i].IUsToInstall = int32(species.NamplaBase[i].IUsToInstall)
+		sd.NampData[i].AUsToInstall = int32(species.NamplaBase[i].AUsToInstall)
+		sd.NampData[i].MiBase = int32(species.NamplaBase[i].MiBase)
+		sd.NampData[i].MaBase = int32(species.NamplaBase[i].MaBase)
+		sd.NampData[i].PopUnits = int32(species.NamplaBase[i].PopUnits)
+		for n := 0; n < len(sd.NampData[i].ItemQuantity); n++ {
+			sd.NampData[i].ItemQuantity[n] = int32(species.NamplaBase[i].ItemQuantity[n])
+		}
+		sd.NampData[i].UseOnAmbush = int32(species.NamplaBase[i].UseOnAmbush)
+		sd.NampData[i].Message = int32(species.NamplaBase[i].Message)
+		sd.NampData[i].Special = int32(species.NamplaBase[i].Special)
+		sd.NampData[i].Reserved1 = uint8(species.NamplaBase[i].Reserved1)
+		sd.NampData[i].Reserved2 = int16(species.NamplaBase[i].Reserved2)
+		sd.NampData[i].Reserved4 = int32(species.NamplaBase[i].Reserved4)
+		sd.NampData[i].Reserved5 = int32(species.NamplaBase[i].Reserved5)
+		sd.NampData[i].Reserved6 = int32(species.NamplaBase[i].Reserved6)
+		for n := 0; n < len(sd.NampData[i].Padding); n++ {
+			sd.NampData[i].Padding[n] = uint8(species.NamplaBase[i].Padding[n])
+		}
+	}
+
+	sd.ShipData = make([]ship_data, len(species.ShipBase), len(species.ShipBase))
+	for i := 0; i < len(species.ShipBase); i++ {
+		sd.ShipData[i].Name = stringToName(species.ShipBase[i].Name)
+		sd.ShipData[i].X = uint8(species.ShipBase[i].X)
+		sd.ShipData[i].Y = uint8(species.ShipBase[i].Y)
+		sd.ShipData[i].Z = uint8(species.ShipBase[i].Z)
+		sd.ShipData[i].PN = uint8(species.ShipBase[i].PN)
+		sd.ShipData[i].Status = uint8(species.ShipBase[i].Status)
+		sd.ShipData[i].Type = uint8(species.ShipBase[i].Type)
+		sd.ShipData[i].DestX = uint8(species.ShipBase[i].DestX)
+		sd.ShipData[i].DestY = uint8(species.ShipBase[i].DestY)
+		sd.ShipData[i].DestZ = uint8(species.ShipBase[i].DestZ)
+		if species.ShipBase[i].JustJumped {
+			sd.ShipData[i].JustJumped = 1
+		}
+		if species.ShipBase[i].ArrivedViaWormhole {
+			sd.ShipData[i].ArrivedViaWormhole = 1
+		}
+		sd.ShipData[i].Class = int16(species.ShipBase[i].Class)
+		sd.ShipData[i].Tonnage = int16(species.ShipBase[i].Tonnage)
+		for n := 0; n < len(sd.ShipData[i].ItemQuantity); n++ {
+			sd.ShipData[i].ItemQuantity[n] = int16(species.ShipBase[i].ItemQuantity[n])
+		}
+		sd.ShipData[i].Age = int16(species.ShipBase[i].Age)
+		sd.ShipData[i].RemainingCost = int16(species.ShipBase[i].RemainingCost)
+		sd.ShipData[i].LoadingPoint = int16(species.ShipBase[i].LoadingPoint)
+		sd.ShipData[i].UnloadingPoint = int16(species.ShipBase[i].UnloadingPoint)
+		sd.ShipData[i].Special = int32(species.ShipBase[i].Special)
+		sd.ShipData[i].Reserved1 = uint8(species.ShipBase[i].Reserved1)
+		sd.ShipData[i].Reserved2 = int16(species.ShipBase[i].Reserved2)
+		sd.ShipData[i].Reserved3 = int16(species.ShipBase[i].Reserved3)
+		sd.ShipData[i].Reserved4 = int16(species.ShipBase[i].Reserved4)
+		for n := 0; n < len(sd.ShipData[i].Padding); n++ {
+			sd.ShipData[i].Padding[n] = uint8(species.ShipBase[i].Padding[n])
+		}
+		for n := 0; n < len(sd.ShipData[i].MorePadding); n++ {
+			sd.ShipData[i].MorePadding[n] = uint8(species.ShipBase[i].MorePadding[n])
+		}
+	}
+
+	return writeFile(name, bo, &sd.Species, sd.NampData, sd.ShipData)
+}
@@ -52,12 +52,21 @@ type Star struct {
 	WormX int `json:"worm_x"`
 	WormY int `json:"worm_y"`
 	WormZ int `json:"worm_z"`
+	/* Reserved for future use. Zero for now. */
+	Reserved1 int `json:"-"`
+	Reserved2 int `json:"-"`
 	/* Index (starting at zero) into the file "planets.dat" of the first planet in the star system. */
 	PlanetIndex int `json:"planet_index"`
 	/* Message associated with this star system, if any. */
 	Message int `json:"message"`
 	/* A bit is set if corresponding species has been here. */
 	VisitedBy []int `json:"visited_by"`
+	/* Reserved for future use. Zero for now. */
+	Reserved3 int `json:"-"`
+	Reserved4 int `json:"-"`
+	Reserved5 int `json:"-"`
+	// padding to make Go struct same size as C
+	Padding [2]int `json:"-"`
 }
 
 // ReadStars returns either an initialized set of stars or an error.
@@ -99,6 +108,14 @@ func ReadStars(name string, bo binary.ByteOrder) (*Stars, error) {
 		stars.Stars[i].WormZ = int(sd.StarBase[i].WormZ)
 		stars.Stars[i].PlanetIndex = int(sd.StarBase[i].PlanetIndex)
 		stars.Stars[i].Message = int(sd.StarBase[i].Message)
+		stars.Stars[i].Reserved1 = int(sd.StarBase[i].Reserved1)
+		stars.Stars[i].Reserved2 = int(sd.StarBase[i].Reserved2)
+		stars.Stars[i].Reserved3 = int(sd.StarBase[i].Reserved3)
+		stars.Stars[i].Reserved4 = int(sd.StarBase[i].Reserved4)
+		stars.Stars[i].Reserved5 = int(sd.StarBase[i].Reserved5)
+		for n := 0; n < len(sd.StarBase[i].Padding); n++ {
+			stars.Stars[i].Padding[n] = int(sd.StarBase[i].Padding[n])
+		}
 		for sp := 1; sp <= MAX_SPECIES; sp++ {
 			if speciesBitIsSet(sd.StarBase[i].VisitedBy, sp) {
 				stars.Stars[i].VisitedBy = append(stars.Stars[i].VisitedBy, sp)
@@ -108,3 +125,39 @@ func ReadStars(name string, bo binary.ByteOrder) (*Stars, error) {
 
 	return &stars, nil
 }
+
+// WriteStars writes the stars to a file using the same layout as ReadStars.
+func WriteStars(name string, stars *Stars, bo binary.ByteOrder) error {
+	var sd star_file_t
+	sd.NumStars = int32(len(stars.Stars))
+	sd.StarBase = make([]star_data, len(stars.Stars), len(stars.Stars))
+	for i := 0; i < len(stars.Stars); i++ {
+		sd.StarBase[i].X = int8(stars.Stars[i].X)
+		sd.StarBase[i].Y = int8(stars.Stars[i].Y)
+		sd.StarBase[i].Z = int8(stars.Stars[i].Z)
+		sd.StarBase[i].Type = int8(stars.Stars[i].Type)
+		sd.StarBase[i].Color = int8(stars.Stars[i].Color)
+		sd.StarBase[i].Size = int8(stars.Stars[i].Size)
+		sd.StarBase[i].NumPlanets = int8(stars.Stars[i].NumPlanets)
+		sd.StarBase[i].HomeSystem = int8(stars.Stars[i].HomeSystem)
+		sd.StarBase[i].WormHere = int8(stars.Stars[i].WormHere)
+		sd.StarBase[i].WormX = int8(stars.Stars[i].WormX)
+		sd.StarBase[i].WormY = int8(stars.Stars[i].WormY)
+		sd.StarBase[i].WormZ = int8(stars.Stars[i].WormZ)
+		sd.StarBase[i].PlanetIndex = int16(stars.Stars[i].PlanetIndex)
+		sd.StarBase[i].Message = int32(stars.Stars[i].Message)
+		sd.StarBase[i].Reserved1 = int16(stars.Stars[i].Reserved1)
+		sd.StarBase[i].Reserved2 = int16(stars.Stars[i].Reserved2)
+		sd.StarBase[i].Reserved3 = int32(stars.Stars[i].Reserved3)
+		sd.StarBase[i].Reserved4 = int32(stars.Stars[i].Reserved4)
+		sd.StarBase[i].Reserved5 = int32(stars.Stars[i].Reserved5)
+		for n := 0; n < len(sd.StarBase[i].Padding); n++ {
+			sd.StarBase[i].Padding[n] = uint8(stars.Stars[i].Padding[n])
+		}
+		for _, sp := range stars.Stars[i].VisitedBy {
+			setSpeciesBit(&sd.StarBase[i].VisitedBy, sp)
+		}
+	}
+
+	return writeFile(name, bo, sd.NumStars, sd.StarBase)
+}
@@ -0,0 +1,266 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+// Package doctor checks game data for the kinds of damage that hand-edits
+// leave behind. It works on a jsondb.Store because the cluster.Store loader
+// drops or panics on most of the records that it looks for; use the convert
+// package to load the other formats.
+package doctor
+
+import (
+	"fmt"
+	"github.com/mdhender/fhcms/cms/store/jsondb"
+	"github.com/mdhender/fhcms/internal/convert"
+	"strings"
+)
+
+// MIN_RADIUS and MAX_RADIUS are the limits on the radius of the cluster.
+const (
+	MIN_RADIUS = 6
+	MAX_RADIUS = 50
+)
+
+// Finding is a single problem and the suggested fix for it.
+type Finding struct {
+	Where   string // record with the problem
+	Problem string
+	Fix     string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s\n\tfix: %s", f.Where, f.Problem, f.Fix)
+}
+
+// Check returns all the problems found in the store.
+func Check(ds *jsondb.Store) []Finding {
+	var findings []Finding
+	findings = append(findings, checkGalaxy(ds)...)
+	findings = append(findings, checkWormholes(ds)...)
+	for _, sp := range ds.Species {
+		findings = append(findings, checkSpecies(ds, sp)...)
+	}
+	return findings
+}
+
+// checkGalaxy reports a radius that is out of range or too small for the stars.
+func checkGalaxy(ds *jsondb.Store) []Finding {
+	maxXYZ := 0
+	for _, star := range ds.Stars {
+		for _, n := range []int{star.X, star.Y, star.Z} {
+			if n > maxXYZ {
+				maxXYZ = n
+			}
+		}
+	}
+	radius := (maxXYZ + 2) / 2 // smallest radius that holds every star
+	if radius < MIN_RADIUS {
+		radius = MIN_RADIUS
+	}
+	if ds.Galaxy.Radius < MIN_RADIUS || ds.Galaxy.Radius > MAX_RADIUS || ds.Galaxy.Radius < radius {
+		return []Finding{{
+			Where:   "galaxy",
+			Problem: fmt.Sprintf("radius is %d, but the stars need a radius in the range %d..%d", ds.Galaxy.Radius, radius, MAX_RADIUS),
+			Fix:     fmt.Sprintf("set radius to %d", radius),
+		}}
+	}
+	return nil
+}
+
+// checkWormholes reports wormholes that don't lead to a star that leads back.
+func checkWormholes(ds *jsondb.Store) []Finding {
+	var findings []Finding
+	for _, star := range ds.Stars {
+		if star.WormHere == 0 {
+			continue
+		}
+		where := fmt.Sprintf("star %d at %d %d %d", star.Id, star.X, star.Y, star.Z)
+		other := findStar(ds, star.WormX, star.WormY, star.WormZ)
+		if other == nil {
+			findings = append(findings, Finding{
+				Where:   where,
+				Problem: fmt.Sprintf("wormhole leads to %d %d %d, which is not a star", star.WormX, star.WormY, star.WormZ),
+				Fix:     "set worm_here, worm_x, worm_y, and worm_z to 0",
+			})
+		} else if other == star {
+			findings = append(findings, Finding{
+				Where:   where,
+				Problem: "wormhole leads back to the same star",
+				Fix:     "set worm_here, worm_x, worm_y, and worm_z to 0",
+			})
+		} else if other.WormHere == 0 || other.WormX != star.X || other.WormY != star.Y || other.WormZ != star.Z {
+			findings = append(findings, Finding{
+				Where:   where,
+				Problem: fmt.Sprintf("wormhole leads to star %d, but star %d does not lead back", other.Id, other.Id),
+				Fix:     fmt.Sprintf("set star %d worm_here to 1 and worm_x, worm_y, worm_z to %d %d %d, or clear this wormhole", other.Id, star.X, star.Y, star.Z),
+			})
+		}
+	}
+	return findings
+}
+
+// checkSpecies reports problems with a species, its named planets, and its ships.
+func checkSpecies(ds *jsondb.Store, sp *jsondb.SpeciesData) []Finding {
+	var findings []Finding
+	where := fmt.Sprintf("SP%02d %q", sp.Id, sp.Name)
+
+	if sp.NumNamplas != len(sp.Namplas) {
+		findings = append(findings, Finding{
+			Where:   where,
+			Problem: fmt.Sprintf("num_namplas is %d but there are %d named planets", sp.NumNamplas, len(sp.Namplas)),
+			Fix:     fmt.Sprintf("set num_namplas to %d", len(sp.Namplas)),
+		})
+	}
+	if sp.NumShips != len(sp.Ships) {
+		findings = append(findings, Finding{
+			Where:   where,
+			Problem: fmt.Sprintf("num_ships is %d but there are %d ships", sp.NumShips, len(sp.Ships)),
+			Fix:     fmt.Sprintf("set num_ships to %d", len(sp.Ships)),
+		})
+	}
+
+	for _, list := range []struct {
+		name string
+		nos  []int
+	}{{"ally", sp.Ally}, {"contact", sp.Contact}, {"enemy", sp.Enemy}} {
+		for _, alienNo := range list.nos {
+			if alienNo < 1 || alienNo > len(ds.Species) {
+				findings = append(findings, Finding{
+					Where:   where,
+					Problem: fmt.Sprintf("%s list includes species %d, which does not exist", list.name, alienNo),
+					Fix:     fmt.Sprintf("remove %d from %s", alienNo, list.name),
+				})
+			}
+		}
+	}
+
+	for _, np := range sp.Namplas {
+		if np.Pn == 99 && strings.EqualFold(np.Name, "Unused") {
+			continue
+		}
+		where := fmt.Sprintf("SP%02d named planet %d %q", sp.Id, np.Id, np.Name)
+		star := findStar(ds, np.X, np.Y, np.Z)
+		if star == nil || np.Pn < 1 || np.Pn > star.NumPlanets {
+			findings = append(findings, Finding{
+				Where:   where,
+				Problem: fmt.Sprintf("%d %d %d %d is not a planet", np.X, np.Y, np.Z, np.Pn),
+				Fix:     "correct x, y, z, and pn, or delete the named planet",
+			})
+		} else if planetIndex := star.PlanetIndex + np.Pn - 1; np.PlanetIndex != planetIndex {
+			findings = append(findings, Finding{
+				Where:   where,
+				Problem: fmt.Sprintf("planet_index is %d, but the planet at %d %d %d %d is %d", np.PlanetIndex, np.X, np.Y, np.Z, np.Pn, planetIndex),
+				Fix:     fmt.Sprintf("set planet_index to %d", planetIndex),
+			})
+		}
+		findings = append(findings, checkInventory(where, np.ItemQuantity)...)
+	}
+
+	// a bad radius is reported by checkGalaxy, so don't report every ship for it
+	validRadius := checkGalaxy(ds) == nil
+	maxXYZ := 2*ds.Galaxy.Radius - 1
+	names := make(map[string]bool)
+	for _, ship := range sp.Ships {
+		if ship.Pn == 99 && strings.EqualFold(ship.Name, "Unused") {
+			continue
+		}
+		names[strings.ToUpper(ship.Name)] = true
+	}
+	seen := make(map[string]*jsondb.ShipData)
+	for _, ship := range sp.Ships {
+		if ship.Pn == 99 && strings.EqualFold(ship.Name, "Unused") {
+			continue
+		}
+		where := fmt.Sprintf("SP%02d ship %d %q", sp.Id, ship.Id, ship.Name)
+		if validRadius && (!inRange(ship.X, maxXYZ) || !inRange(ship.Y, maxXYZ) || !inRange(ship.Z, maxXYZ)) {
+			findings = append(findings, Finding{
+				Where:   where,
+				Problem: fmt.Sprintf("location %d %d %d is outside the cluster (0..%d)", ship.X, ship.Y, ship.Z, maxXYZ),
+				Fix:     fmt.Sprintf("move the ship to %d %d %d", clamp(ship.X, maxXYZ), clamp(ship.Y, maxXYZ), clamp(ship.Z, maxXYZ)),
+			})
+		}
+		if validRadius && (!inRange(ship.DestX, maxXYZ) || !inRange(ship.DestY, maxXYZ) || !inRange(ship.DestZ, maxXYZ)) {
+			findings = append(findings, Finding{
+				Where:   where,
+				Problem: fmt.Sprintf("destination %d %d %d is outside the cluster (0..%d)", ship.DestX, ship.DestY, ship.DestZ, maxXYZ),
+				Fix:     "set dest_x, dest_y, and dest_z to 0",
+			})
+		}
+		key := strings.ToUpper(ship.Name)
+		if first, ok := seen[key]; ok {
+			findings = append(findings, Finding{
+				Where:   where,
+				Problem: fmt.Sprintf("name is already used by ship %d", first.Id),
+				Fix:     fmt.Sprintf("rename the ship to %q", uniqueName(ship.Name, names)),
+			})
+		} else {
+			seen[key] = ship
+		}
+		findings = append(findings, checkInventory(where, ship.ItemQuantity)...)
+	}
+
+	return findings
+}
+
+// checkInventory reports items with a negative quantity.
+func checkInventory(where string, quantities []int) []Finding {
+	var findings []Finding
+	for item, qty := range quantities {
+		if qty < 0 {
+			findings = append(findings, Finding{
+				Where:   where,
+				Problem: fmt.Sprintf("inventory has %d %s", qty, convert.ItemCode(item)),
+				Fix:     fmt.Sprintf("set the quantity of %s to 0", convert.ItemCode(item)),
+			})
+		}
+	}
+	return findings
+}
+
+// findStar returns the star at the coordinates, or nil if there isn't one.
+func findStar(ds *jsondb.Store, x, y, z int) *jsondb.StarData {
+	for _, star := range ds.Stars {
+		if star.X == x && star.Y == y && star.Z == z {
+			return star
+		}
+	}
+	return nil
+}
+
+func inRange(n, max int) bool {
+	return 0 <= n && n <= max
+}
+
+func clamp(n, max int) int {
+	if n < 0 {
+		return 0
+	} else if n > max {
+		return max
+	}
+	return n
+}
+
+// uniqueName returns a name that isn't in use, adding it to the names in use.
+func uniqueName(name string, names map[string]bool) string {
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s %d", name, n)
+		if !names[strings.ToUpper(candidate)] {
+			names[strings.ToUpper(candidate)] = true
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,184 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package doctor
+
+import (
+	"fmt"
+	"github.com/mdhender/fhcms/cms/store/jsondb"
+	"github.com/mdhender/fhcms/internal/galaxy"
+	"testing"
+)
+
+// testStore returns a generated galaxy with two species and no problems.
+func testStore(t *testing.T) *jsondb.Store {
+	t.Helper()
+	ds, err := galaxy.New(galaxy.Config{Species: 2, Seed: 0x1234})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, name := range []string{"Alpha", "Bravo"} {
+		if _, err := galaxy.AddSpecies(ds, galaxy.SpeciesConfig{
+			Name:       name,
+			GovtName:   name + " Government",
+			GovtType:   "Monarchy",
+			HomePlanet: name + " Prime",
+			ML:         4, GV: 4, LS: 4, BI: 3,
+			Seed: uint64(i + 1),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if findings := Check(ds); len(findings) != 0 {
+		t.Fatalf("generated galaxy: got %v, want no findings", findings)
+	}
+	return ds
+}
+
+// TestCheck corrupts a generated galaxy in one way per case and checks
+// that Check reports the single problem and suggests the right fix.
+func TestCheck(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		corrupt func(ds *jsondb.Store) Finding // returns the expected finding
+	}{
+		{"radius", func(ds *jsondb.Store) Finding {
+			// the smallest radius that holds every star
+			radius := MIN_RADIUS
+			for _, star := range ds.Stars {
+				for _, n := range []int{star.X, star.Y, star.Z} {
+					if n >= 2*radius-1 {
+						radius = n/2 + 1
+					}
+				}
+			}
+			ds.Galaxy.Radius = MAX_RADIUS + 1
+			return Finding{
+				Where:   "galaxy",
+				Problem: "radius is 51, but the stars need a radius in the range " + itoa(radius) + "..50",
+				Fix:     "set radius to " + itoa(radius),
+			}
+		}},
+		{"wormhole pair", func(ds *jsondb.Store) Finding {
+			var a, b *jsondb.StarData
+			for _, star := range ds.Stars {
+				if star.WormHere != 0 {
+					continue
+				} else if a == nil {
+					a = star
+				} else {
+					b = star
+					break
+				}
+			}
+			a.WormHere, a.WormX, a.WormY, a.WormZ = 1, b.X, b.Y, b.Z
+			return Finding{
+				Where:   "star " + itoa(a.Id) + " at " + xyz(a.X, a.Y, a.Z),
+				Problem: "wormhole leads to star " + itoa(b.Id) + ", but star " + itoa(b.Id) + " does not lead back",
+				Fix:     "set star " + itoa(b.Id) + " worm_here to 1 and worm_x, worm_y, worm_z to " + xyz(a.X, a.Y, a.Z) + ", or clear this wormhole",
+			}
+		}},
+		{"planet_index", func(ds *jsondb.Store) Finding {
+			np := ds.Species[0].Namplas[0]
+			want := np.PlanetIndex
+			np.PlanetIndex++
+			return Finding{
+				Where:   `SP01 named planet 0 "Alpha Prime"`,
+				Problem: "planet_index is " + itoa(want+1) + ", but the planet at " + xyz(np.X, np.Y, np.Z) + " " + itoa(np.Pn) + " is " + itoa(want),
+				Fix:     "set planet_index to " + itoa(want),
+			}
+		}},
+		{"negative inventory", func(ds *jsondb.Store) Finding {
+			ds.Species[1].Namplas[0].ItemQuantity[5] = -10
+			return Finding{
+				Where:   `SP02 named planet 0 "Bravo Prime"`,
+				Problem: "inventory has -10 IU",
+				Fix:     "set the quantity of IU to 0",
+			}
+		}},
+		{"num_namplas", func(ds *jsondb.Store) Finding {
+			ds.Species[0].NumNamplas = 3
+			return Finding{
+				Where:   `SP01 "Alpha"`,
+				Problem: "num_namplas is 3 but there are 1 named planets",
+				Fix:     "set num_namplas to 1",
+			}
+		}},
+		{"num_ships", func(ds *jsondb.Store) Finding {
+			ds.Species[0].NumShips = 2
+			return Finding{
+				Where:   `SP01 "Alpha"`,
+				Problem: "num_ships is 2 but there are 0 ships",
+				Fix:     "set num_ships to 0",
+			}
+		}},
+		{"duplicate name", func(ds *jsondb.Store) Finding {
+			sp, home := ds.Species[0], ds.Species[0].Namplas[0]
+			for id := 1; id <= 2; id++ {
+				sp.Ships = append(sp.Ships, &jsondb.ShipData{Id: id, Name: "Scout", X: home.X, Y: home.Y, Z: home.Z, Pn: home.Pn, ItemQuantity: []int{}})
+			}
+			sp.NumShips = len(sp.Ships)
+			return Finding{
+				Where:   `SP01 ship 2 "Scout"`,
+				Problem: "name is already used by ship 1",
+				Fix:     `rename the ship to "Scout 2"`,
+			}
+		}},
+		{"unknown species in contact", func(ds *jsondb.Store) Finding {
+			ds.Species[0].Contact = append(ds.Species[0].Contact, 3)
+			return Finding{
+				Where:   `SP01 "Alpha"`,
+				Problem: "contact list includes species 3, which does not exist",
+				Fix:     "remove 3 from contact",
+			}
+		}},
+		{"unknown species in ally", func(ds *jsondb.Store) Finding {
+			ds.Species[1].Ally = append(ds.Species[1].Ally, 0)
+			return Finding{
+				Where:   `SP02 "Bravo"`,
+				Problem: "ally list includes species 0, which does not exist",
+				Fix:     "remove 0 from ally",
+			}
+		}},
+		{"unknown species in enemy", func(ds *jsondb.Store) Finding {
+			ds.Species[1].Enemy = append(ds.Species[1].Enemy, 7)
+			return Finding{
+				Where:   `SP02 "Bravo"`,
+				Problem: "enemy list includes species 7, which does not exist",
+				Fix:     "remove 7 from enemy",
+			}
+		}},
+	} {
+		ds := testStore(t)
+		want := tc.corrupt(ds)
+		findings := Check(ds)
+		if len(findings) != 1 {
+			t.Errorf("%s: got %d findings %v, want 1", tc.name, len(findings), findings)
+		} else if findings[0] != want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tc.name, findings[0], want)
+		}
+	}
+}
+
+func itoa(n int) string {
+	return fmt.Sprint(n)
+}
+
+func xyz(x, y, z int) string {
+	return fmt.Sprintf("%d %d %d", x, y, z)
+}
@@ -19,10 +19,12 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 package domain
 
 import (
+	"github.com/mdhender/fhcms/internal/archive"
 	"github.com/mdhender/fhcms/internal/models"
 	"github.com/mdhender/fhcms/internal/repos/accounts"
 	"github.com/mdhender/fhcms/internal/repos/games"
 	"log"
+	"path/filepath"
 	"sort"
 )
 
@@ -121,19 +123,26 @@ func (s *Store) FetchSpecie(uid string, gid, spid int, turnNo int) *models.Speci
 		log.Printf("[domain] FetchSpecie %q %q %q %d: player spoofing species!\n", uid, gid, spid, turnNo)
 		return &models.Specie{}
 	}
-	var gtf *games.GameTurnFile
-	for _, file := range g.Turns.Files {
-		if file.Turn == turnNo {
-			gtf = file
-			break
+	spNo := g.Players[uid]
+	var sp *Specie
+	var err error
+	if a := archive.Open(filepath.Join(g.Files, "archive")); a.HasTurn(turnNo) {
+		sp, err = s.loadArchivedSpecie(a, spNo, turnNo)
+	} else {
+		// fall back to the files for turns from before the archive
+		var gtf *games.GameTurnFile
+		for _, file := range g.Turns.Files {
+			if file.Turn == turnNo {
+				gtf = file
+				break
+			}
 		}
+		if gtf == nil {
+			log.Printf("[domain] FetchSpecie %q %q %q %d: no such turn\n", uid, gid, spid, turnNo)
+			return &models.Specie{}
+		}
+		sp, err = s.loadSpecie(gtf.Files, spNo)
 	}
-	if gtf == nil {
-		log.Printf("[domain] FetchSpecie %q %q %q %d: no such turn\n", uid, gid, spid, turnNo)
-		return &models.Specie{}
-	}
-	spNo := "sp18" // todo: remove this hack
-	sp, err := s.loadSpecie(gtf.Files, spNo)
 	if err != nil {
 		log.Printf("[domain] FetchSpecie %q %q %q %d: %+v\n", uid, gid, spid, turnNo, err)
 		return &models.Specie{}
@@ -20,10 +20,12 @@ package domain
 
 import (
 	"encoding/binary"
+	"github.com/mdhender/fhcms/internal/archive"
 	"github.com/mdhender/fhcms/internal/dat32"
 	"github.com/spf13/viper"
 	"log"
 	"path/filepath"
+	"strconv"
 )
 
 type Specie struct {
@@ -62,32 +64,8 @@ func (s *Store) loadSpecie(files, spNo string) (*Specie, error) {
 		log.Printf("[domain] loadSpecie %q %q %+v\n", files, spNo, err)
 		return &Specie{}, err
 	}
-	o := &Specie{
-		No:   spNo,
-		Name: sp.Name,
-	}
-	o.Government.Name = sp.GovtName
+	o := newSpecie(spNo, sp.Name, sp.GovtName)
 	for i := 0; i < 6; i++ {
-		switch i {
-		case 0:
-			o.Technology[i].Code = "MI"
-			o.Technology[i].Name = "Mining"
-		case 1:
-			o.Technology[i].Code = "MA"
-			o.Technology[i].Name = "Manufacturing"
-		case 2:
-			o.Technology[i].Code = "ML"
-			o.Technology[i].Name = "Military"
-		case 3:
-			o.Technology[i].Code = "GV"
-			o.Technology[i].Name = "Gravitics"
-		case 4:
-			o.Technology[i].Code = "LS"
-			o.Technology[i].Name = "Life Support"
-		case 5:
-			o.Technology[i].Code = "BI"
-			o.Technology[i].Name = "Biology"
-		}
 		o.Technology[i].CurrentLevel = sp.TechLevel[i]
 		o.Technology[i].InitialLevel = sp.InitTechLevel[i]
 		o.Technology[i].KnowledgeLevel = sp.TechKnowledge[i]
@@ -101,18 +79,69 @@ func (s *Store) loadSpecie(files, spNo string) (*Specie, error) {
 		}
 		shipyards += nampla.Shipyards
 	}
+	o.addStats(shipyards, sp.FleetPercentCost)
+
+	return o, nil
+}
+
+// loadArchivedSpecie loads the species as of the turn from the turn archive.
+func (s *Store) loadArchivedSpecie(a *archive.Archive, spNo string, turn int) (*Specie, error) {
+	no, err := strconv.Atoi(spNo)
+	if err != nil {
+		return &Specie{}, err
+	}
+	sp, err := a.Species(no, turn)
+	if err != nil {
+		log.Printf("[domain] loadArchivedSpecie %q %d %+v\n", spNo, turn, err)
+		return &Specie{}, err
+	}
+	o := newSpecie(spNo, sp.Name, sp.GovtName)
+	for i := 0; i < 6; i++ {
+		o.Technology[i].CurrentLevel = sp.TechLevel[i]
+		o.Technology[i].InitialLevel = sp.InitTechLevel[i]
+		o.Technology[i].KnowledgeLevel = sp.TechKnowledge[i]
+		o.Technology[i].ExperiencePoints = sp.TechEps[i]
+	}
+
+	shipyards := 0
+	for _, nampla := range sp.Namplas {
+		if nampla.Pn == 99 {
+			continue
+		}
+		shipyards += nampla.Shipyards
+	}
+	o.addStats(shipyards, sp.FleetPercentCost)
+
+	return o, nil
+}
+
+func newSpecie(spNo, name, govtName string) *Specie {
+	o := &Specie{
+		No:   spNo,
+		Name: name,
+	}
+	o.Government.Name = govtName
+	for i, tech := range []struct{ code, name string }{
+		{"MI", "Mining"}, {"MA", "Manufacturing"}, {"ML", "Military"},
+		{"GV", "Gravitics"}, {"LS", "Life Support"}, {"BI", "Biology"},
+	} {
+		o.Technology[i].Code = tech.code
+		o.Technology[i].Name = tech.name
+	}
+	return o
+}
+
+func (o *Specie) addStats(shipyards, fleetPercentCost int) {
 	o.Stats = append(o.Stats, &SpecieStat{Label: "Shipyards", Value: float64(shipyards), Units: "yards"})
 
 	// why the check on fleet maintenance cost?
 	var fleetMaintenancePct float64
-	if sp.FleetPercentCost < 0 {
+	if fleetPercentCost < 0 {
 		fleetMaintenancePct = 0
-	} else if sp.FleetPercentCost < 10000 {
-		fleetMaintenancePct = float64(sp.FleetPercentCost) / 100
+	} else if fleetPercentCost < 10000 {
+		fleetMaintenancePct = float64(fleetPercentCost) / 100
 	} else {
 		fleetMaintenancePct = 100
 	}
 	o.Stats = append(o.Stats, &SpecieStat{Label: "Fleet Maintenance", Value: fleetMaintenancePct, Units: "%"})
-
-	return o, nil
 }
@@ -0,0 +1,1137 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+)
+
+func (e *Engine) finish(argv ...string) {
+	var i, j, n, don, nampla_index, ship_index, ls_needed int
+	var ls_actual, tech, turn_number, percent_increase, old_tech_level int
+	var new_tech_level, experience_points, their_level, my_level int
+	var new_level, orders_received, alien_number int
+	var production_penalty, max_tech_level int
+	var ns int
+	var change, total_pop_units, salvage_EUs int
+	var salvage_value, original_cost, ib, ab, increment, old_base int
+	var max_cost, actual_cost, one_point_cost int
+	var ib_increment, ab_increment, md, growth_factor, denom int
+	var fleet_maintenance_cost, balance, total_species_production int
+	var RMs_produced, production_capacity, eb int
+	var home_planet, planet *planet_data
+	var donor_species *species_data
+	var home_nampla *nampla_data
+
+	/* Check for options, if any. */
+	e.test_mode = FALSE
+	e.verbose_mode = FALSE
+	for i = 0; i < len(argv); i++ {
+		if argv[i] == "-t" {
+			e.test_mode = TRUE
+		}
+		if argv[i] == "-v" {
+			e.verbose_mode = TRUE
+		}
+	}
+
+	/* Handle turn number. */
+	e.galaxy.turn_number++
+	turn_number = e.galaxy.turn_number
+
+	/* Do mining difficulty increases for each planet. */
+	for i = 0; i < len(e.planet_base); i++ {
+		planet = e.planet_base[i]
+		planet.mining_difficulty += planet.md_increase
+		planet.md_increase = 0
+	}
+
+	/* Main loop. For each species, take appropriate action. */
+	if e.prompt_gm {
+		log.Printf("Finishing up for all species...\n")
+	}
+	for e.species_number = 1; e.species_number <= e.galaxy.num_species; e.species_number++ {
+		e.species_index = e.species_number - 1
+		if e.species = e.spec_data[e.species_index]; e.species == nil {
+			continue
+		}
+		e.nampla_base = e.namp_data[e.species_index]
+		e.ship_base = e.ship_data[e.species_index]
+
+		/* Check if player submitted orders for this turn. */
+		if e.spec_orders[e.species_index] == nil {
+			orders_received = FALSE
+		} else {
+			orders_received = TRUE
+		}
+		if turn_number == 1 {
+			orders_received = TRUE
+		}
+
+		/* Display name of species. */
+		if e.prompt_gm {
+			if orders_received == FALSE {
+				log.Printf("  Now doing SP %s... WARNING: player did not submit orders this turn!\n", e.species.name)
+			} else {
+				log.Printf("  Now doing SP %s...\n", e.species.name)
+			}
+		}
+
+		/* Open log file for appending. */
+		filename := fmt.Sprintf("sp%02d.log", e.species_number)
+		if e.spec_logs[e.species_index] == nil {
+			e.spec_logs[e.species_index] = &bytes.Buffer{}
+		}
+		e.log_file = fopen(filename, e.spec_logs[e.species_index])
+		e.append_log[e.species_index] = TRUE
+		e.log_stdout = FALSE
+		e.header_printed = FALSE
+
+		if turn_number == 1 {
+			goto check_for_message
+		}
+
+		/* Check if any ships of this species experienced mishaps. */
+		for i = 0; i < e.num_transactions; i++ {
+			if e.transaction[i]._type == SHIP_MISHAP && e.transaction[i].number1 == e.species_number {
+				if e.header_printed == FALSE {
+					e.print_header()
+				}
+				e.log_string("  !!! ")
+				e.log_string(e.transaction[i].name1)
+				if e.transaction[i].value < 3 {
+					/* Intercepted or self-destructed. */
+					e.log_string(" disappeared without a trace, cause unknown!\n")
+				} else if e.transaction[i].value == 3 {
+					/* Mis-jumped. */
+					e.log_string(" mis-jumped to ")
+					e.log_int(e.transaction[i].x)
+					e.log_char(' ')
+					e.log_int(e.transaction[i].y)
+					e.log_char(' ')
+					e.log_int(e.transaction[i].z)
+					e.log_string("!\n")
+				} else {
+					/* One fail-safe jump unit used. */
+					e.log_string(" had a jump mishap! A fail-safe jump unit was expended.\n")
+				}
+			}
+		}
+
+		/* Take care of any disbanded colonies. */
+		for nampla_index = 0; nampla_index < e.species.num_namplas; nampla_index++ {
+			e.nampla = e.nampla_base[nampla_index]
+
+			if (e.nampla.status & DISBANDED_COLONY) == 0 {
+				continue
+			}
+
+			/* Salvage ships on the surface and starbases in orbit. */
+			salvage_EUs = 0
+			for ship_index = 0; ship_index < e.species.num_ships; ship_index++ {
+				e.ship = e.ship_base[ship_index]
+				if e.nampla.x != e.ship.x || e.nampla.y != e.ship.y || e.nampla.z != e.ship.z || e.nampla.pn != e.ship.pn {
+					continue
+				}
+				if e.ship._type != STARBASE && e.ship.status == IN_ORBIT {
+					continue
+				}
+
+				/* Transfer cargo to planet. */
+				for i = 0; i < MAX_ITEMS; i++ {
+					e.nampla.item_quantity[i] += e.ship.item_quantity[i]
+				}
+
+				/* Salvage the ship. */
+				if e.ship.class == TR || e.ship._type == STARBASE {
+					original_cost = ship_cost[e.ship.class] * e.ship.tonnage
+				} else {
+					original_cost = ship_cost[e.ship.class]
+				}
+
+				if e.ship._type == SUB_LIGHT {
+					original_cost = (3 * original_cost) / 4
+				}
+
+				if e.ship.status == UNDER_CONSTRUCTION {
+					salvage_value = (original_cost - e.ship.remaining_cost) / 4
+				} else {
+					salvage_value = (3 * original_cost * (60 - e.ship.age)) / 400
+				}
+
+				salvage_EUs += salvage_value
+
+				/* Destroy the ship. */
+				e.delete_ship(e.ship)
+			}
+
+			/* Salvage items on the planet. */
+			for i = 0; i < MAX_ITEMS; i++ {
+				if i == RM {
+					salvage_value = e.nampla.item_quantity[RM] / 10
+				} else if e.nampla.item_quantity[i] > 0 {
+					original_cost = e.nampla.item_quantity[i] * item_cost[i]
+					if i == TP {
+						if e.species.tech_level[BI] > 0 {
+							original_cost /= e.species.tech_level[BI]
+						} else {
+							original_cost /= 100
+						}
+					}
+					salvage_value = original_cost / 4
+				} else {
+					salvage_value = 0
+				}
+
+				salvage_EUs += salvage_value
+			}
+
+			/* Transfer EUs to species. */
+			e.species.econ_units += salvage_EUs
+
+			/* Log what happened. */
+			if e.header_printed == FALSE {
+				e.print_header()
+			}
+			e.log_string("  PL ")
+			e.log_string(e.nampla.name)
+			e.log_string(" was disbanded, generating ")
+			e.log_long(salvage_EUs)
+			e.log_string(" economic units in salvage.\n")
+
+			/* Destroy the colony. */
+			e.delete_nampla(e.nampla)
+		}
+
+		/* Check if this species is the recipient of a transfer of economic units from another species. */
+		for i = 0; i < e.num_transactions; i++ {
+			if e.transaction[i].recipient == e.species_number && (e.transaction[i]._type == EU_TRANSFER || e.transaction[i]._type == SIEGE_EU_TRANSFER || e.transaction[i]._type == LOOTING_EU_TRANSFER) {
+				/* Transfer EUs to attacker if this is a siege or looting
+				 * transfer. If this is a normal transfer, then just log
+				 * the result since the actual transfer was done when the
+				 * order was processed. */
+				if e.transaction[i]._type != EU_TRANSFER {
+					e.species.econ_units += e.transaction[i].value
+				}
+
+				if e.header_printed == FALSE {
+					e.print_header()
+				}
+				e.log_string("  ")
+				e.log_long(e.transaction[i].value)
+				e.log_string(" economic units were received from SP ")
+				e.log_string(e.transaction[i].name1)
+				if e.transaction[i]._type == SIEGE_EU_TRANSFER {
+					e.log_string(" as a result of your successful siege of their PL ")
+					e.log_string(e.transaction[i].name3)
+					e.log_string(". The siege was ")
+					e.log_long(e.transaction[i].number1)
+					e.log_string("% effective")
+				} else if e.transaction[i]._type == LOOTING_EU_TRANSFER {
+					e.log_string(" as a result of your looting their PL ")
+					e.log_string(e.transaction[i].name3)
+				}
+				e.log_string(".\n")
+			}
+		}
+
+		/* Check if any jump portals of this species were used by aliens. */
+		for i = 0; i < e.num_transactions; i++ {
+			if e.transaction[i]._type == ALIEN_JUMP_PORTAL_USAGE && e.transaction[i].number1 == e.species_number {
+				if e.header_printed == FALSE {
+					e.print_header()
+				}
+				e.log_string("  ")
+				e.log_string(e.transaction[i].name1)
+				e.log_char(' ')
+				e.log_string(e.transaction[i].name2)
+				e.log_string(" used jump portal ")
+				e.log_string(e.transaction[i].name3)
+				e.log_string(".\n")
+			}
+		}
+
+		/* Check if any starbases of this species detected the use of gravitic telescopes by aliens. */
+		for i = 0; i < e.num_transactions; i++ {
+			if e.transaction[i]._type == TELESCOPE_DETECTION && e.transaction[i].number1 == e.species_number {
+				if e.header_printed == FALSE {
+					e.print_header()
+				}
+				e.log_string("! ")
+				e.log_string(e.transaction[i].name1)
+				e.log_string(" detected the operation of an alien gravitic telescope at x = ")
+				e.log_int(e.transaction[i].x)
+				e.log_string(", y = ")
+				e.log_int(e.transaction[i].y)
+				e.log_string(", z = ")
+				e.log_int(e.transaction[i].z)
+				e.log_string(".\n")
+			}
+		}
+
+		/* Check if this species is the recipient of a tech transfer from another species. */
+		for i = 0; i < e.num_transactions; i++ {
+			if e.transaction[i]._type == TECH_TRANSFER && e.transaction[i].recipient == e.species_number {
+				don = e.transaction[i].donor - 1
+
+				/* Try to transfer technology. */
+				if e.header_printed == FALSE {
+					e.print_header()
+				}
+				e.log_string("  ")
+				tech = e.transaction[i].value
+				e.log_string(tech_name[tech])
+				e.log_string(" tech transfer from SP ")
+				e.log_string(e.transaction[i].name1)
+				their_level = e.transaction[i].number3
+				my_level = e.species.tech_level[tech]
+
+				if their_level <= my_level {
+					e.log_string(" failed.\n")
+					e.transaction[i].number1 = -1
+					continue
+				}
+
+				new_level = my_level
+				max_cost = e.transaction[i].number1
+				donor_species = e.spec_data[don]
+				if max_cost == 0 {
+					max_cost = donor_species.econ_units
+				} else if donor_species.econ_units < max_cost {
+					max_cost = donor_species.econ_units
+				}
+				actual_cost = 0
+				for new_level < their_level {
+					one_point_cost = new_level * new_level
+					one_point_cost -= one_point_cost / 4 /* 25% discount. */
+					if (actual_cost + one_point_cost) > max_cost {
+						break
+					}
+					actual_cost += one_point_cost
+					new_level++
+				}
+
+				if new_level == my_level {
+					e.log_string(" failed due to lack of funding.\n")
+					e.transaction[i].number1 = -2
+				} else {
+					e.log_string(" raised your tech level from ")
+					e.log_int(my_level)
+					e.log_string(" to ")
+					e.log_int(new_level)
+					e.log_string(" at a cost to them of ")
+					e.log_long(actual_cost)
+					e.log_string(".\n")
+					e.transaction[i].number1 = actual_cost
+					e.transaction[i].number2 = my_level
+					e.transaction[i].number3 = new_level
+
+					e.species.tech_level[tech] = new_level
+					donor_species.econ_units -= actual_cost
+				}
+			}
+		}
+
+		/* Calculate tech level increases. */
+		for tech = MI; tech <= BI; tech++ {
+			old_tech_level = e.species.tech_level[tech]
+			new_tech_level = old_tech_level
+
+			experience_points = e.species.tech_eps[tech]
+			if experience_points == 0 {
+				max_tech_level = 9999
+				goto check_random
+			}
+
+			/* Determine increase as if there were NO randomness in the process. */
+			i = experience_points
+			j = old_tech_level
+			for i >= j*j {
+				i -= j * j
+				j++
+			}
+
+			/* When extremely large amounts are spent on research, tech
+			 * level increases are sometimes excessive. Set a limit. */
+			if old_tech_level > 50 {
+				max_tech_level = j + 1
+			} else {
+				max_tech_level = 9999
+			}
+
+			/* Allocate half of the calculated increase NON-RANDOMLY. */
+			n = (j - old_tech_level) / 2
+			for i = 0; i < n; i++ {
+				experience_points -= new_tech_level * new_tech_level
+				new_tech_level++
+			}
+
+			/* Allocate the rest randomly. */
+			for experience_points >= new_tech_level {
+				experience_points -= new_tech_level
+				n = new_tech_level
+
+				/* The chance of success is 1 in n. At this point, n is always at least 1. */
+				i = e.rnd(16 * n)
+				if i >= 8*n && i <= 8*n+15 {
+					new_tech_level = n + 1
+				}
+			}
+
+			/* Save unused experience points. */
+			e.species.tech_eps[tech] = experience_points
+
+		check_random:
+
+			/* See if any random increase occurred. Odds are 1 in 6. */
+			if old_tech_level > 0 && e.rnd(6) == 6 {
+				new_tech_level++
+			}
+
+			if new_tech_level > max_tech_level {
+				new_tech_level = max_tech_level
+			}
+
+			/* Report result only if tech level went up. */
+			if new_tech_level > old_tech_level {
+				if e.header_printed == FALSE {
+					e.print_header()
+				}
+				e.log_string("  ")
+				e.log_string(tech_name[tech])
+				e.log_string(" tech level rose from ")
+				e.log_int(old_tech_level)
+				e.log_string(" to ")
+				e.log_int(new_tech_level)
+				e.log_string(".\n")
+
+				e.species.tech_level[tech] = new_tech_level
+			}
+		}
+
+		/* Notify of any new high tech items. */
+		for tech = MI; tech <= BI; tech++ {
+			old_tech_level = e.species.init_tech_level[tech]
+			new_tech_level = e.species.tech_level[tech]
+
+			if new_tech_level > old_tech_level {
+				e.check_high_tech_items(tech, old_tech_level, new_tech_level)
+			}
+
+			e.species.init_tech_level[tech] = new_tech_level
+		}
+
+		/* Check if this species is the recipient of a knowledge transfer from another species. */
+		for i = 0; i < e.num_transactions; i++ {
+			if e.transaction[i]._type == KNOWLEDGE_TRANSFER && e.transaction[i].recipient == e.species_number {
+				/* Try to transfer technology. */
+				tech = e.transaction[i].value
+				their_level = e.transaction[i].number3
+				my_level = e.species.tech_level[tech]
+				n = e.species.tech_knowledge[tech]
+				if n > my_level {
+					my_level = n
+				}
+
+				if their_level <= my_level {
+					continue
+				}
+
+				e.species.tech_knowledge[tech] = their_level
+
+				if e.header_printed == FALSE {
+					e.print_header()
+				}
+				e.log_string("  SP ")
+				e.log_string(e.transaction[i].name1)
+				e.log_string(" transferred knowledge of ")
+				e.log_string(tech_name[tech])
+				e.log_string(" to you up to tech level ")
+				e.log_long(their_level)
+				e.log_string(".\n")
+			}
+		}
+
+		/* Loop through each nampla for this species. */
+		home_nampla = e.nampla_base[0]
+		home_planet = e.planet_base[home_nampla.planet_index]
+		for nampla_index = 0; nampla_index < e.species.num_namplas; nampla_index++ {
+			e.nampla = e.nampla_base[nampla_index]
+
+			if e.nampla.pn == 99 {
+				continue
+			}
+
+			/* Get planet pointer. */
+			planet = e.planet_base[e.nampla.planet_index]
+
+			/* Clear any amount spent on ambush. */
+			e.nampla.use_on_ambush = 0
+
+			/* Handle HIDE order. */
+			e.nampla.hidden = e.nampla.hiding
+			e.nampla.hiding = FALSE
+
+			/* Check if any IUs or AUs were installed. */
+			if e.nampla.IUs_to_install > 0 {
+				e.nampla.mi_base += e.nampla.IUs_to_install
+				e.nampla.IUs_to_install = 0
+			}
+
+			if e.nampla.AUs_to_install > 0 {
+				e.nampla.ma_base += e.nampla.AUs_to_install
+				e.nampla.AUs_to_install = 0
+			}
+
+			/* Check if another species on the same planet has become assimilated. */
+			for i = 0; i < e.num_transactions; i++ {
+				if e.transaction[i]._type == ASSIMILATION && e.transaction[i].value == e.species_number && e.transaction[i].x == e.nampla.x && e.transaction[i].y == e.nampla.y && e.transaction[i].z == e.nampla.z && e.transaction[i].pn == e.nampla.pn {
+					ib = e.transaction[i].number1
+					ab = e.transaction[i].number2
+					ns = e.transaction[i].number3
+					e.nampla.mi_base += ib
+					e.nampla.ma_base += ab
+					e.nampla.shipyards += ns
+
+					if e.header_printed == FALSE {
+						e.print_header()
+					}
+
+					e.log_string("  Assimilation of ")
+					e.log_string(e.transaction[i].name1)
+					e.log_string(" PL ")
+					e.log_string(e.transaction[i].name2)
+					e.log_string(" increased mining base of ")
+					e.log_string(e.species.name)
+					e.log_string(" PL ")
+					e.log_string(e.nampla.name)
+					e.log_string(" by ")
+					e.log_long(ib / 10)
+					e.log_char('.')
+					e.log_long(ib % 10)
+					e.log_string(", and manufacturing base by ")
+					e.log_long(ab / 10)
+					e.log_char('.')
+					e.log_long(ab % 10)
+					if ns > 0 {
+						e.log_string(". Number of shipyards was also increased by ")
+						e.log_int(ns)
+					}
+					e.log_string(".\n")
+				}
+			}
+
+			/* Calculate available population for this turn. */
+			e.nampla.pop_units = 0
+
+			eb = e.nampla.mi_base + e.nampla.ma_base
+			total_pop_units = eb + e.nampla.item_quantity[CU] + e.nampla.item_quantity[PD]
+
+			if (e.nampla.status & HOME_PLANET) != 0 {
+				if (e.nampla.status & POPULATED) != 0 {
+					e.nampla.pop_units = HP_AVAILABLE_POP
+					if e.species.hp_original_base != 0 { /* HP was bombed. */
+						if eb >= e.species.hp_original_base {
+							e.species.hp_original_base = 0 /* Fully recovered. */
+						} else {
+							e.nampla.pop_units = (eb * HP_AVAILABLE_POP) / e.species.hp_original_base
+						}
+					}
+				}
+			} else if (e.nampla.status & POPULATED) != 0 {
+				/* Get life support tech level needed. */
+				ls_needed = life_support_needed(e.species, home_planet, planet)
+
+				/* Basic percent increase is 10*(1 - ls_needed/ls_actual). */
+				ls_actual = e.species.tech_level[LS]
+				percent_increase = 10 * (100 - ((100 * ls_needed) / ls_actual))
+
+				if percent_increase < 0 { /* Colony wiped out! */
+					if e.header_printed == FALSE {
+						e.print_header()
+					}
+
+					e.log_string("  !!! Life support tech level was too low to support colony on PL ")
+					e.log_string(e.nampla.name)
+					e.log_string(". Colony was destroyed.\n")
+
+					e.nampla.status = COLONY /* No longer populated or self-sufficient. */
+					e.nampla.mi_base = 0
+					e.nampla.ma_base = 0
+					e.nampla.pop_units = 0
+					e.nampla.item_quantity[PD] = 0
+					e.nampla.item_quantity[CU] = 0
+					e.nampla.siege_eff = 0
+				} else {
+					percent_increase /= 100
+
+					/* Add a small random variation. */
+					percent_increase += e.rnd(percent_increase/4) - e.rnd(percent_increase/4)
+
+					/* Add bonus for Biology technology. */
+					percent_increase += e.species.tech_level[BI] / 20
+
+					/* Calculate and apply the change. */
+					change = (percent_increase * total_pop_units) / 100
+
+					if e.nampla.mi_base > 0 && e.nampla.ma_base == 0 {
+						e.nampla.status |= MINING_COLONY
+						change = 0
+					} else if (e.nampla.status & MINING_COLONY) != 0 {
+						/* A former mining colony has been converted to a normal colony. */
+						e.nampla.status &= ^MINING_COLONY
+						change = 0
+					}
+
+					if e.nampla.ma_base > 0 && e.nampla.mi_base == 0 && ls_needed <= 6 && planet.gravity <= home_planet.gravity {
+						e.nampla.status |= RESORT_COLONY
+						change = 0
+					} else if (e.nampla.status & RESORT_COLONY) != 0 {
+						/* A former resort colony has been converted to a normal colony. */
+						e.nampla.status &= ^RESORT_COLONY
+						change = 0
+					}
+
+					if total_pop_units == e.nampla.item_quantity[PD] {
+						change = 0 /* Probably an invasion force. */
+					}
+					e.nampla.pop_units = change
+				}
+			}
+
+			/* Handle losses due to attrition and update location array if planet is still populated. */
+			if (e.nampla.status & POPULATED) != 0 {
+				total_pop_units = e.nampla.pop_units + e.nampla.mi_base + e.nampla.ma_base + e.nampla.item_quantity[CU] + e.nampla.item_quantity[PD]
+
+				if total_pop_units > 0 && total_pop_units < 50 {
+					if e.nampla.pop_units > 0 {
+						e.nampla.pop_units--
+						goto do_auto_increases
+					} else if e.nampla.item_quantity[CU] > 0 {
+						e.nampla.item_quantity[CU]--
+						if e.header_printed == FALSE {
+							e.print_header()
+						}
+						e.log_string("  Number of colonist units on PL ")
+						e.log_string(e.nampla.name)
+						e.log_string(" was reduced by one unit due to normal attrition.")
+					} else if e.nampla.item_quantity[PD] > 0 {
+						e.nampla.item_quantity[PD]--
+						if e.header_printed == FALSE {
+							e.print_header()
+						}
+						e.log_string("  Number of planetary defense units on PL ")
+						e.log_string(e.nampla.name)
+						e.log_string(" was reduced by one unit due to normal attrition.")
+					} else if e.nampla.ma_base > 0 {
+						e.nampla.ma_base--
+						if e.header_printed == FALSE {
+							e.print_header()
+						}
+						e.log_string("  Manufacturing base of PL ")
+						e.log_string(e.nampla.name)
+						e.log_string(" was reduced by 0.1 due to normal attrition.")
+					} else {
+						e.nampla.mi_base--
+						if e.header_printed == FALSE {
+							e.print_header()
+						}
+						e.log_string("  Mining base of PL ")
+						e.log_string(e.nampla.name)
+						e.log_string(" was reduced by 0.1 due to normal attrition.")
+					}
+
+					if total_pop_units == 1 {
+						if e.header_printed == FALSE {
+							e.print_header()
+						}
+						e.log_string(" The colony is dead!")
+					}
+
+					e.log_char('\n')
+				}
+			}
+
+		do_auto_increases:
+
+			/* Apply automatic 2% increase to mining and manufacturing bases of home planets. */
+			if (e.nampla.status & HOME_PLANET) != 0 {
+				growth_factor = 20
+				ib = e.nampla.mi_base
+				ab = e.nampla.ma_base
+				old_base = ib + ab
+				increment = (growth_factor * old_base) / 1000
+				md = planet.mining_difficulty
+
+				denom = 100 + md
+				ab_increment = (100*(increment+ib) - (md * ab) + denom/2) / denom
+				ib_increment = increment - ab_increment
+
+				if ib_increment < 0 {
+					ab_increment = increment
+					ib_increment = 0
+				}
+				if ab_increment < 0 {
+					ib_increment = increment
+					ab_increment = 0
+				}
+				e.nampla.mi_base += ib_increment
+				e.nampla.ma_base += ab_increment
+			}
+
+			e.check_population(e.nampla)
+
+		}
+
+		/* Loop through all ships for this species. */
+		for ship_index = 0; ship_index < e.species.num_ships; ship_index++ {
+			e.ship = e.ship_base[ship_index]
+
+			if e.ship.pn == 99 {
+				continue
+			}
+
+			/* Set flag if ship arrived via a natural wormhole. */
+			if e.ship.just_jumped == 99 {
+				e.ship.arrived_via_wormhole = TRUE
+			} else {
+				e.ship.arrived_via_wormhole = FALSE
+			}
+
+			/* Clear 'just-jumped' flag. */
+			e.ship.just_jumped = FALSE
+
+			/* Increase age of ship. */
+			if e.ship.status != UNDER_CONSTRUCTION {
+				e.ship.age++
+				if e.ship.age > 49 {
+					e.ship.age = 49
+				}
+			}
+		}
+
+		/* Check if this species has a populated planet that another species tried to land on. */
+		for i = 0; i < e.num_transactions; i++ {
+			if e.transaction[i]._type == LANDING_REQUEST && e.transaction[i].number1 == e.species_number {
+				if e.header_printed == FALSE {
+					e.print_header()
+				}
+				e.log_string("  ")
+				e.log_string(e.transaction[i].name2)
+				e.log_string(" owned by SP ")
+				e.log_string(e.transaction[i].name3)
+				if e.transaction[i].value != FALSE {
+					e.log_string(" was granted")
+				} else {
+					e.log_string(" was denied")
+				}
+				e.log_string(" permission to land on PL ")
+				e.log_string(e.transaction[i].name1)
+				e.log_string(".\n")
+			}
+		}
+
+		/* Check if this species is the recipient of interspecies construction. */
+		for i = 0; i < e.num_transactions; i++ {
+			if e.transaction[i]._type == INTERSPECIES_CONSTRUCTION && e.transaction[i].recipient == e.species_number {
+				/* Simply log the result. */
+				if e.header_printed == FALSE {
+					e.print_header()
+				}
+				e.log_string("  ")
+				if e.transaction[i].value == 1 {
+					e.log_long(e.transaction[i].number1)
+					e.log_char(' ')
+					e.log_string(item_name[e.transaction[i].number2])
+					if e.transaction[i].number1 == 1 {
+						e.log_string(" was")
+					} else {
+						e.log_string("s were")
+					}
+					e.log_string(" constructed for you by SP ")
+					e.log_string(e.transaction[i].name1)
+					e.log_string(" on PL ")
+					e.log_string(e.transaction[i].name2)
+				} else {
+					e.log_string(e.transaction[i].name2)
+					e.log_string(" was constructed for you by SP ")
+					e.log_string(e.transaction[i].name1)
+				}
+				e.log_string(".\n")
+			}
+		}
+
+		/* Check if this species is besieging another species and detects forbidden construction, landings, etc. */
+		for i = 0; i < e.num_transactions; i++ {
+			if e.transaction[i]._type == DETECTION_DURING_SIEGE && e.transaction[i].number3 == e.species_number {
+				/* Log what was detected and/or destroyed. */
+				if e.header_printed == FALSE {
+					e.print_header()
+				}
+				e.log_string("  ")
+				e.log_string("During the siege of ")
+				e.log_string(e.transaction[i].name3)
+				e.log_string(" PL ")
+				e.log_string(e.transaction[i].name1)
+				e.log_string(", your forces detected the ")
+
+				if e.transaction[i].value == 1 {
+					/* Landing of enemy ship. */
+					e.log_string("landing of ")
+					e.log_string(e.transaction[i].name2)
+					e.log_string(" on the planet.\n")
+				} else if e.transaction[i].value == 2 {
+					/* Enemy ship or starbase construction. */
+					e.log_string("construction of ")
+					e.log_string(e.transaction[i].name2)
+					e.log_string(", but you destroyed it before it")
+					e.log_string(" could be completed.\n")
+				} else if e.transaction[i].value == 3 {
+					/* Enemy PD construction. */
+					e.log_string("construction of planetary defenses, but you")
+					e.log_string(" destroyed them before they could be completed.\n")
+				} else if e.transaction[i].value == 4 || e.transaction[i].value == 5 {
+					/* Enemy item construction. */
+					e.log_string("transfer of ")
+					e.log_int(e.transaction[i].number1)
+					e.log_char(' ')
+					e.log_string(item_name[e.transaction[i].number2])
+					if e.transaction[i].number1 > 1 {
+						e.log_char('s')
+					}
+					if e.transaction[i].value == 4 {
+						e.log_string(" to PL ")
+					} else {
+						e.log_string(" from PL ")
+					}
+					e.log_string(e.transaction[i].name2)
+					e.log_string(", but you destroyed them in transit.\n")
+				} else {
+					fprintf(e.stderr, "\n\tInternal error!  Cannot reach this point!\n\n")
+					panic("\n\tInternal error!  Cannot reach this point!\n\n")
+				}
+			}
+		}
+
+	check_for_message:
+
+		/* Check if this species is the recipient of a message from another species. */
+		for i = 0; i < e.num_transactions; i++ {
+			if e.transaction[i]._type == MESSAGE_TO_SPECIES && e.transaction[i].number2 == e.species_number {
+				if e.header_printed == FALSE {
+					e.print_header()
+				}
+				e.log_string("\n  You received the following message from SP ")
+				e.log_string(e.transaction[i].name1)
+				e.log_string(":\n\n")
+
+				if message, ok := e.message_base[e.transaction[i].value]; ok {
+					fputs(message.Bytes(), e.log_file)
+				} else {
+					e.log_message(fmt.Sprintf("m%d.msg", e.transaction[i].value))
+				}
+
+				e.log_string("\n  *** End of Message ***\n\n")
+			}
+		}
+
+		/* Close log file. */
+		fclose(e.log_file)
+		e.log_file = nil
+	}
+
+	/* Calculate economic efficiency for each planet. */
+	e.update_econ_efficiency()
+
+	/* Create new locations array. */
+	e.do_locations()
+
+	if turn_number == 1 {
+		return
+	}
+
+	/* Go through all species one more time to update alien contact masks,
+	 * report tech transfer results to donors, and calculate fleet
+	 * maintenance costs. */
+	if e.prompt_gm {
+		log.Printf("Now updating contact masks et al.\n")
+	}
+	for e.species_index = 0; e.species_index < e.galaxy.num_species; e.species_index++ {
+		if e.species = e.spec_data[e.species_index]; e.species == nil {
+			continue
+		}
+		e.nampla_base = e.namp_data[e.species_index]
+		e.ship_base = e.ship_data[e.species_index]
+		e.species_number = e.species_index + 1
+
+		home_nampla = e.nampla_base[0]
+		home_planet = e.planet_base[home_nampla.planet_index]
+
+		/* Update contact mask in species data if this species has met a new alien. */
+		for i = 0; i < e.num_locs; i++ {
+			if e.loc[i].s != e.species_number {
+				continue
+			}
+
+			for j = 0; j < e.num_locs; j++ {
+				if e.loc[j].s == e.species_number {
+					continue
+				}
+				if e.loc[j].x != e.loc[i].x || e.loc[j].y != e.loc[i].y || e.loc[j].z != e.loc[i].z {
+					continue
+				}
+
+				/* We are in contact with an alien. Make sure it is not hidden from us. */
+				alien_number = e.loc[j].s
+				if e.alien_is_visible(e.loc[j].x, e.loc[j].y, e.loc[j].z, e.species_number, alien_number) {
+					e.species.contact[alien_number-1] = TRUE
+				}
+			}
+		}
+
+		/* Report results of tech transfers to donor species. */
+		for i = 0; i < e.num_transactions; i++ {
+			if e.transaction[i]._type == TECH_TRANSFER && e.transaction[i].donor == e.species_number {
+				/* Open log file for appending. */
+				filename := fmt.Sprintf("sp%02d.log", e.species_number)
+				if e.spec_logs[e.species_index] == nil {
+					e.spec_logs[e.species_index] = &bytes.Buffer{}
+				}
+				e.log_file = fopen(filename, e.spec_logs[e.species_index])
+				e.log_stdout = FALSE
+
+				e.log_string("  ")
+				tech = e.transaction[i].value
+				e.log_string(tech_name[tech])
+				e.log_string(" tech transfer to SP ")
+				e.log_string(e.transaction[i].name2)
+
+				if e.transaction[i].number1 < 0 {
+					e.log_string(" failed")
+					if e.transaction[i].number1 == -2 {
+						e.log_string(" due to lack of funding")
+					}
+				} else {
+					e.log_string(" raised their tech level from ")
+					e.log_long(e.transaction[i].number2)
+					e.log_string(" to ")
+					e.log_long(e.transaction[i].number3)
+					e.log_string(" at a cost to you of ")
+					e.log_long(e.transaction[i].number1)
+				}
+
+				e.log_string(".\n")
+
+				fclose(e.log_file)
+				e.log_file = nil
+			}
+		}
+
+		/* Calculate fleet maintenance cost and its percentage of total production. */
+		fleet_maintenance_cost = 0
+		for i = 0; i < e.species.num_ships; i++ {
+			e.ship = e.ship_base[i]
+
+			if e.ship.pn == 99 {
+				continue
+			}
+
+			if e.ship.class == TR {
+				n = 4 * e.ship.tonnage
+			} else if e.ship.class == BA {
+				n = 10 * e.ship.tonnage
+			} else {
+				n = 20 * e.ship.tonnage
+			}
+
+			if e.ship._type == SUB_LIGHT {
+				n -= (25 * n) / 100
+			}
+
+			fleet_maintenance_cost += n
+		}
+
+		/* Subtract military discount. */
+		i = e.species.tech_level[ML] / 2
+		fleet_maintenance_cost -= (i * fleet_maintenance_cost) / 100
+
+		/* Calculate total production. */
+		total_species_production = 0
+		for i = 0; i < e.species.num_namplas; i++ {
+			e.nampla = e.nampla_base[i]
+
+			if e.nampla.pn == 99 {
+				continue
+			}
+			if (e.nampla.status & DISBANDED_COLONY) != 0 {
+				continue
+			}
+
+			planet = e.planet_base[e.nampla.planet_index]
+
+			ls_needed = life_support_needed(e.species, home_planet, planet)
+
+			if ls_needed == 0 {
+				production_penalty = 0
+			} else {
+				production_penalty = (100 * ls_needed) / e.species.tech_level[LS]
+			}
+
+			RMs_produced = (10 * e.species.tech_level[MI] * e.nampla.mi_base) / planet.mining_difficulty
+			RMs_produced -= (production_penalty * RMs_produced) / 100
+
+			production_capacity = (e.species.tech_level[MA] * e.nampla.ma_base) / 10
+			production_capacity -= (production_penalty * production_capacity) / 100
+
+			if (e.nampla.status & MINING_COLONY) != 0 {
+				balance = (2 * RMs_produced) / 3
+			} else if (e.nampla.status & RESORT_COLONY) != 0 {
+				balance = (2 * production_capacity) / 3
+			} else {
+				RMs_produced += e.nampla.item_quantity[RM]
+				if RMs_produced > production_capacity {
+					balance = production_capacity
+				} else {
+					balance = RMs_produced
+				}
+			}
+
+			balance = ((planet.econ_efficiency * balance) + 50) / 100
+
+			total_species_production += balance
+		}
+
+		/* Save fleet maintenance results. */
+		e.species.fleet_cost = fleet_maintenance_cost
+		if total_species_production > 0 {
+			e.species.fleet_percent_cost = (10000 * fleet_maintenance_cost) / total_species_production
+		} else {
+			e.species.fleet_percent_cost = 10000
+		}
+	}
+}
+
+func (e *Engine) print_header() {
+	e.log_string("\nOther events:\n")
+	e.header_printed = TRUE
+}
+
+// alien_is_visible returns true if the alien has a ship or an unhidden colony at x y z.
+func (e *Engine) alien_is_visible(x, y, z, species_number, alien_number int) bool {
+	/* Check if the alien has a ship or starbase here that is in orbit or in deep space. */
+	alien := e.spec_data[alien_number-1]
+	for i := 0; i < alien.num_ships; i++ {
+		alien_ship := e.ship_data[alien_number-1][i]
+		if alien_ship.x != x || alien_ship.y != y || alien_ship.z != z {
+			continue
+		}
+		if alien_ship.item_quantity[FD] == alien_ship.tonnage {
+			continue
+		}
+
+		if alien_ship.status == IN_ORBIT || alien_ship.status == IN_DEEP_SPACE {
+			return true
+		}
+	}
+
+	/* Check if alien has a planet that is not hidden. */
+	for i := 0; i < alien.num_namplas; i++ {
+		alien_nampla := e.namp_data[alien_number-1][i]
+		if alien_nampla.x != x || alien_nampla.y != y || alien_nampla.z != z {
+			continue
+		}
+		if (alien_nampla.status & POPULATED) == 0 {
+			continue
+		}
+
+		if alien_nampla.hidden == FALSE {
+			return true
+		}
+
+		/* The colony is hidden. See if we have population on the same planet. */
+		species := e.spec_data[species_number-1]
+		for j := 0; j < species.num_namplas; j++ {
+			nampla := e.namp_data[species_number-1][j]
+			if nampla.x != x || nampla.y != y || nampla.z != z || nampla.pn != alien_nampla.pn {
+				continue
+			}
+			if (nampla.status & POPULATED) == 0 {
+				continue
+			}
+
+			/* We have population on the same planet, so the alien cannot hide. */
+			return true
+		}
+	}
+
+	return false
+}
+
+// check_high_tech_items logs any items that the species can build now that it has reached the new tech level.
+func (e *Engine) check_high_tech_items(tech, old_tech_level, new_tech_level int) {
+	for i := 0; i < MAX_ITEMS; i++ {
+		if item_critical_tech[i] != tech {
+			continue
+		}
+		if new_tech_level < item_tech_requirment[i] {
+			continue
+		}
+		if old_tech_level >= item_tech_requirment[i] {
+			continue
+		}
+
+		e.log_string("  You now have the technology to build ")
+		e.log_string(item_name[i])
+		e.log_string("s.\n")
+	}
+
+	/* Check for high tech abilities that are not associated with specific items. */
+	if tech == MA && old_tech_level < 25 && new_tech_level >= 25 {
+		e.log_string("  You now have the technology to do interspecies construction.\n")
+	}
+}
+
+// update_econ_efficiency recalculates the economic efficiency of every planet.
+// Home planets do not count towards the total economic base of a planet,
+// and planets without any colonies are reset to 100.
+// It must agree with cluster.Store.UpdateEconEfficiency.
+func (e *Engine) update_econ_efficiency() {
+	total_econ_base := make([]int, len(e.planet_base))
+	for species_index, species := range e.spec_data {
+		if species == nil {
+			continue
+		}
+		for _, nampla := range e.namp_data[species_index] {
+			if nampla.pn == 99 || (nampla.status&HOME_PLANET) != 0 {
+				continue
+			}
+			total_econ_base[nampla.planet_index] += nampla.mi_base + nampla.ma_base
+		}
+	}
+	for planet_index, planet := range e.planet_base {
+		total := total_econ_base[planet_index]
+		if diff := total - 2000; diff <= 0 {
+			planet.econ_efficiency = 100
+		} else {
+			planet.econ_efficiency = (100 * (diff/20 + 2000)) / total
+		}
+	}
+}
@@ -502,6 +502,6 @@ Note from the gamemaster:
 		e.orders_file.WriteString("END\n\n")
 
 		// replace any existing orders with these generated orders
-		e.spec_orders[e.species_index] = e.orders_file.Bytes()
+		e.setOrders(e.species_index, fmt.Sprintf("sp%02d.ord", e.species_number), e.orders_file.Bytes())
 	}
 }
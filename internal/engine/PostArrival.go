@@ -0,0 +1,239 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+func (e *Engine) post_arrival(argv ...string) {
+	var i, n, found, num_species, sp_index, command, do_all_species int
+	var sp_num [MAX_SPECIES]int
+	var err error
+
+	e.ignore_field_distorters = TRUE
+	e.post_arrival_phase = TRUE
+
+	// Check arguments.
+	// If an argument is -t, then set test mode.
+	// All other arguments must be species numbers.
+	// If no species numbers are specified, then do all species.
+	e.test_mode = FALSE
+	e.verbose_mode = FALSE
+	for i = 0; i < len(argv); i++ {
+		if argv[i] == "-t" {
+			e.test_mode = TRUE
+		} else if argv[i] == "-v" {
+			e.verbose_mode = TRUE
+		} else if n, err = strconv.Atoi(argv[i]); err == nil && num_species < MAX_SPECIES && (1 <= n && n <= e.galaxy.num_species) {
+			sp_num[num_species] = n
+			num_species++
+		}
+	}
+
+	if num_species == 0 {
+		num_species = e.galaxy.num_species
+		for i = 0; i < num_species; i++ {
+			sp_num[i] = i + 1
+		}
+		do_all_species = TRUE
+	}
+
+	/* Main loop. For each species, take appropriate action. */
+	for sp_index = 0; sp_index < num_species; sp_index++ {
+		e.species_number = sp_num[sp_index]
+		e.species_index = e.species_number - 1
+
+		if e.species = e.spec_data[e.species_index]; e.species == nil {
+			if do_all_species == FALSE {
+				panic(fmt.Sprintf("\n    Cannot get data for species #%d!\n", e.species_number))
+			}
+			continue
+		}
+		e.nampla_base = e.namp_data[e.species_index]
+		e.ship_base = e.ship_data[e.species_index]
+		e.home_planet = e.planet_base[e.nampla_base[0].planet_index]
+
+		/* Do some initializations. */
+		e.species.auto_orders = FALSE
+
+		/* Open orders file for this species. */
+		filename := fmt.Sprintf("sp%02d.ord", e.species_number)
+		if e.spec_orders[e.species_index] == nil {
+			if do_all_species == FALSE {
+				panic(fmt.Sprintf("\n\tCannot open '%s' for reading!\n\n", filename))
+			}
+			if e.prompt_gm {
+				log.Printf("No orders for species #%d, SP %s.\n", e.species_number, e.species.name)
+			}
+			continue
+		}
+		b := &bytes.Buffer{}
+		_, _ = b.ReadFrom(bytes.NewReader(e.spec_orders[e.species_index]))
+		e.input_file = fopen(filename, b)
+
+		e.end_of_file = FALSE
+		e.just_opened_file = TRUE /* Tell parse.c to skip mail header, if any. */
+
+	find_start:
+
+		/* Search for START POST-ARRIVAL order. */
+		found = FALSE
+		for found == FALSE {
+			command = e.get_command()
+			if command == MESSAGE {
+				/* Skip MESSAGE text. It may contain a line that starts with "start". */
+				for {
+					command = e.get_command()
+					if command < 0 {
+						fprintf(e.stderr, "WARNING: Unterminated MESSAGE command in file %s!\n", filename)
+						break
+					}
+					if command == ZZZ {
+						goto find_start
+					}
+				}
+			}
+			if command < 0 {
+				break /* End of file. */
+			}
+			if command != START {
+				continue
+			}
+
+			/* Get the first three letters of the keyword and convert to upper case. */
+			e.skip_whitespace()
+			var keyword string
+			for i = 0; i < 3 && len(e.input_line_pointer) != 0; i++ {
+				keyword += string(e.input_line_pointer[0])
+				e.input_line_pointer = e.input_line_pointer[1:]
+			}
+			if strings.ToUpper(keyword) == "POS" {
+				found = TRUE
+			}
+		}
+
+		if found == FALSE {
+			if e.prompt_gm {
+				log.Printf("No post-arrival orders for species #%d, SP %s.\n", e.species_number, e.species.name)
+			}
+			goto done_orders
+		}
+
+		/* Open log file for appending. */
+		filename = fmt.Sprintf("sp%02d.log", e.species_number)
+		if e.spec_logs[e.species_index] == nil {
+			e.spec_logs[e.species_index] = &bytes.Buffer{}
+		}
+		e.log_file = fopen(filename, e.spec_logs[e.species_index])
+		e.append_log[e.species_index] = TRUE
+		e.log_stdout = FALSE /* We will control value of log_file from here. */
+		e.log_string("\nPost-arrival orders:\n")
+
+		/* For each ship, set dest_z to zero. If a starbase is used as a
+		 * gravitic telescope, it will be set to non-zero. This will
+		 * prevent more than one TELESCOPE order per turn per starbase. */
+		for i = 0; i < e.species.num_ships; i++ {
+			e.ship_base[i].dest_z = 0
+		}
+
+		/* Handle post-arrival orders for this species. */
+		e.do_postarrival_orders()
+
+		fclose(e.log_file)
+		e.log_file = nil
+
+	done_orders:
+
+		fclose(e.input_file)
+	}
+
+	e.post_arrival_phase = FALSE
+}
+
+func (e *Engine) do_postarrival_orders() {
+	if e.prompt_gm {
+		log.Printf("Start of post-arrival orders for species #%d, SP %s...\n", e.species_number, e.species.name)
+	}
+
+	e.truncate_name = TRUE /* For these commands, do not display age or landed/orbital status of ships. */
+
+	for {
+		command := e.get_command()
+		if command == 0 {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Unknown or missing command.\n")
+			continue
+		}
+
+		if e.end_of_file != FALSE || command == END {
+			if e.prompt_gm {
+				log.Printf("End of post-arrival orders for species #%d, SP %s.\n", e.species_number, e.species.name)
+			}
+			break /* END for this species. */
+		}
+
+		switch command {
+		case ALLY:
+			e.do_ALLY_command()
+		case AUTO:
+			e.species.auto_orders = TRUE
+			e.log_string("    An AUTO order was executed.\n")
+		case DEEP:
+			e.do_DEEP_command()
+		case DESTROY:
+			e.do_DESTROY_command()
+		case ENEMY:
+			e.do_ENEMY_command()
+		case LAND:
+			e.do_LAND_command()
+		case MESSAGE:
+			e.do_MESSAGE_command()
+		case NAME:
+			e.do_NAME_command()
+		case NEUTRAL:
+			e.do_NEUTRAL_command()
+		case ORBIT:
+			e.do_ORBIT_command()
+		case REPAIR:
+			e.do_REPAIR_command()
+		case SCAN:
+			e.do_SCAN_command()
+		case SEND:
+			e.do_SEND_command()
+		case TEACH:
+			e.do_TEACH_command()
+		case TELESCOPE:
+			e.do_TELESCOPE_command()
+		case TERRAFORM:
+			e.do_TERRAFORM_command()
+		case TRANSFER:
+			e.do_TRANSFER_command()
+		default:
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Invalid post-arrival command.\n")
+		}
+	}
+}
@@ -0,0 +1,80 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/mdhender/fhcms/internal/cluster"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+)
+
+// SetReportPath sets the directory that the report step writes to.
+// If path is empty, the report step is skipped.
+func (e *Engine) SetReportPath(path string) {
+	e.report_path = path
+}
+
+// report writes the turn report for every species. Each report is the
+// species event log followed by the status and default orders sections.
+func (e *Engine) report() error {
+	if e.report_path == "" {
+		log.Printf("[engine] report: no report path, skipping reports\n")
+		return nil
+	}
+
+	ds, err := cluster.FromDat32Records(e.dat32Records())
+	if err != nil {
+		return err
+	}
+
+	for species_index := 0; species_index < e.galaxy.num_species; species_index++ {
+		species_number := species_index + 1
+		if e.spec_data[species_index] == nil {
+			continue
+		}
+
+		report_file := &bytes.Buffer{}
+
+		/* Copy log file, if any, to output file. */
+		if e.spec_logs[species_index] != nil && e.spec_logs[species_index].Len() != 0 {
+			if e.galaxy.turn_number > 1 {
+				fmt.Fprintf(report_file, "\n\n\t\t\tEVENT LOG FOR TURN %d\n", e.galaxy.turn_number-1)
+			}
+			report_file.Write(e.spec_logs[species_index].Bytes())
+			fmt.Fprintf(report_file, "\n\n")
+		}
+
+		if err := ds.Report(report_file, ds.SpeciesBase[species_number], e.galaxy.turn_number, false); err != nil {
+			return err
+		}
+
+		reportFileName := filepath.Join(e.report_path, fmt.Sprintf("sp%02d.rpt.t%d", species_number, e.galaxy.turn_number))
+		if err := ioutil.WriteFile(reportFileName, report_file.Bytes(), 0644); err != nil {
+			return err
+		}
+		if e.prompt_gm {
+			log.Printf("[engine] report: wrote %q\n", reportFileName)
+		}
+	}
+
+	return nil
+}
@@ -1,6 +1,6 @@
 /*******************************************************************************
 Far Horizons Engine
-Copyright (C) 2021  Michael D Henderson
+Copyright (C) 2022  Michael D Henderson
 
 This program is free software: you can redistribute it and/or modify
 it under the terms of the GNU Affero General Public License as published
@@ -16,41 +16,39 @@ You should have received a copy of the GNU Affero General Public License
 along with this program.  If not, see <https://www.gnu.org/licenses/>.
 ******************************************************************************/
 
-package oldparser
+package engine
 
-func Parse(b []byte) {
-	p := &position{p: b, line: 1}
-	for !p.eof() {
-		if p.skipSpaces() || p.skipComment() {
-			continue
-		}
-		parseSection(p)
-	}
-}
-
-type Section struct{}
+import (
+	"bytes"
+	"fmt"
+	"github.com/mdhender/fhcms/internal/cluster"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+)
 
-func parseSection(p *position) *Section {
-	k := p.accept([]byte{'s', 't', 'a', 'r', 't'})
-	if k == nil {
+// stats writes the game statistics for the turn to the report path.
+func (e *Engine) stats() error {
+	if e.report_path == "" {
+		log.Printf("[engine] stats: no report path, skipping stats\n")
 		return nil
 	}
-	for !p.eof() {
-		if p.skipSpaces() || p.skipComment() {
-			continue
-		}
-	}
-	if p.accept([]byte{'c', 'o', 'm', 'b', 'a', 't'}) {
-	}
-	if p.accept([]byte{'p', 'r', 'e', '-', 'd', 'e', 'p', 'a', 'r', 't', 'u', 'r', 'e'}) {
-	}
-	if p.accept([]byte{'j', 'u', 'm', 'p', 's'}) {
-	}
-	if p.accept([]byte{'p', 'r', 'o', 'd', 'u', 'c', 't', 'i', 'o', 'n'}) {
+
+	ds, err := cluster.FromDat32Records(e.dat32Records())
+	if err != nil {
+		return err
 	}
-	if p.accept([]byte{'p', 'o', 's', 't', '-', 'a', 'r', 'r', 'i', 'v', 'a', 'l'}) {
+
+	stats_file := &bytes.Buffer{}
+	ds.Stats().Write(stats_file)
+
+	statsFileName := filepath.Join(e.report_path, fmt.Sprintf("stats.t%d", e.galaxy.turn_number))
+	if err := ioutil.WriteFile(statsFileName, stats_file.Bytes(), 0644); err != nil {
+		return err
 	}
-	if p.accept([]byte{'s', 't', 'r', 'i', 'k', 'e', 's'}) {
+	if e.prompt_gm {
+		log.Printf("[engine] stats: wrote %q\n", statsFileName)
 	}
+
 	return nil
 }
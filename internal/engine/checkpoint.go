@@ -0,0 +1,170 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/mdhender/fhcms/cms/store/jsondb"
+	"io/ioutil"
+	"log"
+)
+
+// checkpoint is the state saved after a phase of the turn.
+// The store holds the game data. The rest is the state that one phase
+// passes to the next: the logs, orders, messages, and transactions.
+type checkpoint struct {
+	Phase        string                  `json:"phase"`
+	Seed         uint64                  `json:"seed"`
+	Store        *jsondb.Store           `json:"store"`
+	Logs         map[int]string          `json:"logs,omitempty"`   // indexed by species number
+	Orders       map[int]string          `json:"orders,omitempty"` // indexed by species number
+	Messages     map[int]string          `json:"messages,omitempty"`
+	Transactions []checkpointTransaction `json:"transactions,omitempty"`
+}
+
+type checkpointTransaction struct {
+	Type      int    `json:"type"`
+	Donor     int    `json:"donor"`
+	Recipient int    `json:"recipient"`
+	Value     int    `json:"value"`
+	X         int    `json:"x"`
+	Y         int    `json:"y"`
+	Z         int    `json:"z"`
+	PN        int    `json:"pn"`
+	Number1   int    `json:"number1"`
+	Name1     string `json:"name1"`
+	Number2   int    `json:"number2"`
+	Name2     string `json:"name2"`
+	Number3   int    `json:"number3"`
+	Name3     string `json:"name3"`
+}
+
+// SaveCheckpoint saves the engine state after the named phase.
+func (e *Engine) SaveCheckpoint(path, phase string) error {
+	cp := &checkpoint{
+		Phase:    phase,
+		Seed:     e.Seed(),
+		Store:    e.jsondbStore(),
+		Logs:     make(map[int]string),
+		Orders:   make(map[int]string),
+		Messages: make(map[int]string),
+	}
+	for i := 0; i < e.galaxy.num_species; i++ {
+		if e.spec_logs[i] != nil && e.spec_logs[i].Len() != 0 {
+			cp.Logs[i+1] = e.spec_logs[i].String()
+		}
+		if e.spec_orders[i] != nil {
+			cp.Orders[i+1] = string(e.spec_orders[i])
+		}
+	}
+	for k, v := range e.message_base {
+		cp.Messages[k] = v.String()
+	}
+	for i := 0; i < e.num_transactions; i++ {
+		t := &e.transaction[i]
+		cp.Transactions = append(cp.Transactions, checkpointTransaction{
+			Type:      t._type,
+			Donor:     t.donor,
+			Recipient: t.recipient,
+			Value:     t.value,
+			X:         t.x,
+			Y:         t.y,
+			Z:         t.z,
+			PN:        t.pn,
+			Number1:   t.number1,
+			Name1:     t.name1,
+			Number2:   t.number2,
+			Name2:     t.name2,
+			Number3:   t.number3,
+			Name3:     t.name3,
+		})
+	}
+
+	b, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path, b, 0644); err != nil {
+		return err
+	}
+	log.Printf("[engine] saveCheckpoint: saved turn %6d after %s to %q\n", e.galaxy.turn_number, phase, path)
+
+	return nil
+}
+
+// LoadCheckpoint restores the engine state from a checkpoint.
+// It returns the name of the phase that the checkpoint was saved after.
+func (e *Engine) LoadCheckpoint(path string) (string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	var cp checkpoint
+	if err := json.Unmarshal(b, &cp); err != nil {
+		return "", err
+	} else if cp.Store == nil {
+		return "", fmt.Errorf("loadCheckpoint: %q: missing store", path)
+	} else if len(cp.Transactions) > MAX_TRANSACTIONS {
+		return "", fmt.Errorf("loadCheckpoint: %q: too many transactions", path)
+	}
+
+	if err := e.loadStore(cp.Store); err != nil {
+		return "", err
+	}
+	e.SetSeed(cp.Seed)
+	for spNo, text := range cp.Logs {
+		if 0 < spNo && spNo <= e.galaxy.num_species {
+			e.spec_logs[spNo-1].WriteString(text)
+			e.append_log[spNo-1] = TRUE
+		}
+	}
+	for spNo, text := range cp.Orders {
+		if 0 < spNo && spNo <= e.galaxy.num_species {
+			e.setOrders(spNo-1, fmt.Sprintf("sp%02d.ord", spNo), []byte(text))
+		}
+	}
+	e.message_base = make(map[int]*bytes.Buffer)
+	for k, v := range cp.Messages {
+		e.message_base[k] = bytes.NewBufferString(v)
+	}
+	e.num_transactions = len(cp.Transactions)
+	for i, t := range cp.Transactions {
+		e.transaction[i] = trans_data{
+			_type:     t.Type,
+			donor:     t.Donor,
+			recipient: t.Recipient,
+			value:     t.Value,
+			x:         t.X,
+			y:         t.Y,
+			z:         t.Z,
+			pn:        t.PN,
+			number1:   t.Number1,
+			name1:     t.Name1,
+			number2:   t.Number2,
+			name2:     t.Name2,
+			number3:   t.Number3,
+			name3:     t.Name3,
+		}
+	}
+	log.Printf("[engine] loadCheckpoint: loaded turn %6d after %s from %q\n", e.galaxy.turn_number, cp.Phase, path)
+
+	return cp.Phase, nil
+}
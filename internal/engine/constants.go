@@ -365,6 +365,19 @@ var command_name = [NUM_COMMANDS]string{
 	"Teach", "Tech", "Telescope", "Terraform", "Transfer", "Unload",
 	"Upgrade", "Visited", "Withdraw", "Wormhole", "ZZZ"}
 
+// constants from Production.c and do_int.c
+
+/* Maximum number of star systems where interceptions may be prepared. */
+const MAX_INTERCEPTS = 1000
+
+/* Maximum number of enemy ships that may be intercepted at a single location. */
+const MAX_ENEMY_SHIPS = 400
+
+// constants from do_tel.c
+
+/* Maximum number of locations that may be observed by a single gravitic telescope. */
+const MAX_OBS_LOCS = 5000
+
 // constants from combat.h
 
 /* Maximum number of battle locations for all players. */
@@ -0,0 +1,61 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+func (e *Engine) do_ALLY_command() {
+	/* See if declaration is for all species. */
+	all_species := e.get_value()
+	if all_species != FALSE {
+		for i := 0; i < len(e.species.contact); i++ {
+			e.species.ally[i] = e.species.contact[i] /* Ally all known species. */
+			e.species.enemy[i] = FALSE
+		}
+	} else {
+		/* Get name of species. */
+		if e.get_species_name() == FALSE {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Invalid or missing argument in ALLY command.\n")
+			return
+		}
+
+		/* Check if we've met this species. */
+		g_spec_index := e.g_spec_number - 1
+		if e.species.contact[g_spec_index] == FALSE || e.g_spec_number == e.species_number {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! You can't declare alliance with a species you haven't met.\n")
+			return
+		}
+
+		/* Set/clear the appropriate bit. */
+		e.species.ally[g_spec_index] = TRUE   /* Set ally bit. */
+		e.species.enemy[g_spec_index] = FALSE /* Clear enemy bit. */
+	}
+
+	/* Log the result. */
+	e.log_string("    Alliance was declared with ")
+	if all_species != FALSE {
+		e.log_string("ALL species")
+	} else {
+		e.log_string("SP ")
+		e.log_string(e.g_spec_name)
+	}
+	e.log_string(".\n")
+}
@@ -0,0 +1,71 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+func (e *Engine) do_AMBUSH_command() {
+	var status, cost int
+
+	/* Check if this order was preceded by a PRODUCTION order. */
+	if e.doing_production == FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Missing PRODUCTION order!\n")
+		return
+	}
+
+	/* Get amount to spend. */
+	status = e.get_value()
+	if status == FALSE || e.value < 0 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid or missing amount.\n")
+		return
+	}
+	if e.value == 0 {
+		e.value = e.balance
+	}
+	if e.value == 0 {
+		return
+	}
+	cost = e.value
+
+	/* Check if planet is under siege. */
+	if e.nampla.siege_eff != 0 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Besieged planet cannot ambush!\n")
+		return
+	}
+
+	/* Check if sufficient funds are available. */
+	if e.check_bounced(cost) != FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Insufficient funds to execute order.\n")
+		return
+	}
+
+	/* Increment amount spent on ambush. */
+	e.nampla.use_on_ambush += cost
+
+	/* Log transaction. */
+	e.log_string("    Spent ")
+	e.log_long(cost)
+	e.log_string(" in preparation for an ambush.\n")
+}
@@ -0,0 +1,243 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+import "strings"
+
+func (e *Engine) do_BASE_command() {
+	var i, found, su_count, original_count, source_is_a_planet, new_tonnage, max_tonnage int
+	var x, y, z, pn int
+	var source_nampla *nampla_data
+	var source_ship, starbase, unused_ship *ship_data
+
+	/* Get number of starbase units to use. */
+	if e.get_value() == FALSE {
+		e.value = 0
+	} else if e.value < 0 { /* Make sure value is meaningful. */
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid SU count in BASE command.\n")
+		return
+	}
+	su_count = e.value
+	original_count = su_count
+
+	/* Get source of starbase units. */
+	original_line_pointer := e.input_line_pointer
+	if e.get_transfer_point() == FALSE {
+		e.input_line_pointer = original_line_pointer
+		e.fix_separator() /* Check for missing comma or tab. */
+		if e.get_transfer_point() == FALSE {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Invalid source location in BASE command.\n")
+			return
+		}
+	}
+
+	/* Make sure everything makes sense. */
+	if e.abbr_type == SHIP_CLASS {
+		source_is_a_planet = FALSE
+		source_ship = e.ship
+
+		if source_ship.status == UNDER_CONSTRUCTION {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Source ship is still under construction.\n")
+			return
+		}
+
+		if source_ship.status == FORCED_JUMP || source_ship.status == JUMPED_IN_COMBAT {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Source ship jumped during combat and is still in transit.\n")
+			return
+		}
+
+		x, y, z, pn = source_ship.x, source_ship.y, source_ship.z, source_ship.pn
+
+		if su_count == 0 {
+			su_count = source_ship.item_quantity[SU]
+		}
+		if su_count == 0 {
+			return
+		}
+		if source_ship.item_quantity[SU] < su_count {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Source ship does not have the specified number of starbase units!\n")
+			return
+		}
+	} else { /* Source is a planet. */
+		source_is_a_planet = TRUE
+		source_nampla = e.nampla
+
+		x, y, z, pn = source_nampla.x, source_nampla.y, source_nampla.z, source_nampla.pn
+
+		if su_count == 0 {
+			su_count = source_nampla.item_quantity[SU]
+		}
+		if su_count == 0 {
+			return
+		}
+		if source_nampla.item_quantity[SU] < su_count {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Source planet does not have the specified number of starbase units!\n")
+			return
+		}
+	}
+
+	/* Get starbase name. */
+	if e.get_class_abbr() != SHIP_CLASS || e.abbr_index != BA {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid starbase name.\n")
+		return
+	}
+	e.get_name()
+
+	/* Search all ships for name. */
+	found = FALSE
+	upper_name := b2s(e.upper_name)
+	for e.ship_index = 0; e.ship_index < e.species.num_ships; e.ship_index++ {
+		e.ship = e.ship_base[e.ship_index]
+		if e.ship.pn == 99 {
+			/* Keep track of any unused ship structs. */
+			if unused_ship == nil {
+				unused_ship = e.ship
+			}
+			continue
+		}
+
+		/* Compare names. */
+		if strings.ToUpper(e.ship.name) == upper_name {
+			found = TRUE
+			break
+		}
+	}
+
+	if found != FALSE {
+		if e.ship._type != STARBASE {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Ship name already in use.\n")
+			return
+		}
+		if e.ship.x != x || e.ship.y != y || e.ship.z != z {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Starbase units and starbase are not at same X Y Z.\n")
+			return
+		}
+		starbase = e.ship
+	} else {
+		if unused_ship != nil {
+			starbase = unused_ship
+		} else {
+			starbase = &ship_data{}
+			e.ship_data[e.species_index] = append(e.ship_data[e.species_index], starbase)
+			e.ship_base = e.ship_data[e.species_index]
+			e.species.num_ships++
+		}
+		e.delete_ship(starbase) /* Initialize everything to zero. */
+
+		/* Initialize non-zero data for new ship. */
+		starbase.name = b2s(e.original_name)
+		starbase.x, starbase.y, starbase.z, starbase.pn = x, y, z, pn
+		if pn == 0 {
+			starbase.status = IN_DEEP_SPACE
+		} else {
+			starbase.status = IN_ORBIT
+		}
+		starbase._type = STARBASE
+		starbase.class = BA
+		starbase.tonnage = 0
+		starbase.age = -1
+		starbase.remaining_cost = 0
+
+		/* Everything else was set to zero in above call to 'delete_ship'. */
+	}
+
+	/* Make sure that starbase is not being built in the deep space section of a star system .*/
+	if starbase.pn == 0 {
+		for i = 0; i < e.num_stars; i++ {
+			star := e.star_base[i]
+			if star.x != x || star.y != y || star.z != z {
+				continue
+			} else if star.num_planets < 1 {
+				break
+			}
+
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Starbase cannot be built in deep space if there are planets available!\n")
+			if found == FALSE {
+				e.delete_ship(starbase)
+			}
+			return
+		}
+	}
+
+	/* Make sure species can build a starbase of this size. */
+	max_tonnage = e.species.tech_level[MA] / 2
+	new_tonnage = starbase.tonnage + su_count
+	if new_tonnage > max_tonnage && original_count == 0 {
+		su_count = max_tonnage - starbase.tonnage
+		if su_count < 1 {
+			if found == FALSE {
+				e.delete_ship(starbase)
+			}
+			return
+		}
+		new_tonnage = starbase.tonnage + su_count
+	}
+
+	if new_tonnage > max_tonnage {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Maximum allowable tonnage exceeded.\n")
+		if found == FALSE {
+			e.delete_ship(starbase)
+		}
+		return
+	}
+
+	/* Finish up and log results. */
+	e.log_string("    ")
+	if starbase.tonnage == 0 {
+		e.log_string(e.ship_name(starbase))
+		e.log_string(" was constructed.\n")
+	} else {
+		starbase.age = ((starbase.age * starbase.tonnage) - su_count) / new_tonnage /* Weighted average. */
+		e.log_string("Size of ")
+		e.log_string(e.ship_name(starbase))
+		e.log_string(" was increased to ")
+		e.log_string(commas(10000 * new_tonnage))
+		e.log_string(" tons.\n")
+	}
+
+	starbase.tonnage = new_tonnage
+
+	if source_is_a_planet != FALSE {
+		source_nampla.item_quantity[SU] -= su_count
+	} else {
+		source_ship.item_quantity[SU] -= su_count
+	}
+}
@@ -136,7 +136,7 @@ func (e *Engine) do_bombardment(unit_index int, act *action_data) {
 
 	if n := (percent_damage * attacked_nampla.shipyards) / 100; n > 0 {
 		attacked_nampla.shipyards -= n
-		e.log_printf("        %d shipyard")
+		e.log_printf("        %d shipyard", n)
 		if n > 1 {
 			e.log_string("s were")
 		} else {
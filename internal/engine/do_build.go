@@ -0,0 +1,872 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+import "strings"
+
+func (e *Engine) do_BUILD_command(continuing_construction, interspecies_construction int) {
+	var i, n, class, critical_tech, found, name_length int
+	var siege_effectiveness, cost_given, new_ship, max_tonnage, tonnage_increase int
+	var cargo_on_board, unused_nampla_available, unused_ship_available, capacity, pop_check_needed int
+	var num_items, cost, cost_argument, unit_cost, pop_reduction int
+	var premium, total_cost, original_num_items, max_funds_available int
+	var recipient_species *species_data
+	var recipient_nampla, unused_nampla, destination_nampla, temp_nampla *nampla_data
+	var recipient_ship, unused_ship *ship_data
+
+	/* Check if this order was preceded by a PRODUCTION order. */
+	if e.doing_production == FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Missing PRODUCTION order!\n")
+		return
+	}
+
+	/* Get ready if planet is under siege. */
+	if e.nampla.siege_eff < 0 {
+		siege_effectiveness = -e.nampla.siege_eff
+	} else {
+		siege_effectiveness = e.nampla.siege_eff
+	}
+
+	/* Get species name and make appropriate tests if this is an interspecies construction order. */
+	if interspecies_construction != FALSE {
+		original_line_pointer := e.input_line_pointer
+		if e.get_species_name() == FALSE {
+			/* Check for missing comma or tab after species name. */
+			e.input_line_pointer = original_line_pointer
+			e.fix_separator()
+			if e.get_species_name() == FALSE {
+				fprintf(e.log_file, "!!! Order ignored:\n")
+				fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+				fprintf(e.log_file, "!!! Invalid species name.\n")
+				return
+			}
+		}
+		recipient_species = e.spec_data[e.g_spec_number-1]
+
+		if e.species.tech_level[MA] < 25 {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! MA tech level must be at least 25 to do interspecies construction.\n")
+			return
+		}
+
+		/* Check if we've met this species and make sure it is not an enemy. */
+		if e.species.contact[e.g_spec_number-1] == FALSE {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! You can't do interspecies construction for a species you haven't met.\n")
+			return
+		}
+		if e.species.enemy[e.g_spec_number-1] != FALSE {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! You can't do interspecies construction for an ENEMY.\n")
+			return
+		}
+	}
+
+	/* Get number of items to build. */
+	if e.get_value() == FALSE {
+		goto build_ship /* Not an item. */
+	}
+	num_items = e.value
+	original_num_items = e.value
+
+	/* Get class of item. */
+	class = e.get_class_abbr()
+	if class != ITEM_CLASS || e.abbr_index == RM {
+		/* Players sometimes accidentally use "MI" for "IU" or "MA" for "AU". */
+		if class == TECH_ID && e.abbr_index == MI {
+			e.abbr_index = IU
+		} else if class == TECH_ID && e.abbr_index == MA {
+			e.abbr_index = AU
+		} else {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Invalid item class!\n")
+			return
+		}
+	}
+	class = e.abbr_index
+
+	if interspecies_construction != FALSE {
+		if class == PD || class == CU {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! You cannot build CUs or PDs for another species!\n")
+			return
+		}
+	}
+
+	/* Make sure species knows how to build this item. */
+	critical_tech = item_critical_tech[class]
+	if e.species.tech_level[critical_tech] < item_tech_requirment[class] {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Insufficient tech level to build item.\n")
+		return
+	}
+
+	/* Get cost of item. */
+	if class == TP { /* Terraforming plant. */
+		unit_cost = item_cost[class] / e.species.tech_level[critical_tech]
+	} else {
+		unit_cost = item_cost[class]
+	}
+
+	if num_items == 0 {
+		num_items = e.balance / unit_cost
+	}
+	if num_items == 0 {
+		return
+	}
+
+	/* Make sure item count is meaningful. */
+	if num_items < 0 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Meaningless item count.\n")
+		return
+	}
+
+	/* Make sure there is enough available population. */
+	pop_reduction = 0
+	if class == CU || class == PD {
+		if e.nampla.pop_units < num_items {
+			if original_num_items == 0 {
+				num_items = e.nampla.pop_units
+				if num_items == 0 {
+					return
+				}
+			} else {
+				if e.nampla.pop_units > 0 {
+					fprintf(e.log_file, "! WARNING: %s", b2s(e.input_line))
+					fprintf(e.log_file, "! Insufficient available population units. Substituting %d for %d.\n", e.nampla.pop_units, num_items)
+					num_items = e.nampla.pop_units
+				} else {
+					fprintf(e.log_file, "!!! Order ignored:\n")
+					fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+					fprintf(e.log_file, "!!! Insufficient available population units.\n")
+					return
+				}
+			}
+		}
+		pop_reduction = num_items
+	}
+
+do_cost:
+
+	/* Calculate total cost and see if planet has enough money. */
+	cost = num_items * unit_cost
+	if interspecies_construction != FALSE {
+		premium = (cost + 9) / 10
+	} else {
+		premium = 0
+	}
+	cost += premium
+
+	if e.check_bounced(cost) != FALSE {
+		if interspecies_construction != FALSE && original_num_items == 0 {
+			num_items--
+			if num_items < 1 {
+				return
+			}
+			goto do_cost
+		}
+
+		max_funds_available = e.species.econ_units
+		if max_funds_available > e.EU_spending_limit {
+			max_funds_available = e.EU_spending_limit
+		}
+		max_funds_available += e.balance
+
+		num_items = max_funds_available / unit_cost
+		if interspecies_construction != FALSE {
+			num_items -= (num_items + 9) / 10
+		}
+
+		if num_items > 0 {
+			fprintf(e.log_file, "! WARNING: %s", b2s(e.input_line))
+			fprintf(e.log_file, "! Insufficient funds. Substituting %d for %d.\n", num_items, original_num_items)
+			goto do_cost
+		}
+
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Insufficient funds to execute order.\n")
+		return
+	}
+
+	/* Update planet inventory. */
+	e.nampla.item_quantity[class] += num_items
+	e.nampla.pop_units -= pop_reduction
+
+	/* Log what was produced. */
+	e.log_string("    ")
+	e.log_long(num_items)
+	e.log_char(' ')
+	e.log_string(item_name[class])
+
+	if num_items > 1 {
+		e.log_string("s were")
+	} else {
+		e.log_string(" was")
+	}
+
+	e.log_string(" produced")
+	if interspecies_construction != FALSE {
+		e.log_string(" for SP ")
+		e.log_string(recipient_species.name)
+	}
+
+	if unit_cost != 1 || premium != 0 {
+		e.log_string(" at a cost of ")
+		e.log_long(cost)
+	}
+
+	/* Check if planet is under siege and if production of planetary
+	 * defenses was detected. */
+	if class == PD && e.rnd(100) <= siege_effectiveness {
+		e.log_string(". However, they were detected and destroyed by the besiegers!!!\n")
+		e.nampla.item_quantity[PD] = 0
+		e.detection_during_siege(3, "") /* Construction of PDs. */
+		return
+	}
+
+	if interspecies_construction == FALSE {
+		/* Get destination of transfer, if any. */
+		pop_check_needed = FALSE
+		temp_nampla = e.nampla
+		found = e.get_transfer_point()
+		destination_nampla = e.nampla
+		e.nampla = temp_nampla
+		if found == FALSE {
+			goto done_transfer
+		}
+
+		if e.abbr_type == SHIP_CLASS { /* Destination is 'ship'. */
+			if e.ship.x != e.nampla.x || e.ship.y != e.nampla.y || e.ship.z != e.nampla.z {
+				goto done_transfer
+			}
+
+			if e.ship.status == UNDER_CONSTRUCTION {
+				goto done_transfer
+			}
+
+			if e.ship.class == TR {
+				capacity = (10 + (e.ship.tonnage / 2)) * e.ship.tonnage
+			} else if e.ship.class == BA {
+				capacity = 10 * e.ship.tonnage
+			} else {
+				capacity = e.ship.tonnage
+			}
+
+			for i = 0; i < MAX_ITEMS; i++ {
+				capacity -= e.ship.item_quantity[i] * item_carry_capacity[i]
+			}
+
+			n = num_items
+			if num_items*item_carry_capacity[class] > capacity {
+				num_items = capacity / item_carry_capacity[class]
+			}
+
+			e.ship.item_quantity[class] += num_items
+			e.nampla.item_quantity[class] -= num_items
+			e.log_string(" and ")
+			if n > num_items {
+				e.log_long(num_items)
+				e.log_string(" of them ")
+			}
+			if num_items == 1 {
+				e.log_string("was")
+			} else {
+				e.log_string("were")
+			}
+			e.log_string(" transferred to ")
+			e.log_string(e.ship_name(e.ship))
+
+			if class == CU && num_items > 0 {
+				for i = 0; i < e.species.num_namplas; i++ {
+					if e.nampla_base[i] == e.nampla {
+						break
+					}
+				}
+				if i == 0 {
+					i = 9999 /* Home planet. */
+				}
+				e.ship.loading_point = i
+			}
+		} else { /* Destination is 'destination_nampla'. */
+			if destination_nampla.x != e.nampla.x || destination_nampla.y != e.nampla.y || destination_nampla.z != e.nampla.z {
+				goto done_transfer
+			}
+
+			if e.nampla.siege_eff != 0 {
+				goto done_transfer
+			}
+			if destination_nampla.siege_eff != 0 {
+				goto done_transfer
+			}
+
+			destination_nampla.item_quantity[class] += num_items
+			e.nampla.item_quantity[class] -= num_items
+			e.log_string(" and transferred to PL ")
+			e.log_string(destination_nampla.name)
+			pop_check_needed = TRUE
+		}
+
+	done_transfer:
+
+		e.log_string(".\n")
+
+		if pop_check_needed != FALSE {
+			e.check_population(destination_nampla)
+		}
+
+		return
+	}
+
+	e.log_string(".\n")
+
+	/* Check if recipient species has a nampla at this location. */
+	found = FALSE
+	unused_nampla_available = FALSE
+	for i = 0; i < recipient_species.num_namplas; i++ {
+		recipient_nampla = e.namp_data[e.g_spec_number-1][i]
+
+		if recipient_nampla.pn == 99 {
+			unused_nampla = recipient_nampla
+			unused_nampla_available = TRUE
+		}
+
+		if recipient_nampla.x != e.nampla.x {
+			continue
+		}
+		if recipient_nampla.y != e.nampla.y {
+			continue
+		}
+		if recipient_nampla.z != e.nampla.z {
+			continue
+		}
+		if recipient_nampla.pn != e.nampla.pn {
+			continue
+		}
+
+		found = TRUE
+		break
+	}
+
+	if found == FALSE {
+		/* Add new nampla to database for the recipient species. */
+		if unused_nampla_available != FALSE {
+			recipient_nampla = unused_nampla
+		} else {
+			recipient_nampla = &nampla_data{}
+			e.namp_data[e.g_spec_number-1] = append(e.namp_data[e.g_spec_number-1], recipient_nampla)
+			recipient_species.num_namplas++
+		}
+		e.delete_nampla(recipient_nampla) /* Set everything to zero. */
+
+		/* Initialize new nampla. */
+		recipient_nampla.name = e.nampla.name
+		recipient_nampla.x = e.nampla.x
+		recipient_nampla.y = e.nampla.y
+		recipient_nampla.z = e.nampla.z
+		recipient_nampla.pn = e.nampla.pn
+		recipient_nampla.planet_index = e.nampla.planet_index
+		recipient_nampla.status = COLONY
+	}
+
+	/* Transfer the goods. */
+	e.nampla.item_quantity[class] -= num_items
+	recipient_nampla.item_quantity[class] += num_items
+
+	/* Define transaction so that recipient will be notified. */
+	if e.num_transactions == MAX_TRANSACTIONS {
+		fprintf(e.stderr, "\n\n\tERROR! num_transactions > MAX_TRANSACTIONS!\n\n")
+		panic("\n\n\tERROR! num_transactions > MAX_TRANSACTIONS!\n\n")
+	}
+	n = e.num_transactions
+	e.num_transactions++
+	e.transaction[n]._type = INTERSPECIES_CONSTRUCTION
+	e.transaction[n].donor = e.species_number
+	e.transaction[n].recipient = e.g_spec_number
+	e.transaction[n].value = 1 /* Items, not ships. */
+	e.transaction[n].number1 = num_items
+	e.transaction[n].number2 = class
+	e.transaction[n].number3 = cost
+	e.transaction[n].name1 = e.species.name
+	e.transaction[n].name2 = recipient_nampla.name
+
+	return
+
+build_ship:
+
+	original_line_pointer := e.input_line_pointer
+	if continuing_construction != FALSE {
+		found = e.get_ship()
+		if found == FALSE {
+			/* Check for missing comma or tab after ship name. */
+			e.input_line_pointer = original_line_pointer
+			e.fix_separator()
+			found = e.get_ship()
+		}
+		if found != FALSE {
+			goto check_ship
+		}
+		e.input_line_pointer = original_line_pointer
+	}
+
+	class = e.get_class_abbr()
+
+	if class != SHIP_CLASS || e.tonnage < 1 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid ship class.\n")
+		return
+	}
+	class = e.abbr_index
+
+	/* Get ship name. */
+	name_length = e.get_name()
+	if name_length < 1 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid ship name.\n")
+		return
+	}
+
+	/* Search all ships for name. */
+	found = FALSE
+	unused_ship_available = FALSE
+	for e.ship_index = 0; e.ship_index < e.species.num_ships; e.ship_index++ {
+		e.ship = e.ship_base[e.ship_index]
+		if e.ship.pn == 99 {
+			/* Keep track of any unused ship structs. */
+			if unused_ship_available == FALSE {
+				unused_ship_available = TRUE
+				unused_ship = e.ship
+			}
+			continue
+		}
+
+		/* Compare names. */
+		if strings.ToUpper(e.ship.name) == b2s(e.upper_name) {
+			found = TRUE
+			break
+		}
+	}
+
+check_ship:
+
+	if found != FALSE {
+		/* Check if BUILD was accidentally used instead of CONTINUE. */
+		if (e.ship.status == UNDER_CONSTRUCTION || e.ship._type == STARBASE) && e.ship.x == e.nampla.x && e.ship.y == e.nampla.y && e.ship.z == e.nampla.z && e.ship.pn == e.nampla.pn {
+			continuing_construction = TRUE
+		}
+
+		if (e.ship.status != UNDER_CONSTRUCTION && e.ship._type != STARBASE) || continuing_construction == FALSE {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Ship name already in use.\n")
+			return
+		}
+
+		new_ship = FALSE
+	} else {
+		/* If CONTINUE command was used, the player probably mis-spelled the name. */
+		if continuing_construction != FALSE {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Invalid ship name.\n")
+			return
+		}
+
+		if unused_ship_available != FALSE {
+			e.ship = unused_ship
+		} else {
+			/* The new ship is added to the species once construction is paid for. */
+			e.ship = &ship_data{}
+		}
+		new_ship = TRUE
+		e.delete_ship(e.ship) /* Initialize everything to zero. */
+
+		/* Initialize non-zero data for new ship. */
+		e.ship.name = b2s(e.original_name)
+		e.ship.x = e.nampla.x
+		e.ship.y = e.nampla.y
+		e.ship.z = e.nampla.z
+		e.ship.pn = e.nampla.pn
+		e.ship.status = UNDER_CONSTRUCTION
+		if class == BA {
+			e.ship._type = STARBASE
+			e.ship.status = IN_ORBIT
+		} else if e.sub_light != FALSE {
+			e.ship._type = SUB_LIGHT
+		} else {
+			e.ship._type = FTL
+		}
+		e.ship.class = class
+		e.ship.age = -1
+		if e.ship._type != STARBASE {
+			e.ship.tonnage = e.tonnage
+		}
+		e.ship.remaining_cost = ship_cost[class]
+		if e.ship.class == TR {
+			e.ship.remaining_cost = ship_cost[TR] * e.tonnage
+		}
+		if e.ship._type == SUB_LIGHT {
+			e.ship.remaining_cost = (3 * e.ship.remaining_cost) / 4
+		}
+		e.ship.just_jumped = FALSE
+
+		/* Everything else was set to zero in above call to 'delete_ship'. */
+	}
+
+	/* Check if amount to spend was specified. */
+	cost_given = e.get_value()
+	cost = e.value
+	cost_argument = cost
+
+	if cost_given != FALSE {
+		if interspecies_construction != FALSE && e.ship._type != STARBASE {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Amount to spend may not be specified!\n")
+			if new_ship != FALSE {
+				e.delete_ship(e.ship)
+			}
+			return
+		}
+
+		if cost == 0 {
+			cost = e.balance
+			if e.ship._type == STARBASE {
+				if cost%ship_cost[BA] != 0 {
+					cost = ship_cost[BA] * (cost / ship_cost[BA])
+				}
+			}
+			if cost < 1 {
+				if new_ship != FALSE {
+					e.delete_ship(e.ship)
+				}
+				return
+			}
+		}
+
+		if cost < 1 {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Amount specified is meaningless.\n")
+			if new_ship != FALSE {
+				e.delete_ship(e.ship)
+			}
+			return
+		}
+
+		if e.ship._type == STARBASE {
+			if cost%ship_cost[BA] != 0 {
+				fprintf(e.log_file, "!!! Order ignored:\n")
+				fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+				fprintf(e.log_file, "!!! Amount spent on starbase must be multiple of %d.\n", ship_cost[BA])
+				if new_ship != FALSE {
+					e.delete_ship(e.ship)
+				}
+				return
+			}
+		}
+	} else {
+		if e.ship._type == STARBASE {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Amount to spend MUST be specified for starbase.\n")
+			if new_ship != FALSE {
+				e.delete_ship(e.ship)
+			}
+			return
+		}
+
+		cost = e.ship.remaining_cost
+	}
+
+	/* Make sure species can build a ship of this size. */
+	max_tonnage = e.species.tech_level[MA] / 2
+	if e.ship._type == STARBASE {
+		tonnage_increase = cost / ship_cost[BA]
+		e.tonnage = e.ship.tonnage + tonnage_increase
+		if e.tonnage > max_tonnage && cost_argument == 0 {
+			tonnage_increase = max_tonnage - e.ship.tonnage
+			if tonnage_increase < 1 {
+				return
+			}
+			e.tonnage = e.ship.tonnage + tonnage_increase
+			cost = tonnage_increase * ship_cost[BA]
+		}
+	}
+
+	if e.tonnage > max_tonnage {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Maximum allowable tonnage exceeded.\n")
+		if new_ship != FALSE {
+			e.delete_ship(e.ship)
+		}
+		return
+	}
+
+	/* Make sure species has gravitics technology if this is an FTL ship. */
+	if e.ship._type == FTL && e.species.tech_level[GV] < 1 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Gravitics tech needed to build FTL ship!\n")
+		if new_ship != FALSE {
+			e.delete_ship(e.ship)
+		}
+		return
+	}
+
+	/* Make sure amount specified is not an overpayment. */
+	if e.ship._type != STARBASE && cost > e.ship.remaining_cost {
+		cost = e.ship.remaining_cost
+	}
+
+	/* Make sure planet has sufficient shipyards. */
+	if e.shipyard_capacity < 1 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Shipyard capacity exceeded!\n")
+		if new_ship != FALSE {
+			e.delete_ship(e.ship)
+		}
+		return
+	}
+
+	/* Make sure there is enough money to pay for it. */
+	premium = 0
+	if interspecies_construction != FALSE {
+		if e.ship.class == TR || e.ship._type == STARBASE {
+			total_cost = ship_cost[e.ship.class] * e.tonnage
+		} else {
+			total_cost = ship_cost[e.ship.class]
+		}
+
+		if e.ship._type == SUB_LIGHT {
+			total_cost = (3 * total_cost) / 4
+		}
+
+		premium = total_cost / 10
+		if total_cost%10 != 0 {
+			premium++
+		}
+	}
+
+	if e.check_bounced(cost+premium) != FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Insufficient funds to execute order.\n")
+		if new_ship != FALSE {
+			e.delete_ship(e.ship)
+		}
+		return
+	}
+
+	e.shipyard_capacity--
+
+	/* Test if this is a starbase and if planet is under siege. */
+	if e.ship._type == STARBASE && siege_effectiveness > 0 {
+		e.log_string("    Your attempt to build ")
+		e.log_string(e.ship_name(e.ship))
+		e.log_string(" was detected by the besiegers and the starbase was destroyed!!!\n")
+		e.detection_during_siege(2, e.ship_name(e.ship)) /* Construction of ship/starbase. */
+		e.delete_ship(e.ship)
+		return
+	}
+
+	/* Finish up and log results. */
+	e.log_string("    ")
+	if e.ship._type == STARBASE {
+		if e.ship.tonnage == 0 {
+			e.log_string(e.ship_name(e.ship))
+			e.log_string(" was constructed")
+		} else {
+			e.ship.age = ((e.ship.age * e.ship.tonnage) - tonnage_increase) / e.tonnage /* Weighted average. */
+			e.log_string("Size of ")
+			e.log_string(e.ship_name(e.ship))
+			e.log_string(" was increased to ")
+			e.log_string(commas(10000 * e.tonnage))
+			e.log_string(" tons")
+		}
+
+		e.ship.tonnage = e.tonnage
+	} else {
+		e.ship.remaining_cost -= cost
+		if e.ship.remaining_cost == 0 {
+			e.ship.status = ON_SURFACE /* Construction is complete. */
+			if continuing_construction != FALSE {
+				e.log_string("Construction finished on ")
+				e.log_string(e.ship_name(e.ship))
+			} else {
+				e.log_string(e.ship_name(e.ship))
+				e.log_string(" was constructed")
+			}
+		} else {
+			if continuing_construction != FALSE {
+				e.log_string("Construction continued on ")
+			} else {
+				e.log_string("Construction started on ")
+			}
+			e.log_string(e.ship_name(e.ship))
+		}
+	}
+	e.log_string(" at a cost of ")
+	e.log_long(cost + premium)
+
+	if interspecies_construction != FALSE {
+		e.log_string(" for SP ")
+		e.log_string(recipient_species.name)
+	}
+
+	e.log_char('.')
+
+	if new_ship != FALSE && unused_ship_available == FALSE {
+		e.ship_data[e.species_index] = append(e.ship_data[e.species_index], e.ship)
+		e.ship_base = e.ship_data[e.species_index]
+		e.species.num_ships++
+	}
+
+	/* Check if planet is under siege and if construction was detected. */
+	if e.rnd(100) <= siege_effectiveness {
+		e.log_string(" However, the work was detected by the besiegers and the ship was destroyed!!!")
+		e.detection_during_siege(2, e.ship_name(e.ship)) /* Construction of ship/starbase. */
+
+		/* Remove ship from inventory. */
+		e.delete_ship(e.ship)
+	}
+
+	e.log_char('\n')
+
+	if interspecies_construction == FALSE {
+		return
+	}
+
+	/* Transfer any cargo on the ship to the planet. */
+	cargo_on_board = FALSE
+	for i = 0; i < MAX_ITEMS; i++ {
+		if e.ship.item_quantity[i] > 0 {
+			e.nampla.item_quantity[i] += e.ship.item_quantity[i]
+			e.ship.item_quantity[i] = 0
+			cargo_on_board = TRUE
+		}
+	}
+	if cargo_on_board != FALSE {
+		e.log_string("      Forgotten cargo on the ship was first transferred to the planet.\n")
+	}
+
+	/* Transfer the ship to the recipient species. */
+	unused_ship_available = FALSE
+	for i = 0; i < recipient_species.num_ships; i++ {
+		recipient_ship = e.ship_data[e.g_spec_number-1][i]
+		if recipient_ship.pn == 99 {
+			unused_ship_available = TRUE
+			break
+		}
+	}
+
+	if unused_ship_available == FALSE {
+		recipient_ship = &ship_data{}
+		e.ship_data[e.g_spec_number-1] = append(e.ship_data[e.g_spec_number-1], recipient_ship)
+		recipient_species.num_ships++
+	}
+
+	/* Copy donor ship to recipient ship. */
+	*recipient_ship = *e.ship
+	recipient_ship.status = IN_ORBIT
+
+	/* Delete donor ship. */
+	e.delete_ship(e.ship)
+
+	/* Define transaction so that recipient will be notified. */
+	if e.num_transactions == MAX_TRANSACTIONS {
+		fprintf(e.stderr, "\n\n\tERROR! num_transactions > MAX_TRANSACTIONS!\n\n")
+		panic("\n\n\tERROR! num_transactions > MAX_TRANSACTIONS!\n\n")
+	}
+	n = e.num_transactions
+	e.num_transactions++
+	e.transaction[n]._type = INTERSPECIES_CONSTRUCTION
+	e.transaction[n].donor = e.species_number
+	e.transaction[n].recipient = e.g_spec_number
+	e.transaction[n].value = 2 /* Ship, not items. */
+	e.transaction[n].number3 = total_cost + premium
+	e.transaction[n].name1 = e.species.name
+	e.transaction[n].name2 = e.ship_name(recipient_ship)
+}
+
+/* detection_during_siege notifies each species besieging the current
+ * production planet that construction was detected. The value is 2 for
+ * ships and starbases and 3 for planetary defenses. */
+func (e *Engine) detection_during_siege(value int, ship_name string) {
+	var already_notified [MAX_SPECIES]int
+
+	for i := 0; i < e.num_transactions; i++ {
+		/* Find out who is besieging this planet. */
+		if e.transaction[i]._type != BESIEGE_PLANET {
+			continue
+		}
+		if e.transaction[i].x != e.nampla.x {
+			continue
+		}
+		if e.transaction[i].y != e.nampla.y {
+			continue
+		}
+		if e.transaction[i].z != e.nampla.z {
+			continue
+		}
+		if e.transaction[i].pn != e.nampla.pn {
+			continue
+		}
+		if e.transaction[i].number2 != e.species_number {
+			continue
+		}
+
+		alien_number := e.transaction[i].number1
+
+		/* Make sure we don't notify the same species more than once. */
+		if already_notified[alien_number-1] != FALSE {
+			continue
+		}
+
+		/* Define a 'detection' transaction. */
+		if e.num_transactions == MAX_TRANSACTIONS {
+			fprintf(e.stderr, "\n\n\tERROR! num_transactions > MAX_TRANSACTIONS!\n\n")
+			panic("\n\n\tERROR! num_transactions > MAX_TRANSACTIONS!\n\n")
+		}
+		n := e.num_transactions
+		e.num_transactions++
+		e.transaction[n]._type = DETECTION_DURING_SIEGE
+		e.transaction[n].value = value
+		e.transaction[n].name1 = e.nampla.name
+		e.transaction[n].name2 = ship_name
+		e.transaction[n].name3 = e.species.name
+		e.transaction[n].number3 = alien_number
+
+		already_notified[alien_number-1] = TRUE
+	}
+}
@@ -1,6 +1,6 @@
 /*******************************************************************************
 Far Horizons Engine
-Copyright (C) 2021  Michael D Henderson
+Copyright (C) 2022  Michael D Henderson
 
 This program is free software: you can redistribute it and/or modify
 it under the terms of the GNU Affero General Public License as published
@@ -16,4 +16,22 @@ You should have received a copy of the GNU Affero General Public License
 along with this program.  If not, see <https://www.gnu.org/licenses/>.
 ******************************************************************************/
 
-package main
+package engine
+
+func (e *Engine) do_DESTROY_command() {
+	/* Get the ship or starbase. */
+	e.correct_spelling_required = TRUE
+	if e.get_ship() == FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid ship or starbase name in DESTROY command.\n")
+		return
+	}
+
+	/* Log result. */
+	e.log_string("    ")
+	e.log_string(e.ship_name(e.ship))
+	e.log_string(" was destroyed.\n")
+
+	e.delete_ship(e.ship)
+}
@@ -0,0 +1,468 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+func (e *Engine) do_DEVELOP_command() {
+	var i, num_CUs, num_AUs, num_IUs, more_args, load_transport, capacity, resort_colony, mining_colony int
+	var production_penalty, CUs_only int
+	var n, ni, na, amount_to_spend, max_funds_available, ls_needed, raw_material_units, production_capacity int
+	var colony_production, ib, ab, md, denom, reb, specified_max int
+	var colony_planet *planet_data
+	var colony_nampla, temp_nampla *nampla_data
+
+	/* Check if this order was preceded by a PRODUCTION order. */
+	if e.doing_production == FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Missing PRODUCTION order!\n")
+		return
+	}
+
+	/* Get default spending limit. */
+	max_funds_available = e.species.econ_units
+	if max_funds_available > e.EU_spending_limit {
+		max_funds_available = e.EU_spending_limit
+	}
+	max_funds_available += e.balance
+
+	/* Get specified spending limit, if any. */
+	specified_max = -1
+	if e.get_value() != FALSE {
+		if e.value == 0 {
+			max_funds_available = e.balance
+		} else if e.value > 0 {
+			specified_max = e.value
+			if e.value <= max_funds_available {
+				max_funds_available = e.value
+			} else {
+				fprintf(e.log_file, "! WARNING: %s", b2s(e.input_line))
+				fprintf(e.log_file, "! Insufficient funds. Substituting %d for %d.\n", max_funds_available, e.value)
+			}
+		} else {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Invalid spending limit.\n")
+			return
+		}
+	}
+
+	/* See if there are any more arguments. */
+	more_args = e.develop_more_args()
+
+	if more_args == FALSE {
+		/* Make sure planet is not a healthy home planet. */
+		if (e.nampla.status & HOME_PLANET) != 0 {
+			reb = e.species.hp_original_base - (e.nampla.mi_base + e.nampla.ma_base)
+			if reb > 0 {
+				/* Home planet is recovering from bombing. */
+				if reb < max_funds_available {
+					max_funds_available = reb
+				}
+			} else {
+				fprintf(e.log_file, "!!! Order ignored:\n")
+				fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+				fprintf(e.log_file, "!!! You can only DEVELOP a home planet if it is recovering from bombing.\n")
+				return
+			}
+		}
+
+		/* No arguments. Order is for this planet. */
+		num_CUs = e.nampla.pop_units
+		if 2*num_CUs > max_funds_available {
+			num_CUs = max_funds_available / 2
+		}
+		if num_CUs <= 0 {
+			return
+		}
+
+		colony_planet = e.planet_base[e.nampla.planet_index]
+		ib = e.nampla.mi_base + e.nampla.IUs_to_install
+		ab = e.nampla.ma_base + e.nampla.AUs_to_install
+		md = colony_planet.mining_difficulty
+
+		denom = 100 + md
+		num_AUs = (100*(num_CUs+ib) - (md * ab) + denom/2) / denom
+		num_IUs = num_CUs - num_AUs
+
+		if num_IUs < 0 {
+			num_AUs = num_CUs
+			num_IUs = 0
+		}
+		if num_AUs < 0 {
+			num_IUs = num_CUs
+			num_AUs = 0
+		}
+
+		amount_to_spend = num_CUs + num_AUs + num_IUs
+
+		if e.check_bounced(amount_to_spend) != FALSE {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Internal error. Please notify GM!\n")
+			return
+		}
+
+		e.nampla.pop_units -= num_CUs
+		e.nampla.item_quantity[CU] += num_CUs
+		e.nampla.item_quantity[IU] += num_IUs
+		e.nampla.item_quantity[AU] += num_AUs
+
+		e.nampla.auto_IUs += num_IUs
+		e.nampla.auto_AUs += num_AUs
+
+		e.start_dev_log(num_CUs, num_IUs, num_AUs)
+		e.log_string(".\n")
+
+		e.check_population(e.nampla)
+
+		return
+	}
+
+	/* Get the planet to be developed. */
+	temp_nampla = e.nampla
+	found := e.get_location()
+	colony_nampla = e.nampla
+	e.nampla = temp_nampla
+	if found == FALSE || colony_nampla == nil {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid planet name in DEVELOP command.\n")
+		return
+	}
+
+	/* Make sure planet is not a healthy home planet. */
+	if (colony_nampla.status & HOME_PLANET) != 0 {
+		reb = e.species.hp_original_base - (colony_nampla.mi_base + colony_nampla.ma_base)
+		if reb > 0 {
+			/* Home planet is recovering from bombing. */
+			if reb < max_funds_available {
+				max_funds_available = reb
+			}
+		} else {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! You can only DEVELOP a home planet if it is recovering from bombing.\n")
+			return
+		}
+	}
+
+	/* Determine if its a mining or resort colony, and if it can afford to
+	 * build its own IUs and AUs. Note that we cannot use nampla.status
+	 * because it is not correctly set until the Finish program is run. */
+	colony_planet = e.planet_base[colony_nampla.planet_index]
+	ls_needed = life_support_needed(e.species, e.home_planet, colony_planet)
+
+	ni = colony_nampla.mi_base + colony_nampla.IUs_to_install
+	na = colony_nampla.ma_base + colony_nampla.AUs_to_install
+
+	if ni > 0 && na == 0 {
+		colony_production = 0
+		mining_colony = TRUE
+		resort_colony = FALSE
+	} else if na > 0 && ni == 0 && ls_needed <= 6 && colony_planet.gravity <= e.home_planet.gravity {
+		colony_production = 0
+		resort_colony = TRUE
+		mining_colony = FALSE
+	} else {
+		mining_colony = FALSE
+		resort_colony = FALSE
+
+		raw_material_units = (10 * e.species.tech_level[MI] * ni) / colony_planet.mining_difficulty
+		production_capacity = (e.species.tech_level[MA] * na) / 10
+
+		if ls_needed == 0 {
+			production_penalty = 0
+		} else {
+			production_penalty = (100 * ls_needed) / e.species.tech_level[LS]
+		}
+
+		raw_material_units -= (production_penalty * raw_material_units) / 100
+		production_capacity -= (production_penalty * production_capacity) / 100
+
+		if production_capacity > raw_material_units {
+			colony_production = raw_material_units
+		} else {
+			colony_production = production_capacity
+		}
+
+		/* In case there is more than one DEVELOP order for this colony. */
+		colony_production -= colony_nampla.IUs_needed + colony_nampla.AUs_needed
+	}
+
+	/* See if there are more arguments. */
+	more_args = e.develop_more_args()
+
+	if more_args != FALSE {
+		load_transport = TRUE
+
+		/* Get the ship to receive the cargo. */
+		if e.get_ship() == FALSE {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Ship to be loaded does not exist!\n")
+			return
+		}
+
+		if e.ship.class == TR {
+			capacity = (10 + (e.ship.tonnage / 2)) * e.ship.tonnage
+		} else if e.ship.class == BA {
+			capacity = 10 * e.ship.tonnage
+		} else {
+			capacity = e.ship.tonnage
+		}
+
+		for i = 0; i < MAX_ITEMS; i++ {
+			capacity -= e.ship.item_quantity[i] * item_carry_capacity[i]
+		}
+
+		if capacity <= 0 {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! %s was already full and could take no more cargo!\n", e.ship_name(e.ship))
+			return
+		}
+
+		if capacity > max_funds_available {
+			capacity = max_funds_available
+			if max_funds_available != specified_max {
+				fprintf(e.log_file, "! WARNING: %s", b2s(e.input_line))
+				fprintf(e.log_file, "! Insufficient funds to completely fill %s!\n", e.ship_name(e.ship))
+				fprintf(e.log_file, "! Will use all remaining funds (= %d).\n", capacity)
+			}
+		}
+	} else {
+		load_transport = FALSE
+
+		/* No more arguments. Order is for a colony in the same sector as the
+		 * producing planet. */
+		if e.nampla.x != colony_nampla.x || e.nampla.y != colony_nampla.y || e.nampla.z != colony_nampla.z {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Colony and producing planet are not in the same sector.\n")
+			return
+		}
+
+		num_CUs = e.nampla.pop_units
+		if 2*num_CUs > max_funds_available {
+			num_CUs = max_funds_available / 2
+		}
+	}
+
+	CUs_only = FALSE
+	if mining_colony != FALSE {
+		if load_transport != FALSE {
+			num_CUs = capacity / 2
+			if num_CUs > e.nampla.pop_units {
+				fprintf(e.log_file, "! WARNING: %s", b2s(e.input_line))
+				fprintf(e.log_file, "! Insufficient available population! %d CUs are needed", num_CUs)
+				num_CUs = e.nampla.pop_units
+				fprintf(e.log_file, " to fill ship but only %d can be built.\n", num_CUs)
+			}
+		}
+
+		num_AUs = 0
+		num_IUs = num_CUs
+	} else if resort_colony != FALSE {
+		if load_transport != FALSE {
+			num_CUs = capacity / 2
+			if num_CUs > e.nampla.pop_units {
+				fprintf(e.log_file, "! WARNING: %s", b2s(e.input_line))
+				fprintf(e.log_file, "! Insufficient available population! %d CUs are needed", num_CUs)
+				num_CUs = e.nampla.pop_units
+				fprintf(e.log_file, " to fill ship but only %d can be built.\n", num_CUs)
+			}
+		}
+
+		num_IUs = 0
+		num_AUs = num_CUs
+	} else {
+		if load_transport != FALSE {
+			if colony_production >= capacity {
+				/* Colony can build its own IUs and AUs. */
+				num_CUs = capacity
+				CUs_only = TRUE
+			} else {
+				/* Build IUs and AUs for the colony. */
+				num_CUs = capacity / 2
+			}
+
+			if num_CUs > e.nampla.pop_units {
+				fprintf(e.log_file, "! WARNING: %s", b2s(e.input_line))
+				fprintf(e.log_file, "! Insufficient available population! %d CUs are needed", num_CUs)
+				num_CUs = e.nampla.pop_units
+				fprintf(e.log_file, " to fill ship, but\n!   only %d can be built.\n", num_CUs)
+			}
+		}
+
+		i = 100 + colony_planet.mining_difficulty
+		num_AUs = ((100 * num_CUs) + (i+1)/2) / i
+		num_IUs = num_CUs - num_AUs
+	}
+
+	if num_CUs <= 0 {
+		return
+	}
+
+	/* Make sure there's enough money to pay for it all. */
+	if load_transport != FALSE && CUs_only != FALSE {
+		amount_to_spend = num_CUs
+	} else {
+		amount_to_spend = num_CUs + num_IUs + num_AUs
+	}
+
+	if e.check_bounced(amount_to_spend) != FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Internal error. Please notify GM!\n")
+		return
+	}
+
+	/* Start logging what happened. */
+	if load_transport != FALSE && CUs_only != FALSE {
+		e.start_dev_log(num_CUs, 0, 0)
+	} else {
+		e.start_dev_log(num_CUs, num_IUs, num_AUs)
+	}
+
+	e.log_string(" for PL ")
+	e.log_string(colony_nampla.name)
+
+	e.nampla.pop_units -= num_CUs
+
+	if load_transport != FALSE {
+		if CUs_only != FALSE {
+			colony_nampla.IUs_needed += num_IUs
+			colony_nampla.AUs_needed += num_AUs
+		}
+
+		if e.nampla.x != e.ship.x || e.nampla.y != e.ship.y || e.nampla.z != e.ship.z {
+			e.nampla.item_quantity[CU] += num_CUs
+			if CUs_only == FALSE {
+				e.nampla.item_quantity[IU] += num_IUs
+				e.nampla.item_quantity[AU] += num_AUs
+			}
+
+			e.log_string(" but will remain on the planet's surface because ")
+			e.log_string(e.ship_name(e.ship))
+			e.log_string(" is not in the same sector.")
+		} else {
+			e.ship.item_quantity[CU] += num_CUs
+			if CUs_only == FALSE {
+				e.ship.item_quantity[IU] += num_IUs
+				e.ship.item_quantity[AU] += num_AUs
+			}
+
+			for n = 0; n < e.species.num_namplas; n++ {
+				if e.nampla_base[n] == colony_nampla {
+					break
+				}
+			}
+			if n == 0 {
+				n = 9999 /* Home planet. */
+			}
+			e.ship.unloading_point = n
+
+			for n = 0; n < e.species.num_namplas; n++ {
+				if e.nampla_base[n] == e.nampla {
+					break
+				}
+			}
+			if n == 0 {
+				n = 9999 /* Home planet. */
+			}
+			e.ship.loading_point = n
+
+			e.log_string(" and transferred to ")
+			e.log_string(e.ship_name(e.ship))
+		}
+	} else {
+		colony_nampla.item_quantity[CU] += num_CUs
+		colony_nampla.item_quantity[IU] += num_IUs
+		colony_nampla.item_quantity[AU] += num_AUs
+
+		colony_nampla.auto_IUs += num_IUs
+		colony_nampla.auto_AUs += num_AUs
+
+		e.log_string(" and transferred to PL ")
+		e.log_string(colony_nampla.name)
+
+		e.check_population(colony_nampla)
+	}
+
+	e.log_string(".\n")
+}
+
+/* develop_more_args returns TRUE if there is anything other than white
+ * space or a comment left on the current command line. */
+func (e *Engine) develop_more_args() int {
+	for _, c := range e.input_line_pointer {
+		if c == 0 || c == '\n' || c == ';' {
+			break
+		}
+		if c != ' ' && c != '\t' {
+			return TRUE
+		}
+	}
+	return FALSE
+}
+
+func (e *Engine) start_dev_log(num_CUs, num_IUs, num_AUs int) {
+	e.log_string("    ")
+	e.log_int(num_CUs)
+	e.log_string(" Colonist Unit")
+	if num_CUs != 1 {
+		e.log_char('s')
+	}
+
+	if num_IUs+num_AUs == 0 {
+		goto done
+	}
+
+	if num_IUs > 0 {
+		if num_AUs == 0 {
+			e.log_string(" and ")
+		} else {
+			e.log_string(", ")
+		}
+
+		e.log_int(num_IUs)
+		e.log_string(" Colonial Mining Unit")
+		if num_IUs != 1 {
+			e.log_char('s')
+		}
+	}
+
+	if num_AUs > 0 {
+		if num_IUs > 0 {
+			e.log_char(',')
+		}
+
+		e.log_string(" and ")
+
+		e.log_int(num_AUs)
+		e.log_string(" Colonial Manufacturing Unit")
+		if num_AUs != 1 {
+			e.log_char('s')
+		}
+	}
+
+done:
+
+	e.log_string(" were built")
+}
@@ -0,0 +1,58 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+func (e *Engine) do_DISBAND_command() {
+	/* Get the planet. */
+	if e.get_location() == FALSE || e.nampla == nil {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid planet name in DISBAND command.\n")
+		return
+	}
+
+	/* Make sure planet is not the home planet. */
+	if (e.nampla.status & HOME_PLANET) != 0 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! You cannot disband your home planet!\n")
+		return
+	}
+
+	/* Make sure planet is not under siege. */
+	if e.nampla.siege_eff != 0 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! You cannot disband a planet that is under siege!\n")
+		return
+	}
+
+	/* Mark the colony as "disbanded" and convert mining and manufacturing base to CUs, IUs, and AUs. */
+	e.nampla.status |= DISBANDED_COLONY
+	e.nampla.item_quantity[CU] += e.nampla.mi_base + e.nampla.ma_base
+	e.nampla.item_quantity[IU] += e.nampla.mi_base / 2
+	e.nampla.item_quantity[AU] += e.nampla.ma_base / 2
+	e.nampla.mi_base = 0
+	e.nampla.ma_base = 0
+
+	/* Log the event. */
+	e.log_string("    The colony on PL ")
+	e.log_string(e.nampla.name)
+	e.log_string(" was ordered to disband.\n")
+}
@@ -0,0 +1,95 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+func (e *Engine) do_ESTIMATE_command() {
+	var i, max_error, cost int
+	var estimate [6]int
+	var alien *species_data
+
+	/* Check if this order was preceded by a PRODUCTION order. */
+	if e.doing_production == FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Missing PRODUCTION order!\n")
+		return
+	}
+
+	/* Get name of alien species. */
+	if e.get_species_name() == FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid species name in ESTIMATE command.\n")
+		return
+	}
+
+	/* Check if we've met this species. */
+	if e.species.contact[e.g_spec_number-1] == FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! You can't do an estimate of a species you haven't met.\n")
+		return
+	}
+
+	/* Check if sufficient funds are available. */
+	cost = 25
+	if e.check_bounced(cost) != FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Insufficient funds to execute order.\n")
+		return
+	}
+
+	/* Make the estimates. */
+	alien = e.spec_data[e.g_spec_number-1]
+	for i = 0; i < 6; i++ {
+		max_error = alien.tech_level[i] - e.species.tech_level[i]
+		if max_error < 1 {
+			max_error = 1
+		}
+		estimate[i] = alien.tech_level[i] + e.rnd((2*max_error)+1) - (max_error + 1)
+		if alien.tech_level[i] == 0 {
+			estimate[i] = 0
+		}
+		if estimate[i] < 0 {
+			estimate[i] = 0
+		}
+	}
+
+	/* Log the result. */
+	e.log_string("    Estimate of the technology of SP ")
+	e.log_string(alien.name)
+	e.log_string(" (government name '")
+	e.log_string(alien.govt_name)
+	e.log_string("', government type '")
+	e.log_string(alien.govt_type)
+	e.log_string("'):\n      MI = ")
+	e.log_int(estimate[MI])
+	e.log_string(", MA = ")
+	e.log_int(estimate[MA])
+	e.log_string(", ML = ")
+	e.log_int(estimate[ML])
+	e.log_string(", GV = ")
+	e.log_int(estimate[GV])
+	e.log_string(", LS = ")
+	e.log_int(estimate[LS])
+	e.log_string(", BI = ")
+	e.log_int(estimate[BI])
+	e.log_string(".\n")
+}
@@ -0,0 +1,78 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+// do_HIDE_command is only valid in the production phase, after a
+// PRODUCTION order has set the current planet.
+func (e *Engine) do_HIDE_command() {
+	/* Check if this order was preceded by a PRODUCTION order. */
+	if e.doing_production == FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Missing PRODUCTION order!\n")
+		return
+	}
+
+	/* Make sure this is not a mining colony or home planet. */
+	if (e.nampla.status & HOME_PLANET) != 0 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! You may not HIDE a home planet.\n")
+		return
+	}
+	if (e.nampla.status & RESORT_COLONY) != 0 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! You may not HIDE a resort colony.\n")
+		return
+	}
+
+	/* Check if planet is under siege. */
+	if e.nampla.siege_eff != 0 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Besieged planet cannot HIDE!\n")
+		return
+	}
+
+	/* Check if sufficient funds are available. */
+	cost := (e.nampla.mi_base + e.nampla.ma_base) / 10
+	if (e.nampla.status & MINING_COLONY) != 0 {
+		if cost > e.species.econ_units {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Mining colony does not have sufficient EUs to hide.\n")
+			return
+		}
+		e.species.econ_units -= cost
+	} else if e.check_bounced(cost) != FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Insufficient funds to execute order.\n")
+		return
+	}
+
+	/* Set 'hiding' flag. */
+	e.nampla.hiding = TRUE
+
+	/* Log transaction. */
+	e.log_string("    Spent ")
+	e.log_long(cost)
+	e.log_string(" hiding this colony.\n")
+}
@@ -0,0 +1,187 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+func (e *Engine) do_INSTALL_command() {
+	var item_class, item_count, num_available, do_all_units, recovering_home_planet, n, reb int
+
+	/* Get number of items to install. */
+	if e.get_value() != FALSE {
+		do_all_units = FALSE
+	} else {
+		do_all_units = TRUE
+		item_count = 0
+		item_class = IU
+		goto get_planet
+	}
+
+	/* Make sure value is meaningful. */
+	if e.value < 0 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid item count in INSTALL command.\n")
+		return
+	}
+	item_count = e.value
+
+	/* Get class of item. */
+	item_class = e.get_class_abbr()
+	if item_class != ITEM_CLASS || (e.abbr_index != IU && e.abbr_index != AU) {
+		/* Players sometimes accidentally use "MI" for "IU" or "MA" for "AU". */
+		if item_class == TECH_ID && e.abbr_index == MI {
+			e.abbr_index = IU
+		} else if item_class == TECH_ID && e.abbr_index == MA {
+			e.abbr_index = AU
+		} else {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Invalid item class!\n")
+			return
+		}
+	}
+	item_class = e.abbr_index
+
+get_planet:
+
+	/* Get planet where items are to be installed. */
+	if e.get_location() == FALSE || e.nampla == nil {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid planet name in INSTALL command.\n")
+		return
+	}
+
+	/* Make sure this is not someone else's populated homeworld. */
+	for alien_index := 0; alien_index < e.galaxy.num_species; alien_index++ {
+		if e.species_number == alien_index+1 {
+			continue
+		} else if e.spec_data[alien_index] == nil || len(e.namp_data[alien_index]) == 0 {
+			continue
+		}
+
+		alien_home_nampla := e.namp_data[alien_index][0]
+		if alien_home_nampla.x != e.nampla.x {
+			continue
+		} else if alien_home_nampla.y != e.nampla.y {
+			continue
+		} else if alien_home_nampla.z != e.nampla.z {
+			continue
+		} else if alien_home_nampla.pn != e.nampla.pn {
+			continue
+		} else if (alien_home_nampla.status & POPULATED) == 0 {
+			continue
+		}
+
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! You may not colonize someone else's populated home planet!\n")
+		return
+	}
+
+	/* Make sure it's not a healthy home planet. */
+	recovering_home_planet = FALSE
+	if (e.nampla.status & HOME_PLANET) != 0 {
+		n = e.nampla.mi_base + e.nampla.ma_base + e.nampla.IUs_to_install + e.nampla.AUs_to_install
+		reb = e.species.hp_original_base - n
+		if reb > 0 {
+			recovering_home_planet = TRUE /* HP was bombed. */
+		} else {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Installation not allowed on a healthy home planet!\n")
+			return
+		}
+	}
+
+check_items:
+
+	/* Make sure planet has the specified items. */
+	if item_count == 0 {
+		item_count = e.nampla.item_quantity[item_class]
+		if e.nampla.item_quantity[CU] < item_count {
+			item_count = e.nampla.item_quantity[CU]
+		}
+		if item_count == 0 {
+			if do_all_units != FALSE {
+				item_count = 0
+				item_class = AU
+				do_all_units = FALSE
+				goto check_items
+			}
+			return
+		}
+	} else if e.nampla.item_quantity[item_class] < item_count {
+		fprintf(e.log_file, "! WARNING: %s", b2s(e.input_line))
+		fprintf(e.log_file, "! Planet does not have %d %ss. Substituting 0 for %d!\n", item_count, item_abbr[item_class], item_count)
+		item_count = 0
+		goto check_items
+	}
+
+	if recovering_home_planet != FALSE {
+		if item_count > reb {
+			item_count = reb
+		}
+		reb -= item_count
+	}
+
+	/* Make sure planet has enough colonist units. */
+	num_available = e.nampla.item_quantity[CU]
+	if num_available < item_count {
+		if num_available > 0 {
+			fprintf(e.log_file, "! WARNING: %s", b2s(e.input_line))
+			fprintf(e.log_file, "! Planet does not have %d CUs. Substituting %d for %d!\n", item_count, num_available, item_count)
+			item_count = num_available
+		} else {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! No colonist units on planet for installation.\n")
+			return
+		}
+	}
+
+	/* Start the installation. */
+	e.nampla.item_quantity[CU] -= item_count
+	e.nampla.item_quantity[item_class] -= item_count
+	if item_class == IU {
+		e.nampla.IUs_to_install += item_count
+	} else {
+		e.nampla.AUs_to_install += item_count
+	}
+
+	/* Log result. */
+	e.log_string("    Installation of ")
+	e.log_int(item_count)
+	e.log_char(' ')
+	e.log_string(item_name[item_class])
+	if item_count != 1 {
+		e.log_char('s')
+	}
+	e.log_string(" began on PL ")
+	e.log_string(e.nampla.name)
+	e.log_string(".\n")
+
+	if do_all_units != FALSE {
+		item_count = 0
+		item_class = AU
+		do_all_units = FALSE
+		goto check_items
+	}
+
+	e.check_population(e.nampla)
+}
@@ -0,0 +1,261 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+func (e *Engine) do_INTERCEPT_command() {
+	var i, status, cost int
+
+	/* Check if this order was preceded by a PRODUCTION order. */
+	if e.doing_production == FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Missing PRODUCTION order!\n")
+		return
+	}
+
+	/* Get amount to spend. */
+	status = e.get_value()
+	if status == FALSE || e.value < 0 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid or missing amount.\n")
+		return
+	}
+	if e.value == 0 {
+		e.value = e.balance
+	}
+	if e.value == 0 {
+		return
+	}
+	cost = e.value
+
+	/* Check if planet is under siege. */
+	if e.nampla.siege_eff != 0 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Besieged planet cannot INTERCEPT!\n")
+		return
+	}
+
+	/* Check if sufficient funds are available. */
+	if e.check_bounced(cost) != FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Insufficient funds to execute order.\n")
+		return
+	}
+
+	e.log_string("    Preparations were made for an interception at a cost of ")
+	e.log_long(cost)
+	e.log_string(".\n")
+
+	/* Allocate funds. */
+	for i = 0; i < e.num_intercepts; i++ {
+		if e.nampla.x != e.intercept[i].x {
+			continue
+		}
+		if e.nampla.y != e.intercept[i].y {
+			continue
+		}
+		if e.nampla.z != e.intercept[i].z {
+			continue
+		}
+
+		/* This interception was started by another planet in the same star system. */
+		e.intercept[i].amount_spent += cost
+		return
+	}
+
+	if e.num_intercepts == MAX_INTERCEPTS {
+		fprintf(e.stderr, "\n\tMAX_INTERCEPTS exceeded in do_int.c!\n\n")
+		panic("\n\tMAX_INTERCEPTS exceeded in do_int.c!\n\n")
+	}
+
+	e.intercept[e.num_intercepts].x = e.nampla.x
+	e.intercept[e.num_intercepts].y = e.nampla.y
+	e.intercept[e.num_intercepts].z = e.nampla.z
+	e.intercept[e.num_intercepts].amount_spent = cost
+
+	e.num_intercepts++
+}
+
+func (e *Engine) handle_intercept(intercept_index int) {
+	var i, j, n, num_enemy_ships, alien_index, enemy_index, enemy_num, num_ships_left int
+	var is_an_enemy, is_distorted, cost_to_destroy int
+	var enemy_number [MAX_ENEMY_SHIPS]int
+	var enemy_ship [MAX_ENEMY_SHIPS]*ship_data
+	var alien *species_data
+	var alien_sh, enemy_sh *ship_data
+
+	/* Make a list of all enemy ships that jumped into this system. */
+	num_enemy_ships = 0
+	for alien_index = 0; alien_index < e.galaxy.num_species; alien_index++ {
+		if alien = e.spec_data[alien_index]; alien == nil {
+			continue
+		}
+
+		if e.species_number == alien_index+1 {
+			continue
+		}
+
+		/* Is it an enemy species? */
+		is_an_enemy = e.species.enemy[alien_index]
+
+		/* Find enemy ships, if any, that jumped to this location. */
+		for i = 0; i < alien.num_ships; i++ {
+			alien_sh = e.ship_data[alien_index][i]
+
+			if alien_sh.pn == 99 {
+				continue
+			}
+
+			/* Did it jump this turn? */
+			if alien_sh.just_jumped == FALSE {
+				continue
+			}
+			if alien_sh.just_jumped == 50 {
+				continue /* Ship MOVEd. */
+			}
+
+			/* Did it enter this star system? */
+			if alien_sh.x != e.intercept[intercept_index].x {
+				continue
+			}
+			if alien_sh.y != e.intercept[intercept_index].y {
+				continue
+			}
+			if alien_sh.z != e.intercept[intercept_index].z {
+				continue
+			}
+
+			/* Is it field-distorted? */
+			if alien_sh.item_quantity[FD] == alien_sh.tonnage {
+				is_distorted = TRUE
+			} else {
+				is_distorted = FALSE
+			}
+
+			if is_an_enemy == FALSE && is_distorted == FALSE {
+				continue
+			}
+
+			/* This is an enemy ship that just jumped into the system. */
+			if num_enemy_ships == MAX_ENEMY_SHIPS {
+				fprintf(e.stderr, "\n\tERROR! Array overflow in do_int.c!\n\n")
+				panic("\n\tERROR! Array overflow in do_int.c!\n\n")
+			}
+			enemy_number[num_enemy_ships] = alien_index + 1
+			enemy_ship[num_enemy_ships] = alien_sh
+			num_enemy_ships++
+		}
+	}
+
+	if num_enemy_ships == 0 {
+		return /* Nothing to intercept. */
+	}
+
+	num_ships_left = num_enemy_ships
+	for num_ships_left > 0 {
+		/* Select ship for interception. */
+		enemy_index = e.rnd(num_enemy_ships) - 1
+		if enemy_ship[enemy_index] == nil {
+			continue /* We already did this one. */
+		}
+		enemy_num = enemy_number[enemy_index]
+		enemy_sh = enemy_ship[enemy_index]
+
+		/* Are there enough funds to destroy this ship? */
+		cost_to_destroy = 100 * enemy_sh.tonnage
+		if enemy_sh.class == TR {
+			cost_to_destroy /= 10
+		}
+		if cost_to_destroy > e.intercept[intercept_index].amount_spent {
+			break
+		}
+
+		/* Is the ship too large? Check only if ship did NOT arrive via a
+		 * natural wormhole. */
+		if enemy_sh.just_jumped != 99 {
+			if enemy_sh.tonnage > 20 {
+				break
+			}
+			if enemy_sh.class != TR && enemy_sh.tonnage > 5 {
+				break
+			}
+		}
+
+		/* Update funds available. */
+		e.intercept[intercept_index].amount_spent -= cost_to_destroy
+
+		/* Log the result for current species. */
+		e.log_string("\n! ")
+		n = enemy_sh.item_quantity[FD] /* Show real name. */
+		enemy_sh.item_quantity[FD] = 0
+		e.log_string(e.ship_name(enemy_sh))
+		enemy_sh.item_quantity[FD] = n
+
+		/* List cargo destroyed. */
+		n = 0
+		for j = 0; j < MAX_ITEMS; j++ {
+			if enemy_sh.item_quantity[j] > 0 {
+				n++
+				if n == 1 {
+					e.log_string(" (cargo: ")
+				} else {
+					e.log_char(',')
+				}
+				e.log_int(enemy_sh.item_quantity[j])
+				e.log_char(' ')
+				e.log_string(item_abbr[j])
+			}
+		}
+		if n > 0 {
+			e.log_char(')')
+		}
+
+		e.log_string(", owned by SP ")
+		e.log_string(e.spec_data[enemy_num-1].name)
+		e.log_string(", was successfully intercepted and destroyed in sector ")
+		e.log_int(enemy_sh.x)
+		e.log_char(' ')
+		e.log_int(enemy_sh.y)
+		e.log_char(' ')
+		e.log_int(enemy_sh.z)
+		e.log_string(".\n")
+
+		/* Create interspecies transaction so that other player will be notified. */
+		if e.num_transactions == MAX_TRANSACTIONS {
+			fprintf(e.stderr, "\n\n\tERROR! num_transactions > MAX_TRANSACTIONS in do_int.c!\n\n")
+			panic("\n\n\tERROR! num_transactions > MAX_TRANSACTIONS in do_int.c!\n\n")
+		}
+
+		n = e.num_transactions
+		e.num_transactions++
+		e.transaction[n]._type = SHIP_MISHAP
+		e.transaction[n].value = 1 /* Interception. */
+		e.transaction[n].number1 = enemy_num
+		e.transaction[n].name1 = e.ship_name(enemy_sh)
+
+		e.delete_ship(enemy_sh)
+
+		enemy_ship[enemy_index] = nil /* Don't select this ship again. */
+
+		num_ships_left--
+	}
+}
@@ -0,0 +1,334 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+import (
+	"fmt"
+	"strings"
+)
+
+func (e *Engine) do_JUMP_command(jumped_in_combat, using_jump_portal int) {
+	var found, max_xyz, status, mishap_gv, mishap_age, chance, forced_jump int
+	var portal_species_number int
+	var portal_ship *ship_data
+	var original_line_pointer []byte
+
+	if jumped_in_combat == FALSE {
+		/* Get the ship. */
+		original_line_pointer = e.input_line_pointer
+		if found = e.get_ship(); found == FALSE {
+			/* Check for missing comma or tab after ship name. */
+			e.input_line_pointer = original_line_pointer
+			e.fix_separator()
+			if found = e.get_ship(); found == FALSE {
+				fprintf(e.log_file, "!!! Order ignored:\n")
+				fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+				fprintf(e.log_file, "!!! Invalid ship name in JUMP or PJUMP command.\n")
+				return
+			}
+		}
+	}
+
+	/* Make sure ship is not salvage of a disbanded colony. */
+	if e.disbanded_nampla_ship(e.ship) != FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! This ship is salvage of a disbanded colony!\n")
+		return
+	}
+
+	/* Check if this ship withdrew or was forced to jump during combat. */
+	if e.ship.status == FORCED_JUMP || e.ship.status == JUMPED_IN_COMBAT {
+		if jumped_in_combat == FALSE {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Ship jumped during combat and is still in transit.\n")
+			return
+		}
+
+		/* Forced jumps are not subject to mishaps. Withdrawals are. */
+		if e.ship.status == FORCED_JUMP {
+			forced_jump = TRUE
+		}
+
+		e.x, e.y, e.z, e.pn = e.ship.dest_x, e.ship.dest_y, e.ship.dest_z, 0
+		e.nampla = nil
+		mishap_gv = e.species.tech_level[GV]
+		mishap_age = e.ship.age
+		goto do_jump
+	}
+
+	/* Make sure ship has not already jumped or moved this turn. */
+	if e.ship.just_jumped != FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! %s already jumped or moved this turn!\n", e.ship_name(e.ship))
+		return
+	}
+
+	if e.ship.status == UNDER_CONSTRUCTION {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Ship is still under construction.\n")
+		return
+	}
+
+	if e.ship._type == STARBASE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Starbases cannot jump!\n")
+		return
+	}
+
+	/* Sub-light ships may only jump through a jump portal. */
+	if e.ship._type == SUB_LIGHT && using_jump_portal == FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Sub-light ships cannot jump!\n")
+		return
+	}
+
+	/* Get the destination. */
+	if found = e.get_location(); found == FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid destination in JUMP or PJUMP command.\n")
+		return
+	}
+
+	/* Make sure the destination is inside the galaxy. */
+	max_xyz = 2*e.galaxy.radius - 1
+	if e.x < 0 || e.x > max_xyz || e.y < 0 || e.y > max_xyz || e.z < 0 || e.z > max_xyz {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Destination is outside the galaxy!\n")
+		return
+	}
+
+	if using_jump_portal == FALSE {
+		mishap_gv = e.species.tech_level[GV]
+		mishap_age = e.ship.age
+		goto do_jump
+	}
+
+	/* Get the ship or starbase that has the jump portal. */
+	if portal_species_number, portal_ship = e.get_jump_portal(); portal_ship == nil {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid or missing jump portal in PJUMP command.\n")
+		return
+	}
+
+	if portal_ship.x != e.ship.x || portal_ship.y != e.ship.y || portal_ship.z != e.ship.z {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Jump portal is not in the same sector as the ship.\n")
+		return
+	}
+
+	if portal_ship.item_quantity[JP] < e.ship.tonnage {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Jump portal does not have enough jump portal units!\n")
+		return
+	}
+
+	/* The mishap chance depends on the owner of the jump portal. */
+	mishap_gv = e.spec_data[portal_species_number-1].tech_level[GV]
+	mishap_age = portal_ship.age
+
+	/* Let the owner know that an alien used the portal. */
+	if portal_species_number != e.species_number {
+		if e.num_transactions == MAX_TRANSACTIONS {
+			fprintf(e.stderr, "\n\n\tERROR! num_transactions > MAX_TRANSACTIONS!\n\n")
+			panic("\n\n\tERROR! num_transactions > MAX_TRANSACTIONS!\n\n")
+		}
+		n := e.num_transactions
+		e.num_transactions++
+		e.transaction[n]._type = ALIEN_JUMP_PORTAL_USAGE
+		e.transaction[n].donor = portal_species_number
+		e.transaction[n].recipient = e.species_number
+		e.transaction[n].x = e.ship.x
+		e.transaction[n].y = e.ship.y
+		e.transaction[n].z = e.ship.z
+		e.transaction[n].name1 = e.species.name
+		e.transaction[n].name2 = e.ship_name(e.ship)
+		e.transaction[n].name3 = e.ship_name(portal_ship)
+	}
+
+do_jump:
+
+	status = IN_DEEP_SPACE
+	if e.pn != 0 {
+		status = IN_ORBIT
+	}
+
+	if forced_jump == FALSE {
+		chance = mishap_chance(e.ship, e.x, e.y, e.z, mishap_gv, mishap_age)
+	}
+
+	if e.rnd(10000) > chance {
+		goto jump_successful
+	}
+
+	/* Ship had a mishap. Mishaps are reported by Finish from the transactions.
+	 * Check if it has any fail-safe jump units. */
+	if e.ship.item_quantity[FS] > 0 {
+		/* Destroy one unit and roll the dice again. */
+		e.ship.item_quantity[FS]--
+		e.ship_mishap(4, 0, 0, 0) /* Use of one fail-safe unit. */
+		goto do_jump
+	}
+
+	/* Check if ship self-destructed or just mis-jumped. */
+	if e.rnd(10000) <= chance {
+		e.ship_mishap(2, 0, 0, 0) /* Self-destruction. */
+		e.delete_ship(e.ship)
+		return
+	}
+
+	/* Ship mis-jumped. The error grows with the distance and the mishap chance. */
+	max_xyz = 2*e.galaxy.radius - 1
+	e.x = e.misjump_coordinate(e.ship.x, e.x, chance, max_xyz)
+	e.y = e.misjump_coordinate(e.ship.y, e.y, chance, max_xyz)
+	e.z = e.misjump_coordinate(e.ship.z, e.z, chance, max_xyz)
+	e.pn = 0
+	e.nampla = nil
+	status = IN_DEEP_SPACE
+	e.ship_mishap(3, e.x, e.y, e.z) /* Mis-jump. */
+
+	goto move_ship
+
+jump_successful:
+
+	e.log_string("    ")
+	e.log_string(e.ship_name(e.ship))
+	e.log_string(fmt.Sprintf(" jumped to %d %d %d", e.x, e.y, e.z))
+	if e.nampla != nil {
+		e.log_string(", PL ")
+		e.log_string(e.nampla.name)
+	} else if e.pn != 0 {
+		e.log_string(", planet #")
+		e.log_int(e.pn)
+	}
+	e.log_string(".\n")
+
+move_ship:
+
+	e.ship.x, e.ship.y, e.ship.z, e.ship.pn = e.x, e.y, e.z, e.pn
+	e.ship.status = status
+	e.ship.just_jumped = TRUE
+
+	/* Set the visited flag for the star system, if there is one. */
+	e.star_visited(e.x, e.y, e.z)
+}
+
+/* This routine will get the name of the ship or starbase that is providing
+ * the jump portal for a PJUMP command. The portal may belong to the species
+ * itself or to an ally. It returns the species number of the owner and a
+ * pointer to the ship. If no portal is found, the pointer will be nil. */
+func (e *Engine) get_jump_portal() (int, *ship_data) {
+	var first_try int
+
+	/* Save pointers in case of error. */
+	temp1_ptr := e.input_line_pointer
+
+	e.get_class_abbr()
+
+	temp2_ptr := e.input_line_pointer
+
+	first_try = TRUE
+
+again:
+
+	e.input_line_pointer = temp2_ptr
+
+	if e.abbr_type != SHIP_CLASS && first_try == FALSE {
+		/* Assume abbreviation was accidentally omitted. */
+		e.input_line_pointer = temp1_ptr
+	}
+
+	/* Get ship name. */
+	e.get_name()
+
+	/* Search the ships of this species and its allies for the name. */
+	for sp_index := 0; sp_index < e.galaxy.num_species; sp_index++ {
+		if e.spec_data[sp_index] == nil {
+			continue
+		}
+		if sp_index != e.species_index && e.species.ally[sp_index] == FALSE {
+			continue
+		}
+		for _, sh := range e.ship_data[sp_index] {
+			if sh.pn == 99 || sh.status == UNDER_CONSTRUCTION {
+				continue
+			}
+			if strcmp([]byte(strings.ToUpper(sh.name)), e.upper_name) == 0 {
+				return sp_index + 1, sh
+			}
+		}
+	}
+
+	if first_try != FALSE {
+		first_try = FALSE
+		goto again
+	}
+
+	return 0, nil
+}
+
+/* This routine returns the coordinate that a mis-jumping ship will arrive
+ * at, given where it started and where it was headed. */
+func (e *Engine) misjump_coordinate(from, to, chance, max_xyz int) int {
+	difference := to - from
+	if difference < 0 {
+		difference = -difference
+	}
+	difference = (2 * difference * chance) / 10000
+	if difference < 2 {
+		difference = 2
+	}
+
+	coordinate := to - difference + e.rnd(2*difference+1) - 1
+	if coordinate < 0 {
+		coordinate = 0
+	} else if coordinate > max_xyz {
+		coordinate = max_xyz
+	}
+
+	return coordinate
+}
+
+// ship_mishap queues a transaction so that Finish reports the mishap
+// to the owner of the ship.
+func (e *Engine) ship_mishap(value, x, y, z int) {
+	if e.num_transactions == MAX_TRANSACTIONS {
+		fprintf(e.stderr, "\n\n\tERROR! num_transactions > MAX_TRANSACTIONS in do_jump.c!\n\n")
+		panic("\n\n\tERROR! num_transactions > MAX_TRANSACTIONS in do_jump.c!\n\n")
+	}
+	n := e.num_transactions
+	e.num_transactions++
+	e.transaction[n]._type = SHIP_MISHAP
+	e.transaction[n].value = value
+	e.transaction[n].number1 = e.species_number
+	e.transaction[n].name1 = e.ship_name(e.ship)
+	e.transaction[n].x = x
+	e.transaction[n].y = y
+	e.transaction[n].z = z
+}
@@ -0,0 +1,289 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+func (e *Engine) do_LAND_command() {
+	var i, n, found, siege_effectiveness, alien_number, alien_index, alien_pn int
+	var alien_here, requested_alien_landing, landed, landing_detected, already_logged int
+	var alien *species_data
+	var alien_nampla *nampla_data
+
+	/* Get the ship. */
+	original_line_pointer := e.input_line_pointer
+	if found = e.get_ship(); found == FALSE {
+		/* Check for missing comma or tab after ship name. */
+		e.input_line_pointer = original_line_pointer
+		e.fix_separator()
+		if found = e.get_ship(); found == FALSE {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Invalid ship name in LAND command.\n")
+			return
+		}
+	}
+
+	/* Make sure the ship is not a starbase. */
+	if e.ship._type == STARBASE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! A starbase cannot land on a planet!\n")
+		return
+	}
+
+	if e.ship.status == UNDER_CONSTRUCTION {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Ship is still under construction.\n")
+		return
+	}
+
+	if e.ship.status == FORCED_JUMP || e.ship.status == JUMPED_IN_COMBAT {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Ship jumped during combat and is still in transit.\n")
+		return
+	}
+
+	/* Get the planet number, if specified. */
+	found = e.get_value()
+
+get_planet:
+
+	alien_pn = 0
+	alien_here = FALSE
+	requested_alien_landing = FALSE
+	landed = FALSE
+	if found == FALSE {
+		if found = e.get_location(); found == FALSE || e.nampla == nil {
+			found = FALSE
+		}
+	} else {
+		/* Check if we or another species that has declared us ALLY has a colony on this planet. */
+		found = FALSE
+		alien_pn = e.value
+		requested_alien_landing = TRUE
+		for alien_index = 0; alien_index < e.galaxy.num_species; alien_index++ {
+			if alien = e.spec_data[alien_index]; alien == nil {
+				continue
+			}
+			for i = 0; i < alien.num_namplas; i++ {
+				alien_nampla = e.namp_data[alien_index][i]
+				if e.ship.x != alien_nampla.x || e.ship.y != alien_nampla.y || e.ship.z != alien_nampla.z || alien_pn != alien_nampla.pn {
+					continue
+				} else if (alien_nampla.status & POPULATED) == 0 {
+					continue
+				}
+
+				if alien_index == e.species_index {
+					/* We have a colony here. No permission needed. */
+					e.nampla = alien_nampla
+					found = TRUE
+					alien_here = FALSE
+					requested_alien_landing = FALSE
+					goto finish_up
+				}
+
+				alien_here = TRUE
+
+				if alien.ally[e.species_index] == FALSE {
+					continue
+				}
+
+				found = TRUE
+				break
+			}
+
+			if found != FALSE {
+				break
+			}
+		}
+	}
+
+finish_up:
+
+	already_logged = FALSE
+
+	if requested_alien_landing != FALSE && alien_here != FALSE {
+		/* Notify the other alien(s). */
+		landed = found
+		for alien_index = 0; alien_index < e.galaxy.num_species; alien_index++ {
+			if alien_index == e.species_index {
+				continue
+			} else if alien = e.spec_data[alien_index]; alien == nil {
+				continue
+			}
+
+			for i = 0; i < alien.num_namplas; i++ {
+				alien_nampla = e.namp_data[alien_index][i]
+				if e.ship.x != alien_nampla.x || e.ship.y != alien_nampla.y || e.ship.z != alien_nampla.z || alien_pn != alien_nampla.pn {
+					continue
+				} else if (alien_nampla.status & POPULATED) == 0 {
+					continue
+				}
+
+				found = alien.ally[e.species_index]
+				if landed != FALSE && found == FALSE {
+					continue
+				}
+
+				if landed != FALSE {
+					e.log_string("    ")
+				} else {
+					e.log_string("!!! ")
+				}
+				e.log_string(e.ship_name(e.ship))
+				if landed != FALSE {
+					e.log_string(" was granted")
+				} else {
+					e.log_string(" was denied")
+				}
+				e.log_string(" permission to land on PL ")
+				e.log_string(alien_nampla.name)
+				e.log_string(" by SP ")
+				e.log_string(alien.name)
+				e.log_string(".\n")
+
+				already_logged = TRUE
+
+				e.nampla = alien_nampla
+
+				/* Define a 'landing request' transaction. */
+				if e.num_transactions == MAX_TRANSACTIONS {
+					fprintf(e.stderr, "\n\n\tERROR! num_transactions > MAX_TRANSACTIONS!\n\n")
+					panic("\n\n\tERROR! num_transactions > MAX_TRANSACTIONS!\n\n")
+				}
+				n = e.num_transactions
+				e.num_transactions++
+				e.transaction[n]._type = LANDING_REQUEST
+				e.transaction[n].value = landed
+				e.transaction[n].number1 = alien_index + 1
+				e.transaction[n].name1 = alien_nampla.name
+				e.transaction[n].name2 = e.ship_name(e.ship)
+				e.transaction[n].name3 = e.species.name
+
+				break
+			}
+		}
+
+		found = TRUE
+	}
+
+	if alien_here != FALSE && landed == FALSE {
+		return
+	}
+
+	if found == FALSE {
+		if (e.ship.status == IN_ORBIT || e.ship.status == ON_SURFACE) && requested_alien_landing == FALSE {
+			/* Player forgot to specify planet. Use the one it's already at. */
+			e.value = e.ship.pn
+			found = TRUE
+			goto get_planet
+		}
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid or missing planet in LAND command.\n")
+		return
+	}
+
+	/* Make sure the ship and the planet are in the same star system. */
+	if e.ship.x != e.nampla.x || e.ship.y != e.nampla.y || e.ship.z != e.nampla.z {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Ship and planet are not in the same sector.\n")
+		return
+	}
+
+	/* Make sure planet is populated. */
+	if (e.nampla.status & POPULATED) == 0 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Planet in LAND command is not populated.\n")
+		return
+	}
+
+	/* Move the ship. */
+	e.ship.pn = e.nampla.pn
+	e.ship.status = ON_SURFACE
+
+	if already_logged != FALSE {
+		return
+	}
+
+	/* If the planet is under siege, the landing may be detected by the besiegers. */
+	e.log_string("    ")
+	e.log_string(e.ship_name(e.ship))
+
+	if e.nampla.siege_eff != 0 {
+		if e.nampla.siege_eff < 0 {
+			siege_effectiveness = -e.nampla.siege_eff
+		} else {
+			siege_effectiveness = e.nampla.siege_eff
+		}
+
+		landing_detected = FALSE
+		if e.rnd(100) <= siege_effectiveness {
+			landing_detected = TRUE
+			for i = 0; i < e.num_transactions; i++ {
+				/* Find out who is besieging this planet. */
+				if e.transaction[i]._type != BESIEGE_PLANET {
+					continue
+				} else if e.transaction[i].x != e.nampla.x || e.transaction[i].y != e.nampla.y || e.transaction[i].z != e.nampla.z || e.transaction[i].pn != e.nampla.pn {
+					continue
+				} else if e.transaction[i].number2 != e.species_number {
+					continue
+				}
+
+				alien_number = e.transaction[i].number1
+
+				/* Define a 'detection' transaction. */
+				if e.num_transactions == MAX_TRANSACTIONS {
+					fprintf(e.stderr, "\n\n\tERROR! num_transactions > MAX_TRANSACTIONS!\n\n")
+					panic("\n\n\tERROR! num_transactions > MAX_TRANSACTIONS!\n\n")
+				}
+				n = e.num_transactions
+				e.num_transactions++
+				e.transaction[n]._type = DETECTION_DURING_SIEGE
+				e.transaction[n].value = 1 /* Landing. */
+				e.transaction[n].name1 = e.nampla.name
+				e.transaction[n].name2 = e.ship_name(e.ship)
+				e.transaction[n].name3 = e.species.name
+				e.transaction[n].number3 = alien_number
+			}
+		}
+
+		if e.rnd(100) <= siege_effectiveness {
+			/* Ship doesn't know if it was detected. */
+			e.log_string(" may have been detected by the besiegers when it landed on PL ")
+			e.log_string(e.nampla.name)
+		} else if landing_detected != FALSE {
+			/* Ship knows whether or not it was detected. */
+			e.log_string(" was detected by the besiegers when it landed on PL ")
+			e.log_string(e.nampla.name)
+		} else {
+			e.log_string(" landed on PL ")
+			e.log_string(e.nampla.name)
+			e.log_string(" without being detected by the besiegers")
+		}
+	} else {
+		e.log_string(" landed on PL ")
+		e.log_string(e.nampla.name)
+	}
+
+	e.log_string(".\n")
+}
@@ -0,0 +1,100 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+import "bytes"
+
+func (e *Engine) do_MESSAGE_command() {
+	var i, message_number, unterminated_message, bad_species int
+	var message_file *bytes.Buffer
+
+	/* Get destination of message. */
+	if e.get_species_name() == FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid species name in MESSAGE command.\n")
+		bad_species = TRUE
+	}
+
+	/* Generate a random number and use it to store the message.
+	 * Messages are kept in memory, indexed by the message number. */
+	if bad_species == FALSE {
+		for {
+			message_number = 100000 + e.rnd(899999)
+			if _, ok := e.message_base[message_number]; !ok {
+				break
+			}
+		}
+		message_file = &bytes.Buffer{}
+		e.message_base[message_number] = message_file
+	}
+
+	/* Copy message to file. */
+	unterminated_message = FALSE
+	for {
+		/* Read next line. */
+		if e.input_line_pointer = fgets(e.input_line, 256, e.input_file); e.input_line_pointer == nil {
+			unterminated_message = TRUE
+			e.end_of_file = TRUE
+			break
+		}
+
+		/* Check for end of message. The first three non-white characters must be "ZZZ". */
+		p := e.input_line_pointer
+		for len(p) != 0 && (p[0] == ' ' || p[0] == '\t') {
+			p = p[1:]
+		}
+		if len(p) >= 3 && toupper(p[0]) == 'Z' && toupper(p[1]) == 'Z' && toupper(p[2]) == 'Z' {
+			break
+		}
+
+		if message_file != nil {
+			message_file.Write(e.input_line[:strlen(e.input_line)])
+		}
+	}
+
+	if bad_species != FALSE {
+		return
+	}
+
+	/* Log the result. */
+	e.log_string("    A message was sent to SP ")
+	e.log_string(e.g_spec_name)
+	e.log_string(".\n")
+
+	if unterminated_message != FALSE {
+		e.log_string("  ! WARNING: Message was not properly terminated with ZZZ!")
+		e.log_string("\n    Any orders that follow the message will be assumed")
+		e.log_string("\n    to be part of the message and will be ignored!\n")
+	}
+
+	/* Define this message transaction and add to list of transactions. */
+	if e.num_transactions == MAX_TRANSACTIONS {
+		fprintf(e.stderr, "\n\n\tERROR! num_transactions > MAX_TRANSACTIONS!\n\n")
+		panic("\n\n\tERROR! num_transactions > MAX_TRANSACTIONS!\n\n")
+	}
+	i = e.num_transactions
+	e.num_transactions++
+	e.transaction[i]._type = MESSAGE_TO_SPECIES
+	e.transaction[i].value = message_number
+	e.transaction[i].number1 = e.species_number
+	e.transaction[i].name1 = e.species.name
+	e.transaction[i].number2 = e.g_spec_number
+	e.transaction[i].name2 = e.g_spec_name
+}
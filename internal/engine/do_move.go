@@ -0,0 +1,144 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+func (e *Engine) do_MOVE_command() {
+	var found, max_xyz, n, i int
+
+	/* Get the ship. */
+	original_line_pointer := e.input_line_pointer
+	if found = e.get_ship(); found == FALSE {
+		/* Check for missing comma or tab after ship name. */
+		e.input_line_pointer = original_line_pointer
+		e.fix_separator()
+		if found = e.get_ship(); found == FALSE {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Invalid ship name in MOVE command.\n")
+			return
+		}
+	}
+
+	/* Make sure ship has not already jumped or moved this turn. */
+	if e.ship.just_jumped != FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! %s already jumped or moved this turn!\n", e.ship_name(e.ship))
+		return
+	}
+
+	if e.ship.status == UNDER_CONSTRUCTION {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Ship is still under construction.\n")
+		return
+	}
+
+	if e.ship.status == FORCED_JUMP || e.ship.status == JUMPED_IN_COMBAT {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Ship jumped during combat and is still in transit.\n")
+		return
+	}
+
+	if e.ship._type == STARBASE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Starbases cannot move!\n")
+		return
+	}
+
+	/* Make sure ship is not salvage of a disbanded colony. */
+	if e.disbanded_nampla_ship(e.ship) != FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! This ship is salvage of a disbanded colony!\n")
+		return
+	}
+
+	/* Get the destination. */
+	found = e.get_value()
+	e.x = e.value
+	if found != FALSE {
+		found = e.get_value()
+		e.y = e.value
+	}
+	if found != FALSE {
+		found = e.get_value()
+		e.z = e.value
+	}
+	if found == FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid destination in MOVE command.\n")
+		return
+	}
+
+	/* Make sure the destination is inside the galaxy. */
+	max_xyz = 2*e.galaxy.radius - 1
+	if e.x < 0 || e.x > max_xyz || e.y < 0 || e.y > max_xyz || e.z < 0 || e.z > max_xyz {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Destination is outside the galaxy!\n")
+		return
+	}
+
+	/* A ship may only move one parsec along one axis. */
+	n = 0
+	if i = e.x - e.ship.x; i < 0 {
+		n -= i
+	} else {
+		n += i
+	}
+	if i = e.y - e.ship.y; i < 0 {
+		n -= i
+	} else {
+		n += i
+	}
+	if i = e.z - e.ship.z; i < 0 {
+		n -= i
+	} else {
+		n += i
+	}
+	if n > 1 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Destination is too far away!\n")
+		return
+	}
+
+	/* Move the ship. */
+	e.ship.x, e.ship.y, e.ship.z, e.ship.pn = e.x, e.y, e.z, 0
+	e.ship.status = IN_DEEP_SPACE
+	e.ship.just_jumped = 50 /* ORBIT is not allowed immediately after a MOVE. */
+
+	/* Set the visited flag for the star system, if there is one. */
+	e.star_visited(e.x, e.y, e.z)
+
+	/* Log result. */
+	e.log_string("    ")
+	e.log_string(e.ship_name(e.ship))
+	e.log_string(" moved to ")
+	e.log_int(e.x)
+	e.log_char(' ')
+	e.log_int(e.y)
+	e.log_char(' ')
+	e.log_int(e.z)
+	e.log_string(".\n")
+}
@@ -0,0 +1,112 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+import "strings"
+
+func (e *Engine) do_NAME_command() {
+	var unused_nampla *nampla_data
+
+	/* Get x y z coordinates. */
+	if found := e.get_location(); found == FALSE || e.nampla != nil || e.pn == 0 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid coordinates in NAME command.\n")
+		return
+	}
+
+	/* Get planet abbreviation. */
+	if e.get_class_abbr() != PLANET_ID {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid planet name in NAME command.\n")
+		return
+	}
+
+	/* Get planet name. */
+	if e.get_name() < 1 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid planet name in NAME command.\n")
+		return
+	}
+
+	/* Search existing namplas for name and location. */
+	upper_name := b2s(e.upper_name)
+	for nampla_index := 0; nampla_index < e.species.num_namplas; nampla_index++ {
+		nampla := e.nampla_base[nampla_index]
+		if nampla.pn == 99 {
+			/* We can re-use this nampla rather than append a new one. */
+			unused_nampla = nampla
+			continue
+		}
+
+		/* Check if a named planet already exists at this location. */
+		if nampla.x == e.x && nampla.y == e.y && nampla.z == e.z && nampla.pn == e.pn {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! The planet at these coordinates already has a name.\n")
+			return
+		}
+
+		/* Compare names. */
+		if strings.ToUpper(nampla.name) == upper_name {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Planet in NAME command already exists.\n")
+			return
+		}
+	}
+
+	/* Add new nampla to database for this species. */
+	if unused_nampla != nil {
+		e.nampla = unused_nampla
+	} else {
+		e.nampla = &nampla_data{}
+		e.namp_data[e.species_index] = append(e.namp_data[e.species_index], e.nampla)
+		e.nampla_base = e.namp_data[e.species_index]
+		e.species.num_namplas++
+	}
+	e.delete_nampla(e.nampla) /* Set everything to zero. */
+
+	/* Initialize new nampla. */
+	e.nampla.name = b2s(e.original_name)
+	e.nampla.x, e.nampla.y, e.nampla.z, e.nampla.pn = e.x, e.y, e.z, e.pn
+	e.nampla.status = COLONY
+	e.nampla.planet_index = e.star.planet_index + e.pn - 1
+	e.nampla.message = e.planet_base[e.nampla.planet_index].message
+
+	/* Everything else was set to zero in above call to 'delete_nampla'. */
+
+	/* Mark sector as having been visited. */
+	e.star_visited(e.x, e.y, e.z)
+
+	/* Log result. */
+	e.log_string("    Named PL ")
+	e.log_string(e.nampla.name)
+	e.log_string(" at ")
+	e.log_int(e.nampla.x)
+	e.log_char(' ')
+	e.log_int(e.nampla.y)
+	e.log_char(' ')
+	e.log_int(e.nampla.z)
+	e.log_string(", planet #")
+	e.log_int(e.nampla.pn)
+	e.log_string(".\n")
+}
@@ -0,0 +1,148 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+func (e *Engine) do_ORBIT_command() {
+	var found, specified_planet_number int
+
+	/* Get the ship. */
+	original_line_pointer := e.input_line_pointer
+	if found = e.get_ship(); found == FALSE {
+		/* Check for missing comma or tab after ship name. */
+		e.input_line_pointer = original_line_pointer
+		e.fix_separator()
+		if found = e.get_ship(); found == FALSE {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Invalid ship name in ORBIT command.\n")
+			return
+		}
+	}
+
+	if e.ship.status == UNDER_CONSTRUCTION {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Ship is still under construction.\n")
+		return
+	}
+
+	if e.ship.status == FORCED_JUMP || e.ship.status == JUMPED_IN_COMBAT {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Ship jumped during combat and is still in transit.\n")
+		return
+	}
+
+	/* Make sure this ship didn't just arrive via a MOVE command. */
+	if e.ship.just_jumped == 50 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! ORBIT not allowed immediately after a MOVE!\n")
+		return
+	}
+
+	/* Make sure ship is not salvage of a disbanded colony. */
+	if e.disbanded_nampla_ship(e.ship) != FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! This ship is salvage of a disbanded colony!\n")
+		return
+	}
+
+	/* Get the planet. */
+	specified_planet_number = e.get_value()
+
+get_planet:
+
+	if specified_planet_number != FALSE {
+		found = FALSE
+		specified_planet_number = e.value
+		for i := 0; i < e.num_stars; i++ {
+			star := e.star_base[i]
+			if star.x != e.ship.x || star.y != e.ship.y || star.z != e.ship.z {
+				continue
+			}
+			if specified_planet_number >= 1 && specified_planet_number <= star.num_planets {
+				found = TRUE
+			}
+			break
+		}
+		if found == FALSE {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Invalid planet in ORBIT command.\n")
+			return
+		}
+		e.ship.pn = specified_planet_number
+		goto finish_up
+	}
+
+	if found = e.get_location(); found == FALSE || e.nampla == nil {
+		if (e.ship.status == IN_ORBIT || e.ship.status == ON_SURFACE) && e.ship.pn != 0 {
+			/* Player forgot to specify planet. Use the one it's already at. */
+			specified_planet_number = e.ship.pn
+			e.value = specified_planet_number
+			goto get_planet
+		}
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid or missing planet in ORBIT command.\n")
+		return
+	}
+
+	/* Make sure the ship and the planet are in the same star system. */
+	if e.ship.x != e.nampla.x || e.ship.y != e.nampla.y || e.ship.z != e.nampla.z {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Ship and planet are not in the same sector.\n")
+		return
+	}
+
+	/* Move the ship. */
+	e.ship.pn = e.nampla.pn
+
+finish_up:
+
+	e.ship.status = IN_ORBIT
+
+	/* If a planet number is being used, see if it has a name. If so, use the name. */
+	if specified_planet_number != FALSE {
+		for i := 0; i < e.species.num_namplas; i++ {
+			e.nampla = e.nampla_base[i]
+			if e.nampla.x != e.ship.x || e.nampla.y != e.ship.y || e.nampla.z != e.ship.z || e.nampla.pn != e.ship.pn {
+				continue
+			}
+			specified_planet_number = 0
+			break
+		}
+	}
+
+	/* Log result. */
+	e.log_string("    ")
+	e.log_string(e.ship_name(e.ship))
+	e.log_string(" entered orbit around ")
+	if specified_planet_number != FALSE {
+		e.log_string("planet number ")
+		e.log_int(specified_planet_number)
+	} else {
+		e.log_string("PL ")
+		e.log_string(e.nampla.name)
+	}
+	e.log_string(".\n")
+}
@@ -0,0 +1,636 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+import (
+	"fmt"
+	"strings"
+)
+
+func (e *Engine) do_PRODUCTION_command(missing_production_order int) {
+	var i, j, n, alien_number, found, name_length int
+	var siege_percent_effectiveness, num_siege_ships int
+	var trans_index, production_penalty int
+	var ls_needed, shipyards_for_this_species int
+	var RMs_produced, total_siege_effectiveness int
+	var siege_effectiveness [MAX_SPECIES + 1]int
+	var EUs_available_for_siege int
+	var EUs_for_distribution, EUs_for_this_species, total_EUs_stolen int
+	var special_production int
+	var pop_units_here [MAX_SPECIES + 1]int
+	var alien_pop_units, total_alien_pop_here, total_besieged_pop int
+	var ib_for_this_species, ab_for_this_species, total_ib, total_ab int
+	var total_effective_tonnage int
+	var enemy_on_same_planet, mining_colony, new_alien, resort_colony, special_colony, under_siege int
+	var alien *species_data
+	var alien_nampla *nampla_data
+	var alien_nampla_base []*nampla_data
+	var alien_ship *ship_data
+	var planet *planet_data
+
+	/* Terminate production for previous planet. */
+	if e.doing_production != FALSE {
+		if e.last_planet_produced != FALSE {
+			e.transfer_balance()
+			e.last_planet_produced = FALSE
+		}
+		e.log_char('\n')
+	}
+
+	e.doing_production = TRUE
+
+	if missing_production_order != FALSE {
+		e.nampla = e.next_nampla
+		goto got_nampla
+	}
+
+	/* Get PL abbreviation. */
+	if e.get_class_abbr() != PLANET_ID {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid planet name in PRODUCTION command.\n")
+		return
+	}
+
+	/* Get planet name. */
+	name_length = e.get_name()
+
+	/* Search all namplas for name. */
+	found = FALSE
+	for i = 0; i < e.species.num_namplas; i++ {
+		e.nampla = e.nampla_base[i]
+		if e.nampla.pn == 99 {
+			continue
+		}
+
+		/* Compare names. */
+		if strings.ToUpper(e.nampla.name) == b2s(e.upper_name) {
+			found = TRUE
+			break
+		}
+	}
+
+	if found == FALSE || name_length < 1 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid planet name in PRODUCTION command.\n")
+		return
+	}
+
+	/* Check if production was already done for this planet. */
+	if e.production_done[i] != FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! More than one PRODUCTION command for planet.\n")
+		return
+	}
+	e.production_done[i] = TRUE
+
+	/* Check if this colony was disbanded. */
+	if (e.nampla.status & DISBANDED_COLONY) != 0 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Production orders cannot be given for a disbanded colony!\n")
+		return
+	}
+
+got_nampla:
+
+	e.last_planet_produced = TRUE
+	e.shipyard_built = FALSE
+	e.shipyard_capacity = e.nampla.shipyards
+
+	/* See if this is a mining or resort colony. */
+	mining_colony, resort_colony, special_colony = FALSE, FALSE, FALSE
+	if (e.nampla.status & MINING_COLONY) != 0 {
+		mining_colony = TRUE
+		special_colony = TRUE
+	} else if (e.nampla.status & RESORT_COLONY) != 0 {
+		resort_colony = TRUE
+		special_colony = TRUE
+	}
+
+	/* Get planet data for this nampla. */
+	planet = e.planet_base[e.nampla.planet_index]
+
+	/* Check if fleet maintenance cost is so high that riots ensued. */
+	i = 0
+	j = (e.species.fleet_percent_cost - 10000) / 100
+	if e.rnd(100) <= j {
+		e.log_string("!!! WARNING! Riots on PL ")
+		e.log_string(e.nampla.name)
+		e.log_string(" due to excessive and unpopular military build-up reduced ")
+
+		if mining_colony != FALSE || special_colony == FALSE {
+			e.log_string("mining base by ")
+			i = e.rnd(j)
+			e.log_int(i)
+			e.log_string(" percent ")
+			e.nampla.mi_base -= (i * e.nampla.mi_base) / 100
+		}
+
+		if resort_colony != FALSE || special_colony == FALSE {
+			if i != 0 {
+				e.log_string("and ")
+			}
+			e.log_string("manufacturing base by ")
+			i = e.rnd(j)
+			e.log_int(i)
+			e.log_string(" percent")
+			e.nampla.ma_base -= (i * e.nampla.ma_base) / 100
+		}
+		e.log_string("!\n\n")
+	}
+
+	/* Calculate "balance" available for spending and create pseudo "checking account". */
+	ls_needed = life_support_needed(e.species, e.home_planet, planet)
+
+	if ls_needed == 0 {
+		production_penalty = 0
+	} else {
+		production_penalty = (100 * ls_needed) / e.species.tech_level[LS]
+	}
+
+	RMs_produced = (10 * e.species.tech_level[MI] * e.nampla.mi_base) / planet.mining_difficulty
+	RMs_produced -= (production_penalty * RMs_produced) / 100
+	RMs_produced = ((planet.econ_efficiency * RMs_produced) + 50) / 100
+
+	if special_colony != FALSE {
+		/* RMs just 'sitting' on the planet cannot be converted to EUs on a
+		 * mining colony, and cannot create a 'balance' on a resort colony. */
+		e.raw_material_units = 0
+	} else {
+		e.raw_material_units = RMs_produced + e.nampla.item_quantity[RM]
+	}
+
+	e.production_capacity = (e.species.tech_level[MA] * e.nampla.ma_base) / 10
+	e.production_capacity -= (production_penalty * e.production_capacity) / 100
+	e.production_capacity = ((planet.econ_efficiency * e.production_capacity) + 50) / 100
+
+	if e.raw_material_units > e.production_capacity {
+		e.balance = e.production_capacity
+	} else {
+		e.balance = e.raw_material_units
+	}
+
+	if e.species.fleet_percent_cost > 10000 {
+		n = 10000
+	} else {
+		n = e.species.fleet_percent_cost
+	}
+
+	if special_colony != FALSE {
+		e.EU_spending_limit = 0
+	} else {
+		/* Only excess RMs may be recycled. */
+		e.nampla.item_quantity[RM] = e.raw_material_units - e.balance
+
+		e.balance -= ((n * e.balance) + 5000) / 10000
+		e.raw_material_units = e.balance
+		e.production_capacity = e.balance
+		EUs_available_for_siege = e.balance
+		if (e.nampla.status & HOME_PLANET) != 0 {
+			if e.species.hp_original_base != 0 { /* HP was bombed. */
+				e.EU_spending_limit = 4 * e.balance /* Factor = 4 + 1 = 5. */
+			} else {
+				e.EU_spending_limit = e.species.econ_units
+			}
+		} else {
+			e.EU_spending_limit = e.balance
+		}
+	}
+
+	/* Log what was done. Balances for mining and resort colonies will always
+	 * be zero and should not be printed. */
+	e.log_string("  Start of production on PL ")
+	e.log_string(e.nampla.name)
+	e.log_char('.')
+	if special_colony == FALSE {
+		e.log_string(" (Initial balance is ")
+		e.log_long(e.balance)
+		e.log_string(".)")
+	}
+	e.log_char('\n')
+
+	/* If this IS a mining or resort colony, convert RMs or production capacity to EUs. */
+	if mining_colony != FALSE {
+		special_production = (2 * RMs_produced) / 3
+		special_production -= ((n * special_production) + 5000) / 10000
+		e.log_string("    Mining colony ")
+	} else if resort_colony != FALSE {
+		special_production = (2 * e.production_capacity) / 3
+		special_production -= ((n * special_production) + 5000) / 10000
+		e.log_string("    Resort colony ")
+	}
+
+	if special_colony != FALSE {
+		e.log_string(e.nampla.name)
+		e.log_string(" generated ")
+		e.log_long(special_production)
+		e.log_string(" economic units.\n")
+
+		EUs_available_for_siege = special_production
+		e.species.econ_units += special_production
+
+		if mining_colony != FALSE {
+			planet.mining_difficulty += RMs_produced / 150
+		}
+	}
+
+	/* Check if this planet is under siege. */
+	e.nampla.siege_eff = 0
+	under_siege = FALSE
+	alien_number = 0
+	num_siege_ships = 0
+	total_siege_effectiveness = 0
+	enemy_on_same_planet = FALSE
+	total_alien_pop_here = 0
+	for i = 1; i <= MAX_SPECIES; i++ {
+		siege_effectiveness[i] = 0
+		pop_units_here[i] = 0
+	}
+
+	for trans_index = 0; trans_index < e.num_transactions; trans_index++ {
+		/* Check if this is a siege of this nampla. */
+		if e.transaction[trans_index]._type != BESIEGE_PLANET {
+			continue
+		}
+		if e.transaction[trans_index].x != e.nampla.x {
+			continue
+		}
+		if e.transaction[trans_index].y != e.nampla.y {
+			continue
+		}
+		if e.transaction[trans_index].z != e.nampla.z {
+			continue
+		}
+		if e.transaction[trans_index].pn != e.nampla.pn {
+			continue
+		}
+		if e.transaction[trans_index].number2 != e.species_number {
+			continue
+		}
+
+		/* Check if alien ship is still in the same star system as the planet. */
+		if alien_number != e.transaction[trans_index].number1 {
+			/* First transaction for this alien. */
+			alien_number = e.transaction[trans_index].number1
+			if alien = e.spec_data[alien_number-1]; alien == nil {
+				fprintf(e.stderr, "\n\tData for species #%d should be in memory but is not!\n\n", alien_number)
+				panic(fmt.Sprintf("\n\tData for species #%d should be in memory but is not!\n\n", alien_number))
+			}
+			alien_nampla_base = e.namp_data[alien_number-1]
+			new_alien = TRUE
+		}
+
+		/* Find the alien ship. */
+		found = FALSE
+		for i = 0; i < alien.num_ships; i++ {
+			alien_ship = e.ship_data[alien_number-1][i]
+			if alien_ship.pn == 99 {
+				continue
+			}
+			if alien_ship.name == e.transaction[trans_index].name3 {
+				found = TRUE
+				break
+			}
+		}
+
+		/* Check if alien ship is still at the siege location. */
+		if found == FALSE {
+			continue /* It must have jumped away and self-destructed, or was recycled. */
+		}
+		if alien_ship.x != e.nampla.x {
+			continue
+		}
+		if alien_ship.y != e.nampla.y {
+			continue
+		}
+		if alien_ship.z != e.nampla.z {
+			continue
+		}
+		if alien_ship.class == TR {
+			continue
+		}
+
+		/* This nampla is under siege. */
+		if under_siege == FALSE {
+			e.log_string("\n    WARNING! PL ")
+			e.log_string(e.nampla.name)
+			e.log_string(" is under siege by the following:\n      ")
+			under_siege = TRUE
+		}
+
+		num_siege_ships++
+		if num_siege_ships > 1 {
+			e.log_string(", ")
+		}
+
+		if new_alien != FALSE {
+			e.log_string(alien.name)
+			e.log_char(' ')
+			new_alien = FALSE
+
+			/* Check if this alien has a colony on the same planet. */
+			for i = 0; i < alien.num_namplas; i++ {
+				alien_nampla = alien_nampla_base[i]
+				if alien_nampla.x != e.nampla.x {
+					continue
+				}
+				if alien_nampla.y != e.nampla.y {
+					continue
+				}
+				if alien_nampla.z != e.nampla.z {
+					continue
+				}
+				if alien_nampla.pn != e.nampla.pn {
+					continue
+				}
+
+				/* Enemy population that will count for both detection AND assimilation. */
+				alien_pop_units = alien_nampla.mi_base + alien_nampla.ma_base + alien_nampla.IUs_to_install + alien_nampla.AUs_to_install
+
+				/* Any base over 200.0 has only 5% effectiveness. */
+				if alien_pop_units > 2000 {
+					alien_pop_units = (alien_pop_units-2000)/20 + 2000
+				}
+
+				/* Enemy population that counts ONLY for detection. */
+				n = alien_nampla.pop_units + alien_nampla.item_quantity[CU] + alien_nampla.item_quantity[PD]
+
+				if alien_pop_units > 0 {
+					enemy_on_same_planet = TRUE
+					pop_units_here[alien_number] = alien_pop_units
+					total_alien_pop_here += alien_pop_units
+				} else if n > 0 {
+					enemy_on_same_planet = TRUE
+				}
+
+				if alien_nampla.item_quantity[PD] == 0 {
+					continue
+				}
+
+				e.log_string("planetary defenses of PL ")
+				e.log_string(alien_nampla.name)
+				e.log_string(", ")
+
+				n = (4 * alien_nampla.item_quantity[PD]) / 5
+				n = (n * alien.tech_level[ML]) / (e.species.tech_level[ML] + 1)
+				total_siege_effectiveness += n
+				siege_effectiveness[alien_number] += n
+			}
+		}
+		e.log_string(e.ship_name(alien_ship))
+
+		/* Determine the number of planets that this ship is besieging. */
+		n = 0
+		for j = 0; j < e.num_transactions; j++ {
+			if e.transaction[j]._type != BESIEGE_PLANET {
+				continue
+			}
+			if e.transaction[j].number1 != alien_number {
+				continue
+			}
+			if e.transaction[j].name3 != alien_ship.name {
+				continue
+			}
+			n++
+		}
+
+		/* Determine the effectiveness of this ship on the siege. */
+		if alien_ship._type == STARBASE {
+			i = alien_ship.tonnage /* One quarter of normal ships. */
+		} else {
+			i = 4 * alien_ship.tonnage
+		}
+
+		i = (i * alien.tech_level[ML]) / (e.species.tech_level[ML] + 1)
+
+		i /= n
+
+		total_siege_effectiveness += i
+		siege_effectiveness[alien_number] += i
+	}
+
+	if under_siege != FALSE {
+		e.log_string(".\n")
+	} else {
+		return
+	}
+
+	/* Determine percent effectiveness of the siege. */
+	total_effective_tonnage = 2500 * total_siege_effectiveness
+
+	if e.nampla.mi_base+e.nampla.ma_base == 0 {
+		siege_percent_effectiveness = -9999 /* New colony with nothing installed yet. */
+	} else {
+		siege_percent_effectiveness = total_effective_tonnage / (((e.species.tech_level[MI] * e.nampla.mi_base) + (e.species.tech_level[MA] * e.nampla.ma_base)) / 10)
+	}
+
+	if siege_percent_effectiveness > 95 {
+		siege_percent_effectiveness = 95
+	} else if siege_percent_effectiveness == -9999 {
+		e.log_string("      However, although planet is populated, it has no economic base.\n\n")
+		return
+	} else if siege_percent_effectiveness < 1 {
+		e.log_string("      However, because of the weakness of the siege, it was completely ineffective!\n\n")
+		return
+	}
+
+	if enemy_on_same_planet != FALSE {
+		e.nampla.siege_eff = -siege_percent_effectiveness
+	} else {
+		e.nampla.siege_eff = siege_percent_effectiveness
+	}
+
+	e.log_string("      The siege is approximately ")
+	e.log_int(siege_percent_effectiveness)
+	e.log_string("% effective.\n")
+
+	/* Add siege EU transfer(s). */
+	EUs_for_distribution = (siege_percent_effectiveness * EUs_available_for_siege) / 100
+
+	total_EUs_stolen = 0
+
+	for alien_number = 1; alien_number <= MAX_SPECIES; alien_number++ {
+		n = siege_effectiveness[alien_number]
+		if n < 1 {
+			continue
+		}
+		alien = e.spec_data[alien_number-1]
+		EUs_for_this_species = (n * EUs_for_distribution) / total_siege_effectiveness
+		if EUs_for_this_species < 1 {
+			continue
+		}
+		total_EUs_stolen += EUs_for_this_species
+		e.log_string("      ")
+		e.log_long(EUs_for_this_species)
+		e.log_string(" economic unit")
+		if EUs_for_this_species > 1 {
+			e.log_string("s were")
+		} else {
+			e.log_string(" was")
+		}
+		e.log_string(" lost and 25% of the amount was transferred to SP ")
+		e.log_string(alien.name)
+		e.log_string(".\n")
+
+		/* Define this transaction and add to list of transactions. */
+		if e.num_transactions == MAX_TRANSACTIONS {
+			fprintf(e.stderr, "\n\n\tERROR! num_transactions > MAX_TRANSACTIONS!\n\n")
+			panic("\n\n\tERROR! num_transactions > MAX_TRANSACTIONS!\n\n")
+		}
+
+		trans_index = e.num_transactions
+		e.num_transactions++
+		e.transaction[trans_index]._type = SIEGE_EU_TRANSFER
+		e.transaction[trans_index].donor = e.species_number
+		e.transaction[trans_index].recipient = alien_number
+		e.transaction[trans_index].value = EUs_for_this_species / 4
+		e.transaction[trans_index].x = e.nampla.x
+		e.transaction[trans_index].y = e.nampla.y
+		e.transaction[trans_index].z = e.nampla.z
+		e.transaction[trans_index].number1 = siege_percent_effectiveness
+		e.transaction[trans_index].name1 = e.species.name
+		e.transaction[trans_index].name2 = alien.name
+		e.transaction[trans_index].name3 = e.nampla.name
+	}
+	e.log_char('\n')
+
+	/* Correct balances. */
+	if special_colony != FALSE {
+		e.species.econ_units -= total_EUs_stolen
+	} else if e.check_bounced(total_EUs_stolen) != FALSE {
+		fprintf(e.stderr, "\nWARNING! Internal error! Should never reach this point!\n\n")
+		panic("\nWARNING! Internal error! Should never reach this point!\n\n")
+	}
+
+	if enemy_on_same_planet == FALSE {
+		return
+	}
+
+	/* All ships currently under construction may be detected by the besiegers and destroyed. */
+	for i = 0; i < e.species.num_ships; i++ {
+		ship := e.ship_base[i]
+		if ship.status != UNDER_CONSTRUCTION {
+			continue
+		}
+		if ship.x != e.nampla.x || ship.y != e.nampla.y || ship.z != e.nampla.z || ship.pn != e.nampla.pn {
+			continue
+		}
+		if e.rnd(100) > siege_percent_effectiveness {
+			continue
+		}
+
+		e.log_string("      ")
+		e.log_string(e.ship_name(ship))
+		e.log_string(", under construction when the siege began, was detected by the besiegers and destroyed!\n")
+		e.delete_ship(ship)
+	}
+
+	/* Check for assimilation. */
+	if (e.nampla.status & HOME_PLANET) != 0 {
+		return
+	}
+	if total_alien_pop_here < 1 {
+		return
+	}
+
+	total_besieged_pop = e.nampla.mi_base + e.nampla.ma_base + e.nampla.IUs_to_install + e.nampla.AUs_to_install
+
+	/* Any base over 200.0 has only 5% effectiveness. */
+	if total_besieged_pop > 2000 {
+		total_besieged_pop = (total_besieged_pop-2000)/20 + 2000
+	}
+
+	if total_besieged_pop/total_alien_pop_here >= 5 {
+		return
+	}
+	if siege_percent_effectiveness < 95 {
+		return
+	}
+
+	e.log_string("      PL ")
+	e.log_string(e.nampla.name)
+	e.log_string(" has become assimilated by the besieging species")
+	e.log_string(" and is no longer under your control.\n\n")
+
+	total_ib = e.nampla.mi_base + e.nampla.IUs_to_install
+	total_ab = e.nampla.ma_base + e.nampla.AUs_to_install
+
+	for alien_number = 1; alien_number <= MAX_SPECIES; alien_number++ {
+		n = pop_units_here[alien_number]
+		if n < 1 {
+			continue
+		}
+
+		shipyards_for_this_species = (n * e.nampla.shipyards) / total_alien_pop_here
+
+		ib_for_this_species = (n * total_ib) / total_alien_pop_here
+		total_ib -= ib_for_this_species
+
+		ab_for_this_species = (n * total_ab) / total_alien_pop_here
+		total_ab -= ab_for_this_species
+
+		if ib_for_this_species == 0 && ab_for_this_species == 0 {
+			continue
+		}
+
+		/* Define this transaction and add to list of transactions. */
+		if e.num_transactions == MAX_TRANSACTIONS {
+			fprintf(e.stderr, "\n\n\tERROR! num_transactions > MAX_TRANSACTIONS!\n\n")
+			panic("\n\n\tERROR! num_transactions > MAX_TRANSACTIONS!\n\n")
+		}
+
+		trans_index = e.num_transactions
+		e.num_transactions++
+		e.transaction[trans_index]._type = ASSIMILATION
+		e.transaction[trans_index].value = alien_number
+		e.transaction[trans_index].x = e.nampla.x
+		e.transaction[trans_index].y = e.nampla.y
+		e.transaction[trans_index].z = e.nampla.z
+		e.transaction[trans_index].pn = e.nampla.pn
+		e.transaction[trans_index].number1 = ib_for_this_species / 2
+		e.transaction[trans_index].number2 = ab_for_this_species / 2
+		e.transaction[trans_index].number3 = shipyards_for_this_species
+		e.transaction[trans_index].name1 = e.species.name
+		e.transaction[trans_index].name2 = e.nampla.name
+	}
+
+	/* Erase the original colony. */
+	e.balance = 0
+	e.EU_spending_limit = 0
+	e.raw_material_units = 0
+	e.production_capacity = 0
+	e.nampla.mi_base = 0
+	e.nampla.ma_base = 0
+	e.nampla.IUs_to_install = 0
+	e.nampla.AUs_to_install = 0
+	e.nampla.pop_units = 0
+	e.nampla.siege_eff = 0
+	e.nampla.status = COLONY
+	e.nampla.shipyards = 0
+	e.nampla.hiding = 0
+	e.nampla.hidden = 0
+	e.nampla.use_on_ambush = 0
+
+	for i = 0; i < MAX_ITEMS; i++ {
+		e.nampla.item_quantity[i] = 0
+	}
+}
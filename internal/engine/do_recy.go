@@ -0,0 +1,181 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+func (e *Engine) do_RECYCLE_command() {
+	var i, class, cargo, recycle_value, original_cost, units_available int
+
+	/* Check if this order was preceded by a PRODUCTION order. */
+	if e.doing_production == FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Missing PRODUCTION order!\n")
+		return
+	}
+
+	/* Get number of items to recycle. */
+	if e.get_value() == FALSE {
+		goto recycle_ship /* Not an item. */
+	}
+
+	/* Get class of item. */
+	if e.get_class_abbr() != ITEM_CLASS {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid item class in RECYCLE command.\n")
+		return
+	}
+	class = e.abbr_index
+
+	/* Make sure value is meaningful. */
+	if e.value == 0 {
+		e.value = e.nampla.item_quantity[class]
+	}
+	if e.value == 0 {
+		return
+	}
+	if e.value < 0 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid item count in RECYCLE command.\n")
+		return
+	}
+
+	/* Make sure that items exist. */
+	units_available = e.nampla.item_quantity[class]
+	if e.value > units_available {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Attempt to recycle more items than are available.\n")
+		return
+	}
+
+	/* Determine recycle value. */
+	if class == TP {
+		recycle_value = (e.value * item_cost[class]) / (2 * e.species.tech_level[BI])
+	} else if class == RM {
+		recycle_value = e.value / 5
+	} else {
+		recycle_value = (e.value * item_cost[class]) / 2
+	}
+
+	/* Update inventories. */
+	e.nampla.item_quantity[class] -= e.value
+	if class == PD || class == CU {
+		e.nampla.pop_units += e.value
+	}
+	e.species.econ_units += recycle_value
+	if (e.nampla.status & HOME_PLANET) != 0 {
+		e.EU_spending_limit += recycle_value
+	}
+
+	/* Log what was recycled. */
+	e.log_string("    ")
+	e.log_long(e.value)
+	e.log_char(' ')
+	e.log_string(item_name[class])
+
+	if e.value > 1 {
+		e.log_string("s were")
+	} else {
+		e.log_string(" was")
+	}
+
+	e.log_string(" recycled, generating ")
+	e.log_long(recycle_value)
+	e.log_string(" economic units.\n")
+
+	return
+
+recycle_ship:
+
+	e.correct_spelling_required = TRUE
+	if e.get_ship() == FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Ship to be recycled does not exist.\n")
+		return
+	}
+
+	/* Make sure it didn't just jump. */
+	if e.ship.just_jumped != FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Ship just jumped and is still in transit.\n")
+		return
+	}
+
+	/* Make sure item is at producing planet. */
+	if e.ship.x != e.nampla.x || e.ship.y != e.nampla.y || e.ship.z != e.nampla.z || e.ship.pn != e.nampla.pn {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Ship is not at the production planet.\n")
+		return
+	}
+
+	/* Calculate recycled value. */
+	if e.ship.class == TR || e.ship._type == STARBASE {
+		original_cost = ship_cost[e.ship.class] * e.ship.tonnage
+	} else {
+		original_cost = ship_cost[e.ship.class]
+	}
+
+	if e.ship._type == SUB_LIGHT {
+		original_cost = (3 * original_cost) / 4
+	}
+
+	if e.ship.status == UNDER_CONSTRUCTION {
+		recycle_value = (original_cost - e.ship.remaining_cost) / 2
+	} else {
+		recycle_value = (3 * original_cost * (60 - e.ship.age)) / 200
+	}
+
+	e.species.econ_units += recycle_value
+	if (e.nampla.status & HOME_PLANET) != 0 {
+		e.EU_spending_limit += recycle_value
+	}
+
+	/* Log what was recycled. */
+	e.log_string("    ")
+	e.log_string(e.ship_name(e.ship))
+	e.log_string(" was recycled, generating ")
+	e.log_long(recycle_value)
+	e.log_string(" economic units")
+
+	/* Transfer cargo, if any, from ship to planet. */
+	cargo = FALSE
+	for i = 0; i < MAX_ITEMS; i++ {
+		if e.ship.item_quantity[i] > 0 {
+			e.nampla.item_quantity[i] += e.ship.item_quantity[i]
+			cargo = TRUE
+		}
+	}
+
+	if cargo != FALSE {
+		e.log_string(". Cargo onboard ")
+		e.log_string(e.ship_name(e.ship))
+		e.log_string(" was first transferred to PL ")
+		e.log_string(e.nampla.name)
+	}
+
+	e.log_string(".\n")
+
+	/* Remove ship from inventory. */
+	e.delete_ship(e.ship)
+}
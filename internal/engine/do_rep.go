@@ -0,0 +1,230 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+func (e *Engine) do_REPAIR_command() {
+	var i, n, x, y, z, age_reduction, num_dr_units, dr_count int
+	var total_dr_units, dr_units_used, max_age, desired_age int
+	var damaged_ship *ship_data
+
+	/* See if this is a "pool" repair. */
+	if e.get_value() != FALSE {
+		x = e.value
+		e.get_value()
+		y = e.value
+		e.get_value()
+		z = e.value
+
+		if e.get_value() != FALSE {
+			desired_age = e.value
+		} else {
+			desired_age = 0
+		}
+
+		goto pool_repair
+	}
+
+	/* Get the ship to be repaired. */
+	if e.get_ship() == FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Ship to be repaired does not exist.\n")
+		return
+	}
+
+	if e.ship.status == UNDER_CONSTRUCTION {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Item to be repaired is still under construction.\n")
+		return
+	}
+
+	if e.ship.age < 1 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Ship or starbase is too new to repair.\n")
+		return
+	}
+
+	/* Get number of damage repair units to use. */
+	if e.get_value() != FALSE {
+		dr_count = e.value
+		if dr_count == 0 {
+			num_dr_units = e.ship.item_quantity[DR]
+		} else {
+			num_dr_units = dr_count
+		}
+		age_reduction = (16 * num_dr_units) / e.ship.tonnage
+		if age_reduction > e.ship.age {
+			age_reduction = e.ship.age
+			n = age_reduction * e.ship.tonnage
+			num_dr_units = (n + 15) / 16
+		}
+	} else {
+		age_reduction = e.ship.age
+		n = age_reduction * e.ship.tonnage
+		num_dr_units = (n + 15) / 16
+	}
+
+	/* Check if sufficient units are available. */
+	if num_dr_units > e.ship.item_quantity[DR] {
+		if e.ship.item_quantity[DR] == 0 {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Ship does not have any DRs!\n")
+			return
+		}
+		fprintf(e.log_file, "! WARNING: %s", b2s(e.input_line))
+		fprintf(e.log_file, "! Ship does not have %d DRs. Substituting %d for %d.\n", num_dr_units, e.ship.item_quantity[DR], num_dr_units)
+		num_dr_units = e.ship.item_quantity[DR]
+	}
+
+	/* Check if repair will have any effect. */
+	age_reduction = (16 * num_dr_units) / e.ship.tonnage
+	if age_reduction < 1 {
+		if dr_count == 0 {
+			return
+		}
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! %d DRs is not enough to do a repair.\n", num_dr_units)
+		return
+	}
+
+	/* Log what was repaired. */
+	e.log_string("    ")
+	e.log_string(e.ship_name(e.ship))
+	e.log_string(" was repaired using ")
+	e.log_int(num_dr_units)
+	e.log_char(' ')
+	e.log_string(item_name[DR])
+	if num_dr_units != 1 {
+		e.log_char('s')
+	}
+	e.log_string(". Age went from ")
+	e.log_int(e.ship.age)
+	e.log_string(" to ")
+	e.ship.age -= age_reduction
+	if e.ship.age < 0 {
+		e.ship.age = 0
+	}
+	e.ship.item_quantity[DR] -= num_dr_units
+	e.log_int(e.ship.age)
+	e.log_string(".\n")
+
+	return
+
+pool_repair:
+
+	/* Get total number of DR units available. */
+	total_dr_units = 0
+	for i = 0; i < e.species.num_ships; i++ {
+		e.ship = e.ship_base[i]
+		if e.ship.pn == 99 || e.ship.x != x || e.ship.y != y || e.ship.z != z {
+			continue
+		}
+		total_dr_units += e.ship.item_quantity[DR]
+		e.ship.special = 0
+	}
+
+	/* Repair ships, starting with the most heavily damaged. */
+	dr_units_used = 0
+	for total_dr_units > 0 {
+		/* Find most heavily damaged ship. */
+		max_age = 0
+		for i = 0; i < e.species.num_ships; i++ {
+			e.ship = e.ship_base[i]
+			if e.ship.pn == 99 || e.ship.x != x || e.ship.y != y || e.ship.z != z {
+				continue
+			} else if e.ship.special != 0 {
+				continue
+			} else if e.ship.status == UNDER_CONSTRUCTION {
+				continue
+			}
+			if n = e.ship.age; n > max_age {
+				max_age = n
+				damaged_ship = e.ship
+			}
+		}
+		if max_age == 0 {
+			break
+		}
+
+		damaged_ship.special = 99
+
+		age_reduction = max_age - desired_age
+		n = age_reduction * damaged_ship.tonnage
+		num_dr_units = (n + 15) / 16
+
+		if num_dr_units > total_dr_units {
+			num_dr_units = total_dr_units
+			age_reduction = (16 * num_dr_units) / damaged_ship.tonnage
+		}
+
+		if age_reduction < 1 {
+			continue /* This ship is too big. */
+		}
+
+		e.log_string("    ")
+		e.log_string(e.ship_name(damaged_ship))
+		e.log_string(" was repaired using ")
+		e.log_int(num_dr_units)
+		e.log_char(' ')
+		e.log_string(item_name[DR])
+		if num_dr_units != 1 {
+			e.log_char('s')
+		}
+		e.log_string(". Age went from ")
+		e.log_int(damaged_ship.age)
+		e.log_string(" to ")
+		damaged_ship.age -= age_reduction
+		if damaged_ship.age < 0 {
+			damaged_ship.age = 0
+		}
+		e.log_int(damaged_ship.age)
+		e.log_string(".\n")
+
+		total_dr_units -= num_dr_units
+		dr_units_used += num_dr_units
+	}
+
+	if dr_units_used == 0 {
+		return
+	}
+
+	/* Subtract units used from ships at the location. */
+	for i = 0; i < e.species.num_ships; i++ {
+		e.ship = e.ship_base[i]
+		if e.ship.pn == 99 || e.ship.x != x || e.ship.y != y || e.ship.z != z {
+			continue
+		}
+		n = e.ship.item_quantity[DR]
+		if n < 1 {
+			continue
+		}
+		if n > dr_units_used {
+			n = dr_units_used
+		}
+		e.ship.item_quantity[DR] -= n
+		dr_units_used -= n
+		if dr_units_used == 0 {
+			break
+		}
+	}
+}
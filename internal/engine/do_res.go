@@ -0,0 +1,142 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+func (e *Engine) do_RESEARCH_command() {
+	var status, tech, initial_level, current_level, need_amount_to_spend int
+	var cost, amount_spent, cost_for_one_level, funds_remaining, max_funds_available int
+
+	/* Check if this order was preceded by a PRODUCTION order. */
+	if e.doing_production == FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Missing PRODUCTION order!\n")
+		return
+	}
+
+	/* Get amount to spend. */
+	status = e.get_value()
+	need_amount_to_spend = FALSE
+	if status == FALSE { /* Sometimes players reverse the arguments. */
+		need_amount_to_spend = TRUE
+	}
+
+	/* Get technology. */
+	if e.get_class_abbr() != TECH_ID {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid or missing technology.\n")
+		return
+	}
+	tech = e.abbr_index
+
+	if e.species.tech_knowledge[tech] == 0 && e.sp_tech_level[tech] == 0 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Zero level can only be raised via TECH or TEACH.\n")
+		return
+	}
+
+	/* Get amount to spend if it was not obtained above. */
+	if need_amount_to_spend != FALSE {
+		status = e.get_value()
+	}
+
+	if status == FALSE || e.value < 0 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid or missing amount to spend!\n")
+		return
+	}
+
+do_cost:
+
+	if e.value == 0 {
+		e.value = e.balance
+	}
+	if e.value == 0 {
+		return
+	}
+	cost = e.value
+
+	/* Check if sufficient funds are available. */
+	if e.check_bounced(cost) != FALSE {
+		max_funds_available = e.species.econ_units
+		if max_funds_available > e.EU_spending_limit {
+			max_funds_available = e.EU_spending_limit
+		}
+		max_funds_available += e.balance
+
+		if max_funds_available > 0 {
+			fprintf(e.log_file, "! WARNING: %s", b2s(e.input_line))
+			fprintf(e.log_file, "! Insufficient funds. Substituting %d for %d.\n", max_funds_available, e.value)
+			e.value = max_funds_available
+			goto do_cost
+		}
+
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Insufficient funds to execute order.\n")
+		return
+	}
+
+	/* Check if we already have knowledge of this technology. */
+	funds_remaining = cost
+	amount_spent = 0
+	initial_level = e.sp_tech_level[tech]
+	current_level = initial_level
+	for current_level < e.species.tech_knowledge[tech] {
+		cost_for_one_level = current_level * current_level
+		cost_for_one_level -= cost_for_one_level / 4 /* 25% discount. */
+		if funds_remaining < cost_for_one_level {
+			break
+		}
+		funds_remaining -= cost_for_one_level
+		amount_spent += cost_for_one_level
+		current_level++
+	}
+
+	if current_level > initial_level {
+		e.log_string("    Spent ")
+		e.log_long(amount_spent)
+		e.log_string(" raising ")
+		e.log_string(tech_name[tech])
+		e.log_string(" tech level from ")
+		e.log_int(initial_level)
+		e.log_string(" to ")
+		e.log_int(current_level)
+		e.log_string(" using transferred knowledge.\n")
+
+		e.sp_tech_level[tech] = current_level
+	}
+
+	if funds_remaining == 0 {
+		return
+	}
+
+	/* Increase in experience points is equal to whatever was not spent above. */
+	e.species.tech_eps[tech] += funds_remaining
+
+	/* Log transaction. */
+	e.log_string("    Spent ")
+	e.log_long(funds_remaining)
+	e.log_string(" on ")
+	e.log_string(tech_name[tech])
+	e.log_string(" research.\n")
+}
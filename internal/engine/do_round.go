@@ -118,6 +118,9 @@ func (e *Engine) do_round(option, round_number int, bat *battle_data, act *actio
 		// check to make sure we aren't in infinite loop.
 		// that can happen when there are shots remaining but the side with the shots has no more ships left.
 		for i = 0; i < act.num_units_fighting; i++ {
+			if act.unit_type[i] != SHIP {
+				continue /* Planets stay in the fight until they are destroyed. */
+			}
 			attacking_ship, ok = act.fighting_unit[i].(*ship_data)
 			if !ok {
 				panic("act.fighting_unit[i].(*ship_data); !ok")
@@ -0,0 +1,54 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+func (e *Engine) do_SCAN_command() {
+	/* Get the ship. */
+	e.correct_spelling_required = TRUE
+	if e.get_ship() == FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid ship name in SCAN command.\n")
+		return
+	}
+
+	if e.ship.status == UNDER_CONSTRUCTION {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Ship is still under construction.\n")
+		return
+	}
+
+	if e.ship.status == FORCED_JUMP || e.ship.status == JUMPED_IN_COMBAT {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Ship jumped during combat and is still in transit.\n")
+		return
+	}
+
+	/* Write scan of ship's location to log file. */
+	if e.test_mode != FALSE {
+		fprintf(e.log_file, "\nA scan will be done by %s.\n\n", e.ship_name(e.ship))
+	} else {
+		fprintf(e.log_file, "\nScan done by %s:\n\n", e.ship_name(e.ship))
+		e.scan(e.ship.x, e.ship.y, e.ship.z)
+	}
+
+	fprintf(e.log_file, "\n")
+}
@@ -0,0 +1,104 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+func (e *Engine) do_SEND_command() {
+	var n, num_available, item_count int
+
+	/* Get number of EUs to transfer. */
+	if e.get_value() == FALSE || e.value < 0 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid item count in SEND command.\n")
+		return
+	}
+	item_count = e.value
+
+	num_available = e.species.econ_units
+	if item_count == 0 {
+		item_count = num_available
+	}
+	if item_count == 0 {
+		return
+	}
+	if num_available < item_count {
+		if num_available == 0 {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! You do not have any EUs!\n")
+			return
+		}
+		fprintf(e.log_file, "! WARNING: %s", b2s(e.input_line))
+		fprintf(e.log_file, "! You do not have %d EUs! Substituting %d for %d.\n", item_count, num_available, item_count)
+		item_count = num_available
+	}
+
+	/* Get destination of transfer. */
+	if e.get_species_name() == FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid species name in SEND command.\n")
+		return
+	}
+
+	/* Check if we've met this species and make sure it is not an enemy. */
+	if e.species.contact[e.g_spec_number-1] == FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! You can't SEND to a species you haven't met.\n")
+		return
+	}
+	if e.species.enemy[e.g_spec_number-1] != FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! You may not SEND economic units to an ENEMY.\n")
+		return
+	}
+
+	/* Make the transfer and log the result. */
+	e.log_string("    ")
+	e.log_long(item_count)
+	e.log_string(" economic unit")
+	if item_count > 1 {
+		e.log_string("s were")
+	} else {
+		e.log_string(" was")
+	}
+	e.log_string(" sent to SP ")
+	e.log_string(e.g_spec_name)
+	e.log_string(".\n")
+	e.species.econ_units -= item_count
+
+	/* Define this transaction. */
+	if e.num_transactions == MAX_TRANSACTIONS {
+		fprintf(e.stderr, "\n\n\tERROR! num_transactions > MAX_TRANSACTIONS!\n\n")
+		panic("\n\n\tERROR! num_transactions > MAX_TRANSACTIONS!\n\n")
+	}
+	n = e.num_transactions
+	e.num_transactions++
+	e.transaction[n]._type = EU_TRANSFER
+	e.transaction[n].donor = e.species_number
+	e.transaction[n].recipient = e.g_spec_number
+	e.transaction[n].value = item_count
+	e.transaction[n].name1 = e.species.name
+	e.transaction[n].name2 = e.g_spec_name
+
+	/* Make the transfer to the alien. */
+	e.spec_data[e.g_spec_number-1].econ_units += item_count
+}
@@ -0,0 +1,65 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+func (e *Engine) do_SHIPYARD_command() {
+	var cost int
+
+	/* Check if this order was preceded by a PRODUCTION order. */
+	if e.doing_production == FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Missing PRODUCTION order!\n")
+		return
+	}
+
+	/* Make sure this is not a mining or resort colony. */
+	if (e.nampla.status&MINING_COLONY) != 0 || (e.nampla.status&RESORT_COLONY) != 0 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! You may not build shipyards on a mining or resort colony!\n")
+		return
+	}
+
+	/* Check if planet has already built a shipyard. */
+	if e.shipyard_built != FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Only one shipyard can be built per planet per turn!\n")
+		return
+	}
+
+	/* Check if sufficient funds are available. */
+	cost = 10 * e.species.tech_level[MA]
+	if e.check_bounced(cost) != FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Insufficient funds to execute order.\n")
+		return
+	}
+
+	e.nampla.shipyards++
+
+	e.shipyard_built = TRUE
+
+	/* Log transaction. */
+	e.log_string("    Spent ")
+	e.log_long(cost)
+	e.log_string(" to increase shipyard capacity by 1.\n")
+}
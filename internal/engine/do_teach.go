@@ -0,0 +1,78 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+func (e *Engine) do_TEACH_command() {
+	var i, tech, max_tech_level int
+
+	/* Get technology. */
+	if e.get_class_abbr() != TECH_ID {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid or missing technology.\n")
+		return
+	}
+	tech = e.abbr_index
+
+	/* See if a maximum tech level was specified. */
+	if e.get_value() != FALSE {
+		max_tech_level = e.value
+		if max_tech_level > e.species.tech_level[tech] {
+			max_tech_level = e.species.tech_level[tech]
+		}
+	} else {
+		max_tech_level = e.species.tech_level[tech]
+	}
+
+	/* Get species to transfer knowledge to. */
+	if e.get_species_name() == FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid species name in TEACH command.\n")
+		return
+	}
+
+	/* Check if we've met this species and make sure it is not an enemy. */
+	if e.species.contact[e.g_spec_number-1] == FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! You can't TEACH a species you haven't met.\n")
+		return
+	}
+	if e.species.enemy[e.g_spec_number-1] != FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! You can't TEACH an ENEMY.\n")
+		return
+	}
+
+	/* Define this transaction and add to list of transactions. */
+	if e.num_transactions == MAX_TRANSACTIONS {
+		fprintf(e.stderr, "\n\n\tERROR! num_transactions > MAX_TRANSACTIONS!\n\n")
+		panic("\n\n\tERROR! num_transactions > MAX_TRANSACTIONS!\n\n")
+	}
+	i = e.num_transactions
+	e.num_transactions++
+	e.transaction[i]._type = KNOWLEDGE_TRANSFER
+	e.transaction[i].donor = e.species_number
+	e.transaction[i].recipient = e.g_spec_number
+	e.transaction[i].value = tech
+	e.transaction[i].name1 = e.species.name
+	e.transaction[i].number3 = max_tech_level
+}
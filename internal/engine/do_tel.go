@@ -0,0 +1,336 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+func (e *Engine) do_TELESCOPE_command() {
+	var i, n, found, range_in_parsecs, max_range, alien_index int
+	var alien_number, alien_nampla_index, alien_ship_index int
+	var location_printed, industry, detection_chance, num_obs_locs int
+	var alien_name_printed, loc_index, success_chance, something_found int
+	var x, y, z, max_distance, max_distance_squared int
+	var delta_x, delta_y, delta_z, distance_squared int
+	var planet_type string
+	var obs_x, obs_y, obs_z [MAX_OBS_LOCS]int
+	var alien *species_data
+	var alien_nampla *nampla_data
+	var starbase, alien_ship *ship_data
+
+	/* Get the starbase. */
+	found = e.get_ship()
+	if found == FALSE || e.ship._type != STARBASE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid starbase name in TELESCOPE command.\n")
+		return
+	}
+	starbase = e.ship
+
+	/* Make sure starbase does not get more than one TELESCOPE order per turn. */
+	if starbase.dest_z != 0 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! A starbase may only be given one TELESCOPE order per turn.\n")
+		return
+	}
+	starbase.dest_z = 99
+
+	/* Get range of telescope. */
+	range_in_parsecs = starbase.item_quantity[GT] / 2
+	if range_in_parsecs < 1 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Starbase is not carrying enough gravitic telescope units.\n")
+		return
+	}
+
+	/* Define range parameters. */
+	max_range = e.species.tech_level[GV] / 10
+	if range_in_parsecs > max_range {
+		range_in_parsecs = max_range
+	}
+
+	x = starbase.x
+	y = starbase.y
+	z = starbase.z
+
+	max_distance = range_in_parsecs
+	max_distance_squared = max_distance * max_distance
+
+	/* First pass. Simply create a list of X Y Z locations that have observable aliens. */
+	num_obs_locs = 0
+	for alien_index = 0; alien_index < e.galaxy.num_species; alien_index++ {
+		if alien = e.spec_data[alien_index]; alien == nil {
+			continue
+		}
+
+		alien_number = alien_index + 1
+		if alien_number == e.species_number {
+			continue
+		}
+
+		for alien_nampla_index = 0; alien_nampla_index < alien.num_namplas; alien_nampla_index++ {
+			alien_nampla = e.namp_data[alien_index][alien_nampla_index]
+
+			if (alien_nampla.status & POPULATED) == 0 {
+				continue
+			}
+
+			delta_x = x - alien_nampla.x
+			delta_y = y - alien_nampla.y
+			delta_z = z - alien_nampla.z
+			distance_squared = (delta_x * delta_x) + (delta_y * delta_y) + (delta_z * delta_z)
+
+			if distance_squared == 0 {
+				continue /* Same loc as telescope. */
+			}
+			if distance_squared > max_distance_squared {
+				continue
+			}
+
+			found = FALSE
+			for i = 0; i < num_obs_locs; i++ {
+				if alien_nampla.x != obs_x[i] || alien_nampla.y != obs_y[i] || alien_nampla.z != obs_z[i] {
+					continue
+				}
+				found = TRUE
+				break
+			}
+			if found == FALSE {
+				if num_obs_locs == MAX_OBS_LOCS {
+					fprintf(e.stderr, "\n\nInternal error! MAX_OBS_LOCS exceeded in do_tel.c!\n\n")
+					panic("\n\nInternal error! MAX_OBS_LOCS exceeded in do_tel.c!\n\n")
+				}
+				obs_x[num_obs_locs] = alien_nampla.x
+				obs_y[num_obs_locs] = alien_nampla.y
+				obs_z[num_obs_locs] = alien_nampla.z
+				num_obs_locs++
+			}
+		}
+
+		for alien_ship_index = 0; alien_ship_index < alien.num_ships; alien_ship_index++ {
+			alien_ship = e.ship_data[alien_index][alien_ship_index]
+
+			if alien_ship.status == UNDER_CONSTRUCTION || alien_ship.status == ON_SURFACE {
+				continue
+			}
+			if alien_ship.item_quantity[FD] == alien_ship.tonnage {
+				continue
+			}
+
+			delta_x = x - alien_ship.x
+			delta_y = y - alien_ship.y
+			delta_z = z - alien_ship.z
+			distance_squared = (delta_x * delta_x) + (delta_y * delta_y) + (delta_z * delta_z)
+
+			if distance_squared == 0 {
+				continue /* Same loc as telescope. */
+			}
+			if distance_squared > max_distance_squared {
+				continue
+			}
+
+			found = FALSE
+			for i = 0; i < num_obs_locs; i++ {
+				if alien_ship.x != obs_x[i] || alien_ship.y != obs_y[i] || alien_ship.z != obs_z[i] {
+					continue
+				}
+				found = TRUE
+				break
+			}
+			if found == FALSE {
+				if num_obs_locs == MAX_OBS_LOCS {
+					fprintf(e.stderr, "\n\nInternal error! MAX_OBS_LOCS exceeded in do_tel.c!\n\n")
+					panic("\n\nInternal error! MAX_OBS_LOCS exceeded in do_tel.c!\n\n")
+				}
+				obs_x[num_obs_locs] = alien_ship.x
+				obs_y[num_obs_locs] = alien_ship.y
+				obs_z[num_obs_locs] = alien_ship.z
+				num_obs_locs++
+			}
+		}
+	}
+
+	/* Operate the gravitic telescope. */
+	e.log_string("\n  Results of operation of gravitic telescope by ")
+	e.log_string(e.ship_name(starbase))
+	e.log_string(" (location = ")
+	e.log_int(starbase.x)
+	e.log_char(' ')
+	e.log_int(starbase.y)
+	e.log_char(' ')
+	e.log_int(starbase.z)
+	e.log_string(", max range = ")
+	e.log_int(range_in_parsecs)
+	e.log_string(" parsecs):\n")
+
+	something_found = FALSE
+
+	for loc_index = 0; loc_index < num_obs_locs; loc_index++ {
+		x = obs_x[loc_index]
+		y = obs_y[loc_index]
+		z = obs_z[loc_index]
+
+		location_printed = FALSE
+
+		for alien_index = 0; alien_index < e.galaxy.num_species; alien_index++ {
+			if alien = e.spec_data[alien_index]; alien == nil {
+				continue
+			}
+
+			alien_number = alien_index + 1
+			if alien_number == e.species_number {
+				continue
+			}
+
+			alien_name_printed = FALSE
+
+			for alien_nampla_index = 0; alien_nampla_index < alien.num_namplas; alien_nampla_index++ {
+				alien_nampla = e.namp_data[alien_index][alien_nampla_index]
+
+				if (alien_nampla.status & POPULATED) == 0 {
+					continue
+				}
+				if alien_nampla.x != x || alien_nampla.y != y || alien_nampla.z != z {
+					continue
+				}
+
+				industry = alien_nampla.mi_base + alien_nampla.ma_base
+
+				success_chance = e.species.tech_level[GV]
+				success_chance += starbase.item_quantity[GT]
+				success_chance += (industry - 500) / 20
+				if alien_nampla.hiding != FALSE || alien_nampla.hidden != FALSE {
+					success_chance /= 10
+				}
+
+				if e.rnd(100) > success_chance {
+					continue
+				}
+
+				if industry < 100 {
+					industry = (industry + 5) / 10
+				} else {
+					industry = ((industry + 50) / 100) * 10
+				}
+
+				if (alien_nampla.status & HOME_PLANET) != 0 {
+					planet_type = "Home planet"
+				} else if (alien_nampla.status & RESORT_COLONY) != 0 {
+					planet_type = "Resort colony"
+				} else if (alien_nampla.status & MINING_COLONY) != 0 {
+					planet_type = "Mining colony"
+				} else {
+					planet_type = "Colony"
+				}
+
+				if alien_name_printed == FALSE {
+					if location_printed == FALSE {
+						fprintf(e.log_file, "\n    %d%3d%3d:\n", x, y, z)
+						location_printed = TRUE
+						something_found = TRUE
+					}
+					fprintf(e.log_file, "      SP %s:\n", alien.name)
+					alien_name_printed = TRUE
+				}
+
+				fprintf(e.log_file, "\t#%d: %s PL %s (%d)\n", alien_nampla.pn, planet_type, alien_nampla.name, industry)
+			}
+
+			for alien_ship_index = 0; alien_ship_index < alien.num_ships; alien_ship_index++ {
+				alien_ship = e.ship_data[alien_index][alien_ship_index]
+
+				if alien_ship.x != x || alien_ship.y != y || alien_ship.z != z {
+					continue
+				}
+				if alien_ship.status == UNDER_CONSTRUCTION || alien_ship.status == ON_SURFACE {
+					continue
+				}
+				if alien_ship.item_quantity[FD] == alien_ship.tonnage {
+					continue
+				}
+
+				success_chance = e.species.tech_level[GV]
+				success_chance += starbase.item_quantity[GT]
+				success_chance += alien_ship.tonnage - 10
+				if alien_ship._type == STARBASE {
+					success_chance *= 2
+				}
+				if alien_ship.class == TR {
+					success_chance = (3 * success_chance) / 2
+				}
+				if e.rnd(100) > success_chance {
+					continue
+				}
+
+				if alien_name_printed == FALSE {
+					if location_printed == FALSE {
+						fprintf(e.log_file, "\n    %d%3d%3d:\n", x, y, z)
+						location_printed = TRUE
+						something_found = TRUE
+					}
+					fprintf(e.log_file, "      SP %s:\n", alien.name)
+					alien_name_printed = TRUE
+				}
+
+				e.truncate_name = FALSE
+				fprintf(e.log_file, "\t%s", e.ship_name(alien_ship))
+				e.truncate_name = TRUE
+
+				/* See if alien detected that it is being observed. */
+				if alien_ship._type == STARBASE {
+					detection_chance = 2 * alien_ship.item_quantity[GT]
+					if detection_chance > 0 {
+						fprintf(e.log_file, " <- %d GTs installed!", alien_ship.item_quantity[GT])
+					}
+				} else {
+					detection_chance = 0
+				}
+
+				fprintf(e.log_file, "\n")
+
+				detection_chance += 2 * (alien.tech_level[GV] - e.species.tech_level[GV])
+
+				if e.rnd(100) > detection_chance {
+					continue
+				}
+
+				/* Define this transaction. */
+				if e.num_transactions == MAX_TRANSACTIONS {
+					fprintf(e.stderr, "\n\n\tERROR! num_transactions > MAX_TRANSACTIONS!\n\n")
+					panic("\n\n\tERROR! num_transactions > MAX_TRANSACTIONS!\n\n")
+				}
+
+				n = e.num_transactions
+				e.num_transactions++
+				e.transaction[n]._type = TELESCOPE_DETECTION
+				e.transaction[n].x = starbase.x
+				e.transaction[n].y = starbase.y
+				e.transaction[n].z = starbase.z
+				e.transaction[n].number1 = alien_number
+				e.transaction[n].name1 = e.ship_name(alien_ship)
+			}
+		}
+	}
+
+	if something_found != FALSE {
+		e.log_char('\n')
+	} else {
+		e.log_string("    No alien ships or planets were detected.\n\n")
+	}
+}
@@ -0,0 +1,256 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+func (e *Engine) do_TERRAFORM_command() {
+	var i, j, ls_needed, num_plants, got_required_gas, correct_percentage int
+	var home_planet, colony_planet *planet_data
+
+	/* Get number of TPs to use. */
+	if e.get_value() != FALSE {
+		num_plants = e.value
+	} else {
+		num_plants = 0
+	}
+
+	/* Get planet where terraforming is to be done. */
+	if found := e.get_location(); found == FALSE || e.nampla == nil {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid planet name in TERRAFORM command.\n")
+		return
+	}
+
+	/* Make sure planet is not a home planet. */
+	if (e.nampla.status & HOME_PLANET) != 0 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Terraforming may not be done on a home planet.\n")
+		return
+	}
+
+	/* Find out how many terraforming plants are needed. */
+	colony_planet = e.planet_base[e.nampla.planet_index]
+	home_planet = e.planet_base[e.nampla_base[0].planet_index]
+
+	ls_needed = life_support_needed(e.species, home_planet, colony_planet)
+
+	if ls_needed == 0 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Colony does not need to be terraformed.\n")
+		return
+	}
+
+	if num_plants == 0 {
+		num_plants = e.nampla.item_quantity[TP]
+	}
+	if num_plants > ls_needed {
+		num_plants = ls_needed
+	}
+	num_plants = num_plants / 3
+	num_plants *= 3
+
+	if num_plants < 3 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! At least three TPs are needed to terraform.\n")
+		return
+	}
+
+	if num_plants > e.nampla.item_quantity[TP] {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! PL %s doesn't have that many TPs!\n", e.nampla.name)
+		return
+	}
+
+	/* Log results. */
+	e.log_string("    PL ")
+	e.log_string(e.nampla.name)
+	e.log_string(" was terraformed using ")
+	e.log_int(num_plants)
+	e.log_string(" Terraforming Unit")
+	if num_plants != 1 {
+		e.log_char('s')
+	}
+	e.log_string(".\n")
+
+	e.nampla.item_quantity[TP] -= num_plants
+
+	/* Terraform the planet. */
+	for num_plants > 1 {
+		got_required_gas = 0
+		correct_percentage = FALSE
+		for j = 0; j < 4; j++ { /* Check gases on planet. */
+			for i = 0; i < 6; i++ { /* Compare with poisonous gases. */
+				if colony_planet.gas[j] == e.species.required_gas {
+					got_required_gas = j + 1
+
+					if colony_planet.gas_percent[j] >= e.species.required_gas_min && colony_planet.gas_percent[j] <= e.species.required_gas_max {
+						correct_percentage = TRUE
+					}
+				}
+
+				if e.species.poison_gas[i] == colony_planet.gas[j] {
+					colony_planet.gas[j] = 0
+					colony_planet.gas_percent[j] = 0
+
+					/* Make sure percentages add up to 100%. */
+					e.fix_gases(colony_planet)
+
+					goto next_change
+				}
+			}
+		}
+
+		if got_required_gas != 0 && correct_percentage != FALSE {
+			goto do_temp
+		}
+
+		j = 0 /* If all 4 gases are neutral gases, replace the first one. */
+
+		if got_required_gas != 0 {
+			j = got_required_gas - 1
+		} else {
+			for i = 0; i < 4; i++ {
+				if colony_planet.gas_percent[i] == 0 {
+					j = i
+					break
+				}
+			}
+		}
+
+		colony_planet.gas[j] = e.species.required_gas
+		i = e.species.required_gas_max - e.species.required_gas_min
+		colony_planet.gas_percent[j] = e.species.required_gas_min + e.rnd(i)
+
+		/* Make sure percentages add up to 100%. */
+		e.fix_gases(colony_planet)
+
+		goto next_change
+
+	do_temp:
+
+		if colony_planet.temperature_class != home_planet.temperature_class {
+			if colony_planet.temperature_class > home_planet.temperature_class {
+				colony_planet.temperature_class--
+			} else {
+				colony_planet.temperature_class++
+			}
+
+			goto next_change
+		}
+
+		if colony_planet.pressure_class != home_planet.pressure_class {
+			if colony_planet.pressure_class > home_planet.pressure_class {
+				colony_planet.pressure_class--
+			} else {
+				colony_planet.pressure_class++
+			}
+		}
+
+	next_change:
+
+		num_plants -= 3
+	}
+}
+
+func (e *Engine) fix_gases(pl *planet_data) {
+	var i, j, total, left, add_neutral int
+
+	total = 0
+	for i = 0; i < 4; i++ {
+		total += pl.gas_percent[i]
+	}
+	if total == 100 {
+		return
+	}
+
+	left = 100 - total
+
+	/* If we have at least one gas that is not the required gas, then we
+	 *  simply need to adjust existing gases. Otherwise, we have to add a
+	 *  neutral gas. */
+	add_neutral = TRUE
+	for i = 0; i < 4; i++ {
+		if pl.gas_percent[i] == 0 {
+			continue
+		}
+		if pl.gas[i] == e.species.required_gas {
+			continue
+		}
+		add_neutral = FALSE
+		break
+	}
+
+	if add_neutral != FALSE {
+		goto add_neutral_gas
+	}
+
+	/* Randomly modify existing non-required gases until total percentage is exactly 100. */
+	for left != 0 {
+		i = e.rnd(4) - 1
+
+		if pl.gas_percent[i] == 0 {
+			continue
+		}
+		if pl.gas[i] == e.species.required_gas {
+			continue
+		}
+
+		if left > 0 {
+			if left > 2 {
+				j = e.rnd(left)
+			} else {
+				j = left
+			}
+			pl.gas_percent[i] += j
+			left -= j
+		} else {
+			if -left > 2 {
+				j = e.rnd(-left)
+			} else {
+				j = -left
+			}
+			if j < pl.gas_percent[i] {
+				pl.gas_percent[i] -= j
+				left += j
+			}
+		}
+	}
+
+	return
+
+add_neutral_gas:
+
+	/* If we reach this point, there is either no atmosphere or it contains
+	 *  only the required gas.  In either case, add a random neutral gas. */
+	for i = 0; i < 4; i++ {
+		if pl.gas_percent[i] > 0 {
+			continue
+		}
+
+		j = e.rnd(6) - 1
+		pl.gas[i] = e.species.neutral_gas[j]
+		pl.gas_percent[i] = left
+
+		break
+	}
+}
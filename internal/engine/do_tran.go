@@ -0,0 +1,472 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+func (e *Engine) do_TRANSFER_command() {
+	var i, n, item_class, item_count, capacity, transfer_type int
+	var attempt_during_siege, siege_1_chance, siege_2_chance int
+	var alien_number, first_try, both_args_present, need_destination int
+	var x1, x2, y1, y2, z1, z2 int
+	var already_notified [MAX_SPECIES]int
+	var num_available, original_count int
+	var nampla1, nampla2, temp_nampla, besieged_nampla *nampla_data
+	var ship1, ship2 *ship_data
+
+	/* Get number of items to transfer. */
+	if e.get_value() == FALSE || e.value < 0 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid item count in TRANSFER command.\n")
+		return
+	}
+	original_count, item_count = e.value, e.value
+
+	/* Get class of item. */
+	if item_class = e.get_class_abbr(); item_class != ITEM_CLASS {
+		/* Players sometimes accidentally use "MI" for "IU" or "MA" for "AU". */
+		if item_class == TECH_ID && e.abbr_index == MI {
+			e.abbr_index = IU
+		} else if item_class == TECH_ID && e.abbr_index == MA {
+			e.abbr_index = AU
+		} else {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Invalid item class!\n")
+			return
+		}
+	}
+	item_class = e.abbr_index
+
+	/* Get source of transfer. */
+	original_line_pointer := e.input_line_pointer
+	if e.get_transfer_point() == FALSE {
+		/* Check for missing comma or tab after source name. */
+		e.input_line_pointer = original_line_pointer
+		e.fix_separator()
+		if e.get_transfer_point() == FALSE {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Invalid source location in TRANSFER command.\n")
+			return
+		}
+	}
+
+	/* Test if the order has both a source and a destination.
+	 * Sometimes, the player will accidentally omit the source if it's "obvious". */
+	both_args_present = FALSE
+	for _, c := range e.input_line_pointer {
+		if c == 0 || c == ';' || c == '\n' {
+			break /* End of order. */
+		} else if isalpha(c) {
+			both_args_present = TRUE
+			break
+		}
+	}
+
+	need_destination = TRUE
+
+	/* Make sure everything makes sense. */
+	if e.abbr_type == SHIP_CLASS {
+		ship1 = e.ship
+
+		if ship1.status == UNDER_CONSTRUCTION {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! %s is still under construction!\n", e.ship_name(ship1))
+			return
+		}
+
+		if ship1.status == FORCED_JUMP || ship1.status == JUMPED_IN_COMBAT {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Ship jumped during combat and is still in transit.\n")
+			return
+		}
+
+		x1, y1, z1 = ship1.x, ship1.y, ship1.z
+
+		num_available = ship1.item_quantity[item_class]
+
+	check_ship_items:
+
+		if item_count == 0 {
+			item_count = num_available
+		}
+		if item_count == 0 {
+			return
+		}
+
+		if num_available < item_count {
+			if both_args_present != FALSE { /* Change item count to "0". */
+				if num_available == 0 {
+					fprintf(e.log_file, "!!! Order ignored:\n")
+					fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+					fprintf(e.log_file, "!!! %s does not have specified item(s)!\n", e.ship_name(ship1))
+					return
+				}
+
+				fprintf(e.log_file, "! WARNING: %s", b2s(e.input_line))
+				fprintf(e.log_file, "! Ship does not have %d units. Substituting %d for %d!\n", item_count, num_available, item_count)
+				item_count = 0
+				goto check_ship_items
+			}
+
+			/* Check if ship is at a planet that has the items. If so,
+			 * we'll assume that the planet is the source and the ship is
+			 * the destination. We'll look first for a planet that the
+			 * ship is actually landed on or orbiting. If that fails,
+			 * then we'll look for a planet in the same sector. */
+			first_try = TRUE
+
+		next_ship_try:
+
+			for i = 0; i < e.species.num_namplas; i++ {
+				nampla1 = e.nampla_base[i]
+				if nampla1.x != ship1.x || nampla1.y != ship1.y || nampla1.z != ship1.z {
+					continue
+				} else if first_try != FALSE && nampla1.pn != ship1.pn {
+					continue
+				}
+
+				num_available = nampla1.item_quantity[item_class]
+				if num_available < item_count {
+					continue
+				}
+
+				e.ship = ship1           /* Destination. */
+				transfer_type = 1        /* Source = planet. */
+				e.abbr_type = SHIP_CLASS /* Destination type. */
+
+				need_destination = FALSE
+
+				goto get_destination
+			}
+
+			if first_try != FALSE {
+				first_try = FALSE
+				goto next_ship_try
+			}
+
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! %s does not have specified item(s)!\n", e.ship_name(ship1))
+			return
+		}
+
+		transfer_type = 0 /* Source = ship. */
+	} else { /* Source is a planet. */
+		nampla1 = e.nampla
+
+		x1, y1, z1 = nampla1.x, nampla1.y, nampla1.z
+
+		num_available = nampla1.item_quantity[item_class]
+
+	check_planet_items:
+
+		if item_count == 0 {
+			item_count = num_available
+		}
+		if item_count == 0 {
+			return
+		}
+
+		if num_available < item_count {
+			if both_args_present != FALSE { /* Change item count to "0". */
+				if num_available == 0 {
+					fprintf(e.log_file, "!!! Order ignored:\n")
+					fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+					fprintf(e.log_file, "!!! PL %s does not have specified item(s)!\n", nampla1.name)
+					return
+				}
+
+				fprintf(e.log_file, "! WARNING: %s", b2s(e.input_line))
+				fprintf(e.log_file, "! Planet does not have %d units. Substituting %d for %d!\n", item_count, num_available, item_count)
+				item_count = 0
+				goto check_planet_items
+			}
+
+			/* Check if another planet in the same sector has the items.
+			 * If so, we'll assume that it is the source and that the
+			 * named planet is the destination. */
+			for i = 0; i < e.species.num_namplas; i++ {
+				temp_nampla = e.nampla_base[i]
+				if temp_nampla.x != nampla1.x || temp_nampla.y != nampla1.y || temp_nampla.z != nampla1.z {
+					continue
+				}
+
+				num_available = temp_nampla.item_quantity[item_class]
+				if num_available < item_count {
+					continue
+				}
+
+				e.nampla = nampla1      /* Destination. */
+				nampla1 = temp_nampla   /* Source. */
+				transfer_type = 1       /* Source = planet. */
+				e.abbr_type = PLANET_ID /* Destination type. */
+
+				need_destination = FALSE
+
+				goto get_destination
+			}
+
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! PL %s does not have specified item(s)!\n", nampla1.name)
+			return
+		}
+
+		transfer_type = 1 /* Source = planet. */
+	}
+
+get_destination:
+
+	/* Get destination of transfer. */
+	if need_destination != FALSE {
+		if e.get_transfer_point() == FALSE {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Invalid destination location.\n")
+			return
+		}
+	}
+
+	/* Make sure everything makes sense. */
+	if e.abbr_type == SHIP_CLASS {
+		ship2 = e.ship
+
+		if ship2.status == UNDER_CONSTRUCTION {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! %s is still under construction!\n", e.ship_name(ship2))
+			return
+		}
+
+		if ship2.status == FORCED_JUMP || ship2.status == JUMPED_IN_COMBAT {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Ship jumped during combat and is still in transit.\n")
+			return
+		}
+
+		/* Check if destination ship has sufficient carrying capacity. */
+		if ship2.class == TR {
+			capacity = (10 + (ship2.tonnage / 2)) * ship2.tonnage
+		} else if ship2.class == BA {
+			capacity = 10 * ship2.tonnage
+		} else {
+			capacity = ship2.tonnage
+		}
+		for i = 0; i < MAX_ITEMS; i++ {
+			capacity -= ship2.item_quantity[i] * item_carry_capacity[i]
+		}
+
+	do_capacity:
+
+		if original_count == 0 {
+			i = capacity / item_carry_capacity[item_class]
+			if i < item_count {
+				item_count = i
+			}
+			if item_count == 0 {
+				return
+			}
+		}
+
+		if capacity < item_count*item_carry_capacity[item_class] {
+			fprintf(e.log_file, "! WARNING: %s", b2s(e.input_line))
+			fprintf(e.log_file, "! %s does not have sufficient carrying capacity!", e.ship_name(ship2))
+			fprintf(e.log_file, " Changed %d to 0.\n", original_count)
+			original_count = 0
+			goto do_capacity
+		}
+
+		x2, y2, z2 = ship2.x, ship2.y, ship2.z
+	} else {
+		nampla2 = e.nampla
+
+		x2, y2, z2 = nampla2.x, nampla2.y, nampla2.z
+
+		transfer_type |= 2
+
+		/* If this is the post-arrival phase, then make sure the planet is populated. */
+		if e.post_arrival_phase != FALSE && (nampla2.status&POPULATED) == 0 {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Destination planet must be populated for post-arrival TRANSFERs.\n")
+			return
+		}
+	}
+
+	/* Check if source and destination are in same system. */
+	if x1 != x2 || y1 != y2 || z1 != z2 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Source and destination are not at same 'x y z' in TRANSFER command.\n")
+		return
+	}
+
+	/* Check for siege. */
+	siege_1_chance, siege_2_chance = 0, 0
+	if transfer_type == 3 /* Planet to planet. */ && (nampla1.siege_eff != 0 || nampla2.siege_eff != 0) {
+		if nampla1.siege_eff >= 0 {
+			siege_1_chance = nampla1.siege_eff
+		} else {
+			siege_1_chance = -nampla1.siege_eff
+		}
+		if nampla2.siege_eff >= 0 {
+			siege_2_chance = nampla2.siege_eff
+		} else {
+			siege_2_chance = -nampla2.siege_eff
+		}
+		attempt_during_siege = TRUE
+	} else {
+		attempt_during_siege = FALSE
+	}
+
+	/* Make the transfer and log the result. */
+	e.log_string("    ")
+	e.log_int(item_count)
+	e.log_char(' ')
+	e.log_string(item_name[item_class])
+	if item_count > 1 {
+		e.log_string("s were transferred from ")
+	} else {
+		e.log_string(" was transferred from ")
+	}
+
+	switch transfer_type {
+	case 0: /* Ship to ship. */
+		ship1.item_quantity[item_class] -= item_count
+		ship2.item_quantity[item_class] += item_count
+		e.log_string(e.ship_name(ship1))
+		e.log_string(" to ")
+		e.log_string(e.ship_name(ship2))
+		e.log_char('.')
+
+	case 1: /* Planet to ship. */
+		nampla1.item_quantity[item_class] -= item_count
+		ship2.item_quantity[item_class] += item_count
+		if item_class == CU {
+			for i = 0; i < e.species.num_namplas; i++ {
+				if e.nampla_base[i] == nampla1 {
+					ship2.loading_point = i
+					break
+				}
+			}
+			if ship2.loading_point == 0 {
+				ship2.loading_point = 9999 /* Home planet. */
+			}
+		}
+		e.log_string("PL ")
+		e.log_string(nampla1.name)
+		e.log_string(" to ")
+		e.log_string(e.ship_name(ship2))
+		e.log_char('.')
+
+	case 2: /* Ship to planet. */
+		ship1.item_quantity[item_class] -= item_count
+		nampla2.item_quantity[item_class] += item_count
+		e.log_string(e.ship_name(ship1))
+		e.log_string(" to PL ")
+		e.log_string(nampla2.name)
+		e.log_char('.')
+
+	case 3: /* Planet to planet. */
+		nampla1.item_quantity[item_class] -= item_count
+		nampla2.item_quantity[item_class] += item_count
+
+		e.log_string("PL ")
+		e.log_string(nampla1.name)
+		e.log_string(" to PL ")
+		e.log_string(nampla2.name)
+		if attempt_during_siege != FALSE {
+			e.log_string(" despite the siege")
+		}
+		e.log_char('.')
+
+		/* Check if either planet is under siege and if transfer was detected by the besiegers. */
+		if e.rnd(100) > siege_1_chance && e.rnd(100) > siege_2_chance {
+			break
+		}
+
+		e.log_string(" However, the transfer was detected by the besiegers and the items were destroyed!!!")
+		nampla2.item_quantity[item_class] -= item_count
+
+		if siege_1_chance > siege_2_chance {
+			besieged_nampla = nampla1
+		} else {
+			besieged_nampla = nampla2
+		}
+
+		for i = 0; i < e.num_transactions; i++ {
+			/* Find out who is besieging this planet. */
+			if e.transaction[i]._type != BESIEGE_PLANET {
+				continue
+			} else if e.transaction[i].x != besieged_nampla.x || e.transaction[i].y != besieged_nampla.y || e.transaction[i].z != besieged_nampla.z || e.transaction[i].pn != besieged_nampla.pn {
+				continue
+			} else if e.transaction[i].number2 != e.species_number {
+				continue
+			}
+
+			alien_number = e.transaction[i].number1
+			if already_notified[alien_number-1] != FALSE {
+				continue
+			}
+
+			/* Define a 'detection' transaction. */
+			if e.num_transactions == MAX_TRANSACTIONS {
+				fprintf(e.stderr, "\n\n\tERROR! num_transactions > MAX_TRANSACTIONS!\n\n")
+				panic("\n\n\tERROR! num_transactions > MAX_TRANSACTIONS!\n\n")
+			}
+			n = e.num_transactions
+			e.num_transactions++
+			e.transaction[n]._type = DETECTION_DURING_SIEGE
+			e.transaction[n].number1 = item_count
+			e.transaction[n].number2 = item_class
+			if siege_1_chance > siege_2_chance {
+				/* Besieged planet is the source of the transfer. */
+				e.transaction[n].value = 4
+				e.transaction[n].name1 = nampla1.name
+				e.transaction[n].name2 = nampla2.name
+			} else {
+				/* Besieged planet is the destination of the transfer. */
+				e.transaction[n].value = 5
+				e.transaction[n].name1 = nampla2.name
+				e.transaction[n].name2 = nampla1.name
+			}
+			e.transaction[n].name3 = e.species.name
+			e.transaction[n].number3 = alien_number
+
+			already_notified[alien_number-1] = TRUE
+		}
+
+	default: /* Internal error. */
+		fprintf(e.stderr, "\n\n\tInternal error: transfer type!\n\n")
+		panic("\n\n\tInternal error: transfer type!\n\n")
+	}
+
+	e.log_char('\n')
+
+	if nampla1 != nil {
+		e.check_population(nampla1)
+	}
+	if nampla2 != nil {
+		e.check_population(nampla2)
+	}
+}
@@ -0,0 +1,190 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+func (e *Engine) do_UNLOAD_command() {
+	var i, found, item_count, recovering_home_planet, n, reb int
+
+	/* Get the ship. */
+	if e.get_ship() == FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid ship name in UNLOAD command.\n")
+		return
+	}
+
+	/* Make sure ship is not under construction. */
+	if e.ship.status == UNDER_CONSTRUCTION {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! %s is still under construction!\n", e.ship_name(e.ship))
+		return
+	}
+
+	if e.ship.status == FORCED_JUMP || e.ship.status == JUMPED_IN_COMBAT {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Ship jumped during combat and is still in transit.\n")
+		return
+	}
+
+	/* Find which planet the ship is at. */
+	found = FALSE
+	for i = 0; i < e.species.num_namplas; i++ {
+		e.nampla = e.nampla_base[i]
+		if e.ship.x != e.nampla.x || e.ship.y != e.nampla.y || e.ship.z != e.nampla.z || e.ship.pn != e.nampla.pn {
+			continue
+		}
+		found = TRUE
+		break
+	}
+	if found == FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Ship is not at a named planet.\n")
+		return
+	}
+
+	/* Make sure this is not someone else's populated homeworld. */
+	for alien_index := 0; alien_index < e.galaxy.num_species; alien_index++ {
+		if e.species_number == alien_index+1 {
+			continue
+		} else if e.spec_data[alien_index] == nil || len(e.namp_data[alien_index]) == 0 {
+			continue
+		}
+
+		alien_home_nampla := e.namp_data[alien_index][0]
+		if alien_home_nampla.x != e.nampla.x || alien_home_nampla.y != e.nampla.y || alien_home_nampla.z != e.nampla.z || alien_home_nampla.pn != e.nampla.pn {
+			continue
+		} else if (alien_home_nampla.status & POPULATED) == 0 {
+			continue
+		}
+
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! You may not colonize someone else's populated home planet!\n")
+		return
+	}
+
+	/* Make sure it's not a healthy home planet. */
+	recovering_home_planet = FALSE
+	if (e.nampla.status & HOME_PLANET) != 0 {
+		n = e.nampla.mi_base + e.nampla.ma_base + e.nampla.IUs_to_install + e.nampla.AUs_to_install
+		reb = e.species.hp_original_base - n
+		if reb > 0 {
+			recovering_home_planet = TRUE /* HP was bombed. */
+		} else {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Installation not allowed on a healthy home planet!\n")
+			return
+		}
+	}
+
+	/* Transfer the items from the ship to the planet. */
+	e.log_string("    ")
+
+	item_count = e.ship.item_quantity[CU]
+	e.nampla.item_quantity[CU] += item_count
+	e.log_int(item_count)
+	e.log_char(' ')
+	e.log_string(item_abbr[CU])
+	if item_count != 1 {
+		e.log_char('s')
+	}
+	e.ship.item_quantity[CU] = 0
+
+	item_count = e.ship.item_quantity[IU]
+	e.nampla.item_quantity[IU] += item_count
+	e.log_string(", ")
+	e.log_int(item_count)
+	e.log_char(' ')
+	e.log_string(item_abbr[IU])
+	if item_count != 1 {
+		e.log_char('s')
+	}
+	e.ship.item_quantity[IU] = 0
+
+	item_count = e.ship.item_quantity[AU]
+	e.nampla.item_quantity[AU] += item_count
+	e.log_string(", and ")
+	e.log_int(item_count)
+	e.log_char(' ')
+	e.log_string(item_abbr[AU])
+	if item_count != 1 {
+		e.log_char('s')
+	}
+	e.ship.item_quantity[AU] = 0
+
+	e.log_string(" were transferred from ")
+	e.log_string(e.ship_name(e.ship))
+	e.log_string(" to PL ")
+	e.log_string(e.nampla.name)
+	e.log_string(". ")
+
+	/* Do the installation. */
+	item_count = e.nampla.item_quantity[CU]
+	if item_count > e.nampla.item_quantity[IU] {
+		item_count = e.nampla.item_quantity[IU]
+	}
+	if recovering_home_planet != FALSE {
+		if item_count > reb {
+			item_count = reb
+		}
+		reb -= item_count
+	}
+
+	e.nampla.item_quantity[CU] -= item_count
+	e.nampla.item_quantity[IU] -= item_count
+	e.nampla.IUs_to_install += item_count
+
+	e.log_string("Installation of ")
+	e.log_int(item_count)
+	e.log_char(' ')
+	e.log_string(item_abbr[IU])
+	if item_count != 1 {
+		e.log_char('s')
+	}
+
+	item_count = e.nampla.item_quantity[CU]
+	if item_count > e.nampla.item_quantity[AU] {
+		item_count = e.nampla.item_quantity[AU]
+	}
+	if recovering_home_planet != FALSE {
+		if item_count > reb {
+			item_count = reb
+		}
+		reb -= item_count
+	}
+
+	e.nampla.item_quantity[CU] -= item_count
+	e.nampla.item_quantity[AU] -= item_count
+	e.nampla.AUs_to_install += item_count
+
+	e.log_string(" and ")
+	e.log_int(item_count)
+	e.log_char(' ')
+	e.log_string(item_abbr[AU])
+	if item_count != 1 {
+		e.log_char('s')
+	}
+	e.log_string(" began on the planet.\n")
+
+	e.check_population(e.nampla)
+}
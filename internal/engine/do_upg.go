@@ -0,0 +1,150 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+func (e *Engine) do_UPGRADE_command() {
+	var age_reduction, value_specified int
+	var amount_to_spend, original_cost, max_funds_available int
+
+	/* Check if this order was preceded by a PRODUCTION order. */
+	if e.doing_production == FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Missing PRODUCTION order!\n")
+		return
+	}
+
+	/* Get the ship to be upgraded. */
+	original_line_pointer := e.input_line_pointer
+	if e.get_ship() == FALSE {
+		/* Check for missing comma or tab after ship name. */
+		e.input_line_pointer = original_line_pointer
+		e.fix_separator()
+		if e.get_ship() == FALSE {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Ship to be upgraded does not exist.\n")
+			return
+		}
+	}
+
+	/* Make sure it didn't just jump. */
+	if e.ship.just_jumped != FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Ship just jumped and is still in transit.\n")
+		return
+	}
+
+	/* Make sure it's in the same sector as the producing planet. */
+	if e.ship.x != e.nampla.x || e.ship.y != e.nampla.y || e.ship.z != e.nampla.z {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Item to be upgraded is not in the same sector as the production planet.\n")
+		return
+	}
+
+	if e.ship.status == UNDER_CONSTRUCTION {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Item to be upgraded is still under construction.\n")
+		return
+	}
+
+	if e.ship.age < 1 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Ship or starbase is too new to upgrade.\n")
+		return
+	}
+
+	/* Calculate the original cost of the ship. */
+	if e.ship.class == TR || e.ship._type == STARBASE {
+		original_cost = ship_cost[e.ship.class] * e.ship.tonnage
+	} else {
+		original_cost = ship_cost[e.ship.class]
+	}
+
+	if e.ship._type == SUB_LIGHT {
+		original_cost = (3 * original_cost) / 4
+	}
+
+	/* Get amount to be spent. */
+	if value_specified = e.get_value(); value_specified != FALSE {
+		if e.value == 0 {
+			e.value = e.balance
+		}
+		age_reduction = (40 * e.value) / original_cost
+	} else {
+		age_reduction = e.ship.age
+	}
+
+try_again:
+
+	if age_reduction < 1 {
+		if e.value == 0 {
+			return
+		}
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Amount specified is not enough to do an upgrade.\n")
+		return
+	}
+
+	if age_reduction > e.ship.age {
+		age_reduction = e.ship.age
+	}
+
+	/* Check if sufficient funds are available. */
+	amount_to_spend = ((age_reduction * original_cost) + 39) / 40
+	if e.check_bounced(amount_to_spend) != FALSE {
+		max_funds_available = e.species.econ_units
+		if max_funds_available > e.EU_spending_limit {
+			max_funds_available = e.EU_spending_limit
+		}
+		max_funds_available += e.balance
+
+		if max_funds_available > 0 {
+			if value_specified != FALSE {
+				fprintf(e.log_file, "! WARNING: %s", b2s(e.input_line))
+				fprintf(e.log_file, "! Insufficient funds. Substituting %d for %d.\n", max_funds_available, e.value)
+			}
+			e.value = max_funds_available
+			age_reduction = (40 * e.value) / original_cost
+			goto try_again
+		}
+
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Insufficient funds to execute order.\n")
+		return
+	}
+
+	/* Log what was upgraded. */
+	e.log_string("    ")
+	e.log_string(e.ship_name(e.ship))
+	e.log_string(" was upgraded from age ")
+	e.log_int(e.ship.age)
+	e.log_string(" to age ")
+	e.ship.age -= age_reduction
+	e.log_int(e.ship.age)
+	e.log_string(" at a cost of ")
+	e.log_long(amount_to_spend)
+	e.log_string(".\n")
+}
@@ -0,0 +1,47 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+/* The VISITED command lets a species record that it has been to a star
+ * system, for example one that it learned about from another species. */
+func (e *Engine) do_VISITED_command() {
+	/* Get the x y z coordinates. */
+	if found := e.get_location(); found == FALSE || e.nampla != nil || e.pn != 0 {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Invalid coordinates in VISITED command.\n")
+		return
+	}
+
+	if e.star_visited(e.x, e.y, e.z) == FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! There is no star system at these coordinates!\n")
+		return
+	}
+
+	/* Log result. */
+	e.log_string("    The star system at ")
+	e.log_int(e.x)
+	e.log_char(' ')
+	e.log_int(e.y)
+	e.log_char(' ')
+	e.log_int(e.z)
+	e.log_string(" was marked as visited.\n")
+}
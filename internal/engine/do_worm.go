@@ -0,0 +1,137 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+func (e *Engine) do_WORMHOLE_command() {
+	var found, pn int
+	var star *star_data
+
+	/* Get the ship making the jump. */
+	original_line_pointer := e.input_line_pointer
+	if found = e.get_ship(); found == FALSE {
+		/* Check for missing comma or tab after ship name. */
+		e.input_line_pointer = original_line_pointer
+		e.fix_separator()
+		if found = e.get_ship(); found == FALSE {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Invalid ship name in WORMHOLE command.\n")
+			return
+		}
+	}
+
+	/* Make sure ship is not salvage of a disbanded colony. */
+	if e.disbanded_nampla_ship(e.ship) != FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! This ship is salvage of a disbanded colony!\n")
+		return
+	}
+
+	/* Make sure ship can jump. */
+	if e.ship.status == UNDER_CONSTRUCTION {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Ship is still under construction.\n")
+		return
+	}
+
+	if e.ship.status == FORCED_JUMP || e.ship.status == JUMPED_IN_COMBAT {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! Ship jumped during combat and is still in transit.\n")
+		return
+	}
+
+	if e.ship.just_jumped != FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! %s already jumped or moved this turn!\n", e.ship_name(e.ship))
+		return
+	}
+
+	/* Find the wormhole at the ship's location. */
+	for i := 0; i < e.num_stars; i++ {
+		if e.star_base[i].x == e.ship.x && e.star_base[i].y == e.ship.y && e.star_base[i].z == e.ship.z {
+			star = e.star_base[i]
+			break
+		}
+	}
+	if star == nil || star.worm_here == FALSE {
+		fprintf(e.log_file, "!!! Order ignored:\n")
+		fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+		fprintf(e.log_file, "!!! There is no wormhole at the ship's location!\n")
+		return
+	}
+
+	/* Get the optional planet number at the other end of the wormhole. */
+	if found = e.get_value(); found != FALSE {
+		pn = e.value
+		found = FALSE
+		for i := 0; i < e.num_stars; i++ {
+			if e.star_base[i].x != star.worm_x || e.star_base[i].y != star.worm_y || e.star_base[i].z != star.worm_z {
+				continue
+			}
+			if pn >= 1 && pn <= e.star_base[i].num_planets {
+				found = TRUE
+			}
+			break
+		}
+		if found == FALSE {
+			fprintf(e.log_file, "!!! Order ignored:\n")
+			fprintf(e.log_file, "!!! %s", b2s(e.input_line))
+			fprintf(e.log_file, "!!! Invalid planet in WORMHOLE command.\n")
+			return
+		}
+	}
+
+	/* Log result. */
+	e.log_string("    ")
+	e.log_string(e.ship_name(e.ship))
+	e.log_string(" jumped via natural wormhole at ")
+	e.log_int(e.ship.x)
+	e.log_char(' ')
+	e.log_int(e.ship.y)
+	e.log_char(' ')
+	e.log_int(e.ship.z)
+	e.log_string(" to ")
+	e.log_int(star.worm_x)
+	e.log_char(' ')
+	e.log_int(star.worm_y)
+	e.log_char(' ')
+	e.log_int(star.worm_z)
+	if pn != 0 {
+		e.log_string(", planet #")
+		e.log_int(pn)
+	}
+	e.log_string(".\n")
+
+	/* Do the jump. */
+	e.ship.x, e.ship.y, e.ship.z, e.ship.pn = star.worm_x, star.worm_y, star.worm_z, pn
+	if pn == 0 {
+		e.ship.status = IN_DEEP_SPACE
+	} else {
+		e.ship.status = IN_ORBIT
+	}
+	e.ship.just_jumped = 99 /* 99 means that ship arrived via a natural wormhole. */
+	e.ship.arrived_via_wormhole = TRUE
+
+	/* Set the visited flag for the star system at the other end. */
+	e.star_visited(e.ship.x, e.ship.y, e.ship.z)
+}
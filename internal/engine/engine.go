@@ -18,19 +18,56 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 
 package engine
 
-import "github.com/mdhender/fhcms/cms/prng"
+import (
+	"bytes"
+	"github.com/mdhender/fhcms/cms/prng"
+	"github.com/mdhender/fhcms/internal/orders"
+)
 
 func New(promptGM bool) *Engine {
 	return &Engine{
 		correct_spelling_required: FALSE,
 		defaultPRNG:               prng.New(0xBADC0FFEE),
-		input_abbr:                make([]byte, 256, 256),
 		input_line:                make([]byte, 256, 256),
 		log_line:                  make([]byte, 1024, 1024),
+		log_start_of_line:         TRUE,
+		log_to_file:               TRUE,
+		message_base:              make(map[int]*bytes.Buffer),
 		original_line:             make([]byte, 256, 256),
 		original_name:             make([]byte, 32, 32),
+		print_LSN:                 TRUE,
 		prompt_gm:                 promptGM,
 		stderr:                    fopen("*stderr*", nil),
 		upper_name:                make([]byte, 32, 32),
 	}
 }
+
+// NumSpecies returns the number of species in the galaxy.
+func (e *Engine) NumSpecies() int {
+	return e.galaxy.num_species
+}
+
+// Orders returns the parsed orders for a species, or nil if the species has no orders.
+// The species number is one-based.
+// The phases execute the text of the orders, not these typed orders.
+func (e *Engine) Orders(speciesNo int) *orders.Orders {
+	if speciesNo < 1 || speciesNo > len(e.spec_parsed) {
+		return nil
+	}
+	return e.spec_parsed[speciesNo-1]
+}
+
+// TurnNumber returns the current turn number.
+func (e *Engine) TurnNumber() int {
+	return e.galaxy.turn_number
+}
+
+// Seed returns the current state of the random number generator.
+func (e *Engine) Seed() uint64 {
+	return e.defaultPRNG.GetSeed()
+}
+
+// SetSeed sets the state of the random number generator.
+func (e *Engine) SetSeed(seed uint64) {
+	e.defaultPRNG = prng.New(seed)
+}
@@ -0,0 +1,124 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+import (
+	"fmt"
+	"github.com/mdhender/fhcms/cms/store/jsondb"
+	"github.com/mdhender/fhcms/internal/cluster"
+	"github.com/mdhender/fhcms/internal/galaxy"
+	"testing"
+)
+
+// TestFinishEconEfficiency checks that the Finish phase reduces the economic
+// efficiency of planets whose colonies have more than 2,000 units of economic
+// base, ignores home planets, and resets planets without colonies to 100.
+// The result must agree with cluster.Store.UpdateEconEfficiency.
+func TestFinishEconEfficiency(t *testing.T) {
+	ds, err := galaxy.New(galaxy.Config{Species: 3, Seed: 0x1234})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, name := range []string{"Alpha", "Bravo", "Charlie"} {
+		if _, err := galaxy.AddSpecies(ds, galaxy.SpeciesConfig{
+			Name:       name,
+			GovtName:   name + " Government",
+			GovtType:   "Monarchy",
+			HomePlanet: name + " Prime",
+			ML:         4, GV: 4, LS: 4, BI: 3,
+			Seed: uint64(i + 1),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// find two planets outside of the home systems
+	var colonized, abandoned = -1, -1
+	var colonyStar *jsondb.StarData
+	for _, star := range ds.Stars {
+		if star.HomeSystem != 0 || star.NumPlanets == 0 {
+			continue
+		} else if colonized == -1 {
+			colonized, colonyStar = star.PlanetIndex, star
+		} else {
+			abandoned = star.PlanetIndex
+			break
+		}
+	}
+	if abandoned == -1 {
+		t.Fatal("galaxy has too few systems without a home planet")
+	}
+
+	// a home planet well past the threshold does not count
+	home := ds.Species[0].Namplas[0]
+	home.MiBase, home.MaBase = 12_000, 9_000
+	// two colonies from different species share a planet and pass the threshold
+	for i, base := range []int{2_500, 500} {
+		sp := ds.Species[i+1]
+		sp.Namplas = append(sp.Namplas, &jsondb.NamedPlanetData{
+			Id:           len(sp.Namplas),
+			Name:         fmt.Sprintf("Colony %d", i+1),
+			X:            colonyStar.X,
+			Y:            colonyStar.Y,
+			Z:            colonyStar.Z,
+			Pn:           1,
+			PlanetIndex:  colonized,
+			Status:       jsondb.COLONY | jsondb.POPULATED,
+			MiBase:       base,
+			ItemQuantity: make([]int, len(home.ItemQuantity)),
+		})
+		sp.NumNamplas = len(sp.Namplas)
+	}
+	// a planet that was reduced before its colonies were destroyed
+	ds.Planets[abandoned].EconEfficiency = 50
+
+	e := New(false)
+	if err := e.loadStore(ds); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.RunPhase("Finish"); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, planet := range e.planet_base {
+		want := 100
+		if i == colonized {
+			want = (100 * ((3_000-2_000)/20 + 2000)) / 3_000
+		}
+		if planet.econ_efficiency != want {
+			t.Errorf("planet %d: econ efficiency: got %d, want %d", i, planet.econ_efficiency, want)
+		}
+	}
+
+	cs, err := cluster.FromDat32Records(e.dat32Records())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]int)
+	for id, planet := range cs.Planets {
+		got[id] = planet.EconEfficiency
+		planet.EconEfficiency = 0
+	}
+	cs.UpdateEconEfficiency()
+	for id, planet := range cs.Planets {
+		if got[id] != planet.EconEfficiency {
+			t.Errorf("planet %s: finish set %d, UpdateEconEfficiency set %d", id, got[id], planet.EconEfficiency)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+import "strings"
+
+/* This routine will assign values to global variables x, y, z, pn, star
+ * and nampla. If the location is not a named planet, then nampla will be
+ * set to nil. If planet is not specified, pn will be set to zero. If
+ * location is valid, TRUE will be returned, otherwise FALSE will be
+ * returned. */
+func (e *Engine) get_location() int {
+	var n, temp_nampla_index, first_try, name_length, best_score, next_best_score, best_nampla_index, minimum_score int
+	var temp_nampla *nampla_data
+
+	/* Check first if x, y, z are specified. */
+	e.nampla = nil
+	e.skip_whitespace()
+
+	if e.get_value() == FALSE {
+		goto get_planet
+	}
+	e.x = e.value
+
+	if e.get_value() == FALSE {
+		return FALSE
+	}
+	e.y = e.value
+
+	if e.get_value() == FALSE {
+		return FALSE
+	}
+	e.z = e.value
+
+	if e.get_value() == FALSE {
+		e.pn = 0
+	} else {
+		e.pn = e.value
+	}
+
+	if e.pn == 0 {
+		return TRUE
+	}
+
+	/* Get star. Check if planet exists. */
+	for i := 0; i < e.num_stars; i++ {
+		e.star = e.star_base[i]
+		if e.star.x != e.x || e.star.y != e.y || e.star.z != e.z {
+			continue
+		}
+		if e.pn > e.star.num_planets {
+			return FALSE
+		}
+		return TRUE
+	}
+
+	return FALSE
+
+get_planet:
+
+	/* Save pointers in case of error. */
+	temp1_ptr := e.input_line_pointer
+
+	e.get_class_abbr()
+
+	temp2_ptr := e.input_line_pointer
+
+	first_try = TRUE
+
+again:
+
+	e.input_line_pointer = temp2_ptr
+
+	if e.abbr_type != PLANET_ID && first_try == FALSE {
+		/* Assume abbreviation was accidentally omitted. */
+		e.input_line_pointer = temp1_ptr
+	}
+
+	/* Get planet name. */
+	e.get_name()
+
+	/* Search all temp_namplas for name. */
+	for temp_nampla_index = 0; temp_nampla_index < e.species.num_namplas; temp_nampla_index++ {
+		temp_nampla = e.nampla_base[temp_nampla_index]
+		if temp_nampla.pn == 99 {
+			continue
+		}
+
+		/* Compare names. */
+		if strcmp([]byte(strings.ToUpper(temp_nampla.name)), e.upper_name) == 0 {
+			goto done
+		}
+	}
+
+	if first_try != FALSE {
+		first_try = FALSE
+		goto again
+	}
+
+	/* Possibly a spelling error. Find the best match that is approximately the same. */
+	first_try = TRUE
+
+yet_again:
+
+	e.input_line_pointer = temp2_ptr
+
+	if e.abbr_type != PLANET_ID && first_try == FALSE {
+		/* Assume abbreviation was accidentally omitted. */
+		e.input_line_pointer = temp1_ptr
+	}
+
+	/* Get planet name. */
+	e.get_name()
+
+	best_score, next_best_score = -9999, -9999
+	for temp_nampla_index = 0; temp_nampla_index < e.species.num_namplas; temp_nampla_index++ {
+		temp_nampla = e.nampla_base[temp_nampla_index]
+		if temp_nampla.pn == 99 {
+			continue
+		}
+
+		/* Compare names. */
+		n = agrep_score([]byte(strings.ToUpper(temp_nampla.name)), e.upper_name)
+		if n > best_score {
+			best_score = n /* Best match so far. */
+			best_nampla_index = temp_nampla_index
+		} else if n > next_best_score {
+			next_best_score = n
+		}
+	}
+
+	if e.species.num_namplas == 0 {
+		return FALSE
+	}
+	temp_nampla = e.nampla_base[best_nampla_index]
+	name_length = len(temp_nampla.name)
+	minimum_score = name_length - ((name_length / 7) + 1)
+
+	if best_score < minimum_score /* Score too low. */ || name_length < 5 /* No errors allowed. */ || best_score == next_best_score /* Another name with equal score. */ {
+		if first_try != FALSE {
+			first_try = FALSE
+			goto yet_again
+		}
+		return FALSE
+	}
+
+done:
+
+	e.abbr_type = PLANET_ID
+
+	e.x = temp_nampla.x
+	e.y = temp_nampla.y
+	e.z = temp_nampla.z
+	e.pn = temp_nampla.pn
+	e.nampla = temp_nampla
+
+	return TRUE
+}
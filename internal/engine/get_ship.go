@@ -18,13 +18,13 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 
 package engine
 
+import "strings"
+
 // returns TRUE if a ship was found, FALSE if no ship was found
 func (e *Engine) get_ship() int {
-	var i, n, name_length, best_score, next_best_score, best_ship_index, first_try, minimum_score int
+	var n, name_length, best_score, next_best_score, best_ship_index, first_try, minimum_score int
 	var best_ship *ship_data
 
-	upper_ship_name := make([]byte, 32, 32)
-
 	// save in case of an error
 	temp1_ptr := e.input_line_pointer
 
@@ -59,9 +59,7 @@ again:
 		}
 
 		// make upper case copy of ship name
-		for i = 0; i < 32; i++ {
-			upper_ship_name[i] = toupper(e.ship.name[i])
-		}
+		upper_ship_name := []byte(strings.ToUpper(e.ship.name))
 
 		// compare names
 		if strcmp(upper_ship_name, e.upper_name) == 0 {
@@ -105,9 +103,7 @@ yet_again:
 		}
 
 		// make upper case copy of ship name
-		for i = 0; i < 32; i++ {
-			upper_ship_name[i] = toupper(e.ship.name[i])
-		}
+		upper_ship_name := []byte(strings.ToUpper(e.ship.name))
 
 		n = agrep_score(upper_ship_name, e.upper_name)
 		if n > best_score {
@@ -0,0 +1,123 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+import "strings"
+
+/* This routine will get a species name and return TRUE if found and if
+ * it is valid. It will also set global values "g_spec_number" and
+ * "g_spec_name". The algorithm employed allows minor spelling errors,
+ * as well as accidental deletion of the SP abbreviation. */
+func (e *Engine) get_species_name() int {
+	var n, species_index, best_score, best_species_index, next_best_score, first_try, minimum_score, name_length int
+	var sp *species_data
+
+	e.g_spec_number = 0
+
+	/* Save pointers in case of error. */
+	temp1_ptr := e.input_line_pointer
+
+	e.get_class_abbr()
+
+	temp2_ptr := e.input_line_pointer
+
+	first_try = TRUE
+
+again:
+
+	e.input_line_pointer = temp2_ptr
+
+	if e.abbr_type != SPECIES_ID && first_try == FALSE {
+		/* Assume abbreviation was accidentally omitted. */
+		e.input_line_pointer = temp1_ptr
+	}
+
+	/* Get species name. */
+	e.get_name()
+
+	for species_index = 0; species_index < e.galaxy.num_species; species_index++ {
+		if sp = e.spec_data[species_index]; sp == nil {
+			continue
+		}
+
+		/* Compare upper case copy of name. */
+		if strcmp([]byte(strings.ToUpper(sp.name)), e.upper_name) == 0 {
+			e.g_spec_name = sp.name
+			e.g_spec_number = species_index + 1
+			e.abbr_type = SPECIES_ID
+			return TRUE
+		}
+	}
+
+	if first_try != FALSE {
+		first_try = FALSE
+		goto again
+	}
+
+	/* Possibly a spelling error. Find the best match that is approximately the same. */
+	first_try = TRUE
+
+yet_again:
+
+	e.input_line_pointer = temp2_ptr
+
+	if e.abbr_type != SPECIES_ID && first_try == FALSE {
+		/* Assume abbreviation was accidentally omitted. */
+		e.input_line_pointer = temp1_ptr
+	}
+
+	/* Get species name. */
+	e.get_name()
+
+	best_score, next_best_score = -9999, -9999
+	for species_index = 0; species_index < e.galaxy.num_species; species_index++ {
+		if sp = e.spec_data[species_index]; sp == nil {
+			continue
+		}
+
+		n = agrep_score([]byte(strings.ToUpper(sp.name)), e.upper_name)
+		if n > best_score {
+			best_score = n /* Best match so far. */
+			best_species_index = species_index
+		} else if n > next_best_score {
+			next_best_score = n
+		}
+	}
+
+	if best_species_index >= len(e.spec_data) || e.spec_data[best_species_index] == nil {
+		return FALSE
+	}
+	sp = e.spec_data[best_species_index]
+	name_length = len(sp.name)
+	minimum_score = name_length - ((name_length / 7) + 1)
+
+	if best_score < minimum_score /* Score too low. */ || name_length < 5 /* No errors allowed. */ || best_score == next_best_score /* Another name with equal score. */ {
+		if first_try != FALSE {
+			first_try = FALSE
+			goto yet_again
+		}
+		return FALSE
+	}
+
+	e.g_spec_name = sp.name
+	e.g_spec_number = best_species_index + 1
+	e.abbr_type = SPECIES_ID
+
+	return TRUE
+}
@@ -0,0 +1,49 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+/* This routine will return TRUE if the next argument is a valid ship or
+ * named planet. If it is a ship, "abbr_type" will be SHIP_CLASS and the
+ * globals "ship" and "ship_index" will be set. If it is a named planet,
+ * "abbr_type" will be PLANET_ID and "nampla" will be set. */
+func (e *Engine) get_transfer_point() int {
+	/* Find out if it is a ship or a planet. First try for a correctly spelled ship name. */
+	temp_ptr := e.input_line_pointer
+	e.correct_spelling_required = TRUE
+	if e.get_ship() != FALSE {
+		return TRUE
+	}
+
+	/* Probably not a ship. See if it's a planet. */
+	e.input_line_pointer = temp_ptr
+	if e.get_location() != FALSE {
+		if e.nampla == nil {
+			return FALSE
+		}
+		return TRUE
+	}
+
+	/* Now check for an incorrectly spelled ship name. */
+	e.input_line_pointer = temp_ptr
+	if e.get_ship() != FALSE {
+		return TRUE
+	}
+
+	return FALSE
+}
@@ -0,0 +1,138 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+import (
+	"fmt"
+	"github.com/mdhender/fhcms/cms/store/jsondb"
+	"github.com/mdhender/fhcms/internal/galaxy"
+	"strings"
+	"testing"
+)
+
+// testStore returns a generated galaxy with three species.
+func testStore(t *testing.T) *jsondb.Store {
+	t.Helper()
+	ds, err := galaxy.New(galaxy.Config{Species: 3, Seed: 0x1234})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, name := range []string{"Alpha", "Bravo", "Charlie"} {
+		if _, err := galaxy.AddSpecies(ds, galaxy.SpeciesConfig{
+			Name:       name,
+			GovtName:   name + " Government",
+			GovtType:   "Monarchy",
+			HomePlanet: name + " Prime",
+			ML:         4, GV: 4, LS: 4, BI: 3,
+			Seed: uint64(i + 1),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return ds
+}
+
+// TestJumpMishap checks that a ship with a fail-safe jump unit re-rolls a
+// mishap, mis-jumps when the second roll also fails, and that Finish reports
+// each mishap exactly once.
+func TestJumpMishap(t *testing.T) {
+	ds := testStore(t)
+	ds.Galaxy.TurnNumber = 1
+
+	sp := ds.Species[0]
+	home := sp.Namplas[0]
+	ship := &jsondb.ShipData{
+		Name:         "Scout",
+		Class:        TR,
+		Type:         FTL,
+		Tonnage:      1,
+		Status:       IN_ORBIT,
+		X:            home.X,
+		Y:            home.Y,
+		Z:            home.Z,
+		Pn:           home.Pn,
+		ItemQuantity: make([]int, MAX_ITEMS),
+	}
+	ship.ItemQuantity[FS] = 1
+	sp.Ships = append(sp.Ships, ship)
+	sp.NumShips = len(sp.Ships)
+
+	// a jump of 10 sectors on two axes has a 50% chance of a mishap at GV 4
+	x, y := home.X+10, home.Y+10
+	if max := 2*ds.Galaxy.Radius - 1; x > max || y > max {
+		x, y = home.X-10, home.Y-10
+	}
+
+	for _, tc := range []struct {
+		name     string
+		seed     uint64
+		misjump  bool
+		wantLogs []string
+	}{
+		{name: "fail-safe re-roll", seed: 17, wantLogs: []string{" jumped to", "fail-safe jump unit was expended"}},
+		{name: "mis-jump", seed: 1, misjump: true, wantLogs: []string{"fail-safe jump unit was expended", "mis-jumped to"}},
+	} {
+		e := New(false)
+		if err := e.loadStore(ds); err != nil {
+			t.Fatal(err)
+		}
+		e.setOrders(0, "sp01.ord", []byte(fmt.Sprintf("START JUMPS\nJump TR1 Scout, %d %d %d\nEND\n", x, y, home.Z)))
+		e.SetSeed(tc.seed)
+		if err := e.RunPhase("Jump"); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if err := e.RunPhase("Finish"); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+
+		sh := e.ship_data[0][0]
+		if sh.item_quantity[FS] != 0 {
+			t.Errorf("%s: fail-safe units: got %d, want 0", tc.name, sh.item_quantity[FS])
+		}
+		if sh.status != IN_DEEP_SPACE {
+			t.Errorf("%s: status: got %d, want %d", tc.name, sh.status, IN_DEEP_SPACE)
+		}
+		if arrived := sh.x == x && sh.y == y && sh.z == home.Z; arrived == tc.misjump {
+			t.Errorf("%s: ship is at %d %d %d, destination is %d %d %d", tc.name, sh.x, sh.y, sh.z, x, y, home.Z)
+		}
+		var mishaps []int
+		for i := 0; i < e.num_transactions; i++ {
+			if e.transaction[i]._type == SHIP_MISHAP {
+				mishaps = append(mishaps, e.transaction[i].value)
+			}
+		}
+		wantMishaps := []int{4}
+		if tc.misjump {
+			wantMishaps = append(wantMishaps, 3)
+		}
+		if fmt.Sprint(mishaps) != fmt.Sprint(wantMishaps) {
+			t.Errorf("%s: mishaps: got %v, want %v", tc.name, mishaps, wantMishaps)
+		}
+
+		log := e.spec_logs[0].String()
+		for _, want := range tc.wantLogs {
+			if n := strings.Count(log, want); n != 1 {
+				t.Errorf("%s: log reports %q %d times, want once", tc.name, want, n)
+			}
+		}
+		if tc.misjump && strings.Contains(log, " jumped to") {
+			t.Errorf("%s: log reports a successful jump", tc.name)
+		}
+	}
+}
@@ -22,9 +22,12 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"github.com/mdhender/fhcms/cms/store/jsondb"
 	"github.com/mdhender/fhcms/internal/dat32"
+	"github.com/mdhender/fhcms/internal/orders"
 	"io/ioutil"
 	"log"
+	"os"
 	"path/filepath"
 )
 
@@ -62,6 +65,12 @@ func (e *Engine) LoadBinary(root, prefix string, endian binary.ByteOrder) error
 			planet_index: stars.Stars[i].PlanetIndex,
 			message:      stars.Stars[i].Message,
 			visited_by:   make([]int, e.galaxy.num_species+1, e.galaxy.num_species+1),
+			reserved1:    stars.Stars[i].Reserved1,
+			reserved2:    stars.Stars[i].Reserved2,
+			reserved3:    stars.Stars[i].Reserved3,
+			reserved4:    stars.Stars[i].Reserved4,
+			reserved5:    stars.Stars[i].Reserved5,
+			padding:      stars.Stars[i].Padding,
 		}
 		for v := 0; v < len(stars.Stars[i].VisitedBy); v++ {
 			if stars.Stars[i].VisitedBy[v] <= e.galaxy.num_species {
@@ -91,6 +100,11 @@ func (e *Engine) LoadBinary(root, prefix string, endian binary.ByteOrder) error
 			econ_efficiency:   planets.Planets[i].EconEfficiency,
 			md_increase:       planets.Planets[i].MDIncrease,
 			message:           planets.Planets[i].Message,
+			reserved1:         planets.Planets[i].Reserved1,
+			reserved2:         planets.Planets[i].Reserved2,
+			reserved3:         planets.Planets[i].Reserved3,
+			reserved4:         planets.Planets[i].Reserved4,
+			reserved5:         planets.Planets[i].Reserved5,
 		}
 	}
 	log.Printf("[engine] loadBinary: loaded %6d planets\n", len(planets.Planets))
@@ -98,13 +112,18 @@ func (e *Engine) LoadBinary(root, prefix string, endian binary.ByteOrder) error
 	e.spec_data = make([]*species_data, e.galaxy.num_species, e.galaxy.num_species)
 	e.spec_logs = make([]*bytes.Buffer, e.galaxy.num_species, e.galaxy.num_species)
 	e.spec_orders = make([][]byte, e.galaxy.num_species, e.galaxy.num_species)
+	e.spec_parsed = make([]*orders.Orders, e.galaxy.num_species, e.galaxy.num_species)
 	e.namp_data = make([][]*nampla_data, e.galaxy.num_species, e.galaxy.num_species)
 	e.ship_data = make([][]*ship_data, e.galaxy.num_species, e.galaxy.num_species)
 	for i := 0; i < galaxy.NumSpecies; i++ {
 		e.spec_logs[i] = &bytes.Buffer{}
 
 		sp, err := dat32.ReadSpecies(filepath.Join(root, prefix+fmt.Sprintf("sp%02d.dat", i+1)), i+1, endian)
-		if err != nil {
+		if os.IsNotExist(err) {
+			// SaveBinary does not write a file for a species that is gone
+			log.Printf("[engine] loadBinary: species %d is missing\n", i+1)
+			continue
+		} else if err != nil {
 			return err
 		}
 		sd := &species_data{
@@ -128,6 +147,10 @@ func (e *Engine) LoadBinary(root, prefix string, endian binary.ByteOrder) error
 			econ_units:         sp.EconUnits,
 			fleet_cost:         sp.FleetCost,
 			fleet_percent_cost: sp.FleetPercentCost,
+			reserved3:          sp.Reserved3,
+			reserved4:          sp.Reserved4,
+			reserved5:          sp.Reserved5,
+			padding:            sp.Padding,
 			contact:            make([]int, MAX_SPECIES, MAX_SPECIES),
 			ally:               make([]int, MAX_SPECIES, MAX_SPECIES),
 			enemy:              make([]int, MAX_SPECIES, MAX_SPECIES),
@@ -183,6 +206,12 @@ func (e *Engine) LoadBinary(root, prefix string, endian binary.ByteOrder) error
 				use_on_ambush:  sp.NamplaBase[j].UseOnAmbush,
 				message:        sp.NamplaBase[j].Message,
 				special:        sp.NamplaBase[j].Special,
+				reserved1:      sp.NamplaBase[j].Reserved1,
+				reserved2:      sp.NamplaBase[j].Reserved2,
+				reserved4:      sp.NamplaBase[j].Reserved4,
+				reserved5:      sp.NamplaBase[j].Reserved5,
+				reserved6:      sp.NamplaBase[j].Reserved6,
+				padding:        sp.NamplaBase[j].Padding,
 			}
 			if sp.NamplaBase[j].Hiding {
 				np.hiding = TRUE
@@ -212,6 +241,12 @@ func (e *Engine) LoadBinary(root, prefix string, endian binary.ByteOrder) error
 				loading_point:   sp.ShipBase[j].LoadingPoint,
 				unloading_point: sp.ShipBase[j].UnloadingPoint,
 				special:         sp.ShipBase[j].Special,
+				reserved1:       sp.ShipBase[j].Reserved1,
+				reserved2:       sp.ShipBase[j].Reserved2,
+				reserved3:       sp.ShipBase[j].Reserved3,
+				reserved4:       sp.ShipBase[j].Reserved4,
+				padding:         sp.ShipBase[j].Padding,
+				more_padding:    sp.ShipBase[j].MorePadding,
 			}
 			if sp.ShipBase[j].JustJumped {
 				sh.just_jumped = TRUE
@@ -225,6 +260,17 @@ func (e *Engine) LoadBinary(root, prefix string, endian binary.ByteOrder) error
 	}
 	log.Printf("[engine] loadBinary: loaded %6d species\n", len(e.spec_data))
 
+	locations, err := dat32.ReadLocations(filepath.Join(root, prefix+"locations.dat"), endian)
+	if err != nil {
+		return err
+	}
+	e.loc = nil
+	for _, loc := range locations {
+		e.loc = append(e.loc, &sp_loc_data{s: loc.S, x: loc.X, y: loc.Y, z: loc.Z})
+	}
+	e.num_locs = len(e.loc)
+	log.Printf("[engine] loadBinary: loaded %6d locations\n", e.num_locs)
+
 	//// create new locations array
 	//e.locations = nil
 	//// add all colonies
@@ -247,19 +293,257 @@ func (e *Engine) LoadBinary(root, prefix string, endian binary.ByteOrder) error
 	return nil
 }
 
+// LoadOrders loads the orders files for every species.
+// An orders file may be in the text, JSON, or YAML format;
+// JSON and YAML files are converted to text when they are loaded.
+// Fleets and macros are expanded, and the expanded orders are saved
+// next to the original as spNN.expanded.ord so that the GM can see
+// exactly what was run.
 func (e *Engine) LoadOrders(root, prefix string) error {
 	for i := 0; i < e.galaxy.num_species; i++ {
 		ordersFile := filepath.Join(root, prefix+fmt.Sprintf("sp%02d.ord", i+1))
-		if orders, err := ioutil.ReadFile(ordersFile); err == nil {
+		if b, err := ioutil.ReadFile(ordersFile); err == nil {
+			if b, err = orders.ToText(b); err != nil {
+				return fmt.Errorf("%s: %w", ordersFile, err)
+			}
 			log.Printf("[engine] loaded %q\n", ordersFile)
-			e.spec_orders[i] = orders
+			if x := orders.Expand(b); x.Expanded {
+				for _, err := range x.Errors {
+					log.Printf("[engine] %s: %v\n", ordersFile, err)
+				}
+				expandedFile := filepath.Join(root, prefix+fmt.Sprintf("sp%02d.expanded.ord", i+1))
+				if err := ioutil.WriteFile(expandedFile, x.Text, 0644); err != nil {
+					return err
+				}
+				log.Printf("[engine] expanded %q to %q\n", ordersFile, expandedFile)
+				b = x.Text
+			}
+			e.setOrders(i, ordersFile, b)
 		}
 	}
 	return nil
 }
 
-// LoadJson will load all data from JSON files
-func LoadJson() error {
+// setOrders stores the orders for a species and parses them into typed orders
+// for Orders. Any errors found by the parser are logged.
+func (e *Engine) setOrders(spIndex int, name string, b []byte) {
+	e.spec_orders[spIndex] = b
+	e.spec_parsed[spIndex] = orders.Parse(b)
+	for _, err := range e.spec_parsed[spIndex].AllErrors() {
+		log.Printf("[engine] %s: %v\n", name, err)
+	}
+}
+
+// LoadJSON loads all data from a single jsondb file.
+// It fills the same structures as LoadBinary, including the locations.
+func (e *Engine) LoadJSON(path string) error {
+	ds, err := jsondb.Read(path)
+	if err != nil {
+		return err
+	}
+	return e.loadStore(ds)
+}
+
+// loadStore fills the engine data from a jsondb store.
+func (e *Engine) loadStore(ds *jsondb.Store) error {
+	e.galaxy.d_num_species = ds.Galaxy.DNumSpecies
+	e.galaxy.num_species = ds.Galaxy.NumSpecies
+	e.galaxy.radius = ds.Galaxy.Radius
+	e.galaxy.turn_number = ds.Galaxy.TurnNumber
+	log.Printf("[engine] loadJSON: loaded galaxy turn %6d\n", e.galaxy.turn_number)
+	// species are placed by number, and species that are gone are left out of the store
+	for i, sp := range ds.Species {
+		if sp == nil {
+			return fmt.Errorf("loadJSON: species entry %d is null", i+1)
+		} else if sp.Id < 1 || sp.Id > e.galaxy.num_species {
+			return fmt.Errorf("loadJSON: galaxy has %d species but store has species %d", e.galaxy.num_species, sp.Id)
+		}
+	}
+
+	e.star_base = make([]*star_data, len(ds.Stars), len(ds.Stars))
+	for i, star := range ds.Stars {
+		sd := &star_data{
+			x:            star.X,
+			y:            star.Y,
+			z:            star.Z,
+			_type:        star.Type,
+			color:        star.Color,
+			size:         star.Size,
+			num_planets:  star.NumPlanets,
+			home_system:  star.HomeSystem,
+			worm_here:    star.WormHere,
+			worm_x:       star.WormX,
+			worm_y:       star.WormY,
+			worm_z:       star.WormZ,
+			planet_index: star.PlanetIndex,
+			message:      star.Message,
+			visited_by:   make([]int, e.galaxy.num_species+1, e.galaxy.num_species+1),
+		}
+		for _, spNo := range star.VisitedBy {
+			if 0 < spNo && spNo <= e.galaxy.num_species {
+				sd.visited_by[spNo-1] = TRUE
+			}
+		}
+		e.star_base[i] = sd
+	}
+	e.num_stars = len(ds.Stars)
+	log.Printf("[engine] loadJSON: loaded %6d stars\n", e.num_stars)
+
+	e.planet_base = make([]*planet_data, len(ds.Planets), len(ds.Planets))
+	for i, planet := range ds.Planets {
+		e.planet_base[i] = &planet_data{
+			temperature_class: planet.TemperatureClass,
+			pressure_class:    planet.PressureClass,
+			special:           planet.Special,
+			gas:               planet.Gas,
+			gas_percent:       planet.GasPercent,
+			diameter:          planet.Diameter,
+			gravity:           planet.Gravity,
+			mining_difficulty: planet.MiningDifficulty,
+			econ_efficiency:   planet.EconEfficiency,
+			md_increase:       planet.MdIncrease,
+			message:           planet.Message,
+		}
+	}
+	log.Printf("[engine] loadJSON: loaded %6d planets\n", len(ds.Planets))
+
+	e.spec_data = make([]*species_data, e.galaxy.num_species, e.galaxy.num_species)
+	e.spec_logs = make([]*bytes.Buffer, e.galaxy.num_species, e.galaxy.num_species)
+	e.spec_orders = make([][]byte, e.galaxy.num_species, e.galaxy.num_species)
+	e.spec_parsed = make([]*orders.Orders, e.galaxy.num_species, e.galaxy.num_species)
+	e.namp_data = make([][]*nampla_data, e.galaxy.num_species, e.galaxy.num_species)
+	e.ship_data = make([][]*ship_data, e.galaxy.num_species, e.galaxy.num_species)
+	for i := range e.spec_logs {
+		e.spec_logs[i] = &bytes.Buffer{}
+	}
+	for _, sp := range ds.Species {
+		i := sp.Id - 1
+		if e.spec_data[i] != nil {
+			return fmt.Errorf("loadJSON: store has species %d more than once", sp.Id)
+		}
+
+		sd := &species_data{
+			name:               sp.Name,
+			govt_name:          sp.GovtName,
+			govt_type:          sp.GovtType,
+			x:                  sp.X,
+			y:                  sp.Y,
+			z:                  sp.Z,
+			pn:                 sp.Pn,
+			required_gas:       sp.RequiredGas,
+			required_gas_min:   sp.RequiredGasMin,
+			required_gas_max:   sp.RequiredGasMax,
+			neutral_gas:        sp.NeutralGas,
+			poison_gas:         sp.PoisonGas,
+			tech_level:         sp.TechLevel,
+			init_tech_level:    sp.InitTechLevel,
+			tech_knowledge:     sp.TechKnowledge,
+			num_namplas:        len(sp.Namplas),
+			num_ships:          len(sp.Ships),
+			tech_eps:           sp.TechEps,
+			hp_original_base:   sp.HpOriginalBase,
+			econ_units:         sp.EconUnits,
+			fleet_cost:         sp.FleetCost,
+			fleet_percent_cost: sp.FleetPercentCost,
+			contact:            make([]int, MAX_SPECIES, MAX_SPECIES),
+			ally:               make([]int, MAX_SPECIES, MAX_SPECIES),
+			enemy:              make([]int, MAX_SPECIES, MAX_SPECIES),
+		}
+		if sp.AutoOrders != 0 {
+			sd.auto_orders = TRUE
+		}
+		for _, spNo := range sp.Contact {
+			if 0 < spNo && spNo <= e.galaxy.num_species {
+				sd.contact[spNo-1] = TRUE
+			}
+		}
+		for _, spNo := range sp.Ally {
+			if 0 < spNo && spNo <= e.galaxy.num_species {
+				sd.ally[spNo-1] = TRUE
+			}
+		}
+		for _, spNo := range sp.Enemy {
+			if 0 < spNo && spNo <= e.galaxy.num_species {
+				sd.enemy[spNo-1] = TRUE
+			}
+		}
+		for _, nampla := range sp.Namplas {
+			np := &nampla_data{
+				name:           nampla.Name,
+				x:              nampla.X,
+				y:              nampla.Y,
+				z:              nampla.Z,
+				pn:             nampla.Pn,
+				status:         nampla.Status,
+				planet_index:   nampla.PlanetIndex,
+				siege_eff:      nampla.SiegeEff,
+				shipyards:      nampla.Shipyards,
+				IUs_needed:     nampla.IUsNeeded,
+				AUs_needed:     nampla.AUsNeeded,
+				auto_IUs:       nampla.AutoIUs,
+				auto_AUs:       nampla.AutoAUs,
+				IUs_to_install: nampla.IUsToInstall,
+				AUs_to_install: nampla.AUsToInstall,
+				mi_base:        nampla.MiBase,
+				ma_base:        nampla.MaBase,
+				pop_units:      nampla.PopUnits,
+				use_on_ambush:  nampla.UseOnAmbush,
+				message:        nampla.Message,
+				special:        nampla.Special,
+			}
+			if nampla.Hiding != 0 {
+				np.hiding = TRUE
+			}
+			if nampla.Hidden != 0 {
+				np.hidden = TRUE
+			}
+			for item := 0; item < MAX_ITEMS && item < len(nampla.ItemQuantity); item++ {
+				np.item_quantity[item] = nampla.ItemQuantity[item]
+			}
+			e.namp_data[i] = append(e.namp_data[i], np)
+		}
+		for _, ship := range sp.Ships {
+			sh := &ship_data{
+				name:            ship.Name,
+				x:               ship.X,
+				y:               ship.Y,
+				z:               ship.Z,
+				pn:              ship.Pn,
+				status:          ship.Status,
+				_type:           ship.Type,
+				dest_x:          ship.DestX,
+				dest_y:          ship.DestY,
+				dest_z:          ship.DestZ,
+				class:           ship.Class,
+				tonnage:         ship.Tonnage,
+				age:             ship.Age,
+				remaining_cost:  ship.RemainingCost,
+				loading_point:   ship.LoadingPoint,
+				unloading_point: ship.UnloadingPoint,
+				special:         ship.Special,
+			}
+			if ship.JustJumped != 0 {
+				sh.just_jumped = TRUE
+			}
+			if ship.ArrivedViaWormhole != 0 {
+				sh.arrived_via_wormhole = TRUE
+			}
+			for item := 0; item < MAX_ITEMS && item < len(ship.ItemQuantity); item++ {
+				sh.item_quantity[item] = ship.ItemQuantity[item]
+			}
+			e.ship_data[i] = append(e.ship_data[i], sh)
+		}
+		e.spec_data[i] = sd
+	}
+	log.Printf("[engine] loadJSON: loaded %6d species\n", len(ds.Species))
+
+	e.loc = nil
+	for _, loc := range ds.Locations {
+		e.loc = append(e.loc, &sp_loc_data{s: loc.S, x: loc.X, y: loc.Y, z: loc.Z})
+	}
+	e.num_locs = len(e.loc)
+	log.Printf("[engine] loadJSON: loaded %6d locations\n", e.num_locs)
+
 	return nil
 }
 
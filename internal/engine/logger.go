@@ -57,7 +57,7 @@ func (e *Engine) log_char(c byte) {
 		e.log_line[e.log_position] = 0
 		e.log_position = e.log_indentation + 2
 		for i := 0; i < e.log_position; i++ {
-			e.log_line[i], e.log_line[i+1] = '.', 0
+			e.log_line[i], e.log_line[i+1] = ' ', 0
 		}
 		for i := 0; i < temp_position+1; i++ {
 			e.log_line[e.log_position+i] = e.log_line[temp_position+1+i]
@@ -0,0 +1,100 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+/* check_bounced will return TRUE if the amount needed is more than the
+ * current balance and the shortfall cannot be covered by the species'
+ * banked economic units. Otherwise, it will reduce the raw material units,
+ * production capacity and balance of the current planet and return FALSE. */
+func (e *Engine) check_bounced(amount_needed int) int {
+	var take_from_EUs, limiting_balance int
+
+	/* Check if we have sufficient funds for this purchase. */
+	if amount_needed > e.balance {
+		take_from_EUs = amount_needed - e.balance
+
+		if take_from_EUs <= e.EU_spending_limit && take_from_EUs <= e.species.econ_units {
+			e.species.econ_units -= take_from_EUs
+			e.EU_spending_limit -= take_from_EUs
+			e.balance = amount_needed
+		} else {
+			return TRUE
+		}
+	}
+
+	/* Reduce various balances appropriately. */
+	if e.raw_material_units >= amount_needed {
+		if e.production_capacity >= amount_needed {
+			/* Enough of both. */
+			e.raw_material_units -= amount_needed
+			e.production_capacity -= amount_needed
+		} else {
+			/* Enough RMs but not enough PC. */
+			e.raw_material_units -= e.production_capacity
+			e.production_capacity = 0
+		}
+	} else {
+		if e.production_capacity >= amount_needed {
+			/* Enough PC but not enough RMs. */
+			e.production_capacity -= e.raw_material_units
+			e.raw_material_units = 0
+		} else {
+			/* Not enough RMs or PC. */
+			if e.raw_material_units > e.production_capacity {
+				limiting_balance = e.production_capacity
+			} else {
+				limiting_balance = e.raw_material_units
+			}
+			e.raw_material_units -= limiting_balance
+			e.production_capacity -= limiting_balance
+		}
+	}
+
+	e.balance -= amount_needed
+
+	return FALSE
+}
+
+/* transfer_balance terminates production for the current planet. Any unused
+ * balance is converted to economic units and banked by the species, and any
+ * unused raw material units are carried over into the next turn. */
+func (e *Engine) transfer_balance() {
+	var limiting_amount int
+
+	/* Log end of production. Do not print ending balance for mining or resort colonies. */
+	fprintf(e.log_file, "  End of production on PL %s.", e.nampla.name)
+	if (e.nampla.status & (MINING_COLONY | RESORT_COLONY)) == 0 {
+		if e.raw_material_units > e.production_capacity {
+			limiting_amount = e.production_capacity
+		} else {
+			limiting_amount = e.raw_material_units
+		}
+		fprintf(e.log_file, " (Ending balance is %d.)", limiting_amount)
+	}
+	fprintf(e.log_file, "\n")
+
+	/* Convert unused balance to economic units. */
+	e.species.econ_units += limiting_amount
+	e.raw_material_units -= limiting_amount
+
+	/* Carry over unused raw material units into next turn. */
+	e.nampla.item_quantity[RM] += e.raw_material_units
+
+	e.balance = 0
+}
@@ -20,7 +20,7 @@ package engine
 
 import (
 	"bytes"
-	"strconv"
+	"github.com/mdhender/fhcms/internal/orders"
 	"strings"
 )
 
@@ -36,152 +36,56 @@ import (
  * and "sub_light" will be TRUE or FALSE. (Tonnage value returned is based
  * ONLY on abbreviation.) */
 
+// get_class_abbr uses orders.ClassAbbr so that the engine and the orders
+// package read abbreviations the same way.
 func (e *Engine) get_class_abbr() int {
 	e.skip_whitespace()
-	e.abbr_type = UNKNOWN
 
-	if !isalnum(e.input_line_pointer[0]) {
-		return UNKNOWN
-	}
-	e.input_abbr[0], e.input_abbr[1] = toupper(e.input_line_pointer[0]), 0
-	e.input_line_pointer = e.input_line_pointer[1:]
-
-	if !isalnum(e.input_line_pointer[0]) {
-		return UNKNOWN
-	}
-	e.input_abbr[1], e.input_abbr[2] = toupper(e.input_line_pointer[0]), 0
-	e.input_line_pointer = e.input_line_pointer[1:]
-
-	// check for IDs that are followed by one or more digits or letters
-	i := 2
-	digit_start := e.input_line_pointer
-	for isalnum(e.input_line_pointer[0]) {
-		e.input_abbr[i], e.input_abbr[i+1] = e.input_line_pointer[0], 0
-		i, e.input_line_pointer = i+1, e.input_line_pointer[1:]
-	}
-
-	// check tech ID
-	for i = 0; i < 6; i++ {
-		if strcmp(e.input_abbr, []byte(tech_abbr[i])) == 0 {
-			e.abbr_index, e.abbr_type = i, TECH_ID
-			return e.abbr_type
-		}
-	}
-
-	// check item abbreviations
-	for i = 0; i < MAX_ITEMS; i++ {
-		if strcmp(e.input_abbr, []byte(item_abbr[i])) == 0 {
-			e.abbr_index, e.abbr_type = i, ITEM_CLASS
-			return e.abbr_type
-		}
-	}
-
-	// check ship abbreviations
-	for i = 0; i < NUM_SHIP_CLASSES; i++ {
-		if strncmp(e.input_abbr, []byte(ship_abbr[i]), 2) != 0 {
-			continue
-		}
-		e.input_line_pointer = digit_start
-		e.abbr_index = i
-		e.tonnage = ship_tonnage[i]
-		if i == TR {
-			e.tonnage = 0
-			for isdigit(e.input_line_pointer[0]) {
-				e.tonnage = (10 * e.tonnage) + int(e.input_line_pointer[0]-'0')
-				e.input_line_pointer = e.input_line_pointer[1:]
-			}
-		}
-		e.sub_light = FALSE
-		if toupper(e.input_line_pointer[0]) == 'S' {
+	var abbr orders.Abbr
+	abbr, e.input_line_pointer = orders.ClassAbbr(e.input_line_pointer)
+	e.abbr_type = abbr.Type
+	switch abbr.Type {
+	case TECH_ID, ITEM_CLASS:
+		e.abbr_index = abbr.Index
+	case SHIP_CLASS:
+		e.abbr_index, e.tonnage, e.sub_light = abbr.Index, abbr.Tonnage, FALSE
+		if abbr.SubLight {
 			e.sub_light = TRUE
-			e.input_line_pointer = e.input_line_pointer[1:]
 		}
-		if isalnum(e.input_line_pointer[0]) {
-			break // garbage, not a ship class
-		}
-		e.abbr_type = SHIP_CLASS
-		return e.abbr_type
-	}
-
-	// check for planet name
-	if strcmp(e.input_abbr, []byte("PL")) == 0 {
-		e.abbr_type = PLANET_ID
-		return (e.abbr_type)
-	}
-
-	// check for species name
-	if strcmp(e.input_abbr, []byte("SP")) == 0 {
-		e.abbr_type = SPECIES_ID
-		return e.abbr_type
 	}
-
-	e.abbr_type = UNKNOWN
 	return e.abbr_type
 }
 
 /* Get a command and return its index. */
+
+// get_command uses orders.CommandWord so that the engine and the orders
+// package read commands the same way. The index of a command is the same
+// as its orders.COMMAND value.
 func (e *Engine) get_command() int {
 	e.skip_junk()
 	if e.end_of_file != FALSE {
 		return -1
 	}
 
-	/* Get first three characters of command word. */
-	var cmd_s string
-	for i := 0; i < 3 && len(e.input_line_pointer) != 0; i++ {
-		if !isalpha(e.input_line_pointer[0]) {
-			return 0
-		}
-		cmd_s += string(e.input_line_pointer[:1])
-		e.input_line_pointer = e.input_line_pointer[1:]
-	}
-	cmd_s = strings.ToUpper(cmd_s)
-
-	/* Skip everything after third character of command word. */
-	for len(e.input_line_pointer) != 0 && bytes.IndexByte([]byte{'\t', '\r', '\n', ' ', ',', ';'}, e.input_line_pointer[0]) == -1 {
-		e.input_line_pointer = e.input_line_pointer[1:]
-	}
-
-	/* Find corresponding string in list. */
-	cmd_n := UNKNOWN
-	for i := 1; i < NUM_COMMANDS; i++ {
-		if cmd_s == command_abbr[i] {
-			cmd_n = i
-			break
-		}
-	}
-
-	return cmd_n
+	var command orders.COMMAND
+	command, e.input_line_pointer = orders.CommandWord(e.input_line_pointer)
+	return int(command)
 }
 
 /* Get a name and copy original version to "original_name" and upper
  * case version to "upper_name". Return length of name. */
+// get_name uses orders.GetName so that the engine and the orders
+// package read names the same way. Names are cut to 31 characters.
 func (e *Engine) get_name() int {
-	e.skip_whitespace()
-
-	var name_length int
-	for {
-		c := e.input_line_pointer[0]
-		if c == ';' {
-			break
-		}
-		e.input_line_pointer = e.input_line_pointer[1:]
-		if c == ',' || c == '\t' || c == '\n' {
-			break
-		}
-		if name_length < 31 {
-			e.original_name[name_length] = c
-			e.upper_name[name_length] = toupper(c)
-			name_length++
-		}
+	var name string
+	name, e.input_line_pointer = orders.GetName(e.input_line_pointer)
+	if len(name) > 31 {
+		name = strings.TrimRight(name[:31], " ")
 	}
 
-	// remove any trailing spaces in name
-	for name_length > 0 {
-		if e.original_name[name_length-1] != ' ' {
-			break
-		}
-		name_length--
+	name_length := copy(e.original_name, name)
+	for i := 0; i < name_length; i++ {
+		e.upper_name[i] = toupper(e.original_name[i])
 	}
 
 	// terminate strings
@@ -192,31 +96,16 @@ func (e *Engine) get_name() int {
 }
 
 // get_value reads an integer and places its value in 'value'.
-// ignores overflow.
+// It uses orders.GetValue so that the engine and the orders package read
+// values the same way.
 // returns TRUE if it could read an integer, FALSE otherwise
 func (e *Engine) get_value() int {
-	e.skip_whitespace()
-	if len(e.input_line_pointer) == 0 {
+	value, rest, ok := orders.GetValue(e.input_line_pointer)
+	if !ok {
+		e.input_line_pointer = orders.SkipWhitespace(e.input_line_pointer)
 		return FALSE
-	} else if !(e.input_line_pointer[0] == '+' || e.input_line_pointer[0] == '-' || isdigit(e.input_line_pointer[0])) {
-		return FALSE
-	}
-
-	input := strings.Builder{}
-
-	// consume sign or first digit
-	input.WriteByte(e.input_line_pointer[0])
-	e.input_line_pointer = e.input_line_pointer[1:]
-
-	// consume the next run of digits
-	for len(e.input_line_pointer) != 0 && isdigit(e.input_line_pointer[0]) {
-		input.WriteByte(e.input_line_pointer[0])
-		e.input_line_pointer = e.input_line_pointer[1:]
 	}
-
-	// convert the input to an int value. ignores overflow.
-	e.value, _ = strconv.Atoi(input.String())
-
+	e.value, e.input_line_pointer = value, rest
 	return TRUE
 }
 
@@ -252,25 +141,70 @@ again:
 	copy(e.original_line, e.input_line) // make a copy
 
 	/* Skip white space and comments. */
-	for len(e.input_line_pointer) != 0 {
-		switch e.input_line_pointer[0] {
-		case ';', '\n': /* Semi-colon. Newline. */
-			goto again
-		case '\t', ' ', ',', '\r': /* Tab. Space. Comma. CR. */
-			e.input_line_pointer = e.input_line_pointer[1:]
-		default:
-			return
-		}
+	e.skip_whitespace()
+	if len(e.input_line_pointer) != 0 && (e.input_line_pointer[0] == ';' || e.input_line_pointer[0] == '\n') {
+		goto again /* Semi-colon. Newline. */
 	}
 }
 
+// skip_whitespace uses orders.SkipWhitespace so that the engine and the
+// orders package separate arguments the same way.
 func (e *Engine) skip_whitespace() {
+	e.input_line_pointer = orders.SkipWhitespace(e.input_line_pointer)
+}
+
+/* The following routine will check that the next argument in the current
+ * command line is followed by a comma or tab. If not present, it will
+ * try to insert a comma in the proper position. This routine should
+ * be called only AFTER an error has been detected. */
+func (e *Engine) fix_separator() {
+	e.skip_whitespace()
+	if len(e.input_line_pointer) == 0 || isdigit(e.input_line_pointer[0]) {
+		return /* Nothing can be done. */
+	}
+	if bytes.IndexByte(e.input_line_pointer[:strlen(e.input_line_pointer)], ' ') == -1 {
+		return /* Ditto. */
+	}
+
+	fix_made := FALSE
+
+	/* Look for a ship, planet, or species abbreviation after the first one.
+	 * If it is preceded by a space, convert the space to a comma. */
+	temp_ptr := e.input_line_pointer
+	e.get_class_abbr() /* Skip first abbreviation. */
 	for len(e.input_line_pointer) != 0 {
-		switch e.input_line_pointer[0] {
-		case '\t', ' ', ',', '\r': /* Tab. Space. Comma. CR. */
-			e.input_line_pointer = e.input_line_pointer[1:]
-		default:
-			return
+		c := e.input_line_pointer[0]
+		if c == 0 || c == '\n' || c == ';' || c == ',' || c == '\t' {
+			break
+		}
+		e.input_line_pointer = e.input_line_pointer[1:]
+		if c != ' ' {
+			continue
+		}
+		temp2_ptr := e.input_line_pointer
+		if n := e.get_class_abbr(); n == SHIP_CLASS || n == PLANET_ID || n == SPECIES_ID {
+			// convert the space in front of the abbreviation to a comma
+			temp_ptr[len(temp_ptr)-len(temp2_ptr)-1] = ','
+			fix_made = TRUE
+			break
+		}
+		e.input_line_pointer = temp2_ptr
+	}
+
+	e.input_line_pointer = temp_ptr
+	if fix_made != FALSE {
+		return
+	}
+
+	/* Look for a space followed by a digit. If found, convert the space to a comma. */
+	for i := 1; i < len(temp_ptr); i++ {
+		c := temp_ptr[i]
+		if c == 0 || c == '\n' || c == ';' || c == ',' || c == '\t' {
+			break
+		}
+		if c == ' ' && i+1 < len(temp_ptr) && isdigit(temp_ptr[i+1]) {
+			temp_ptr[i] = ','
+			break
 		}
 	}
 }
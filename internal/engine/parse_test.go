@@ -0,0 +1,117 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+import (
+	"github.com/mdhender/fhcms/internal/orders"
+	"testing"
+)
+
+// TestCommandIndex checks that the engine's command indexes are the same
+// as the orders package's command values, since get_command returns them.
+func TestCommandIndex(t *testing.T) {
+	for i := 1; i < NUM_COMMANDS; i++ {
+		command, _ := orders.CommandWord([]byte(command_abbr[i]))
+		if int(command) != i {
+			t.Errorf("%s: got %d, want %d", command_abbr[i], command, i)
+		} else if command.String() != command_name[i] {
+			t.Errorf("%s: got %q, want %q", command_abbr[i], command.String(), command_name[i])
+		}
+	}
+}
+
+// TestGetClassAbbr checks that get_class_abbr identifies the engine's
+// abbreviations and leaves the rest of the line.
+func TestGetClassAbbr(t *testing.T) {
+	type want struct {
+		abbrType, abbrIndex, tonnage, subLight int
+	}
+	tests := map[string]want{
+		"PL":   {abbrType: PLANET_ID},
+		"sp":   {abbrType: SPECIES_ID},
+		"TR":   {abbrType: SHIP_CLASS, abbrIndex: TR},
+		"TR12": {abbrType: SHIP_CLASS, abbrIndex: TR, tonnage: 12},
+		"tr3s": {abbrType: SHIP_CLASS, abbrIndex: TR, tonnage: 3, subLight: TRUE},
+		"DDX":  {abbrType: UNKNOWN},
+		"PLX":  {abbrType: UNKNOWN},
+		"XX":   {abbrType: UNKNOWN},
+	}
+	for i, abbr := range tech_abbr {
+		tests[abbr] = want{abbrType: TECH_ID, abbrIndex: i}
+	}
+	for i, abbr := range item_abbr {
+		tests[abbr] = want{abbrType: ITEM_CLASS, abbrIndex: i}
+	}
+	for i, abbr := range ship_abbr {
+		if i != TR {
+			tests[abbr] = want{abbrType: SHIP_CLASS, abbrIndex: i, tonnage: ship_tonnage[i]}
+			tests[abbr+"S"] = want{abbrType: SHIP_CLASS, abbrIndex: i, tonnage: ship_tonnage[i], subLight: TRUE}
+		}
+	}
+	for input, w := range tests {
+		e := New(false)
+		e.input_line_pointer = []byte(" " + input + ", Name\n\x00")
+		if got := e.get_class_abbr(); got != w.abbrType {
+			t.Errorf("%q: type: got %d, want %d", input, got, w.abbrType)
+			continue
+		} else if w.abbrType == UNKNOWN {
+			continue
+		}
+		got := want{e.abbr_type, e.abbr_index, e.tonnage, e.sub_light}
+		if w.abbrType == PLANET_ID || w.abbrType == SPECIES_ID {
+			got.abbrIndex, got.tonnage, got.subLight = 0, 0, 0
+		} else if w.abbrType != SHIP_CLASS {
+			got.tonnage, got.subLight = 0, 0
+		}
+		if got != w {
+			t.Errorf("%q: got %+v, want %+v", input, got, w)
+		}
+		if rest := string(e.input_line_pointer); rest != ", Name\n\x00" {
+			t.Errorf("%q: rest: got %q, want %q", input, rest, ", Name\n\x00")
+		}
+	}
+}
+
+// TestGetNameValue checks how get_name and get_value read names, comments,
+// and values, and that long names are cut to 31 characters.
+func TestGetNameValue(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		name  string
+		value int
+		ok    bool
+	}{
+		{input: " Scout , 10 10 10\n", name: "Scout", value: 10, ok: true},
+		{input: "Scout\t-12\n", name: "Scout", value: -12, ok: true},
+		{input: "Scout\r\n", name: "Scout"},
+		{input: "Scout ; a comment\n", name: "Scout"},
+		{input: "Scout, +\n", name: "Scout"},
+		{input: "Scout, 99999999999999999999\n", name: "Scout"},
+		{input: "A Name That Goes On Past The Limit, 3\n", name: "A Name That Goes On Past The Li", value: 3, ok: true},
+	} {
+		e := New(false)
+		e.input_line_pointer = []byte(tc.input + "\x00")
+		if n := e.get_name(); string(e.original_name[:n]) != tc.name {
+			t.Errorf("%q: name: got %q, want %q", tc.input, e.original_name[:n], tc.name)
+		}
+		if ok := e.get_value() == TRUE; ok != tc.ok || (ok && e.value != tc.value) {
+			t.Errorf("%q: value: got %d %v, want %d %v", tc.input, e.value, ok, tc.value, tc.ok)
+		}
+	}
+}
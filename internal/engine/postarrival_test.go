@@ -0,0 +1,94 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestPostArrival checks that post-arrival orders move ships into orbit and name planets.
+func TestPostArrival(t *testing.T) {
+	ds := testStore(t)
+	alpha := ds.Species[0]
+	scout := addShip(alpha, "Scout", TR, 1)
+	scout.Status, scout.Pn = IN_DEEP_SPACE, 0
+	pn := 1
+	if pn == alpha.Namplas[0].Pn {
+		pn = 2
+	}
+
+	e := New(false)
+	if err := e.loadStore(ds); err != nil {
+		t.Fatal(err)
+	}
+	e.setOrders(0, "sp01.ord", []byte("START POST-ARRIVAL\n"+
+		"ORBIT TR1 Scout, PL Alpha Prime\n"+
+		fmt.Sprintf("NAME %d %d %d %d PL Outpost\n", scout.X, scout.Y, scout.Z, pn)+
+		"END\n"))
+	if err := e.RunPhase("PostArrival"); err != nil {
+		t.Fatal(err)
+	}
+
+	log := e.spec_logs[0].String()
+	if ship := e.ship_data[0][0]; ship.status != IN_ORBIT || ship.pn != alpha.Namplas[0].Pn {
+		t.Errorf("orbit: got status %d orbit %d, want orbit %d", ship.status, ship.pn, alpha.Namplas[0].Pn)
+	}
+	if sp := e.spec_data[0]; sp.num_namplas != 2 || e.namp_data[0][1].name != "Outpost" || e.namp_data[0][1].pn != pn {
+		t.Errorf("name: got %d named planets", sp.num_namplas)
+	}
+	if strings.Contains(log, "!!!") {
+		t.Errorf("log has errors:\n%s", log)
+	}
+}
+
+// TestStrike checks that a strike runs combat after the locations are updated.
+func TestStrike(t *testing.T) {
+	ds := testStore(t)
+	alpha, bravo := ds.Species[0], ds.Species[1]
+	alpha.Contact, alpha.Enemy = []int{2}, []int{2}
+	bravo.Contact = []int{1}
+	target := bravo.Namplas[0]
+	for i := 0; i < 3; i++ {
+		ship := addShip(alpha, fmt.Sprintf("Raider %d", i+1), DD, ship_tonnage[DD])
+		ship.X, ship.Y, ship.Z, ship.Pn = target.X, target.Y, target.Z, target.Pn
+	}
+	target.ItemQuantity[PD] = 20
+
+	e := New(false)
+	if err := e.loadStore(ds); err != nil {
+		t.Fatal(err)
+	}
+	e.SetSeed(1)
+	e.setOrders(0, "sp01.ord", []byte(fmt.Sprintf("START STRIKES\nBATTLE %d %d %d\nENGAGE 4 %d\nATTACK SP Bravo\nEND\n", target.X, target.Y, target.Z, target.Pn)))
+	for _, phase := range []string{"StrikeLocations", "Strike"} {
+		if err := e.RunPhase(phase); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := e.namp_data[1][0].item_quantity[PD]; n != 0 {
+		t.Errorf("strike: got %d PDs left on the target, want 0", n)
+	}
+	for _, want := range []string{"The battle begins", "All planetary defenses have been destroyed on PL Bravo Prime!"} {
+		if !strings.Contains(e.spec_logs[1].String(), want) {
+			t.Errorf("strike: defender log is missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+import (
+	"github.com/mdhender/fhcms/cms/store/jsondb"
+	"strings"
+	"testing"
+)
+
+// addShip adds a ship of the class to the species, in orbit around its home planet.
+func addShip(sp *jsondb.SpeciesData, name string, class, tonnage int) *jsondb.ShipData {
+	home := sp.Namplas[0]
+	ship := &jsondb.ShipData{
+		Id:           len(sp.Ships),
+		Name:         name,
+		Class:        class,
+		Type:         FTL,
+		Tonnage:      tonnage,
+		Status:       IN_ORBIT,
+		X:            home.X,
+		Y:            home.Y,
+		Z:            home.Z,
+		Pn:           home.Pn,
+		ItemQuantity: make([]int, MAX_ITEMS),
+	}
+	sp.Ships = append(sp.Ships, ship)
+	sp.NumShips = len(sp.Ships)
+	return ship
+}
+
+// TestPreDeparture checks that pre-departure orders change diplomacy, transfer
+// cargo, and move ships into deep space.
+func TestPreDeparture(t *testing.T) {
+	ds := testStore(t)
+	alpha := ds.Species[0]
+	alpha.Contact = []int{2}
+	scout := addShip(alpha, "Scout", TR, 1)
+	scout.ItemQuantity[CU] = 5
+	addShip(alpha, "Picket", TR, 1)
+
+	e := New(false)
+	if err := e.loadStore(ds); err != nil {
+		t.Fatal(err)
+	}
+	e.setOrders(0, "sp01.ord", []byte("START PRE-DEPARTURE\n"+
+		"ALLY SP Bravo\n"+
+		"TRANSFER 5 CU TR1 Scout, PL Alpha Prime\n"+
+		"DEEP TR1 Picket\n"+
+		"END\n"))
+	if err := e.RunPhase("PreDeparture"); err != nil {
+		t.Fatal(err)
+	}
+
+	sp := e.spec_data[0]
+	if sp.ally[1] != TRUE || sp.enemy[1] != FALSE {
+		t.Errorf("ally: got ally %d enemy %d, want Bravo as an ally", sp.ally[1], sp.enemy[1])
+	}
+	if got := e.ship_data[0][0].item_quantity[CU]; got != 0 {
+		t.Errorf("transfer: ship has %d CU, want 0", got)
+	}
+	if got := e.namp_data[0][0].item_quantity[CU]; got != 5 {
+		t.Errorf("transfer: home planet has %d CU, want 5", got)
+	}
+	if picket := e.ship_data[0][1]; picket.status != IN_DEEP_SPACE || picket.pn != 0 {
+		t.Errorf("deep: got status %d orbit %d, want deep space", picket.status, picket.pn)
+	}
+	if log := e.spec_logs[0].String(); strings.Contains(log, "!!!") {
+		t.Errorf("log has errors:\n%s", log)
+	}
+}
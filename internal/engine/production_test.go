@@ -0,0 +1,66 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestProduction checks that production orders spend the economic units of
+// the home planet on items, ships, and research, and bank the rest.
+func TestProduction(t *testing.T) {
+	ds := testStore(t)
+	ds.Species[0].EconUnits = 100
+
+	e := New(false)
+	if err := e.loadStore(ds); err != nil {
+		t.Fatal(err)
+	}
+	e.setOrders(0, "sp01.ord", []byte("START PRODUCTION\n"+
+		"PRODUCTION PL Alpha Prime\n"+
+		"BUILD 10 CU\n"+
+		"BUILD TR1 Scout\n"+
+		"RESEARCH 20 GV\n"+
+		"END\n"))
+	if err := e.RunPhase("Production"); err != nil {
+		t.Fatal(err)
+	}
+
+	log := e.spec_logs[0].String()
+	sp, home := e.spec_data[0], e.namp_data[0][0]
+	if home.item_quantity[CU] != 10 {
+		t.Errorf("build: home planet has %d CU, want 10", home.item_quantity[CU])
+	}
+	if len(e.ship_data[0]) != 1 || sp.num_ships != 1 {
+		t.Fatalf("build: got %d ships, want 1", sp.num_ships)
+	} else if ship := e.ship_data[0][0]; ship.name != "Scout" || ship.class != TR || ship.status != ON_SURFACE {
+		t.Errorf("build: got ship %q class %d status %d", ship.name, ship.class, ship.status)
+	}
+	if sp.tech_eps[GV] != 20 {
+		t.Errorf("research: got %d GV points, want 20", sp.tech_eps[GV])
+	}
+	// the balance left on the planet is added to the banked units
+	if want := 100 + 78; sp.econ_units != want || !strings.Contains(log, "(Ending balance is 78.)") {
+		t.Errorf("econ units: got %d, want %d", sp.econ_units, want)
+	}
+	if strings.Contains(log, "!!!") {
+		t.Errorf("log has errors:\n%s", log)
+	}
+}
@@ -18,30 +18,89 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 
 package engine
 
-import "log"
+import (
+	"fmt"
+	"log"
+)
+
+// phases is the order that the phases of a turn are run in.
+// Locations is run twice, so the second run is named for the Strike phase that follows it.
+var phases = []string{
+	"Locations",
+	"NoOrders",
+	"Combat",
+	"PreDeparture",
+	"Jump",
+	"Production",
+	"PostArrival",
+	"StrikeLocations",
+	"Strike",
+	"Finish",
+	"Report",
+	"Stats",
+}
+
+// Phases returns the names of the phases of a turn, in the order they are run.
+func Phases() []string {
+	return append([]string{}, phases...)
+}
+
+// PhaseIndex returns the index of the named phase, or -1 if there is no such phase.
+func PhaseIndex(phase string) int {
+	for i, name := range phases {
+		if name == phase {
+			return i
+		}
+	}
+	return -1
+}
 
 func (e *Engine) Run() error {
 	log.Printf("[engine] running turn      %5d\n", e.galaxy.turn_number)
-	log.Printf("[engine] running Locations......\n")
-	e.do_locations()
-	log.Printf("[engine] created %d/%d locations\n", e.num_locs, len(e.loc))
-	log.Printf("[engine] running NoOrders.......\n")
-	e.no_orders()
-	//for i, b := range e.spec_orders {
-	//	if (i == 4 || i == 17) && b != nil {
-	//		log.Printf("orders: SP%02d\n%s\n", i+1, string(b))
-	//	}
-	//}
-	log.Printf("[engine] running Combat.........\n")
-	e.combat()
-	log.Printf("[engine] running PreDeparture...\n")
-	log.Printf("[engine] running Jump...........\n")
-	log.Printf("[engine] running Production.....\n")
-	log.Printf("[engine] running PostArrival....\n")
-	log.Printf("[engine] running Locations......\n")
-	log.Printf("[engine] running Strike.........\n")
-	log.Printf("[engine] running Finish.........\n")
-	log.Printf("[engine] running Report.........\n")
+	for _, phase := range phases {
+		if err := e.RunPhase(phase); err != nil {
+			return err
+		}
+	}
 	log.Printf("[engine] success!\n")
 	return nil
 }
+
+// RunPhase runs a single phase of the turn.
+// A panic in the phase is returned as an error.
+func (e *Engine) RunPhase(phase string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("phase %s: %v", phase, r)
+		}
+	}()
+	log.Printf("[engine] running %-15s\n", phase)
+	switch phase {
+	case "Locations", "StrikeLocations":
+		e.do_locations()
+		log.Printf("[engine] created %d/%d locations\n", e.num_locs, len(e.loc))
+	case "NoOrders":
+		e.no_orders()
+	case "Combat":
+		e.combat()
+	case "PreDeparture":
+		e.pre_departure()
+	case "Jump":
+		e.jump()
+	case "Production":
+		e.production()
+	case "PostArrival":
+		e.post_arrival()
+	case "Strike":
+		e.combat("Strike")
+	case "Finish":
+		e.finish()
+	case "Report":
+		return e.report()
+	case "Stats":
+		return e.stats()
+	default:
+		return fmt.Errorf("unknown phase %q", phase)
+	}
+	return nil
+}
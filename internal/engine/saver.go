@@ -0,0 +1,455 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/mdhender/fhcms/cms/store/jsondb"
+	"github.com/mdhender/fhcms/internal/archive"
+	"github.com/mdhender/fhcms/internal/dat32"
+	"log"
+	"path/filepath"
+)
+
+// SaveBinary saves all data to the original binary files.
+// It is the inverse of LoadBinary.
+func (e *Engine) SaveBinary(root, prefix string, endian binary.ByteOrder) error {
+	galaxy, stars, planets, species, locations := e.dat32Records()
+
+	if err := dat32.WriteGalaxy(filepath.Join(root, prefix+"galaxy.dat"), galaxy, endian); err != nil {
+		return err
+	}
+	log.Printf("[engine] saveBinary: saved galaxy turn %6d\n", e.galaxy.turn_number)
+
+	if err := dat32.WriteStars(filepath.Join(root, prefix+"stars.dat"), stars, endian); err != nil {
+		return err
+	}
+	log.Printf("[engine] saveBinary: saved %6d stars\n", len(stars.Stars))
+
+	if err := dat32.WritePlanets(filepath.Join(root, prefix+"planets.dat"), planets, endian); err != nil {
+		return err
+	}
+	log.Printf("[engine] saveBinary: saved %6d planets\n", len(planets.Planets))
+
+	for _, sp := range species {
+		if err := dat32.WriteSpecies(filepath.Join(root, prefix+fmt.Sprintf("sp%02d.dat", sp.Id)), sp, endian); err != nil {
+			return err
+		}
+	}
+	log.Printf("[engine] saveBinary: saved %6d species\n", len(species))
+
+	if err := dat32.WriteLocations(filepath.Join(root, prefix+"locations.dat"), locations, endian); err != nil {
+		return err
+	}
+	log.Printf("[engine] saveBinary: saved %6d locations\n", len(locations))
+
+	return nil
+}
+
+// dat32Records converts the engine data to the records stored in the original binary files.
+func (e *Engine) dat32Records() (*dat32.Galaxy, *dat32.Stars, *dat32.Planets, []*dat32.Species, []dat32.SpLocData) {
+	galaxy := &dat32.Galaxy{
+		DNumSpecies: e.galaxy.d_num_species,
+		NumSpecies:  e.galaxy.num_species,
+		Radius:      e.galaxy.radius,
+		TurnNumber:  e.galaxy.turn_number,
+	}
+	stars := &dat32.Stars{NumStars: len(e.star_base), Stars: make([]dat32.Star, len(e.star_base), len(e.star_base))}
+	for i, sd := range e.star_base {
+		stars.Stars[i] = dat32.Star{
+			X:           sd.x,
+			Y:           sd.y,
+			Z:           sd.z,
+			Type:        sd._type,
+			Color:       sd.color,
+			Size:        sd.size,
+			NumPlanets:  sd.num_planets,
+			HomeSystem:  sd.home_system,
+			WormHere:    sd.worm_here,
+			WormX:       sd.worm_x,
+			WormY:       sd.worm_y,
+			WormZ:       sd.worm_z,
+			PlanetIndex: sd.planet_index,
+			Message:     sd.message,
+			Reserved1:   sd.reserved1,
+			Reserved2:   sd.reserved2,
+			Reserved3:   sd.reserved3,
+			Reserved4:   sd.reserved4,
+			Reserved5:   sd.reserved5,
+			Padding:     sd.padding,
+		}
+		for spIndex := 0; spIndex < e.galaxy.num_species && spIndex < len(sd.visited_by); spIndex++ {
+			if sd.visited_by[spIndex] != FALSE {
+				stars.Stars[i].VisitedBy = append(stars.Stars[i].VisitedBy, spIndex+1)
+			}
+		}
+	}
+	planets := &dat32.Planets{NumPlanets: len(e.planet_base), Planets: make([]dat32.Planet, len(e.planet_base), len(e.planet_base))}
+	for i, pd := range e.planet_base {
+		planets.Planets[i] = dat32.Planet{
+			Id:               i,
+			TemperatureClass: pd.temperature_class,
+			PressureClass:    pd.pressure_class,
+			Special:          pd.special,
+			Gas:              pd.gas,
+			GasPercent:       pd.gas_percent,
+			Diameter:         pd.diameter,
+			Gravity:          pd.gravity,
+			MiningDifficulty: pd.mining_difficulty,
+			EconEfficiency:   pd.econ_efficiency,
+			MDIncrease:       pd.md_increase,
+			Message:          pd.message,
+			Reserved1:        pd.reserved1,
+			Reserved2:        pd.reserved2,
+			Reserved3:        pd.reserved3,
+			Reserved4:        pd.reserved4,
+			Reserved5:        pd.reserved5,
+		}
+	}
+	var species []*dat32.Species
+	for i := 0; i < e.galaxy.num_species; i++ {
+		sd := e.spec_data[i]
+		if sd == nil {
+			continue
+		}
+		sp := &dat32.Species{
+			Id:               i + 1,
+			Name:             sd.name,
+			GovtName:         sd.govt_name,
+			GovtType:         sd.govt_type,
+			X:                sd.x,
+			Y:                sd.y,
+			Z:                sd.z,
+			PN:               sd.pn,
+			RequiredGas:      sd.required_gas,
+			RequiredGasMin:   sd.required_gas_min,
+			RequiredGasMax:   sd.required_gas_max,
+			AutoOrders:       sd.auto_orders != FALSE,
+			TechLevel:        sd.tech_level,
+			InitTechLevel:    sd.init_tech_level,
+			TechKnowledge:    sd.tech_knowledge,
+			NumNamplas:       sd.num_namplas,
+			NumShips:         sd.num_ships,
+			TechEps:          sd.tech_eps,
+			HPOriginalBase:   sd.hp_original_base,
+			EconUnits:        sd.econ_units,
+			FleetCost:        sd.fleet_cost,
+			FleetPercentCost: sd.fleet_percent_cost,
+			Reserved3:        sd.reserved3,
+			Reserved4:        sd.reserved4,
+			Reserved5:        sd.reserved5,
+			Padding:          sd.padding,
+		}
+		for _, gas := range sd.neutral_gas {
+			if gas != 0 {
+				sp.NeutralGas = append(sp.NeutralGas, gas)
+			}
+		}
+		for _, gas := range sd.poison_gas {
+			if gas != 0 {
+				sp.PoisonGas = append(sp.PoisonGas, gas)
+			}
+		}
+		for spIndex := 0; spIndex < e.galaxy.num_species; spIndex++ {
+			if sd.contact[spIndex] != FALSE {
+				sp.Contact = append(sp.Contact, spIndex+1)
+			}
+			if sd.ally[spIndex] != FALSE {
+				sp.Ally = append(sp.Ally, spIndex+1)
+			}
+			if sd.enemy[spIndex] != FALSE {
+				sp.Enemy = append(sp.Enemy, spIndex+1)
+			}
+		}
+		for j := 0; j < sd.num_namplas; j++ {
+			np := e.namp_data[i][j]
+			sp.NamplaBase = append(sp.NamplaBase, dat32.NamedPlanet{
+				Name:         np.name,
+				X:            np.x,
+				Y:            np.y,
+				Z:            np.z,
+				PN:           np.pn,
+				Status:       np.status,
+				Hiding:       np.hiding != FALSE,
+				Hidden:       np.hidden != FALSE,
+				PlanetIndex:  np.planet_index,
+				SiegeEff:     np.siege_eff,
+				Shipyards:    np.shipyards,
+				IUsNeeded:    np.IUs_needed,
+				AUsNeeded:    np.AUs_needed,
+				AutoIUs:      np.auto_IUs,
+				AutoAUs:      np.auto_AUs,
+				IUsToInstall: np.IUs_to_install,
+				AUsToInstall: np.AUs_to_install,
+				MiBase:       np.mi_base,
+				MaBase:       np.ma_base,
+				PopUnits:     np.pop_units,
+				ItemQuantity: np.item_quantity,
+				UseOnAmbush:  np.use_on_ambush,
+				Message:      np.message,
+				Special:      np.special,
+				Reserved1:    np.reserved1,
+				Reserved2:    np.reserved2,
+				Reserved4:    np.reserved4,
+				Reserved5:    np.reserved5,
+				Reserved6:    np.reserved6,
+				Padding:      np.padding,
+			})
+		}
+		for j := 0; j < sd.num_ships; j++ {
+			sh := e.ship_data[i][j]
+			sp.ShipBase = append(sp.ShipBase, dat32.Ship{
+				Name:               sh.name,
+				X:                  sh.x,
+				Y:                  sh.y,
+				Z:                  sh.z,
+				PN:                 sh.pn,
+				Status:             sh.status,
+				Type:               sh._type,
+				DestX:              sh.dest_x,
+				DestY:              sh.dest_y,
+				DestZ:              sh.dest_z,
+				JustJumped:         sh.just_jumped != FALSE,
+				ArrivedViaWormhole: sh.arrived_via_wormhole != FALSE,
+				Class:              sh.class,
+				Tonnage:            sh.tonnage,
+				ItemQuantity:       sh.item_quantity,
+				Age:                sh.age,
+				RemainingCost:      sh.remaining_cost,
+				LoadingPoint:       sh.loading_point,
+				UnloadingPoint:     sh.unloading_point,
+				Special:            sh.special,
+				Reserved1:          sh.reserved1,
+				Reserved2:          sh.reserved2,
+				Reserved3:          sh.reserved3,
+				Reserved4:          sh.reserved4,
+				Padding:            sh.padding,
+				MorePadding:        sh.more_padding,
+			})
+		}
+		species = append(species, sp)
+	}
+
+	var locations []dat32.SpLocData
+	for _, loc := range e.loc {
+		locations = append(locations, dat32.SpLocData{S: loc.s, X: loc.x, Y: loc.y, Z: loc.z})
+	}
+	return galaxy, stars, planets, species, locations
+}
+
+// SaveJSON saves all data to a single jsondb file.
+// It is the inverse of LoadJSON.
+func (e *Engine) SaveJSON(path string) error {
+	if err := e.jsondbStore().Write(path); err != nil {
+		return err
+	}
+	log.Printf("[engine] saveJSON: saved galaxy turn %6d to %q\n", e.galaxy.turn_number, path)
+
+	return nil
+}
+
+// SaveArchive saves a snapshot of all data to the turn archive, along with
+// the orders that were executed and the files (usually the reports) for the turn.
+// The orders include any that NoOrders generated.
+func (e *Engine) SaveArchive(a *archive.Archive, files map[string]string) error {
+	executed := make(map[int][]byte)
+	for i, b := range e.spec_orders {
+		if b != nil {
+			executed[i+1] = b
+		}
+	}
+	if err := a.Save(e.jsondbStore(), executed, files); err != nil {
+		return err
+	}
+	log.Printf("[engine] saveArchive: archived galaxy turn %6d with %d orders and %d files\n", e.galaxy.turn_number, len(executed), len(files))
+
+	return nil
+}
+
+// jsondbStore converts the engine data to a jsondb store.
+func (e *Engine) jsondbStore() *jsondb.Store {
+	ds := &jsondb.Store{}
+	ds.Galaxy.DNumSpecies = e.galaxy.d_num_species
+	ds.Galaxy.NumSpecies = e.galaxy.num_species
+	ds.Galaxy.Radius = e.galaxy.radius
+	ds.Galaxy.TurnNumber = e.galaxy.turn_number
+
+	for i, sd := range e.star_base {
+		star := &jsondb.StarData{
+			Id:          i,
+			Color:       sd.color,
+			HomeSystem:  sd.home_system,
+			Message:     sd.message,
+			NumPlanets:  sd.num_planets,
+			PlanetIndex: sd.planet_index,
+			Size:        sd.size,
+			Type:        sd._type,
+			WormHere:    sd.worm_here,
+			WormX:       sd.worm_x,
+			WormY:       sd.worm_y,
+			WormZ:       sd.worm_z,
+			X:           sd.x,
+			Y:           sd.y,
+			Z:           sd.z,
+		}
+		for spIndex := 0; spIndex < e.galaxy.num_species && spIndex < len(sd.visited_by); spIndex++ {
+			if sd.visited_by[spIndex] != FALSE {
+				star.VisitedBy = append(star.VisitedBy, spIndex+1)
+			}
+		}
+		ds.Stars = append(ds.Stars, star)
+	}
+
+	for i, pd := range e.planet_base {
+		ds.Planets = append(ds.Planets, &jsondb.PlanetData{
+			Id:               i,
+			Diameter:         pd.diameter,
+			EconEfficiency:   pd.econ_efficiency,
+			Gas:              pd.gas,
+			GasPercent:       pd.gas_percent,
+			Gravity:          pd.gravity,
+			MdIncrease:       pd.md_increase,
+			Message:          pd.message,
+			MiningDifficulty: pd.mining_difficulty,
+			PressureClass:    pd.pressure_class,
+			Special:          pd.special,
+			TemperatureClass: pd.temperature_class,
+		})
+	}
+
+	for i := 0; i < e.galaxy.num_species; i++ {
+		sd := e.spec_data[i]
+		if sd == nil {
+			continue
+		}
+		sp := &jsondb.SpeciesData{
+			Id:               i + 1,
+			EconUnits:        sd.econ_units,
+			FleetCost:        sd.fleet_cost,
+			FleetPercentCost: sd.fleet_percent_cost,
+			GovtName:         sd.govt_name,
+			GovtType:         sd.govt_type,
+			HpOriginalBase:   sd.hp_original_base,
+			InitTechLevel:    sd.init_tech_level,
+			Name:             sd.name,
+			NeutralGas:       sd.neutral_gas,
+			NumNamplas:       sd.num_namplas,
+			NumShips:         sd.num_ships,
+			Pn:               sd.pn,
+			PoisonGas:        sd.poison_gas,
+			RequiredGas:      sd.required_gas,
+			RequiredGasMax:   sd.required_gas_max,
+			RequiredGasMin:   sd.required_gas_min,
+			TechEps:          sd.tech_eps,
+			TechKnowledge:    sd.tech_knowledge,
+			TechLevel:        sd.tech_level,
+			X:                sd.x,
+			Y:                sd.y,
+			Z:                sd.z,
+		}
+		if sd.auto_orders != FALSE {
+			sp.AutoOrders = 1
+		}
+		for spIndex := 0; spIndex < e.galaxy.num_species; spIndex++ {
+			if sd.contact[spIndex] != FALSE {
+				sp.Contact = append(sp.Contact, spIndex+1)
+			}
+			if sd.ally[spIndex] != FALSE {
+				sp.Ally = append(sp.Ally, spIndex+1)
+			}
+			if sd.enemy[spIndex] != FALSE {
+				sp.Enemy = append(sp.Enemy, spIndex+1)
+			}
+		}
+		for j := 0; j < sd.num_namplas; j++ {
+			np := e.namp_data[i][j]
+			nampla := &jsondb.NamedPlanetData{
+				Id:           j,
+				AUsNeeded:    np.AUs_needed,
+				AUsToInstall: np.AUs_to_install,
+				AutoAUs:      np.auto_AUs,
+				AutoIUs:      np.auto_IUs,
+				ItemQuantity: append([]int{}, np.item_quantity[:]...),
+				IUsNeeded:    np.IUs_needed,
+				IUsToInstall: np.IUs_to_install,
+				Name:         np.name,
+				PlanetIndex:  np.planet_index,
+				Pn:           np.pn,
+				PopUnits:     np.pop_units,
+				MaBase:       np.ma_base,
+				Message:      np.message,
+				MiBase:       np.mi_base,
+				Shipyards:    np.shipyards,
+				SiegeEff:     np.siege_eff,
+				Status:       np.status,
+				Special:      np.special,
+				UseOnAmbush:  np.use_on_ambush,
+				X:            np.x,
+				Y:            np.y,
+				Z:            np.z,
+			}
+			if np.hidden != FALSE {
+				nampla.Hidden = 1
+			}
+			if np.hiding != FALSE {
+				nampla.Hiding = 1
+			}
+			sp.Namplas = append(sp.Namplas, nampla)
+		}
+		for j := 0; j < sd.num_ships; j++ {
+			sh := e.ship_data[i][j]
+			ship := &jsondb.ShipData{
+				Id:             j,
+				Age:            sh.age,
+				Class:          sh.class,
+				DestX:          sh.dest_x,
+				DestY:          sh.dest_y,
+				DestZ:          sh.dest_z,
+				ItemQuantity:   append([]int{}, sh.item_quantity[:]...),
+				LoadingPoint:   sh.loading_point,
+				Name:           sh.name,
+				Pn:             sh.pn,
+				RemainingCost:  sh.remaining_cost,
+				Special:        sh.special,
+				Status:         sh.status,
+				Tonnage:        sh.tonnage,
+				Type:           sh._type,
+				UnloadingPoint: sh.unloading_point,
+				X:              sh.x,
+				Y:              sh.y,
+				Z:              sh.z,
+			}
+			if sh.arrived_via_wormhole != FALSE {
+				ship.ArrivedViaWormhole = 1
+			}
+			if sh.just_jumped != FALSE {
+				ship.JustJumped = 1
+			}
+			sp.Ships = append(sp.Ships, ship)
+		}
+		ds.Species = append(ds.Species, sp)
+	}
+
+	for _, loc := range e.loc {
+		ds.Locations = append(ds.Locations, jsondb.Location{S: loc.s, X: loc.x, Y: loc.y, Z: loc.z})
+	}
+
+	return ds
+}
@@ -0,0 +1,118 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// TestBinaryRoundTrip checks that loading the binary data files and saving
+// them again writes the same bytes, including the reserved and padding fields.
+func TestBinaryRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		dir    string
+		endian binary.ByteOrder
+	}{
+		{"little-endian", binary.LittleEndian},
+		{"big-endian", binary.BigEndian},
+	} {
+		t.Run(tc.dir, func(t *testing.T) {
+			input, output := filepath.Join("testdata", tc.dir), t.TempDir()
+			e := New(false)
+			if err := e.LoadBinary(input, "", tc.endian); err != nil {
+				t.Fatal(err)
+			}
+			if err := e.SaveBinary(output, "", tc.endian); err != nil {
+				t.Fatal(err)
+			}
+			names, err := filepath.Glob(filepath.Join(input, "*.dat"))
+			if err != nil {
+				t.Fatal(err)
+			} else if len(names) == 0 {
+				t.Fatalf("%s: no data files", input)
+			}
+			for _, name := range names {
+				want, err := ioutil.ReadFile(name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, err := ioutil.ReadFile(filepath.Join(output, filepath.Base(name)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(got, want) {
+					t.Errorf("%s: saved %d bytes, want the %d bytes that were loaded", filepath.Base(name), len(got), len(want))
+				}
+			}
+		})
+	}
+}
+
+// TestSaveLoadSpeciesGap checks that a galaxy with a species that is gone
+// can be saved and loaded again without moving the species that follow it.
+func TestSaveLoadSpeciesGap(t *testing.T) {
+	for _, format := range []string{"json", "binary"} {
+		t.Run(format, func(t *testing.T) {
+			e := New(false)
+			if err := e.LoadBinary(filepath.Join("testdata", "little-endian"), "", binary.LittleEndian); err != nil {
+				t.Fatal(err)
+			}
+			e.spec_data[1], e.namp_data[1], e.ship_data[1] = nil, nil, nil
+			third := e.spec_data[2].name
+			want, err := json.Marshal(e.jsondbStore())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			loaded, dir := New(false), t.TempDir()
+			if format == "json" {
+				if err := e.SaveJSON(filepath.Join(dir, "galaxy.json")); err != nil {
+					t.Fatal(err)
+				} else if err = loaded.LoadJSON(filepath.Join(dir, "galaxy.json")); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				if err := e.SaveBinary(dir, "", binary.LittleEndian); err != nil {
+					t.Fatal(err)
+				} else if err = loaded.LoadBinary(dir, "", binary.LittleEndian); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if loaded.spec_data[1] != nil {
+				t.Errorf("species 2: got %q, want nothing", loaded.spec_data[1].name)
+			}
+			if loaded.spec_data[2] == nil || loaded.spec_data[2].name != third {
+				t.Errorf("species 3: want %q", third)
+			}
+			got, err := json.Marshal(loaded.jsondbStore())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("store changed\ngot:  %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
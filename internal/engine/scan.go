@@ -0,0 +1,99 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+import "fmt"
+
+/* This routine will write a scan of the star system at x y z to the log file. */
+func (e *Engine) scan(x, y, z int) {
+	var n, num_gases, ls_needed int
+	var home_planet *planet_data
+
+	/* Find star. */
+	var star *star_data
+	for i := 0; i < e.num_stars; i++ {
+		if e.star_base[i].x == x && e.star_base[i].y == y && e.star_base[i].z == z {
+			star = e.star_base[i]
+			break
+		}
+	}
+	if star == nil {
+		fprintf(e.log_file, "Scan Report: There is no star system at x = %d, y = %d, z = %d.\n", x, y, z)
+		return
+	}
+
+	/* Print data for star, */
+	fprintf(e.log_file, "Coordinates:\tx = %d\ty = %d\tz = %d", x, y, z)
+	fprintf(e.log_file, "\tstellar type = %s%s%s", type_char[star._type], color_char[star.color], size_char[star.size])
+	fprintf(e.log_file, "   %d planets.\n\n", star.num_planets)
+
+	if star.worm_here != FALSE {
+		fprintf(e.log_file, "This star system is the terminus of a natural wormhole.\n\n")
+	}
+
+	/* Print header. */
+	fprintf(e.log_file, "               Temp  Press Mining\n")
+	fprintf(e.log_file, "  #  Dia  Grav Class Class  Diff  LSN  Atmosphere\n")
+	fprintf(e.log_file, " ---------------------------------------------------------------------\n")
+
+	/* Check for nova. */
+	if star.num_planets == 0 {
+		fprintf(e.log_file, "\n\tThis star is a nova remnant. Any planets it may have once\n")
+		fprintf(e.log_file, "\thad have been blown away.\n\n")
+		return
+	}
+
+	/* Print data for each planet. */
+	if e.print_LSN != FALSE {
+		home_planet = e.planet_base[e.nampla_base[0].planet_index]
+	}
+	for i := 1; i <= star.num_planets; i++ {
+		planet := e.planet_base[star.planet_index+i-1]
+
+		/* Get life support tech level needed. */
+		if e.print_LSN != FALSE {
+			ls_needed = life_support_needed(e.species, home_planet, planet)
+		} else {
+			ls_needed = 99
+		}
+
+		fprintf(e.log_file, "  %d  %3d  %d.%02d  %2d    %2d    %d.%02d %4d  ", i, planet.diameter, planet.gravity/100, planet.gravity%100, planet.temperature_class, planet.pressure_class, planet.mining_difficulty/100, planet.mining_difficulty%100, ls_needed)
+
+		num_gases = 0
+		for n = 0; n < 4; n++ {
+			if planet.gas_percent[n] > 0 {
+				if num_gases > 0 {
+					fprintf(e.log_file, ",")
+				}
+				fprintf(e.log_file, "%s(%d%%)", gas_string[planet.gas[n]], planet.gas_percent[n])
+				num_gases++
+			}
+		}
+		if num_gases == 0 {
+			fprintf(e.log_file, "No atmosphere")
+		}
+
+		fprintf(e.log_file, "\n")
+	}
+
+	if star.message != 0 {
+		/* There is a message that must be logged whenever this star system is scanned. */
+		e.log_message(fmt.Sprintf("message%d.txt", star.message))
+	}
+}
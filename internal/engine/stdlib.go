@@ -72,7 +72,7 @@ func strncmp(s1, s2 []byte, n int) int {
 
 func toupper(c byte) byte {
 	if 'a' <= c && c <= 'z' {
-		return ('A' - 1) + c
+		return c - 'a' + 'A'
 	}
 	return c
 }
@@ -21,6 +21,7 @@ package engine
 import (
 	"bytes"
 	"github.com/mdhender/fhcms/cms/prng"
+	"github.com/mdhender/fhcms/internal/orders"
 )
 
 type Engine struct {
@@ -43,7 +44,6 @@ type Engine struct {
 	abbr_index                int
 	abbr_type                 int
 	correct_spelling_required int // TRUE or FALSE
-	input_abbr                []byte
 	original_name             []byte
 	ship                      *ship_data // single ship
 	ship_index                int        // zero-based
@@ -52,9 +52,12 @@ type Engine struct {
 	upper_name                []byte
 
 	// species globals
-	spec_data      []*species_data // zero-based index by species
-	spec_logs      []*bytes.Buffer // zero-based index by species
-	spec_orders    [][]byte        // zero-based index by species
+	g_spec_name    string           // set by get_species_name()
+	g_spec_number  int              // set by get_species_name(), one-based
+	spec_data      []*species_data  // zero-based index by species
+	spec_logs      []*bytes.Buffer  // zero-based index by species
+	spec_orders    [][]byte         // zero-based index by species
+	spec_parsed    []*orders.Orders // zero-based index by species, parsed from spec_orders
 	species        *species_data
 	species_index  int
 	species_number int
@@ -63,6 +66,8 @@ type Engine struct {
 	loc            []*sp_loc_data
 	locations_base []*sp_loc_data
 	num_locs       int
+	pn             int        // set by get_location()
+	star           *star_data // set by get_location()
 
 	// combat globals
 	ambush_took_place  int // TRUE or FALSE
@@ -87,6 +92,28 @@ type Engine struct {
 	transaction        [MAX_TRANSACTIONS]trans_data
 	x_attacked_y       [MAX_SPECIES][MAX_SPECIES]int
 
+	// finish globals
+	header_printed int // TRUE or FALSE
+
+	// report globals
+	report_path string // directory for spNN.rpt.tN files, empty to skip reports
+
+	// production globals
+	EU_spending_limit    int
+	balance              int
+	doing_production     int // TRUE or FALSE
+	intercept            [MAX_INTERCEPTS]intercept_data
+	last_planet_produced int // TRUE or FALSE
+	next_nampla          *nampla_data
+	next_nampla_index    int
+	num_intercepts       int
+	production_capacity  int
+	production_done      []int // zero-based index by nampla, TRUE or FALSE
+	raw_material_units   int
+	shipyard_built       int // TRUE or FALSE
+	shipyard_capacity    int
+	sp_tech_level        [6]int // working copy of species tech levels during production
+
 	// input and output hacks
 	append_log         [MAX_SPECIES]int // zero-based index by species
 	end_of_file        int
@@ -111,7 +138,10 @@ type Engine struct {
 	// miscellaneous globals
 	defaultPRNG             *prng.PRNG
 	ignore_field_distorters int
+	message_base            map[int]*bytes.Buffer // message text indexed by message number
 	orders_file             *bytes.Buffer
+	post_arrival_phase      int // TRUE or FALSE
+	print_LSN               int // TRUE or FALSE
 	prompt_gm               bool
 	test_mode               int
 	truncate_name           int
@@ -201,6 +231,7 @@ type ship_data struct {
 	unloading_point      int            /* Nampla index for planet that ship should be given orders to jump to where it will unload. Zero = none. Use 9999 for home planet. */
 	special              int            /* Different for each application. */
 	padding              [28]int        /* Use for expansion. Initialized to all zeroes. */
+	more_padding         [2]int         /* Padding to make the record the same size as C. */
 }
 
 type sp_loc_data struct {
@@ -246,14 +277,15 @@ type star_data struct {
 	home_system            int /* TRUE if this is a good potential home system. */
 	worm_here              int /* TRUE if wormhole entry/exit. */
 	worm_x, worm_y, worm_z int
-	reserved1              int   /* Reserved for future use. Zero for now. */
-	reserved2              int   /* Reserved for future use. Zero for now. */
-	planet_index           int   /* Index (starting at zero) into the file "planets.dat" of the first planet in the star system. */
-	message                int   /* Message associated with this star system, if any. */
-	visited_by             []int /* A bit is set if corresponding species has been here. */
-	reserved3              int   /* Reserved for future use. Zero for now. */
-	reserved4              int   /* Reserved for future use. Zero for now. */
-	reserved5              int   /* Reserved for future use. Zero for now. */
+	reserved1              int    /* Reserved for future use. Zero for now. */
+	reserved2              int    /* Reserved for future use. Zero for now. */
+	planet_index           int    /* Index (starting at zero) into the file "planets.dat" of the first planet in the star system. */
+	message                int    /* Message associated with this star system, if any. */
+	visited_by             []int  /* A bit is set if corresponding species has been here. */
+	reserved3              int    /* Reserved for future use. Zero for now. */
+	reserved4              int    /* Reserved for future use. Zero for now. */
+	reserved5              int    /* Reserved for future use. Zero for now. */
+	padding                [2]int /* Padding to make the record the same size as C. */
 }
 
 type action_data struct {
@@ -292,6 +324,11 @@ type battle_data struct {
 	ambush_amount             [MAX_SPECIES]int
 }
 
+type intercept_data struct {
+	x, y, z      int
+	amount_spent int
+}
+
 type trans_data struct {
 	_type            int /* Transaction type. */
 	donor, recipient int
@@ -92,7 +92,7 @@ func (e *Engine) check_population(nampla *nampla_data) bool {
 		if was_already_populated := (nampla.status & POPULATED) != 0; !was_already_populated {
 			if nampla.message != 0 {
 				// there is a message that must be logged whenever this planet becomes populated for the first time
-				filename := fmt.Sprintf("message%ld.txt", nampla.message)
+				filename := fmt.Sprintf("message%d.txt", nampla.message)
 				e.log_message(filename)
 			}
 		}
@@ -174,6 +174,33 @@ func (e *Engine) delete_ship(ship *ship_data) {
 	ship.special = 0
 }
 
+// delete_nampla will delete a named planet record.
+// like delete_ship, it's a logical delete.
+func (e *Engine) delete_nampla(nampla *nampla_data) {
+	*nampla = nampla_data{}
+	nampla.name = "Unused"
+	nampla.pn = 99 // todo: this is a flag for 'deleted'
+}
+
+// disbanded_nampla_ship returns TRUE if the ship is salvage of a colony
+// that the current species has disbanded. Unlike disbanded_ship, it uses
+// the current species' named planets rather than the combat arrays.
+func (e *Engine) disbanded_nampla_ship(ship *ship_data) int {
+	for nampla_index := 0; nampla_index < e.species.num_namplas; nampla_index++ {
+		nampla := e.nampla_base[nampla_index]
+		if nampla.x != ship.x || nampla.y != ship.y || nampla.z != ship.z || nampla.pn != ship.pn {
+			continue
+		} else if (nampla.status & DISBANDED_COLONY) == 0 {
+			continue
+		} else if ship._type != STARBASE && ship.status == IN_ORBIT {
+			continue
+		}
+		/* This ship is either on the surface of a disbanded colony or is a starbase orbiting a disbanded colony. */
+		return TRUE
+	}
+	return FALSE
+}
+
 // distorted provides the 'distorted' species number used to identify a species that uses field distortion units.
 // The input variable 'species_number' is the same number used in filename creation for the species.
 func (e *Engine) distorted(species_number int) int {
@@ -186,16 +213,52 @@ func (e *Engine) distorted(species_number int) int {
 	return (ls%5+3)*(4*i+j) + (ls%11 + 7)
 }
 
-func (e *Engine) print_mishap_chance_orders(ship *ship_data, destx, desty, destz int) {
-	if destx == -1 {
-		e.orders_file.WriteString("Mishap chance = ???")
-		return
+// life_support_needed returns the life support tech level needed by the species to live on the colony.
+func life_support_needed(species *species_data, home, colony *planet_data) int {
+	i := colony.temperature_class - home.temperature_class
+	if i < 0 {
+		i = -i
 	}
-	x, y, z := destx, desty, destz
+	ls_needed := 3 * i /* Temperature class. */
 
-	mishap_GV := e.species.tech_level[GV]
-	mishap_age := ship.age
-	mishap_chance := (100 * (((x - ship.x) * (x - ship.x)) + ((y - ship.y) * (y - ship.y)) + ((z - ship.z) * (z - ship.z)))) / mishap_GV
+	i = colony.pressure_class - home.pressure_class
+	if i < 0 {
+		i = -i
+	}
+	ls_needed += 3 * i /* Pressure class. */
+
+	/* Check gases. Assume required gas is NOT present. */
+	ls_needed += 3
+	for j := 0; j < 4; j++ { /* Check gases on planet. */
+		if colony.gas_percent[j] == 0 {
+			continue
+		}
+		for i = 0; i < 6; i++ { /* Compare with poisonous gases. */
+			if species.poison_gas[i] == colony.gas[j] {
+				ls_needed += 3
+			}
+		}
+		if colony.gas[j] == species.required_gas {
+			if colony.gas_percent[j] >= species.required_gas_min && colony.gas_percent[j] <= species.required_gas_max {
+				ls_needed -= 3
+			}
+		}
+	}
+
+	return ls_needed
+}
+
+/* This routine returns the chance, in hundredths of a percent, that a ship
+ * will have a mishap when jumping to x-y-z. The result is always between
+ * zero and 10000, inclusive. */
+func mishap_chance(ship *ship_data, x, y, z, mishap_gv, mishap_age int) int {
+	if x == ship.x && y == ship.y && z == ship.z {
+		return 0
+	} else if mishap_gv <= 0 {
+		return 10000
+	}
+
+	mishap_chance := (100 * (((x - ship.x) * (x - ship.x)) + ((y - ship.y) * (y - ship.y)) + ((z - ship.z) * (z - ship.z)))) / mishap_gv
 	if mishap_age > 0 && mishap_chance < 10000 {
 		success_chance := 10000 - mishap_chance
 		success_chance -= (2 * mishap_age * success_chance) / 100
@@ -206,6 +269,17 @@ func (e *Engine) print_mishap_chance_orders(ship *ship_data, destx, desty, destz
 		mishap_chance = 10000
 	}
 
+	return mishap_chance
+}
+
+func (e *Engine) print_mishap_chance_orders(ship *ship_data, destx, desty, destz int) {
+	if destx == -1 {
+		e.orders_file.WriteString("Mishap chance = ???")
+		return
+	}
+
+	mishap_chance := mishap_chance(ship, destx, desty, destz, e.species.tech_level[GV], ship.age)
+
 	e.orders_file.WriteString(fmt.Sprintf("mishap chance = %d.%02d%%", mishap_chance/100, mishap_chance%100))
 }
 
@@ -288,6 +362,23 @@ func (e *Engine) ship_name(ship *ship_data) string {
 	return full_ship_id + ")"
 }
 
+/* The following routine will check if coordinates x-y-z contain a star and,
+ * if so, will set the appropriate flag in the "visited_by" variable for the
+ * star. If the star exists, TRUE will be returned; otherwise, FALSE will
+ * be returned. */
+func (e *Engine) star_visited(x, y, z int) int {
+	for i := 0; i < e.num_stars; i++ {
+		star := e.star_base[i]
+		if x != star.x || y != star.y || z != star.z {
+			continue
+		}
+		/* Set the appropriate flag. visited_by is indexed by species index. */
+		star.visited_by[e.species_number-1] = TRUE
+		return TRUE
+	}
+	return FALSE
+}
+
 func (e *Engine) undistorted(distorted_species_number int) int {
 	for i := 0; i < MAX_SPECIES; i++ {
 		species_number := i + 1
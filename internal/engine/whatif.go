@@ -0,0 +1,197 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/mdhender/fhcms/internal/orders"
+	"log"
+	"strconv"
+)
+
+// WhatIf is the result of a dry run of the orders for a single species.
+type WhatIf struct {
+	Turn      int             `json:"turn"`
+	SpeciesNo int             `json:"species_no"`
+	Log       string          `json:"log"`        // the event log that the species would see
+	EconUnits int             `json:"econ_units"` // banked economic units after production
+	Ships     []*WhatIfShip   `json:"ships"`
+	Colonies  []*WhatIfColony `json:"colonies"`
+	Errors    []string        `json:"errors,omitempty"` // errors in the fleet and macro directives
+}
+
+// WhatIfShip is the projected state of a ship or starbase.
+type WhatIfShip struct {
+	Name          string         `json:"name"` // name with the class abbreviation, like "TR1 Alpha"
+	X             int            `json:"x"`
+	Y             int            `json:"y"`
+	Z             int            `json:"z"`
+	Orbit         int            `json:"orbit,omitempty"`
+	Status        string         `json:"status"`
+	JustJumped    bool           `json:"just_jumped,omitempty"`
+	RemainingCost int            `json:"remaining_cost,omitempty"` // cost to finish a ship under construction
+	Inventory     map[string]int `json:"inventory,omitempty"`      // key is the item abbreviation
+}
+
+// WhatIfColony is the projected state of a named planet.
+type WhatIfColony struct {
+	Name      string         `json:"name"`
+	X         int            `json:"x"`
+	Y         int            `json:"y"`
+	Z         int            `json:"z"`
+	Orbit     int            `json:"orbit"`
+	Shipyards int            `json:"shipyards,omitempty"`
+	Inventory map[string]int `json:"inventory,omitempty"` // key is the item abbreviation
+}
+
+// whatIfPhases are the phases run by WhatIf, in order.
+var whatIfPhases = []string{"PreDeparture", "Jump", "Production"}
+
+// WhatIf runs the pre-departure, jump, and production orders for a single
+// species and returns the log and the projected ships and colonies.
+// The orders may be text, JSON, or YAML; fleets and macros are expanded.
+// Other species and their orders are ignored, and there is no combat.
+//
+// The engine state is changed by the run, so WhatIf should be called on
+// an engine that was loaded for the dry run and is never saved.
+func (e *Engine) WhatIf(speciesNo int, b []byte) (w *WhatIf, err error) {
+	if speciesNo < 1 || speciesNo > e.galaxy.num_species || e.spec_data[speciesNo-1] == nil {
+		return nil, fmt.Errorf("whatIf: no such species %d", speciesNo)
+	}
+	spIndex := speciesNo - 1
+	if b, err = orders.ToText(b); err != nil {
+		return nil, err
+	}
+	var expandErrors []string
+	if x := orders.Expand(b); x.Expanded {
+		for _, err := range x.Errors {
+			expandErrors = append(expandErrors, err.Error())
+		}
+		b = x.Text
+	}
+
+	// run only this species, starting with an empty log
+	for i := range e.spec_orders {
+		e.spec_orders[i], e.spec_parsed[i] = nil, nil
+	}
+	e.setOrders(spIndex, fmt.Sprintf("sp%02d.ord", speciesNo), b)
+	e.spec_logs[spIndex], e.append_log[spIndex] = &bytes.Buffer{}, FALSE
+
+	phase := "Locations"
+	defer func() {
+		if r := recover(); r != nil {
+			w, err = nil, fmt.Errorf("whatIf: phase %s: %v", phase, r)
+		}
+	}()
+	e.do_locations()
+	for _, phase = range whatIfPhases {
+		log.Printf("[engine] whatIf: running %-15s for species %d\n", phase, speciesNo)
+		switch phase {
+		case "PreDeparture":
+			e.pre_departure(strconv.Itoa(speciesNo))
+		case "Jump":
+			e.jump(strconv.Itoa(speciesNo))
+		case "Production":
+			e.production(strconv.Itoa(speciesNo))
+		}
+	}
+
+	w = e.whatIfResult(spIndex)
+	w.Errors = expandErrors
+	return w, nil
+}
+
+// whatIfResult returns the log, ships, and colonies of a species.
+func (e *Engine) whatIfResult(spIndex int) *WhatIf {
+	species := e.spec_data[spIndex]
+	w := &WhatIf{
+		Turn:      e.galaxy.turn_number,
+		SpeciesNo: spIndex + 1,
+		Log:       e.spec_logs[spIndex].String(),
+		EconUnits: species.econ_units,
+		Ships:     []*WhatIfShip{},
+		Colonies:  []*WhatIfColony{},
+	}
+
+	inventory := func(quantity [MAX_ITEMS]int) map[string]int {
+		m := make(map[string]int)
+		for i, n := range quantity {
+			if n > 0 {
+				m[item_abbr[i]] = n
+			}
+		}
+		return m
+	}
+
+	e.truncate_name, e.ignore_field_distorters = TRUE, TRUE
+	for i := 0; i < species.num_ships && i < len(e.ship_data[spIndex]); i++ {
+		ship := e.ship_data[spIndex][i]
+		if ship == nil || ship.pn == 99 {
+			continue
+		}
+		w.Ships = append(w.Ships, &WhatIfShip{
+			Name:          e.ship_name(ship),
+			X:             ship.x,
+			Y:             ship.y,
+			Z:             ship.z,
+			Orbit:         ship.pn,
+			Status:        shipStatus(ship.status),
+			JustJumped:    ship.just_jumped != FALSE,
+			RemainingCost: ship.remaining_cost,
+			Inventory:     inventory(ship.item_quantity),
+		})
+	}
+	for i := 0; i < species.num_namplas && i < len(e.namp_data[spIndex]); i++ {
+		nampla := e.namp_data[spIndex][i]
+		if nampla == nil || nampla.pn == 99 {
+			continue
+		}
+		w.Colonies = append(w.Colonies, &WhatIfColony{
+			Name:      nampla.name,
+			X:         nampla.x,
+			Y:         nampla.y,
+			Z:         nampla.z,
+			Orbit:     nampla.pn,
+			Shipyards: nampla.shipyards,
+			Inventory: inventory(nampla.item_quantity),
+		})
+	}
+
+	return w
+}
+
+// shipStatus returns the name of a ship status.
+func shipStatus(status int) string {
+	switch status {
+	case UNDER_CONSTRUCTION:
+		return "under construction"
+	case ON_SURFACE:
+		return "on surface"
+	case IN_ORBIT:
+		return "in orbit"
+	case IN_DEEP_SPACE:
+		return "in deep space"
+	case JUMPED_IN_COMBAT:
+		return "jumped in combat"
+	case FORCED_JUMP:
+		return "forced jump"
+	}
+	return "unknown"
+}
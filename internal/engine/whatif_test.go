@@ -0,0 +1,101 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package engine
+
+import (
+	"fmt"
+	"github.com/mdhender/fhcms/cms/store/jsondb"
+	"strings"
+	"testing"
+)
+
+// TestWhatIf checks that WhatIf expands and runs the orders of one species,
+// reports errors in the directives, and leaves the other species alone.
+func TestWhatIf(t *testing.T) {
+	ds := testStore(t)
+
+	ship := func(sp *jsondb.SpeciesData, name string, status int) *jsondb.ShipData {
+		home := sp.Namplas[0]
+		sh := &jsondb.ShipData{
+			Name:         name,
+			Class:        TR,
+			Type:         FTL,
+			Tonnage:      1,
+			Status:       status,
+			X:            home.X,
+			Y:            home.Y,
+			Z:            home.Z,
+			Pn:           home.Pn,
+			DestX:        home.X,
+			DestY:        home.Y,
+			DestZ:        home.Z,
+			ItemQuantity: make([]int, MAX_ITEMS),
+		}
+		sp.Ships = append(sp.Ships, sh)
+		sp.NumShips = len(sp.Ships)
+		return sh
+	}
+	scout := ship(ds.Species[0], "Scout", IN_ORBIT)
+	// a ship of another species that would jump if its species were run
+	withdrawn := ship(ds.Species[1], "Runner", JUMPED_IN_COMBAT)
+	withdrawn.DestX = withdrawn.X + 1
+	if withdrawn.DestX > 2*ds.Galaxy.Radius-1 {
+		withdrawn.DestX = withdrawn.X - 1
+	}
+
+	x := scout.X + 1
+	if x > 2*ds.Galaxy.Radius-1 {
+		x = scout.X - 1
+	}
+	text := "FLEET Scouts TR1 Scout\n" +
+		"START JUMPS\n" +
+		"FOR EACH TR IN FLEET Scouts JUMP " + fmt.Sprintf("%d %d %d", x, scout.Y, scout.Z) + "\n" +
+		"CALL Missing\n" +
+		"END\n"
+
+	e := New(false)
+	if err := e.loadStore(ds); err != nil {
+		t.Fatal(err)
+	}
+	e.SetSeed(1)
+	w, err := e.WhatIf(1, []byte(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(w.Ships) != 1 {
+		t.Fatalf("ships: got %d, want 1", len(w.Ships))
+	} else if got := w.Ships[0]; got.X != x || got.Y != scout.Y || got.Z != scout.Z || got.Status != "in deep space" || !got.JustJumped {
+		t.Errorf("ship: got %+v, want a jump to %d %d %d", *got, x, scout.Y, scout.Z)
+	}
+	if !strings.Contains(w.Log, "TR1 Scout jumped to") {
+		t.Errorf("log: missing the jump:\n%s", w.Log)
+	}
+	if len(w.Errors) != 1 || !strings.Contains(w.Errors[0], `unknown macro "Missing"`) {
+		t.Errorf("errors: got %q, want the unknown macro", w.Errors)
+	}
+
+	runner := e.ship_data[1][0]
+	if runner.x != withdrawn.X || runner.status != JUMPED_IN_COMBAT {
+		t.Errorf("other species: ship moved to %d %d %d with status %d", runner.x, runner.y, runner.z, runner.status)
+	}
+	if e.spec_logs[1] != nil && e.spec_logs[1].Len() != 0 {
+		t.Errorf("other species: log: got %q, want nothing", e.spec_logs[1].String())
+	}
+}
@@ -0,0 +1,239 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+// Package galaxy creates the stars and planets for a new game.
+// The algorithms are modeled on the original NewGalaxy and MakeHomes programs.
+package galaxy
+
+import (
+	"fmt"
+	"github.com/mdhender/fhcms/cms/prng"
+	"github.com/mdhender/fhcms/cms/store/jsondb"
+)
+
+const (
+	MIN_RADIUS  = 6
+	MAX_RADIUS  = 50
+	MAX_SPECIES = 100
+	MAX_STARS   = 1000
+)
+
+// star types
+const (
+	DWARF = iota + 1
+	DEGENERATE
+	MAIN_SEQUENCE
+	GIANT
+)
+
+// star colors
+const (
+	BLUE = iota + 1
+	BLUE_WHITE
+	WHITE
+	YELLOW_WHITE
+	YELLOW
+	ORANGE
+	RED
+)
+
+// the galaxy is sized so that a game for 15 species has 90 stars in a radius of 20 parsecs
+const (
+	STANDARD_NUMBER_OF_SPECIES      = 15
+	STANDARD_NUMBER_OF_STAR_SYSTEMS = 90
+	STANDARD_GALACTIC_RADIUS        = 20
+)
+
+// Config is the settings for a new galaxy.
+type Config struct {
+	Species int    // number of species the galaxy is designed for
+	Radius  int    // radius in parsecs, or zero to size the galaxy for the number of species
+	Seed    uint64 // seed for the random number generator, must not be zero
+}
+
+// generator holds the state used while creating a galaxy.
+type generator struct {
+	prng *prng.PRNG
+	ds   *jsondb.Store
+}
+
+// New returns a new galaxy with stars, planets, wormholes, and one
+// pre-built home system for each species. There are no species yet.
+// The same settings always return the same galaxy.
+func New(cfg Config) (*jsondb.Store, error) {
+	if cfg.Species < 1 || cfg.Species > MAX_SPECIES {
+		return nil, fmt.Errorf("species must be in range 1..%d", MAX_SPECIES)
+	} else if cfg.Seed == 0 {
+		return nil, fmt.Errorf("seed must not be zero")
+	}
+
+	numStars := (cfg.Species * STANDARD_NUMBER_OF_STAR_SYSTEMS) / STANDARD_NUMBER_OF_SPECIES
+	if numStars > MAX_STARS {
+		return nil, fmt.Errorf("%d species need %d stars, the limit is %d", cfg.Species, numStars, MAX_STARS)
+	}
+	radius := cfg.Radius
+	if radius == 0 {
+		// keep the same density of stars as the standard galaxy
+		volume := numStars * STANDARD_GALACTIC_RADIUS * STANDARD_GALACTIC_RADIUS * STANDARD_GALACTIC_RADIUS / STANDARD_NUMBER_OF_STAR_SYSTEMS
+		for radius = MIN_RADIUS; radius*radius*radius < volume; radius++ {
+		}
+	}
+	if radius < MIN_RADIUS || radius > MAX_RADIUS {
+		return nil, fmt.Errorf("radius must be in range %d..%d", MIN_RADIUS, MAX_RADIUS)
+	}
+	// there is only one star in each x,y column, so the disk must have room for every star
+	columns := 0
+	for x := -radius; x < radius; x++ {
+		for y := -radius; y < radius; y++ {
+			if x*x+y*y < radius*radius {
+				columns++
+			}
+		}
+	}
+	if numStars > columns/2 {
+		return nil, fmt.Errorf("radius %d is too small for %d stars", radius, numStars)
+	}
+
+	g := &generator{prng: prng.New(cfg.Seed), ds: &jsondb.Store{}}
+	g.ds.Galaxy.DNumSpecies = cfg.Species
+	g.ds.Galaxy.Radius = radius
+	g.ds.Locations = []jsondb.Location{}
+	g.ds.Species = []*jsondb.SpeciesData{}
+
+	g.placeStars(numStars, radius)
+	homes := g.homeSystems(cfg.Species, radius)
+	for _, star := range g.ds.Stars {
+		g.generatePlanets(star, homes[star.Id])
+	}
+	g.wormholes(radius)
+
+	return g.ds, nil
+}
+
+// rnd returns a random number in the range 1..max.
+func (g *generator) rnd(max int) int {
+	return g.prng.Roll(max)
+}
+
+// placeStars places the stars at random inside a sphere, with no more than
+// one star in any x,y column. Coordinates range from 0 to twice the radius.
+func (g *generator) placeStars(numStars, radius int) {
+	diameter := 2 * radius
+	used := make(map[[2]int]bool)
+	for len(g.ds.Stars) < numStars {
+		x, y, z := g.rnd(diameter)-1, g.rnd(diameter)-1, g.rnd(diameter)-1
+		dx, dy, dz := x-radius, y-radius, z-radius
+		if dx*dx+dy*dy+dz*dz >= radius*radius || used[[2]int{x, y}] {
+			continue
+		}
+		used[[2]int{x, y}] = true
+
+		star := &jsondb.StarData{Id: len(g.ds.Stars), X: x, Y: y, Z: z}
+		if star.Type = g.rnd(GIANT + 6); star.Type > GIANT {
+			star.Type = MAIN_SEQUENCE
+		}
+		star.Color = g.rnd(RED)
+		star.Size = g.rnd(10) - 1
+
+		// hotter and larger stars have more planets
+		n := -2 + g.rnd(3) + g.rnd(3) + g.rnd(3)
+		if star.Color < YELLOW {
+			n += g.rnd(2)
+		}
+		switch star.Type {
+		case DWARF:
+			n--
+		case GIANT:
+			n += g.rnd(3)
+		}
+		if n < 1 {
+			n = 1
+		} else if n > 9 {
+			n = 9
+		}
+		star.NumPlanets = n
+		g.ds.Stars = append(g.ds.Stars, star)
+	}
+}
+
+// homeSystems picks a home system for each species, keeping them as far
+// apart as possible. Home systems are main sequence stars with at least
+// three planets. Returns a map of star id to the orbit of the home planet.
+func (g *generator) homeSystems(numSpecies, radius int) map[int]int {
+	homes := make(map[int]int)
+	var picked []*jsondb.StarData
+	// try every star in random order, relaxing the distance until there are enough
+	for minDistance := radius; len(picked) < numSpecies; minDistance-- {
+		order := make([]*jsondb.StarData, len(g.ds.Stars))
+		copy(order, g.ds.Stars)
+		for i := len(order) - 1; i > 0; i-- {
+			j := g.rnd(i+1) - 1
+			order[i], order[j] = order[j], order[i]
+		}
+		for _, star := range order {
+			if len(picked) == numSpecies {
+				break
+			} else if _, ok := homes[star.Id]; ok {
+				continue
+			}
+			ok := true
+			for _, home := range picked {
+				if distanceSquared(star, home) < minDistance*minDistance {
+					ok = false
+					break
+				}
+			}
+			if ok {
+				star.HomeSystem, star.Type = 1, MAIN_SEQUENCE
+				if star.NumPlanets < 3 {
+					star.NumPlanets = 3 + g.rnd(4) - 1
+				}
+				homes[star.Id] = 3
+				picked = append(picked, star)
+			}
+		}
+	}
+	return homes
+}
+
+// wormholes connects pairs of stars. Home systems never have a wormhole,
+// and the ends of a wormhole are at least half the radius apart.
+func (g *generator) wormholes(radius int) {
+	minDistance := radius / 2
+	for _, star := range g.ds.Stars {
+		if star.HomeSystem != 0 || star.WormHere != 0 || g.rnd(100) <= 92 {
+			continue
+		}
+		// try a few stars for the other end
+		for try := 0; try < 20; try++ {
+			other := g.ds.Stars[g.rnd(len(g.ds.Stars))-1]
+			if other == star || other.HomeSystem != 0 || other.WormHere != 0 || distanceSquared(star, other) < minDistance*minDistance {
+				continue
+			}
+			star.WormHere, star.WormX, star.WormY, star.WormZ = 1, other.X, other.Y, other.Z
+			other.WormHere, other.WormX, other.WormY, other.WormZ = 1, star.X, star.Y, star.Z
+			break
+		}
+	}
+}
+
+// distanceSquared returns the square of the distance between two stars.
+func distanceSquared(a, b *jsondb.StarData) int {
+	dx, dy, dz := a.X-b.X, a.Y-b.Y, a.Z-b.Z
+	return dx*dx + dy*dy + dz*dz
+}
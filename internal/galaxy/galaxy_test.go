@@ -0,0 +1,113 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package galaxy
+
+import (
+	"encoding/json"
+	"github.com/mdhender/fhcms/cms/store/jsondb"
+	"testing"
+)
+
+// TestNew checks that a galaxy is the same for the same settings, that every
+// star is inside the sphere, that wormholes come in pairs, and that every
+// home system has an ideal home planet.
+func TestNew(t *testing.T) {
+	cfg := Config{Species: 15, Seed: 0x1234}
+	ds, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, b := marshal(t, ds), marshal(t, again); a != b {
+		t.Errorf("same seed: galaxies differ")
+	}
+	if other, err := New(Config{Species: 15, Seed: 0x4321}); err != nil {
+		t.Fatal(err)
+	} else if marshal(t, ds) == marshal(t, other) {
+		t.Errorf("different seed: galaxies are the same")
+	}
+
+	radius := ds.Galaxy.Radius
+	if radius != STANDARD_GALACTIC_RADIUS {
+		t.Errorf("radius: got %d, want %d", radius, STANDARD_GALACTIC_RADIUS)
+	}
+	if len(ds.Stars) != STANDARD_NUMBER_OF_STAR_SYSTEMS {
+		t.Errorf("stars: got %d, want %d", len(ds.Stars), STANDARD_NUMBER_OF_STAR_SYSTEMS)
+	}
+
+	homeSystems, wormholes := 0, 0
+	for _, star := range ds.Stars {
+		dx, dy, dz := star.X-radius, star.Y-radius, star.Z-radius
+		if dx*dx+dy*dy+dz*dz >= radius*radius {
+			t.Errorf("star %d at %d %d %d is outside the radius", star.Id, star.X, star.Y, star.Z)
+		}
+		if star.PlanetIndex < 0 || star.PlanetIndex+star.NumPlanets > len(ds.Planets) {
+			t.Errorf("star %d: planets %d..%d are not in the store", star.Id, star.PlanetIndex, star.PlanetIndex+star.NumPlanets-1)
+			continue
+		}
+
+		if star.WormHere != 0 {
+			wormholes++
+			var other *jsondb.StarData
+			for _, s := range ds.Stars {
+				if s.X == star.WormX && s.Y == star.WormY && s.Z == star.WormZ {
+					other = s
+				}
+			}
+			if other == nil || other == star {
+				t.Errorf("star %d: wormhole leads to %d %d %d, which is not another star", star.Id, star.WormX, star.WormY, star.WormZ)
+			} else if other.WormHere == 0 || other.WormX != star.X || other.WormY != star.Y || other.WormZ != star.Z {
+				t.Errorf("star %d: wormhole leads to star %d, which does not lead back", star.Id, other.Id)
+			} else if star.HomeSystem != 0 {
+				t.Errorf("star %d: home system has a wormhole", star.Id)
+			}
+		}
+
+		if star.HomeSystem != 0 {
+			homeSystems++
+			ideal := 0
+			for _, planet := range ds.Planets[star.PlanetIndex : star.PlanetIndex+star.NumPlanets] {
+				if planet.Special == IDEAL_HOME_PLANET {
+					ideal++
+				}
+			}
+			if ideal != 1 {
+				t.Errorf("star %d: home system has %d ideal home planets, want 1", star.Id, ideal)
+			}
+		}
+	}
+	if homeSystems != cfg.Species {
+		t.Errorf("home systems: got %d, want %d", homeSystems, cfg.Species)
+	}
+	if wormholes == 0 || wormholes%2 != 0 {
+		t.Errorf("wormholes: got %d ends, want an even number greater than 0", wormholes)
+	}
+}
+
+func marshal(t *testing.T, v interface{}) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
@@ -0,0 +1,240 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package galaxy
+
+import "github.com/mdhender/fhcms/cms/store/jsondb"
+
+// gases in planetary atmospheres, listed from the coldest to the hottest
+const (
+	H2  = 1  // Hydrogen
+	CH4 = 2  // Methane
+	HE  = 3  // Helium
+	NH3 = 4  // Ammonia
+	N2  = 5  // Nitrogen
+	CO2 = 6  // Carbon Dioxide
+	O2  = 7  // Oxygen
+	HCL = 8  // Hydrogen Chloride
+	CL2 = 9  // Chlorine
+	F2  = 10 // Fluorine
+	H2O = 11 // Steam
+	SO2 = 12 // Sulfur Dioxide
+	H2S = 13 // Hydrogen Sulfide
+)
+
+// values for PlanetData.Special
+const (
+	IDEAL_HOME_PLANET = 1
+)
+
+// planets start out like the planets of Sol, in thousands of kilometers and temperature class
+var startDiameter = [10]int{0, 5, 12, 13, 7, 20, 143, 120, 51, 49}
+var startTemperatureClass = [10]int{0, 29, 27, 11, 9, 8, 6, 5, 5, 3}
+
+// generatePlanets adds the planets for a star. If homeOrbit is not zero,
+// the planet in that orbit is made into an ideal home planet.
+func (g *generator) generatePlanets(star *jsondb.StarData, homeOrbit int) {
+	star.PlanetIndex = len(g.ds.Planets)
+	for orbit := 1; orbit <= star.NumPlanets; orbit++ {
+		planet := &jsondb.PlanetData{Id: len(g.ds.Planets)}
+		if orbit == homeOrbit {
+			g.homePlanet(planet)
+		} else {
+			g.planet(planet, star, orbit)
+		}
+		g.ds.Planets = append(g.ds.Planets, planet)
+	}
+}
+
+// vary adds or subtracts a random amount from a value the given number of times.
+// The amount is up to a quarter of the value, but never less than two.
+func (g *generator) vary(value, times int) int {
+	dieSize := value / 4
+	if dieSize < 2 {
+		dieSize = 2
+	}
+	for i := 0; i < times; i++ {
+		if g.rnd(100) > 50 {
+			value += g.rnd(dieSize)
+		} else {
+			value -= g.rnd(dieSize)
+		}
+	}
+	return value
+}
+
+// planet fills in a planet for an orbit around a star.
+func (g *generator) planet(planet *jsondb.PlanetData, star *jsondb.StarData, orbit int) {
+	// diameter is in thousands of kilometers, and anything over 40 is a gas giant
+	diameter := g.vary(startDiameter[orbit], 4)
+	for diameter < 3 {
+		diameter += g.rnd(4)
+	}
+	gasGiant := diameter > 40
+
+	// gravity is a multiple of Earth gravity, times 100, and the factor 72 makes it 100 for the Earth
+	var density int
+	if gasGiant {
+		density = 58 + g.rnd(56) + g.rnd(56)
+	} else {
+		density = 368 + g.rnd(101) + g.rnd(101)
+	}
+	gravity := density * diameter / 72
+
+	// hotter stars have hotter planets
+	tc := g.vary(startTemperatureClass[orbit]+(YELLOW-star.Color), g.rnd(3)+g.rnd(3)+g.rnd(3))
+	if gasGiant {
+		for tc < 3 {
+			tc += g.rnd(2)
+		}
+		for tc > 7 {
+			tc -= g.rnd(2)
+		}
+	} else {
+		for tc < 1 {
+			tc += g.rnd(3)
+		}
+		for tc > 30 {
+			tc -= g.rnd(3)
+		}
+	}
+
+	// pressure depends mostly on gravity
+	pc := g.vary(gravity/10, g.rnd(3)+g.rnd(3)+g.rnd(3))
+	if gasGiant {
+		for pc < 11 {
+			pc += g.rnd(3)
+		}
+		for pc > 29 {
+			pc -= g.rnd(3)
+		}
+	} else {
+		for pc < 0 {
+			pc += g.rnd(3)
+		}
+		for pc > 12 {
+			pc -= g.rnd(3)
+		}
+	}
+	if gravity < 10 || tc < 2 || tc > 27 {
+		// too light or too cold or hot to hold an atmosphere
+		pc = 0
+	}
+
+	planet.Diameter = diameter
+	planet.Gravity = gravity
+	planet.TemperatureClass = tc
+	planet.PressureClass = pc
+	if pc != 0 {
+		g.atmosphere(planet)
+	}
+	planet.MiningDifficulty = g.miningDifficulty(diameter)
+}
+
+// atmosphere picks up to four gases for a planet, starting from the
+// coldest gas that suits the temperature, and sets their percentages.
+func (g *generator) atmosphere(planet *jsondb.PlanetData) {
+	firstGas := 100 * planet.TemperatureClass / 225
+	if firstGas < 1 {
+		firstGas = 1
+	} else if firstGas > 9 {
+		firstGas = 9
+	}
+	wanted := (g.rnd(4) + g.rnd(4)) / 2
+	var gases, amounts []int
+	for tries := 0; len(gases) < wanted && tries < 10; tries++ {
+		for gas := firstGas; gas <= firstGas+4 && len(gases) < wanted; gas++ {
+			if hasGas(gases, gas) || g.rnd(3) != 3 {
+				continue
+			}
+			amount := g.rnd(100)
+			if gas == HE {
+				if planet.TemperatureClass > 5 {
+					continue // too hot for helium
+				}
+				amount = g.rnd(20)
+			}
+			gases, amounts = append(gases, gas), append(amounts, amount)
+		}
+	}
+	g.setGases(planet, gases, amounts)
+}
+
+// setGases converts the amounts of the gases to percentages.
+// Rounding leftovers go to the first gas.
+func (g *generator) setGases(planet *jsondb.PlanetData, gases, amounts []int) {
+	total := 0
+	for _, n := range amounts {
+		total += n
+	}
+	percent := 0
+	for i := range gases {
+		planet.Gas[i] = gases[i]
+		planet.GasPercent[i] = 100 * amounts[i] / total
+		percent += planet.GasPercent[i]
+	}
+	if len(gases) != 0 {
+		planet.GasPercent[0] += 100 - percent
+	}
+}
+
+// miningDifficulty returns the mining difficulty, times 100, which grows
+// with the diameter, with the occasional surprise.
+func (g *generator) miningDifficulty(diameter int) int {
+	md := 0
+	for md < 40 || md > 500 {
+		md = (g.rnd(3)+g.rnd(3)+g.rnd(3)-g.rnd(4))*g.rnd(diameter) + g.rnd(30) + g.rnd(30)
+	}
+	return md * 11 / 5
+}
+
+// homePlanet fills in an Earth-like planet that is ideal for a new species.
+func (g *generator) homePlanet(planet *jsondb.PlanetData) {
+	planet.Diameter = 11 + g.rnd(3)
+	planet.Gravity = (368 + g.rnd(101) + g.rnd(101)) * planet.Diameter / 72
+	planet.TemperatureClass = 10 + g.rnd(3)
+	planet.PressureClass = 8 + g.rnd(5)
+	planet.Special = IDEAL_HOME_PLANET
+	planet.EconEfficiency = 100
+
+	// nitrogen and oxygen, with a little of a third gas
+	gases, amounts := []int{N2, O2}, []int{60 + g.rnd(20), 15 + g.rnd(10)}
+	switch g.rnd(3) {
+	case 1:
+		gases, amounts = append(gases, CO2), append(amounts, g.rnd(5))
+	case 2:
+		gases, amounts = append(gases, H2O), append(amounts, g.rnd(5))
+	}
+	g.setGases(planet, gases, amounts)
+
+	// home planets are easy to mine
+	planet.MiningDifficulty = 0
+	for planet.MiningDifficulty < 80 || planet.MiningDifficulty > 150 {
+		planet.MiningDifficulty = g.miningDifficulty(planet.Diameter)
+	}
+}
+
+// hasGas returns true if the gas is in the list.
+func hasGas(gases []int, gas int) bool {
+	for _, n := range gases {
+		if n == gas {
+			return true
+		}
+	}
+	return false
+}
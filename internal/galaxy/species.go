@@ -0,0 +1,243 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package galaxy
+
+import (
+	"fmt"
+	"github.com/mdhender/fhcms/cms/prng"
+	"github.com/mdhender/fhcms/cms/store/jsondb"
+	"sort"
+	"strings"
+)
+
+// tech levels
+const (
+	MI = iota // Mining
+	MA        // Manufacturing
+	ML        // Military
+	GV        // Gravitics
+	LS        // Life Support
+	BI        // Biology
+)
+
+const (
+	MAX_ITEMS        = 38
+	MAX_NAME_LENGTH  = 31
+	HP_AVAILABLE_POP = 1500
+	// HOME_ECON_BASE is the total of the mining and manufacturing bases
+	// of a new home planet, in tenths.
+	HOME_ECON_BASE = 500
+	// STARTING_TECH_POINTS is the number of points a new player allocates
+	// to the Military, Gravitics, Life Support, and Biology tech levels.
+	STARTING_TECH_POINTS = 15
+	// STARTING_TECH_LEVEL is the Mining and Manufacturing tech level of every new species.
+	STARTING_TECH_LEVEL = 10
+)
+
+// SpeciesConfig is the set-up form for a new player.
+type SpeciesConfig struct {
+	Name       string // name of the species
+	GovtName   string // name of the government
+	GovtType   string // type of the government
+	HomePlanet string // name of the home planet
+	ML, GV     int    // Military and Gravitics tech levels
+	LS, BI     int    // Life Support and Biology tech levels
+	Seed       uint64 // seed for the random number generator, must not be zero
+}
+
+// AddSpecies adds a new species to the galaxy, living on the home planet of
+// a home system that no other species has claimed. The species breathes the
+// oxygen of its home planet and tolerates the other gases there. The rest of
+// the neutral gases are picked at random and the gases left over are poison.
+// It returns an error if the set-up form breaks the rules.
+func AddSpecies(ds *jsondb.Store, cfg SpeciesConfig) (*jsondb.SpeciesData, error) {
+	if cfg.Seed == 0 {
+		return nil, fmt.Errorf("seed must not be zero")
+	} else if ds.Galaxy.NumSpecies != len(ds.Species) {
+		return nil, fmt.Errorf("galaxy has %d species but store has %d", ds.Galaxy.NumSpecies, len(ds.Species))
+	} else if ds.Galaxy.NumSpecies >= MAX_SPECIES {
+		return nil, fmt.Errorf("galaxy already has %d species", ds.Galaxy.NumSpecies)
+	}
+	for _, field := range []struct{ label, value string }{
+		{"species name", cfg.Name},
+		{"government name", cfg.GovtName},
+		{"government type", cfg.GovtType},
+		{"home planet name", cfg.HomePlanet},
+	} {
+		if err := validName(field.label, field.value); err != nil {
+			return nil, err
+		}
+	}
+	for _, sp := range ds.Species {
+		if strings.EqualFold(sp.Name, cfg.Name) {
+			return nil, fmt.Errorf("species name %q is already used by species %d", cfg.Name, sp.Id)
+		}
+	}
+	for _, level := range []int{cfg.ML, cfg.GV, cfg.LS, cfg.BI} {
+		if level < 0 {
+			return nil, fmt.Errorf("tech levels must not be negative")
+		}
+	}
+	if total := cfg.ML + cfg.GV + cfg.LS + cfg.BI; total != STARTING_TECH_POINTS {
+		return nil, fmt.Errorf("tech levels ML, GV, LS, and BI must add up to %d, not %d", STARTING_TECH_POINTS, total)
+	}
+
+	g := &generator{prng: prng.New(cfg.Seed), ds: ds}
+	star, pn := g.unusedHomeSystem()
+	if star == nil {
+		return nil, fmt.Errorf("there are no unused home systems")
+	}
+	planetIndex := star.PlanetIndex + pn - 1
+	home := ds.Planets[planetIndex]
+	oxygen := 0
+	for i, gas := range home.Gas {
+		if gas == O2 {
+			oxygen = home.GasPercent[i]
+		}
+	}
+	if oxygen == 0 {
+		return nil, fmt.Errorf("home planet at %d %d %d %d has no oxygen", star.X, star.Y, star.Z, pn)
+	}
+
+	speciesNo := ds.Galaxy.NumSpecies + 1
+	sp := &jsondb.SpeciesData{
+		Id:       speciesNo,
+		Name:     cfg.Name,
+		GovtName: cfg.GovtName,
+		GovtType: cfg.GovtType,
+		X:        star.X,
+		Y:        star.Y,
+		Z:        star.Z,
+		Pn:       pn,
+	}
+	sp.TechLevel[MI], sp.TechLevel[MA] = STARTING_TECH_LEVEL, STARTING_TECH_LEVEL
+	sp.TechLevel[ML], sp.TechLevel[GV], sp.TechLevel[LS], sp.TechLevel[BI] = cfg.ML, cfg.GV, cfg.LS, cfg.BI
+	sp.InitTechLevel, sp.TechKnowledge = sp.TechLevel, sp.TechLevel
+	g.gases(sp, home, oxygen)
+
+	// the bases are balanced so that the raw materials mined each turn are
+	// exactly what the factories can use
+	maBase := (HOME_ECON_BASE * 100) / (100 + home.MiningDifficulty)
+	sp.Namplas = []*jsondb.NamedPlanetData{{
+		Name:         cfg.HomePlanet,
+		X:            star.X,
+		Y:            star.Y,
+		Z:            star.Z,
+		Pn:           pn,
+		PlanetIndex:  planetIndex,
+		Status:       jsondb.HOME_PLANET | jsondb.POPULATED,
+		PopUnits:     HP_AVAILABLE_POP,
+		MiBase:       HOME_ECON_BASE - maBase,
+		MaBase:       maBase,
+		Shipyards:    1,
+		ItemQuantity: make([]int, MAX_ITEMS, MAX_ITEMS),
+	}}
+	sp.NumNamplas = len(sp.Namplas)
+	sp.Ships = []*jsondb.ShipData{}
+
+	star.VisitedBy = append(star.VisitedBy, speciesNo)
+	ds.Species = append(ds.Species, sp)
+	ds.Galaxy.NumSpecies = len(ds.Species)
+	ds.SetLocations()
+
+	return sp, nil
+}
+
+// validName returns an error if the name breaks the rules for names.
+func validName(label, name string) error {
+	if strings.TrimSpace(name) != name {
+		return fmt.Errorf("%s %q must not start or end with spaces", label, name)
+	} else if name == "" {
+		return fmt.Errorf("%s is required", label)
+	} else if len(name) > MAX_NAME_LENGTH {
+		return fmt.Errorf("%s %q is longer than %d characters", label, name, MAX_NAME_LENGTH)
+	} else if strings.ContainsAny(name, ",;") {
+		return fmt.Errorf("%s %q must not contain commas or semi-colons", label, name)
+	}
+	for _, ch := range name {
+		if ch < ' ' || ch > '~' {
+			return fmt.Errorf("%s %q must contain only printable characters", label, name)
+		}
+	}
+	return nil
+}
+
+// unusedHomeSystem returns a random home system that no species has
+// settled, along with the orbit of its home planet.
+func (g *generator) unusedHomeSystem() (*jsondb.StarData, int) {
+	var unused []*jsondb.StarData
+	for _, star := range g.ds.Stars {
+		if star.HomeSystem == 0 {
+			continue
+		}
+		used := false
+		for _, sp := range g.ds.Species {
+			for _, nampla := range sp.Namplas {
+				used = used || (nampla.X == star.X && nampla.Y == star.Y && nampla.Z == star.Z)
+			}
+		}
+		if !used {
+			unused = append(unused, star)
+		}
+	}
+	if len(unused) == 0 {
+		return nil, 0
+	}
+	star := unused[g.rnd(len(unused))-1]
+	for pn := 1; pn <= star.NumPlanets; pn++ {
+		if g.ds.Planets[star.PlanetIndex+pn-1].Special == IDEAL_HOME_PLANET {
+			return star, pn
+		}
+	}
+	return nil, 0
+}
+
+// gases sets the required, neutral, and poison gases for a species.
+// Oxygen is required and every other gas on the home planet is neutral.
+// Random gases are added until there are six neutral gases.
+func (g *generator) gases(sp *jsondb.SpeciesData, home *jsondb.PlanetData, oxygen int) {
+	sp.RequiredGas = O2
+	if sp.RequiredGasMin = oxygen / 2; sp.RequiredGasMin < 1 {
+		sp.RequiredGasMin = 1
+	}
+	if sp.RequiredGasMax = oxygen * 2; sp.RequiredGasMax > 100 {
+		sp.RequiredGasMax = 100
+	}
+
+	var neutral []int
+	for _, gas := range home.Gas {
+		if gas != 0 && gas != O2 {
+			neutral = append(neutral, gas)
+		}
+	}
+	for len(neutral) < len(sp.NeutralGas) {
+		if gas := g.rnd(H2S); gas != O2 && !hasGas(neutral, gas) {
+			neutral = append(neutral, gas)
+		}
+	}
+	sort.Ints(neutral)
+	copy(sp.NeutralGas[:], neutral)
+
+	n := 0
+	for gas := H2; gas <= H2S; gas++ {
+		if gas != O2 && !hasGas(neutral, gas) {
+			sp.PoisonGas[n], n = gas, n+1
+		}
+	}
+}
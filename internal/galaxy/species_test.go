@@ -0,0 +1,146 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package galaxy
+
+import (
+	"strings"
+	"testing"
+)
+
+// testConfig returns a valid set-up form.
+func testConfig(name string) SpeciesConfig {
+	return SpeciesConfig{
+		Name:       name,
+		GovtName:   name + " Government",
+		GovtType:   "Monarchy",
+		HomePlanet: name + " Prime",
+		ML:         4, GV: 4, LS: 4, BI: 3,
+		Seed: 1,
+	}
+}
+
+// TestAddSpeciesRejects checks that AddSpecies rejects set-up forms that break the rules.
+func TestAddSpeciesRejects(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		change func(cfg *SpeciesConfig)
+		want   string
+	}{
+		{"tech points", func(cfg *SpeciesConfig) { cfg.BI = 4 }, "must add up to 15, not 16"},
+		{"negative level", func(cfg *SpeciesConfig) { cfg.ML, cfg.GV = -1, 9 }, "tech levels must not be negative"},
+		{"comma in name", func(cfg *SpeciesConfig) { cfg.Name = "Alpha, Beta" }, "must not contain commas or semi-colons"},
+		{"semi-colon in name", func(cfg *SpeciesConfig) { cfg.HomePlanet = "Alpha;Prime" }, "must not contain commas or semi-colons"},
+		{"long name", func(cfg *SpeciesConfig) { cfg.GovtName = strings.Repeat("x", MAX_NAME_LENGTH+1) }, "is longer than 31 characters"},
+		{"duplicate name", func(cfg *SpeciesConfig) { cfg.Name = "ALPHA" }, `species name "ALPHA" is already used by species 1`},
+		{"zero seed", func(cfg *SpeciesConfig) { cfg.Seed = 0 }, "seed must not be zero"},
+		{"no unused home system", func(cfg *SpeciesConfig) { cfg.Name = "Charlie" }, "there are no unused home systems"},
+	} {
+		ds, err := New(Config{Species: 2, Seed: 0x1234})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := AddSpecies(ds, testConfig("Alpha")); err != nil {
+			t.Fatal(err)
+		}
+		if tc.name == "no unused home system" {
+			if _, err := AddSpecies(ds, testConfig("Bravo")); err != nil {
+				t.Fatal(err)
+			}
+		}
+		cfg := testConfig("Bravo")
+		tc.change(&cfg)
+		numSpecies := len(ds.Species)
+		if _, err := AddSpecies(ds, cfg); err == nil {
+			t.Errorf("%s: got no error, want %q", tc.name, tc.want)
+		} else if !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: got %q, want %q", tc.name, err, tc.want)
+		}
+		if len(ds.Species) != numSpecies || ds.Galaxy.NumSpecies != numSpecies {
+			t.Errorf("%s: species added after an error", tc.name)
+		}
+	}
+}
+
+// TestAddSpecies checks the home planet and the gases of a new species.
+func TestAddSpecies(t *testing.T) {
+	ds, err := New(Config{Species: 2, Seed: 0x1234})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp, err := AddSpecies(ds, testConfig("Alpha"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sp.Id != 1 || ds.Galaxy.NumSpecies != 1 || len(ds.Species) != 1 {
+		t.Errorf("species number: got %d of %d", sp.Id, ds.Galaxy.NumSpecies)
+	}
+	if sp.TechLevel != [6]int{10, 10, 4, 4, 4, 3} || sp.InitTechLevel != sp.TechLevel {
+		t.Errorf("tech levels: got %v, init %v", sp.TechLevel, sp.InitTechLevel)
+	}
+
+	if len(sp.Namplas) != 1 {
+		t.Fatalf("named planets: got %d, want 1", len(sp.Namplas))
+	}
+	np := sp.Namplas[0]
+	if total := np.MiBase + np.MaBase; total != HOME_ECON_BASE {
+		t.Errorf("econ base: got %d + %d = %d, want %d", np.MiBase, np.MaBase, total, HOME_ECON_BASE)
+	}
+	star := ds.Stars[0]
+	for _, s := range ds.Stars {
+		if s.X == np.X && s.Y == np.Y && s.Z == np.Z {
+			star = s
+		}
+	}
+	if star.HomeSystem == 0 || np.PlanetIndex != star.PlanetIndex+np.Pn-1 {
+		t.Errorf("home planet %d %d %d %d is not in a home system", np.X, np.Y, np.Z, np.Pn)
+	}
+
+	home := ds.Planets[np.PlanetIndex]
+	oxygen := 0
+	for i, gas := range home.Gas {
+		if gas == O2 {
+			oxygen = home.GasPercent[i]
+		}
+	}
+	if sp.RequiredGas != O2 || sp.RequiredGasMin > oxygen || oxygen > sp.RequiredGasMax {
+		t.Errorf("required gas: got %d %d..%d, want O2 around %d%%", sp.RequiredGas, sp.RequiredGasMin, sp.RequiredGasMax, oxygen)
+	}
+	// every gas other than oxygen is either neutral or poison, and the gases of the home planet are neutral
+	kind := make(map[int]string)
+	for _, gas := range sp.NeutralGas {
+		kind[gas] += "neutral"
+	}
+	for _, gas := range sp.PoisonGas {
+		kind[gas] += "poison"
+	}
+	for gas := H2; gas <= H2S; gas++ {
+		if gas == O2 {
+			if kind[gas] != "" {
+				t.Errorf("gas %d: got %q, want neither neutral nor poison", gas, kind[gas])
+			}
+		} else if kind[gas] != "neutral" && kind[gas] != "poison" {
+			t.Errorf("gas %d: got %q, want neutral or poison", gas, kind[gas])
+		}
+	}
+	for _, gas := range home.Gas {
+		if gas != 0 && gas != O2 && kind[gas] != "neutral" {
+			t.Errorf("home planet gas %d: got %q, want neutral", gas, kind[gas])
+		}
+	}
+}
@@ -0,0 +1,337 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package orders
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// abbreviation types returned by ClassAbbr
+const (
+	UNKNOWN_ID = iota
+	TECH_ID
+	ITEM_CLASS
+	SHIP_CLASS
+	PLANET_ID
+	SPECIES_ID
+)
+
+var techAbbr = []string{"MI", "MA", "ML", "GV", "LS", "BI"}
+
+var itemAbbr = []string{
+	"RM", "PD", "SU", "DR", "CU", "IU", "AU", "FS",
+	"JP", "FM", "FJ", "GT", "FD", "TP", "GW", "SG1",
+	"SG2", "SG3", "SG4", "SG5", "SG6", "SG7", "SG8", "SG9",
+	"GU1", "GU2", "GU3", "GU4", "GU5", "GU6", "GU7", "GU8",
+	"GU9", "X1", "X2", "X3", "X4", "X5"}
+
+var shipAbbr = []string{
+	"PB", "CT", "ES", "FF", "DD", "CL", "CS",
+	"CA", "CC", "BC", "BS", "DN", "SD", "BM",
+	"BW", "BR", "BA", "TR"}
+
+var shipTonnage = []int{
+	1, 2, 5, 10, 15, 20, 25,
+	30, 35, 40, 45, 50, 55, 60,
+	65, 70, 1, 1}
+
+// args is the unparsed remainder of an order.
+// The methods mirror the get routines in the engine's parser.
+type args struct {
+	b []byte
+}
+
+// empty returns true if there are no more arguments.
+func (a *args) empty() bool {
+	a.skipWhitespace()
+	return len(a.b) == 0
+}
+
+// skipWhitespace skips spaces, tabs, commas, and carriage returns.
+func (a *args) skipWhitespace() {
+	a.b = SkipWhitespace(a.b)
+}
+
+// value reads an integer.
+// Returns false, without consuming any input, if there is no integer.
+func (a *args) value() (int, bool) {
+	n, rest, ok := GetValue(a.b)
+	if ok {
+		a.b = rest
+	}
+	return n, ok
+}
+
+// SkipWhitespace returns b without its leading spaces, tabs, commas, and
+// carriage returns.
+// This is how the engine skips between arguments, and it uses this function to do so.
+func SkipWhitespace(b []byte) []byte {
+	for len(b) != 0 && (b[0] == ' ' || b[0] == '\t' || b[0] == ',' || b[0] == '\r') {
+		b = b[1:]
+	}
+	return b
+}
+
+// GetValue reads the integer, with an optional sign, at the start of b after
+// skipping whitespace, and returns it along with the remainder of b.
+// Returns false if there is no integer or it does not fit in an int.
+// This is how the engine reads values, and it uses this function to do so.
+func GetValue(b []byte) (int, []byte, bool) {
+	b = SkipWhitespace(b)
+	i := 0
+	if i < len(b) && (b[i] == '+' || b[i] == '-') {
+		i++
+	}
+	digits := i
+	for i < len(b) && isdigit(b[i]) {
+		i++
+	}
+	if i == digits {
+		return 0, b, false
+	}
+	n, err := strconv.Atoi(string(b[:i]))
+	if err != nil {
+		return 0, b, false
+	}
+	return n, b[i:], true
+}
+
+// GetName reads the name at the start of b after skipping whitespace, and
+// returns it along with the remainder of b. The name ends at a comma, a tab,
+// or the end of the line, and the comma or tab is consumed. A semi-colon
+// starts a comment and also ends the name. Trailing spaces are removed.
+// This is how the engine reads names, and it uses this function to do so.
+func GetName(b []byte) (string, []byte) {
+	b = SkipWhitespace(b)
+	i := 0
+	for i < len(b) && b[i] != ',' && b[i] != '\t' && b[i] != '\n' && b[i] != ';' && b[i] != 0 {
+		i++
+	}
+	name := trimName(b[:i])
+	if i < len(b) && (b[i] == ',' || b[i] == '\t' || b[i] == '\n') {
+		i++
+	}
+	return string(name), b[i:]
+}
+
+// trimName removes trailing spaces and carriage returns from a name.
+func trimName(name []byte) []byte {
+	for len(name) != 0 && (name[len(name)-1] == ' ' || name[len(name)-1] == '\r') {
+		name = name[:len(name)-1]
+	}
+	return name
+}
+
+// abbr reads a class abbreviation and returns its type and upper-case text.
+// Returns UNKNOWN_ID, without consuming any input, if the abbreviation is not recognized.
+func (a *args) abbr() (int, string) {
+	a.skipWhitespace()
+	abbr, rest := ClassAbbr(a.b)
+	if abbr.Type == UNKNOWN_ID {
+		return UNKNOWN_ID, ""
+	}
+	word := make([]byte, len(a.b)-len(rest))
+	for j := range word {
+		word[j] = toUpper(a.b[j])
+	}
+	a.b = rest
+	return abbr.Type, string(word)
+}
+
+// Abbr is a class abbreviation.
+type Abbr struct {
+	Type     int  // TECH_ID, ITEM_CLASS, SHIP_CLASS, PLANET_ID, SPECIES_ID, or UNKNOWN_ID
+	Index    int  // index of the technology, item, or ship class
+	Tonnage  int  // tonnage of a ship class in units of 10,000 tons, based only on the abbreviation
+	SubLight bool // true if the ship class is sub-light
+}
+
+// ClassAbbr reads the class abbreviation at the start of b and returns it
+// along with the remainder of b. The abbreviation must start with two
+// letters or digits. Transports include their tonnage, as in TR10, and ship
+// classes may end in S for sub-light.
+// This is how the engine reads abbreviations, and it uses this function to do so.
+func ClassAbbr(b []byte) (Abbr, []byte) {
+	if len(b) == 0 || !isalnum(b[0]) {
+		return Abbr{}, b
+	} else if len(b) == 1 || !isalnum(b[1]) {
+		return Abbr{}, b[1:]
+	}
+	i := 2
+	for i < len(b) && isalnum(b[i]) {
+		i++
+	}
+	word := make([]byte, i)
+	for j := range word {
+		word[j] = toUpper(b[j])
+	}
+
+	for n, abbr := range techAbbr {
+		if string(word) == abbr {
+			return Abbr{Type: TECH_ID, Index: n}, b[i:]
+		}
+	}
+	for n, abbr := range itemAbbr {
+		if string(word) == abbr {
+			return Abbr{Type: ITEM_CLASS, Index: n}, b[i:]
+		}
+	}
+	for n, abbr := range shipAbbr {
+		if string(word[:2]) != abbr {
+			continue
+		}
+		ship, rest := Abbr{Type: SHIP_CLASS, Index: n, Tonnage: shipTonnage[n]}, b[2:]
+		if abbr == "TR" {
+			ship.Tonnage = 0
+			for len(rest) != 0 && isdigit(rest[0]) {
+				ship.Tonnage = 10*ship.Tonnage + int(rest[0]-'0')
+				rest = rest[1:]
+			}
+		}
+		if len(rest) != 0 && toUpper(rest[0]) == 'S' {
+			ship.SubLight, rest = true, rest[1:]
+		}
+		if len(rest) != 0 && isalnum(rest[0]) {
+			// garbage, not a ship class
+			return Abbr{}, rest
+		}
+		return ship, rest
+	}
+	switch string(word) {
+	case "PL":
+		return Abbr{Type: PLANET_ID}, b[i:]
+	case "SP":
+		return Abbr{Type: SPECIES_ID}, b[i:]
+	}
+	return Abbr{}, b[i:]
+}
+
+// name reads a name with GetName.
+// If more arguments are expected and the name was not followed by a comma or tab,
+// name will end the name in front of a class abbreviation or number that follows
+// a space, the same way the engine fixes a missing separator.
+func (a *args) name(more bool) string {
+	a.skipWhitespace()
+	if more && bytes.IndexAny(a.b, ",\t") == -1 {
+		for j := 1; j < len(a.b); j++ {
+			if a.b[j-1] != ' ' || a.b[j] == ' ' {
+				continue
+			}
+			if isdigit(a.b[j]) {
+				name := trimName(a.b[:j])
+				a.b = a.b[j:]
+				return string(name)
+			}
+			if abbr, _ := ClassAbbr(a.b[j:]); abbr.Type == SHIP_CLASS || abbr.Type == PLANET_ID || abbr.Type == SPECIES_ID {
+				name := trimName(a.b[:j])
+				a.b = a.b[j:]
+				return string(name)
+			}
+		}
+	}
+	name, rest := GetName(a.b)
+	a.b = rest
+	return name
+}
+
+// classed reads a name that starts with one of the given abbreviation types.
+// The result is the upper-case abbreviation, a space, and the name.
+// Returns false, without consuming any input, if there is no such name.
+func (a *args) classed(more bool, kinds ...int) (string, bool) {
+	saved := a.b
+	kind, abbr := a.abbr()
+	for _, k := range kinds {
+		if kind != k {
+			continue
+		}
+		if name := a.name(more); name != "" {
+			return abbr + " " + name, true
+		}
+		break
+	}
+	a.b = saved
+	return "", false
+}
+
+// ship reads the name of a ship or starbase.
+func (a *args) ship(more bool) (string, bool) {
+	return a.classed(more, SHIP_CLASS)
+}
+
+// planet reads the name of a planet.
+func (a *args) planet(more bool) (string, bool) {
+	return a.classed(more, PLANET_ID)
+}
+
+// species reads the name of a species.
+func (a *args) species(more bool) (string, bool) {
+	return a.classed(more, SPECIES_ID)
+}
+
+// transferPoint reads the name of a ship, starbase, or planet.
+func (a *args) transferPoint(more bool) (string, bool) {
+	return a.classed(more, SHIP_CLASS, PLANET_ID)
+}
+
+// item reads an item class abbreviation.
+func (a *args) item() (string, bool) {
+	saved := a.b
+	if kind, abbr := a.abbr(); kind == ITEM_CLASS {
+		return abbr, true
+	}
+	a.b = saved
+	return "", false
+}
+
+// tech reads a technology abbreviation.
+func (a *args) tech() (string, bool) {
+	saved := a.b
+	if kind, abbr := a.abbr(); kind == TECH_ID {
+		return abbr, true
+	}
+	a.b = saved
+	return "", false
+}
+
+// coords reads the three values of a sector's coordinates.
+func (a *args) coords() (x, y, z int, ok bool) {
+	saved := a.b
+	if x, ok = a.value(); ok {
+		if y, ok = a.value(); ok {
+			if z, ok = a.value(); ok {
+				return x, y, z, true
+			}
+		}
+	}
+	a.b = saved
+	return 0, 0, 0, false
+}
+
+func isalnum(ch byte) bool {
+	return isdigit(ch) || ('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z')
+}
+
+func isalpha(ch byte) bool {
+	return ('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z')
+}
+
+func isdigit(ch byte) bool {
+	return '0' <= ch && ch <= '9'
+}
@@ -0,0 +1,404 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package orders
+
+import (
+	"fmt"
+	"github.com/mdhender/fhcms/cms/agrep"
+	"sort"
+	"strings"
+)
+
+// Names holds the game state needed to check the orders for a species.
+// Names do not include the class abbreviation, except for ShipIds.
+type Names struct {
+	Ships    []string // names of the species' ships and starbases
+	ShipIds  []string // names of the species' ships and starbases with their class, like "TR1 Alpha"
+	Planets  []string // names of the species' named planets
+	Species  []string // names of every species in the game
+	Contacts []string // names of the species that this species has met
+}
+
+// Diagnostic is a problem found while checking orders.
+type Diagnostic struct {
+	Line       int    `json:"line"`
+	Severity   string `json:"severity"` // "error" or "warning"
+	Section    string `json:"section,omitempty"`
+	Command    string `json:"command,omitempty"`
+	Input      string `json:"input,omitempty"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+func (d *Diagnostic) String() string {
+	if d.Suggestion == "" {
+		return fmt.Sprintf("%d: %s: %s", d.Line, d.Severity, d.Message)
+	}
+	return fmt.Sprintf("%d: %s: %s (did you mean %q?)", d.Line, d.Severity, d.Message, d.Suggestion)
+}
+
+// sectionCommands are the commands that the engine accepts in each section.
+var sectionCommands = map[string][]COMMAND{
+	"COMBAT":        {ATTACK, BATTLE, ENGAGE, HAVEN, HIDE, HIJACK, MESSAGE, SUMMARY, TARGET, WITHDRAW},
+	"PRE-DEPARTURE": {ALLY, BASE, DEEP, DESTROY, DISBAND, ENEMY, HIDE, INSTALL, LAND, MESSAGE, NAME, NEUTRAL, ORBIT, REPAIR, SCAN, SEND, TRANSFER, UNLOAD},
+	"JUMPS":         {JUMP, MOVE, PJUMP, VISITED, WORMHOLE},
+	"PRODUCTION":    {ALLY, AMBUSH, BUILD, CONTINUE, DEVELOP, ENEMY, ESTIMATE, HIDE, IBUILD, ICONTINUE, INTERCEPT, NEUTRAL, PRODUCTION, RECYCLE, RESEARCH, SHIPYARD, TEACH, TECH, UPGRADE},
+	"POST-ARRIVAL":  {ALLY, AUTO, DEEP, DESTROY, ENEMY, LAND, MESSAGE, NAME, NEUTRAL, ORBIT, REPAIR, SCAN, SEND, TEACH, TELESCOPE, TERRAFORM, TRANSFER},
+	"STRIKES":       {ATTACK, BATTLE, ENGAGE, HAVEN, HIDE, HIJACK, MESSAGE, SUMMARY, TARGET, WITHDRAW},
+}
+
+// Check returns the problems with the orders, sorted by line number.
+// It reports parse errors, orders placed in a section that does not
+// accept them, and names of ships, planets, and species that are not in names.
+// Ships built and planets named by the orders are known to later sections.
+// It also reports orders for species that have not been contacted and
+// ALLY, ENEMY, and NEUTRAL orders that contradict each other.
+func (o *Orders) Check(names *Names) []*Diagnostic {
+	var list []*Diagnostic
+	for _, err := range o.Errors {
+		d := &Diagnostic{Severity: "error", Message: err.Error()}
+		if e, ok := err.(*Error); ok {
+			d.Line, d.Message = e.Line, e.Err.Error()
+		}
+		list = append(list, d)
+	}
+
+	known := map[int]map[string]string{
+		SHIP_CLASS: upperNames(names.Ships),
+		PLANET_ID:  upperNames(names.Planets),
+		SPECIES_ID: upperNames(names.Species),
+	}
+	contacts := upperNames(names.Contacts)
+	stances := make(map[string]Order) // last ALLY, ENEMY, or NEUTRAL order for each species
+	for _, name := range sectionNames {
+		section := o.Section(name)
+		if section == nil {
+			continue
+		}
+		for _, order := range section.Orders {
+			diag := func(severity, message, suggestion string) {
+				list = append(list, &Diagnostic{
+					Line:       order.LineNo(),
+					Severity:   severity,
+					Section:    section.Name,
+					Command:    strings.ToUpper(order.Command().String()),
+					Input:      order.Input(),
+					Message:    message,
+					Suggestion: suggestion,
+				})
+			}
+			for _, err := range order.Errs() {
+				diag("error", err.Error(), "")
+			}
+			if !accepts(section.Name, order.Command()) {
+				var allowed []string
+				for _, name := range sectionNames {
+					if accepts(name, order.Command()) {
+						allowed = append(allowed, name)
+					}
+				}
+				message := fmt.Sprintf("%s is not allowed in the %s section", strings.ToUpper(order.Command().String()), section.Name)
+				if len(allowed) != 0 {
+					message += ", it belongs in " + strings.Join(allowed, " or ")
+				}
+				diag("error", message, "")
+			}
+			for _, r := range references(order) {
+				if r.name == "" {
+					continue
+				} else if r.created {
+					known[r.kind][strings.ToUpper(r.name)] = r.name
+					continue
+				}
+				severity, message, suggestion := resolve(r.kind, r.name, known[r.kind])
+				if severity != "" {
+					diag(severity, message, suggestion)
+				}
+			}
+			if len(order.Errs()) != 0 {
+				continue
+			}
+
+			// the engine ignores these orders for species that have not been met
+			if name, ok := contactedSpecies(order); ok {
+				if name = knownName(SPECIES_ID, name, known[SPECIES_ID]); name != "" {
+					if _, ok := contacts[strings.ToUpper(name)]; !ok {
+						diag("error", fmt.Sprintf("species %q has not been contacted", name), "")
+					}
+				}
+			}
+
+			// the engine applies every declaration, so only the last one for a species counts
+			if all, name, ok := diplomacy(order); ok {
+				key, target := "*", "all species"
+				if !all {
+					if s := knownName(SPECIES_ID, name, known[SPECIES_ID]); s != "" {
+						name = s
+					}
+					key, target = strings.ToUpper(name), fmt.Sprintf("species %q", name)
+				}
+				if prior, ok := stances[key]; ok && prior.Command() != order.Command() {
+					diag("error", fmt.Sprintf("%s of %s conflicts with %s on line %d, only the last one counts",
+						strings.ToUpper(order.Command().String()), target, strings.ToUpper(prior.Command().String()), prior.LineNo()), "")
+				}
+				stances[key] = order
+			}
+		}
+	}
+
+	// sections that the engine will never read
+	for _, section := range o.Sections {
+		if _, ok := sectionCommands[section.Name]; !ok {
+			list = append(list, &Diagnostic{Line: section.Line, Severity: "error", Section: section.Name, Message: fmt.Sprintf("unknown section %q", section.Name)})
+		}
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Line < list[j].Line
+	})
+	return list
+}
+
+// accepts returns true if the engine accepts the command in the section.
+func accepts(section string, command COMMAND) bool {
+	for _, c := range sectionCommands[section] {
+		if c == command {
+			return true
+		}
+	}
+	return false
+}
+
+// reference is a name used by an order.
+type reference struct {
+	kind    int    // SHIP_CLASS, PLANET_ID, or SPECIES_ID
+	name    string // name without the class abbreviation
+	created bool   // true if the order creates the name
+}
+
+// references returns the names used by an order.
+func references(order Order) []reference {
+	ship := func(s string) reference { return reference{kind: SHIP_CLASS, name: stripAbbr(s)} }
+	planet := func(s string) reference { return reference{kind: PLANET_ID, name: stripAbbr(s)} }
+	species := func(s string) reference { return reference{kind: SPECIES_ID, name: stripAbbr(s)} }
+	point := func(s string) reference {
+		if strings.HasPrefix(s, "PL ") {
+			return planet(s)
+		}
+		return ship(s)
+	}
+
+	switch o := order.(type) {
+	case *Ally:
+		return []reference{species(o.Species)}
+	case *Attack:
+		return []reference{species(o.Species)}
+	case *Base:
+		// the starbase is created if it does not exist
+		return []reference{point(o.Source), {kind: SHIP_CLASS, name: stripAbbr(o.Base), created: true}}
+	case *Build:
+		refs := []reference{species(o.Species)}
+		if o.Continuation {
+			return append(refs, ship(o.Ship))
+		} else if o.Species != "" {
+			// ships built for another species belong to that species
+			return refs
+		} else if o.Destination != "" {
+			return append(refs, point(o.Destination))
+		}
+		return append(refs, reference{kind: SHIP_CLASS, name: stripAbbr(o.Ship), created: true})
+	case *Deep:
+		return []reference{ship(o.Ship)}
+	case *Destroy:
+		return []reference{ship(o.Ship)}
+	case *Develop:
+		return []reference{planet(o.Planet), ship(o.Ship)}
+	case *Disband:
+		return []reference{planet(o.Planet)}
+	case *Enemy:
+		return []reference{species(o.Species)}
+	case *Estimate:
+		return []reference{species(o.Species)}
+	case *Hide:
+		return []reference{ship(o.Ship)}
+	case *Hijack:
+		return []reference{species(o.Species)}
+	case *Install:
+		return []reference{planet(o.Planet)}
+	case *Jump:
+		// a jump portal may belong to another species
+		return []reference{ship(o.Ship), planet(o.Planet)}
+	case *Land:
+		return []reference{ship(o.Ship), planet(o.Planet)}
+	case *Message:
+		return []reference{species(o.Species)}
+	case *Move:
+		return []reference{ship(o.Ship)}
+	case *Name:
+		return []reference{{kind: PLANET_ID, name: stripAbbr(o.Planet), created: true}}
+	case *Neutral:
+		return []reference{species(o.Species)}
+	case *Orbit:
+		return []reference{ship(o.Ship), planet(o.Planet)}
+	case *Production:
+		return []reference{planet(o.Planet)}
+	case *Recycle:
+		return []reference{ship(o.Ship)}
+	case *Repair:
+		return []reference{ship(o.Ship)}
+	case *Scan:
+		return []reference{ship(o.Ship)}
+	case *Send:
+		return []reference{species(o.Species)}
+	case *Teach:
+		return []reference{species(o.Species)}
+	case *Tech:
+		return []reference{species(o.Species)}
+	case *Telescope:
+		return []reference{ship(o.Base)}
+	case *Terraform:
+		return []reference{planet(o.Planet)}
+	case *Transfer:
+		return []reference{point(o.Source), point(o.Destination)}
+	case *Unload:
+		return []reference{ship(o.Ship)}
+	case *Upgrade:
+		return []reference{ship(o.Ship)}
+	case *Wormhole:
+		return []reference{ship(o.Ship)}
+	}
+	return nil
+}
+
+// contactedSpecies returns the species named by an order that
+// the engine accepts only for species that have been contacted.
+func contactedSpecies(order Order) (string, bool) {
+	var species string
+	switch o := order.(type) {
+	case *Ally:
+		species = o.Species
+	case *Enemy:
+		species = o.Species
+	case *Estimate:
+		species = o.Species
+	case *Neutral:
+		species = o.Species
+	case *Send:
+		species = o.Species
+	case *Teach:
+		species = o.Species
+	case *Tech:
+		species = o.Species
+	}
+	if species == "" {
+		return "", false
+	}
+	return stripAbbr(species), true
+}
+
+// diplomacy returns the target of an ALLY, ENEMY, or NEUTRAL order.
+func diplomacy(order Order) (all bool, species string, ok bool) {
+	switch o := order.(type) {
+	case *Ally:
+		return o.All, stripAbbr(o.Species), true
+	case *Enemy:
+		return o.All, stripAbbr(o.Species), true
+	case *Neutral:
+		return o.All, stripAbbr(o.Species), true
+	}
+	return false, "", false
+}
+
+// knownName returns the known name that the engine will use for a name,
+// or an empty string if the engine will not find the name.
+func knownName(kind int, name string, known map[string]string) string {
+	if s, ok := known[strings.ToUpper(name)]; ok {
+		return s
+	} else if severity, _, suggestion := resolve(kind, name, known); severity == "warning" {
+		return suggestion
+	}
+	return ""
+}
+
+// resolve looks up a name the same way the engine does.
+// It returns an error if the engine will not find the name and a warning
+// if the engine will accept it as a misspelling of another name.
+// The suggestion is the closest known name, if there is one.
+func resolve(kind int, name string, known map[string]string) (severity, message, suggestion string) {
+	var what string
+	switch kind {
+	case SHIP_CLASS:
+		what = "ship"
+	case PLANET_ID:
+		what = "planet"
+	case SPECIES_ID:
+		what = "species"
+	}
+
+	upper := strings.ToUpper(name)
+	if _, ok := known[upper]; ok {
+		return "", "", ""
+	}
+
+	bestScore, nextBestScore, best := -9999, -9999, ""
+	var keys []string
+	for key := range known {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if n := agrep.Score(key, upper); n > bestScore {
+			nextBestScore, bestScore, best = bestScore, n, known[key]
+		} else if n > nextBestScore {
+			nextBestScore = n
+		}
+	}
+	if best == "" {
+		return "error", fmt.Sprintf("unknown %s %q", what, name), ""
+	}
+
+	// the engine accepts a misspelling if the best match is close enough and unique
+	length := len(best)
+	if bestScore >= length-((length/7)+1) && length >= 5 && bestScore != nextBestScore {
+		return "warning", fmt.Sprintf("%s %q is misspelled, the engine will use the closest match", what, name), best
+	}
+	if bestScore*2 >= length {
+		return "error", fmt.Sprintf("unknown %s %q", what, name), best
+	}
+	return "error", fmt.Sprintf("unknown %s %q", what, name), ""
+}
+
+// stripAbbr returns the name without its class abbreviation.
+func stripAbbr(s string) string {
+	if i := strings.IndexByte(s, ' '); i != -1 {
+		return s[i+1:]
+	}
+	return s
+}
+
+// upperNames returns a map of upper-case name to name.
+func upperNames(names []string) map[string]string {
+	m := make(map[string]string)
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			m[strings.ToUpper(name)] = name
+		}
+	}
+	return m
+}
@@ -0,0 +1,94 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package orders
+
+import (
+	"strings"
+	"testing"
+)
+
+// testNames are the names known to the species in the check tests.
+var testNames = &Names{
+	Ships:    []string{"Enterprise", "Seeker"},
+	ShipIds:  []string{"TR1 Enterprise", "TR1 Seeker"},
+	Planets:  []string{"Home"},
+	Species:  []string{"Klingon", "Romulan", "Vulcan"},
+	Contacts: []string{"Klingon", "Vulcan"},
+}
+
+// checkTests runs Check on each input and compares the diagnostics.
+func checkTests(t *testing.T, tests []struct{ name, input, want string }) {
+	t.Helper()
+	for _, tc := range tests {
+		var got []string
+		for _, d := range Parse([]byte(tc.input)).Check(testNames) {
+			got = append(got, d.String())
+		}
+		if strings.Join(got, "\n") != tc.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tc.name, strings.Join(got, "\n"), tc.want)
+		}
+	}
+}
+
+// TestCheckNames checks that a name the engine will accept as a misspelling
+// is a warning, and a name that it will not find is an error.
+func TestCheckNames(t *testing.T) {
+	checkTests(t, []struct{ name, input, want string }{
+		{"known names", "START PRE-DEPARTURE\nSCAN TR1 enterprise\nEND\nSTART PRODUCTION\nPRODUCTION PL Home\nEND\n", ""},
+		{"misspelled ship", "START PRE-DEPARTURE\nSCAN TR1 Enterprize\nSCAN TR1 Seekr\nEND\n",
+			`2: warning: ship "Enterprize" is misspelled, the engine will use the closest match (did you mean "Enterprise"?)` + "\n" +
+				`3: warning: ship "Seekr" is misspelled, the engine will use the closest match (did you mean "Seeker"?)`},
+		{"misspelled short name", "START PRODUCTION\nPRODUCTION PL Hom\nEND\n",
+			`2: error: unknown planet "Hom" (did you mean "Home"?)`},
+		{"unknown ship", "START PRE-DEPARTURE\nSCAN TR1 Xyz\nEND\n",
+			`2: error: unknown ship "Xyz"`},
+		{"built ship", "START PRODUCTION\nBUILD TR1 Scout\nEND\nSTART POST-ARRIVAL\nSCAN TR1 Scout\nEND\n", ""},
+		{"wrong section", "START PRODUCTION\nJUMP TR1 Seeker, 1 2 3\nEND\n",
+			`2: error: JUMP is not allowed in the PRODUCTION section, it belongs in JUMPS`},
+	})
+}
+
+// TestCheckContacts checks that orders for species that have not been
+// contacted are errors.
+func TestCheckContacts(t *testing.T) {
+	checkTests(t, []struct{ name, input, want string }{
+		{"contacted", "START PRE-DEPARTURE\nALLY SP Klingon\nEND\nSTART PRODUCTION\nESTIMATE SP Vulcan\nEND\n", ""},
+		{"not contacted", "START PRE-DEPARTURE\nENEMY SP Romulan\nEND\nSTART PRODUCTION\nESTIMATE SP Romulan\nEND\n",
+			`2: error: species "Romulan" has not been contacted` + "\n" +
+				`5: error: species "Romulan" has not been contacted`},
+		{"misspelled and not contacted", "START PRE-DEPARTURE\nNEUTRAL SP Romulen\nEND\n",
+			`2: warning: species "Romulen" is misspelled, the engine will use the closest match (did you mean "Romulan"?)` + "\n" +
+				`2: error: species "Romulan" has not been contacted`},
+	})
+}
+
+// TestCheckDiplomacy checks that ALLY, ENEMY, and NEUTRAL orders that
+// contradict each other are errors.
+func TestCheckDiplomacy(t *testing.T) {
+	checkTests(t, []struct{ name, input, want string }{
+		{"same stance", "START PRE-DEPARTURE\nALLY SP Klingon\nEND\nSTART PRODUCTION\nALLY SP Klingon\nEND\n", ""},
+		{"different species", "START PRE-DEPARTURE\nALLY SP Klingon\nENEMY SP Vulcan\nEND\n", ""},
+		{"conflicting species", "START PRE-DEPARTURE\nALLY SP Klingon\nNEUTRAL SP klingon\nEND\n",
+			`3: error: NEUTRAL of species "Klingon" conflicts with ALLY on line 2, only the last one counts`},
+		{"conflicting across sections", "START PRE-DEPARTURE\nENEMY SP Vulcan\nEND\nSTART POST-ARRIVAL\nALLY SP Vulcan\nEND\n",
+			`5: error: ALLY of species "Vulcan" conflicts with ENEMY on line 2, only the last one counts`},
+		{"conflicting all species", "START PRE-DEPARTURE\nENEMY 1\nNEUTRAL 1\nEND\n",
+			`3: error: NEUTRAL of all species conflicts with ENEMY on line 2, only the last one counts`},
+	})
+}
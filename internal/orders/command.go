@@ -22,148 +22,135 @@ import "bytes"
 
 type COMMAND int
 
-// getCommand returns a command and error.
-// A command is the command, parameters, and comments.
-// Some commands, like START, have children.
-// Some commands, like MESSAGE, will consume multiple lines.
-func getCommand(lines [][]byte) (COMMAND, [][]byte, error) {
-	if len(lines) == 0 {
-		return EOF, nil, nil
-	}
-	line, _ := splitLine(lines[0])
-	command := []byte{' ', ' ', ' '}
-	for i := 0; i < 3 && i < len(line); i++ {
-		if bytes.IndexByte([]byte{' ', '\t'}, line[i]) != -1 {
-			break
-		}
-		command[i] = toUpper(line[i])
-	}
-	return EOF, nil, nil
-}
-
-// getCommandWord returns a command word and the remainder of the line.
-func getCommandWord(line []byte) COMMAND {
-	// command is the first three characters or up to the first space or tab
+// CommandWord returns the command at the start of the line and the remainder of the line.
+// The command is identified by the first three characters of the command word,
+// which must be letters. The rest of the word is skipped.
+// This is how the engine reads commands, and it uses this function to do so.
+func CommandWord(line []byte) (COMMAND, []byte) {
 	var command []byte
 	for len(command) != 3 && len(line) != 0 {
-		var ch byte
-		if ch, line = line[0], line[1:]; ch == ' ' || ch == '\t' {
-			break
+		if !isalpha(line[0]) {
+			return UNDEFINED, line
 		}
-		command = append(command, toUpper(ch))
+		command = append(command, toUpper(line[0]))
+		line = line[1:]
+	}
+	// skip everything after the third character of the command word
+	for len(line) != 0 && bytes.IndexByte([]byte{'\t', '\r', '\n', ' ', ',', ';'}, line[0]) == -1 {
+		line = line[1:]
 	}
 
 	switch string(command) {
 	case "ALL":
-		return ALLY
+		return ALLY, line
 	case "AMB":
-		return AMBUSH
+		return AMBUSH, line
 	case "ATT":
-		return ATTACK
+		return ATTACK, line
 	case "AUT":
-		return AUTO
+		return AUTO, line
 	case "BAS":
-		return BASE
+		return BASE, line
 	case "BAT":
-		return BATTLE
+		return BATTLE, line
 	case "BUI":
-		return BUILD
+		return BUILD, line
 	case "CON":
-		return CONTINUE
+		return CONTINUE, line
 	case "DEE":
-		return DEEP
+		return DEEP, line
 	case "DES":
-		return DESTROY
+		return DESTROY, line
 	case "DEV":
-		return DEVELOP
+		return DEVELOP, line
 	case "DIS":
-		return DISBAND
+		return DISBAND, line
 	case "END":
-		return END
+		return END, line
 	case "ENE":
-		return ENEMY
+		return ENEMY, line
 	case "ENG":
-		return ENGAGE
+		return ENGAGE, line
 	case "EST":
-		return ESTIMATE
+		return ESTIMATE, line
 	case "HAV":
-		return HAVEN
+		return HAVEN, line
 	case "HID":
-		return HIDE
+		return HIDE, line
 	case "HIJ":
-		return HIJACK
+		return HIJACK, line
 	case "IBU":
-		return IBUILD
+		return IBUILD, line
 	case "ICO":
-		return ICONTINUE
+		return ICONTINUE, line
 	case "INS":
-		return INSTALL
+		return INSTALL, line
 	case "INT":
-		return INTERCEPT
+		return INTERCEPT, line
 	case "JUM":
-		return JUMP
+		return JUMP, line
 	case "LAN":
-		return LAND
+		return LAND, line
 	case "MES":
-		return MESSAGE
+		return MESSAGE, line
 	case "MOV":
-		return MOVE
+		return MOVE, line
 	case "NAM":
-		return NAME
+		return NAME, line
 	case "NEU":
-		return NEUTRAL
+		return NEUTRAL, line
 	case "ORB":
-		return ORBIT
+		return ORBIT, line
 	case "PJU":
-		return PJUMP
+		return PJUMP, line
 	case "PRO":
-		return PRODUCTION
+		return PRODUCTION, line
 	case "REC":
-		return RECYCLE
+		return RECYCLE, line
 	case "REN":
-		return RENAME
+		return RENAME, line
 	case "REP":
-		return REPAIR
+		return REPAIR, line
 	case "RES":
-		return RESEARCH
+		return RESEARCH, line
 	case "SCA":
-		return SCAN
+		return SCAN, line
 	case "SEN":
-		return SEND
+		return SEND, line
 	case "SHI":
-		return SHIPYARD
+		return SHIPYARD, line
 	case "STA":
-		return START
+		return START, line
 	case "SUM":
-		return SUMMARY
+		return SUMMARY, line
 	case "SUR":
-		return SURRENDER
+		return SURRENDER, line
 	case "TAR":
-		return TARGET
+		return TARGET, line
 	case "TEA":
-		return TEACH
+		return TEACH, line
 	case "TEC":
-		return TECH
+		return TECH, line
 	case "TEL":
-		return TELESCOPE
+		return TELESCOPE, line
 	case "TER":
-		return TERRAFORM
+		return TERRAFORM, line
 	case "TRA":
-		return TRANSFER
+		return TRANSFER, line
 	case "UNL":
-		return UNLOAD
+		return UNLOAD, line
 	case "UPG":
-		return UPGRADE
+		return UPGRADE, line
 	case "VIS":
-		return VISITED
+		return VISITED, line
 	case "WIT":
-		return WITHDRAW
+		return WITHDRAW, line
 	case "WOR":
-		return WORMHOLE
+		return WORMHOLE, line
 	case "ZZZ":
-		return ZZZ
+		return ZZZ, line
 	default:
-		return UNDEFINED
+		return UNDEFINED, line
 	}
 }
 
@@ -247,3 +234,22 @@ const (
 	ZZZ
 	EOF
 )
+
+var commandName = [...]string{
+	"Undefined", "Ally", "Ambush", "Attack", "Auto", "Base",
+	"Battle", "Build", "Continue", "Deep", "Destroy", "Develop",
+	"Disband", "End", "Enemy", "Engage", "Estimate", "Haven",
+	"Hide", "Hijack", "Ibuild", "Icontinue", "Install", "Intercept",
+	"Jump", "Land", "Message", "Move", "Name", "Neutral", "Orbit",
+	"Pjump", "Production", "Recycle", "Rename", "Repair", "Research",
+	"Scan", "Send", "Shipyard", "Start", "Summary", "Surrender", "Target",
+	"Teach", "Tech", "Telescope", "Terraform", "Transfer", "Unload",
+	"Upgrade", "Visited", "Withdraw", "Wormhole", "ZZZ", "EOF"}
+
+// String implements the Stringer interface.
+func (c COMMAND) String() string {
+	if c < 0 || int(c) >= len(commandName) {
+		return commandName[UNDEFINED]
+	}
+	return commandName[c]
+}
@@ -0,0 +1,195 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package orders
+
+import (
+	"sort"
+	"strings"
+)
+
+// Completion is a suggestion for the word at the cursor.
+// The text from Start up to End should be replaced with Label.
+type Completion struct {
+	Label string `json:"label"`
+	Kind  string `json:"kind"`  // keyword, section, command, ship, planet, species, item, ship class, or tech
+	Start int    `json:"start"` // byte offset in the line
+	End   int    `json:"end"`   // byte offset in the line, always the cursor
+}
+
+// completeArgs are the kinds of names and abbreviations that each command accepts.
+var completeArgs = map[COMMAND][]int{
+	ALLY:       {SPECIES_ID},
+	ATTACK:     {SPECIES_ID},
+	BASE:       {SHIP_CLASS, PLANET_ID},
+	BUILD:      {ITEM_CLASS, SHIP_CLASS, PLANET_ID},
+	CONTINUE:   {SHIP_CLASS},
+	DEEP:       {SHIP_CLASS},
+	DESTROY:    {SHIP_CLASS},
+	DEVELOP:    {PLANET_ID, SHIP_CLASS},
+	DISBAND:    {PLANET_ID},
+	ENEMY:      {SPECIES_ID},
+	ESTIMATE:   {SPECIES_ID},
+	HIDE:       {SHIP_CLASS},
+	HIJACK:     {SPECIES_ID},
+	IBUILD:     {SPECIES_ID, ITEM_CLASS, SHIP_CLASS},
+	ICONTINUE:  {SPECIES_ID, SHIP_CLASS},
+	INSTALL:    {ITEM_CLASS, PLANET_ID},
+	JUMP:       {SHIP_CLASS, PLANET_ID},
+	LAND:       {SHIP_CLASS, PLANET_ID},
+	MESSAGE:    {SPECIES_ID},
+	MOVE:       {SHIP_CLASS},
+	NEUTRAL:    {SPECIES_ID},
+	ORBIT:      {SHIP_CLASS, PLANET_ID},
+	PJUMP:      {SHIP_CLASS, PLANET_ID},
+	PRODUCTION: {PLANET_ID},
+	RECYCLE:    {ITEM_CLASS, SHIP_CLASS},
+	REPAIR:     {SHIP_CLASS},
+	RESEARCH:   {TECH_ID},
+	SCAN:       {SHIP_CLASS},
+	SEND:       {SPECIES_ID},
+	TEACH:      {TECH_ID, SPECIES_ID},
+	TECH:       {TECH_ID, SPECIES_ID},
+	TELESCOPE:  {SHIP_CLASS},
+	TERRAFORM:  {PLANET_ID},
+	TRANSFER:   {ITEM_CLASS, SHIP_CLASS, PLANET_ID},
+	UNLOAD:     {SHIP_CLASS},
+	UPGRADE:    {SHIP_CLASS},
+	WORMHOLE:   {SHIP_CLASS},
+}
+
+// Complete returns the completions for the word in front of the cursor.
+// Section is the name of the section that the line is in, or an empty
+// string if the line is not in a section.
+//
+// The line is read with CommandWord and ClassAbbr, the same functions that
+// the engine uses: the first word is the command, and class abbreviations
+// like TR1, PL, and SP start the names of ships, planets, and species.
+// Only species that have been contacted are suggested for orders that the
+// engine ignores for other species. Nothing is suggested inside a comment.
+func Complete(line string, cursor int, section string, names *Names) []*Completion {
+	if cursor < 0 || cursor > len(line) {
+		cursor = len(line)
+	}
+	before := line[:cursor]
+	if strings.IndexByte(before, ';') != -1 {
+		return nil
+	}
+	var list []*Completion
+	add := func(start int, kind, prefix string, labels ...string) {
+		for _, label := range labels {
+			if label != "" && strings.HasPrefix(strings.ToUpper(label), strings.ToUpper(prefix)) {
+				list = append(list, &Completion{Label: label, Kind: kind, Start: start, End: cursor})
+			}
+		}
+	}
+
+	// the command word ends at the first space, tab, or comma
+	start := len(before) - len(strings.TrimLeft(before, " \t"))
+	end := strings.IndexAny(before[start:], " \t,")
+	if end == -1 {
+		word := before[start:]
+		if section == "" {
+			add(start, "keyword", word, "START")
+			return list
+		}
+		add(start, "keyword", word, "END")
+		for _, command := range sectionCommands[sectionName([]byte(section))] {
+			add(start, "command", word, command.String())
+		}
+		return sorted(list)
+	}
+	verb, _ := CommandWord([]byte(before[start:]))
+	if verb == START {
+		word := strings.TrimLeft(before[start+end:], " \t,")
+		add(len(before)-len(word), "section", word, sectionNames...)
+		return list
+	}
+	kinds, ok := completeArgs[verb]
+	if !ok {
+		return nil
+	}
+
+	// names may contain spaces, so the argument ends at the last comma or tab
+	arg := before[start+end:]
+	if i := strings.LastIndexAny(arg, ",\t"); i != -1 {
+		arg = arg[i+1:]
+	}
+
+	species := names.Species
+	switch verb {
+	case ALLY, ENEMY, ESTIMATE, NEUTRAL, SEND, TEACH, TECH:
+		species = names.Contacts
+	}
+
+	// a class abbreviation followed by a space starts a name, and the last one wins
+	words := strings.Split(arg, " ")
+	for i := len(words) - 1; i >= 0; i-- {
+		prefix := strings.Join(words[i+1:], " ")
+		offset := cursor - len(prefix)
+		if i == len(words)-1 || len(words[i]) < 2 {
+			continue
+		}
+		abbr, _ := ClassAbbr([]byte(words[i]))
+		switch abbr.Type {
+		case SHIP_CLASS:
+			add(offset, "ship", prefix, names.Ships...)
+			return sorted(list)
+		case PLANET_ID:
+			add(offset, "planet", prefix, names.Planets...)
+			return sorted(list)
+		case SPECIES_ID:
+			add(offset, "species", prefix, species...)
+			return sorted(list)
+		}
+	}
+
+	// otherwise complete the last word
+	word := words[len(words)-1]
+	wordStart := cursor - len(word)
+	for _, kind := range kinds {
+		switch kind {
+		case ITEM_CLASS:
+			add(wordStart, "item", word, itemAbbr...)
+		case SHIP_CLASS:
+			add(wordStart, "ship", word, names.ShipIds...)
+			if verb == BUILD || verb == IBUILD {
+				add(wordStart, "ship class", word, shipAbbr...)
+			}
+		case PLANET_ID:
+			for _, name := range names.Planets {
+				add(wordStart, "planet", word, "PL "+name)
+			}
+		case SPECIES_ID:
+			for _, name := range species {
+				add(wordStart, "species", word, "SP "+name)
+			}
+		case TECH_ID:
+			add(wordStart, "tech", word, techAbbr...)
+		}
+	}
+	return list
+}
+
+// sorted returns the completions sorted by label.
+func sorted(list []*Completion) []*Completion {
+	sort.SliceStable(list, func(i, j int) bool {
+		return strings.ToUpper(list[i].Label) < strings.ToUpper(list[j].Label)
+	})
+	return list
+}
@@ -0,0 +1,69 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package orders
+
+import "testing"
+
+// TestCompleteAbbr checks that Complete starts a name after the same class
+// abbreviations that the engine accepts.
+func TestCompleteAbbr(t *testing.T) {
+	names := &Names{
+		Ships:    []string{"Ferry"},
+		Planets:  []string{"Home"},
+		Species:  []string{"Sp2", "Sp3"},
+		Contacts: []string{"Sp2"},
+	}
+	for _, tc := range []struct {
+		line string
+		kind string // empty if no names should be suggested
+	}{
+		{"Jump TR F", "ship"},
+		{"Jump tr10s F", "ship"},
+		{"Unload DDS F", "ship"},
+		{"Jump DDX F", ""},
+		{"Jump TR1X F", ""},
+		{"Production pl H", "planet"},
+		{"Production PLX H", ""},
+		{"Ally sp S", "species"},
+	} {
+		list := Complete(tc.line, len(tc.line), "PRODUCTION", names)
+		var got []*Completion
+		for _, c := range list {
+			if c.Kind == "ship" || c.Kind == "planet" || c.Kind == "species" {
+				got = append(got, c)
+			}
+		}
+		if tc.kind == "" {
+			if len(got) != 0 {
+				t.Errorf("%q: got %q, want nothing", tc.line, got[0].Label)
+			}
+			continue
+		} else if len(got) != 1 {
+			t.Errorf("%q: got %d names, want 1", tc.line, len(got))
+			continue
+		}
+		if got[0].Kind != tc.kind {
+			t.Errorf("%q: kind: got %q, want %q", tc.line, got[0].Kind, tc.kind)
+		}
+		// the completion replaces only the name after the abbreviation
+		if got[0].Start != len(tc.line)-1 {
+			t.Errorf("%q: start: got %d, want %d", tc.line, got[0].Start, len(tc.line)-1)
+		}
+	}
+}
@@ -0,0 +1,646 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package orders
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"regexp"
+	"strings"
+)
+
+// Document is the structured form of an orders file, for clients that would
+// rather submit orders as data than as free text. It is written as JSON or YAML
+// and is accepted anywhere an orders file is.
+//
+// A document looks like
+//
+//	{
+//	  "sections": [
+//	    {
+//	      "name": "PRE-DEPARTURE",
+//	      "orders": [
+//	        {"command": "Unload", "ship": "TR1 Hauler"},
+//	        {"comment": "a line with only a comment"},
+//	        {"command": "Message", "species": "SP Klingon", "text": ["Hello there"]}
+//	      ]
+//	    },
+//	    {
+//	      "name": "PRODUCTION",
+//	      "production": [
+//	        {
+//	          "planet": "PL Home",
+//	          "orders": [
+//	            {"command": "Build", "count": 5, "item": "CU", "comment": "for the colony"},
+//	            {"command": "Research", "amount": 100, "tech": "GV"}
+//	          ]
+//	        }
+//	      ]
+//	    }
+//	  ]
+//	}
+//
+// Every order has a "command", which is the command name from the text format,
+// and the fields of its type, named by the json tags in types.go.
+// Ships, planets, and species are named with their class abbreviation, like "TR1 Hauler".
+// An entry with "raw" instead of "command" is a line of the text format; it is
+// how lines with errors are kept when a text file is converted. The "lines"
+// of a raw entry are written after it as is, like the text of a message with errors.
+// An entry with "blank" set is a blank line.
+//
+// The "sections" field, the name of every section, and the planet of every
+// production center are required, and every order must have the fields
+// that the text format requires. Decode rejects documents that do not.
+//
+// Converting a document to text and back returns the same document.
+// Converting text to a document and back keeps every order, comment, blank line,
+// and message, and returns the same text as Format. A message that is not
+// ended by a ZZZ line is marked "unterminated", and, as in the text, it
+// takes the place of the END of its section.
+type Document struct {
+	Header   []string `json:"header,omitempty"`   // mail header, if any
+	Preamble []*Entry `json:"preamble,omitempty"` // comments and lines found before the first section
+	Sections []*Block `json:"sections"`
+}
+
+// Block is a START ... END section of a document.
+type Block struct {
+	Name       string    `json:"name"`
+	Comment    string    `json:"comment,omitempty"` // comment on the START line
+	Orders     []*Entry  `json:"orders,omitempty"`
+	Production []*Center `json:"production,omitempty"` // production centers, PRODUCTION section only
+	EndComment string    `json:"end_comment,omitempty"`
+	Trailer    []*Entry  `json:"trailer,omitempty"` // comments and lines found after the END, before the next section
+}
+
+// Center is a production center: a PRODUCTION order and the orders that follow it.
+type Center struct {
+	Planet  string   `json:"planet"`
+	Comment string   `json:"comment,omitempty"` // comment on the PRODUCTION line
+	Orders  []*Entry `json:"orders,omitempty"`
+}
+
+// Entry is an order, a line kept as entered, a comment, or a blank line.
+type Entry struct {
+	Order      Order    // nil for comments, blank lines, and lines kept as entered
+	Raw        string   // line as entered, set only for lines with errors
+	Lines      []string // lines after a raw line that are kept as entered, like the text of a message with errors
+	Comment    string
+	EndComment string // comment on the ZZZ line of a message
+	Blank      bool   // true for a blank line
+	// Unterminated is true for a message, or the raw line of a message with errors,
+	// that is not ended by a ZZZ line because the file ends first.
+	Unterminated bool
+}
+
+// FromText returns the document for an orders file in the text format.
+func FromText(b []byte) *Document {
+	d := &Document{Sections: []*Block{}}
+	var block *Block
+	var message *Message
+	var open *Entry // entry for the message, or message with errors, that text lines belong to
+	entries := &d.Preamble
+	for _, l := range scan(b) {
+		switch l.kind {
+		case headerLine:
+			d.Header = append(d.Header, l.text)
+		case blankLine:
+			*entries = append(*entries, &Entry{Blank: true})
+		case commentLine:
+			*entries = append(*entries, &Entry{Comment: l.comment})
+		case startLine:
+			block = &Block{Name: l.name, Comment: l.comment}
+			d.Sections = append(d.Sections, block)
+			entries = &block.Orders
+		case endLine:
+			block.EndComment = l.comment
+			entries, block = &block.Trailer, nil
+		case orderLine:
+			if len(l.order.Errs()) != 0 {
+				e := &Entry{Raw: strings.TrimSpace(l.text)}
+				*entries = append(*entries, e)
+				if _, ok := l.order.(*Message); ok {
+					open = e
+				}
+				continue
+			}
+			if p, ok := l.order.(*Production); ok && block.Name == "PRODUCTION" {
+				center := &Center{Planet: p.Planet, Comment: l.comment}
+				block.Production = append(block.Production, center)
+				entries = &center.Orders
+				continue
+			}
+			e := &Entry{Order: l.order, Comment: l.comment}
+			*entries = append(*entries, e)
+			if m, ok := l.order.(*Message); ok {
+				message, open = m, e
+			}
+		case rawLine:
+			e := &Entry{Raw: l.text}
+			*entries = append(*entries, e)
+			if _, ok := l.order.(*Message); ok {
+				open = e
+			}
+		case textLine:
+			if message != nil {
+				message.Text = append(message.Text, l.text)
+			} else if open != nil {
+				open.Lines = append(open.Lines, l.text)
+			}
+		case zzzLine:
+			if message != nil {
+				open.EndComment = l.comment
+			} else if open != nil {
+				open.Lines = append(open.Lines, withComment("ZZZ", l.comment))
+			}
+			message, open = nil, nil
+		}
+	}
+	if open != nil {
+		open.Unterminated = true
+	}
+	return d
+}
+
+// Text returns the document in the text format.
+func (d *Document) Text() []byte {
+	f := &formatter{}
+	for _, line := range d.Header {
+		f.println(line)
+	}
+	f.blank = len(f.lines) != 0
+	for _, e := range d.Preamble {
+		f.entry(false, e)
+	}
+	for _, block := range d.Sections {
+		f.blank = len(f.lines) != 0
+		f.print(false, withComment(strings.TrimSpace("START "+sectionName([]byte(block.Name))), block.Comment))
+		for _, e := range block.Orders {
+			f.entry(true, e)
+		}
+		for _, center := range block.Production {
+			f.open = false
+			f.print(true, withComment("Production\t"+center.Planet, center.Comment))
+			for _, e := range center.Orders {
+				f.entry(true, e)
+			}
+		}
+		// an unterminated message swallows the END of its section
+		if !f.open {
+			f.blank = false
+			f.print(false, withComment("END", block.EndComment))
+		}
+		for _, e := range block.Trailer {
+			f.entry(false, e)
+		}
+	}
+	return []byte(strings.Join(f.lines, "\n") + "\n")
+}
+
+// entry adds the lines for an entry.
+func (f *formatter) entry(indent bool, e *Entry) {
+	f.open = false
+	if e.Blank {
+		f.blank = len(f.lines) != 0
+		return
+	} else if e.Order == nil {
+		if e.Raw != "" {
+			f.print(indent, withComment(e.Raw, e.Comment))
+		} else if e.Comment != "" {
+			f.print(indent, withComment("", e.Comment))
+		}
+		for _, line := range e.Lines {
+			f.println(line)
+		}
+		f.open = e.Unterminated
+		return
+	}
+	text, ok := f.order(e.Order)
+	if !ok {
+		text = e.Order.Input()
+	}
+	f.print(indent, withComment(text, e.Comment))
+	if m, ok := e.Order.(*Message); ok {
+		for _, line := range m.Text {
+			f.println(line)
+		}
+		if e.Unterminated {
+			f.open = true
+		} else {
+			f.println(withComment("ZZZ", e.EndComment))
+		}
+	}
+}
+
+// validate returns an error if a required field of the document is missing.
+// Orders are checked by writing them as text and parsing them again.
+func (d *Document) validate() error {
+	if d.Sections == nil {
+		return fmt.Errorf("orders: missing sections")
+	}
+	f := &formatter{}
+	check := func(where string, entries []*Entry) error {
+		for i, e := range entries {
+			if e.Order == nil {
+				if !e.Blank && e.Raw == "" && e.Comment == "" {
+					return fmt.Errorf("orders: %s: entry %d: missing command, raw, comment, or blank", where, i+1)
+				}
+				continue
+			}
+			text, _ := f.order(e.Order)
+			verb, rest := CommandWord([]byte(text))
+			if errs := parseOrder(Common{Verb: verb, OriginalInput: text}, &args{b: rest}).Errs(); len(errs) != 0 {
+				return fmt.Errorf("orders: %s: entry %d: %s: %v", where, i+1, e.Order.Command(), errs[0])
+			}
+			// text has no place for a planet number after a planet name
+			if o, ok := e.Order.(*Jump); ok && o.Orbit != 0 && !o.CoordsSpecified {
+				return fmt.Errorf("orders: %s: entry %d: %s: orbit requires coordinates", where, i+1, e.Order.Command())
+			}
+		}
+		return nil
+	}
+	if err := check("preamble", d.Preamble); err != nil {
+		return err
+	}
+	for i, block := range d.Sections {
+		if block == nil || block.Name == "" {
+			return fmt.Errorf("orders: section %d: missing name", i+1)
+		}
+		if err := check(block.Name, block.Orders); err != nil {
+			return err
+		}
+		for j, center := range block.Production {
+			if center == nil || center.Planet == "" {
+				return fmt.Errorf("orders: %s: production center %d: missing planet", block.Name, j+1)
+			}
+			if err := check(block.Name+" "+center.Planet, center.Orders); err != nil {
+				return err
+			}
+		}
+		if err := check(block.Name+" trailer", block.Trailer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decode returns the document for an orders file in the text, JSON, or YAML format.
+func Decode(b []byte) (*Document, error) {
+	switch FileFormat(b) {
+	case "json":
+		d := &Document{}
+		if err := json.Unmarshal(b, d); err != nil {
+			return nil, fmt.Errorf("orders: %w", err)
+		}
+		return d, d.validate()
+	case "yaml":
+		var v interface{}
+		if err := yaml.Unmarshal(b, &v); err != nil {
+			return nil, fmt.Errorf("orders: %w", err)
+		}
+		buf, err := json.Marshal(fromYAML(v))
+		if err != nil {
+			return nil, fmt.Errorf("orders: %w", err)
+		}
+		d := &Document{}
+		if err := json.Unmarshal(buf, d); err != nil {
+			return nil, fmt.Errorf("orders: %w", err)
+		}
+		return d, d.validate()
+	}
+	return FromText(b), nil
+}
+
+// ToText returns an orders file in the text format.
+// Files that are already text are returned unchanged.
+func ToText(b []byte) ([]byte, error) {
+	if FileFormat(b) == "text" {
+		return b, nil
+	}
+	d, err := Decode(b)
+	if err != nil {
+		return nil, err
+	}
+	return d.Text(), nil
+}
+
+var yamlKey = regexp.MustCompile(`^[A-Za-z_]+:(\s|$)`)
+
+// FileFormat returns "json", "yaml", or "text" for the contents of an orders file.
+// A JSON document starts with "{". A YAML document starts with "---" or a key
+// like "sections:" on the first line that is not blank or a "#" comment.
+// Everything else is text.
+func FileFormat(b []byte) string {
+	for _, line := range bytes.Split(b, []byte{'\n'}) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		} else if line[0] == '{' {
+			return "json"
+		} else if bytes.HasPrefix(line, []byte("---")) || yamlKey.Match(line) {
+			return "yaml"
+		}
+		break
+	}
+	return "text"
+}
+
+// JSON returns the document as indented JSON.
+func (d *Document) JSON() ([]byte, error) {
+	b, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// YAML returns the document as YAML, with keys in the same order as the JSON.
+func (d *Document) YAML() ([]byte, error) {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	v, err := orderedJSON(dec)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(v)
+}
+
+func (e *Entry) MarshalJSON() ([]byte, error) {
+	if e.Order == nil {
+		return json.Marshal(struct {
+			Raw          string   `json:"raw,omitempty"`
+			Lines        []string `json:"lines,omitempty"`
+			Comment      string   `json:"comment,omitempty"`
+			Blank        bool     `json:"blank,omitempty"`
+			Unterminated bool     `json:"unterminated,omitempty"`
+		}{e.Raw, e.Lines, e.Comment, e.Blank, e.Unterminated})
+	}
+	fields, err := json.Marshal(e.Order)
+	if err != nil {
+		return nil, err
+	}
+	command, _ := json.Marshal(e.Order.Command().String())
+	buf := &bytes.Buffer{}
+	buf.WriteString(`{"command":`)
+	buf.Write(command)
+	if len(fields) > 2 {
+		buf.WriteByte(',')
+		buf.Write(fields[1 : len(fields)-1])
+	}
+	if e.Comment != "" {
+		comment, _ := json.Marshal(e.Comment)
+		buf.WriteString(`,"comment":`)
+		buf.Write(comment)
+	}
+	if e.EndComment != "" {
+		comment, _ := json.Marshal(e.EndComment)
+		buf.WriteString(`,"end_comment":`)
+		buf.Write(comment)
+	}
+	if e.Unterminated {
+		buf.WriteString(`,"unterminated":true`)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (e *Entry) UnmarshalJSON(b []byte) error {
+	var head struct {
+		Command      string   `json:"command"`
+		Raw          string   `json:"raw"`
+		Lines        []string `json:"lines"`
+		Comment      string   `json:"comment"`
+		EndComment   string   `json:"end_comment"`
+		Blank        bool     `json:"blank"`
+		Unterminated bool     `json:"unterminated"`
+	}
+	if err := json.Unmarshal(b, &head); err != nil {
+		return err
+	}
+	e.Order, e.Raw, e.Lines, e.Comment, e.EndComment = nil, head.Raw, nil, head.Comment, ""
+	e.Blank, e.Unterminated = head.Blank, head.Unterminated
+	if head.Command == "" {
+		e.Lines = head.Lines
+		return nil
+	}
+	e.EndComment = head.EndComment
+	order := newOrder(commandByName(head.Command))
+	if order == nil {
+		return fmt.Errorf("unknown command %q", head.Command)
+	}
+	if err := json.Unmarshal(b, order); err != nil {
+		return fmt.Errorf("%s: %w", head.Command, err)
+	}
+
+	// set the flags that the text format implies but the data format leaves out
+	switch o := order.(type) {
+	case *Build:
+		o.Continuation = o.Verb == CONTINUE || o.Verb == ICONTINUE
+		o.LimitSpecified = o.LimitSpecified || o.Limit != 0
+	case *Develop:
+		o.LimitSpecified = o.LimitSpecified || o.Limit != 0
+	case *Jump:
+		o.CoordsSpecified = o.Planet == ""
+	case *Orbit:
+		o.OrbitSpecified, o.PlanetSpecified = o.Orbit != 0, o.Planet != ""
+	case *Upgrade:
+		o.LimitSpecified = o.LimitSpecified || o.Limit != 0
+	}
+	e.Order = order
+	return nil
+}
+
+// commandByName returns the command for a command name, ignoring case.
+// Returns UNDEFINED if the name is not the name of an order.
+func commandByName(name string) COMMAND {
+	for c, s := range commandName {
+		if strings.EqualFold(name, s) {
+			return COMMAND(c)
+		}
+	}
+	return UNDEFINED
+}
+
+// newOrder returns an empty order for a command,
+// or nil if the command is not an order that can be given in a document.
+func newOrder(verb COMMAND) Order {
+	c := Common{Verb: verb}
+	switch verb {
+	case ALLY:
+		return &Ally{Common: c}
+	case AMBUSH:
+		return &Ambush{Common: c}
+	case ATTACK:
+		return &Attack{Common: c}
+	case AUTO:
+		return &Auto{Common: c}
+	case BASE:
+		return &Base{Common: c}
+	case BATTLE:
+		return &Battle{Common: c}
+	case BUILD, CONTINUE, IBUILD, ICONTINUE:
+		return &Build{Common: c}
+	case DEEP:
+		return &Deep{Common: c}
+	case DESTROY:
+		return &Destroy{Common: c}
+	case DEVELOP:
+		return &Develop{Common: c}
+	case DISBAND:
+		return &Disband{Common: c}
+	case ENEMY:
+		return &Enemy{Common: c}
+	case ENGAGE:
+		return &Engage{Common: c}
+	case ESTIMATE:
+		return &Estimate{Common: c}
+	case HAVEN:
+		return &Haven{Common: c}
+	case HIDE:
+		return &Hide{Common: c}
+	case HIJACK:
+		return &Hijack{Common: c}
+	case INSTALL:
+		return &Install{Common: c}
+	case INTERCEPT:
+		return &Intercept{Common: c}
+	case JUMP, PJUMP:
+		return &Jump{Common: c}
+	case LAND:
+		return &Land{Common: c}
+	case MESSAGE:
+		return &Message{Common: c}
+	case MOVE:
+		return &Move{Common: c}
+	case NAME:
+		return &Name{Common: c}
+	case NEUTRAL:
+		return &Neutral{Common: c}
+	case ORBIT:
+		return &Orbit{Common: c}
+	case PRODUCTION:
+		return &Production{Common: c}
+	case RECYCLE:
+		return &Recycle{Common: c}
+	case REPAIR:
+		return &Repair{Common: c}
+	case RESEARCH:
+		return &Research{Common: c}
+	case SCAN:
+		return &Scan{Common: c}
+	case SEND:
+		return &Send{Common: c}
+	case SHIPYARD:
+		return &Shipyard{Common: c}
+	case SUMMARY:
+		return &Summary{Common: c}
+	case TARGET:
+		return &Target{Common: c}
+	case TEACH:
+		return &Teach{Common: c}
+	case TECH:
+		return &Tech{Common: c}
+	case TELESCOPE:
+		return &Telescope{Common: c}
+	case TERRAFORM:
+		return &Terraform{Common: c}
+	case TRANSFER:
+		return &Transfer{Common: c}
+	case UNLOAD:
+		return &Unload{Common: c}
+	case UPGRADE:
+		return &Upgrade{Common: c}
+	case VISITED:
+		return &Visited{Common: c}
+	case WITHDRAW:
+		return &Withdraw{Common: c}
+	case WORMHOLE:
+		return &Wormhole{Common: c}
+	}
+	return nil
+}
+
+// fromYAML converts the maps from the YAML decoder to maps that can be encoded as JSON.
+// YAML 1.1 reads a plain y key as true, so a true key is turned back into "y".
+func fromYAML(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			if key == true {
+				key = "y"
+			}
+			m[fmt.Sprint(key)] = fromYAML(value)
+		}
+		return m
+	case []interface{}:
+		for i, value := range v {
+			v[i] = fromYAML(value)
+		}
+	}
+	return v
+}
+
+// orderedJSON decodes the next JSON value, keeping the order of the keys in objects.
+func orderedJSON(dec *json.Decoder) (interface{}, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		var m yaml.MapSlice
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := orderedJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			m = append(m, yaml.MapItem{Key: key, Value: value})
+		}
+		_, err = dec.Token()
+		return m, err
+	case json.Delim('['):
+		s := []interface{}{}
+		for dec.More() {
+			value, err := orderedJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, value)
+		}
+		_, err = dec.Token()
+		return s, err
+	}
+	if n, ok := token.(json.Number); ok {
+		if i, err := n.Int64(); err == nil {
+			return i, nil
+		}
+	}
+	return token, nil
+}
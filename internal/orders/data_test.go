@@ -0,0 +1,122 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package orders
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestDocumentText checks that converting text to a document and back
+// returns the same text as Format, and that converting the document to
+// JSON or YAML and back does not change it.
+func TestDocumentText(t *testing.T) {
+	for _, tc := range formatTests {
+		t.Run(tc.name, func(t *testing.T) {
+			want := string(Format([]byte(tc.input), nil))
+			d := FromText([]byte(tc.input))
+			if got := string(d.Text()); got != want {
+				t.Errorf("text: got\n%q\nwant\n%q", got, want)
+			}
+			for _, kind := range []string{"json", "yaml"} {
+				var b []byte
+				var err error
+				if kind == "json" {
+					b, err = d.JSON()
+				} else {
+					b, err = d.YAML()
+				}
+				if err != nil {
+					t.Fatalf("%s: %v", kind, err)
+				}
+				decoded, err := Decode(b)
+				if err != nil {
+					t.Fatalf("%s: decode: %v\n%s", kind, err, b)
+				}
+				if got := string(decoded.Text()); got != want {
+					t.Errorf("%s: got\n%q\nwant\n%q", kind, got, want)
+				}
+			}
+		})
+	}
+}
+
+// TestDecodeJumpOrbit checks that the planet number of a jump to coordinates
+// is kept when a document is converted to text.
+func TestDecodeJumpOrbit(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		input string
+	}{
+		{"json", `{"sections": [{"name": "JUMPS", "orders": [{"command": "Jump", "ship": "TR1 Alpha", "x": 10, "y": 10, "z": 10, "orbit": 3}]}]}`},
+		{"yaml", "sections:\n  - name: JUMPS\n    orders:\n      - command: Jump\n        ship: TR1 Alpha\n        x: 10\n        y: 10\n        z: 10\n        orbit: 3\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			d, err := Decode([]byte(tc.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := "START JUMPS\n\tJump\tTR1 Alpha\t10 10 10 3\nEND\n"
+			if got := string(d.Text()); got != want {
+				t.Errorf("got\n%q\nwant\n%q", got, want)
+			}
+		})
+	}
+}
+
+// TestDecodeRequired checks that documents that are missing a required field are rejected.
+func TestDecodeRequired(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"sections", `{"header": ["From gm"]}`, "missing sections"},
+		{"section name", `{"sections": [{"orders": [{"command": "Auto"}]}]}`, "section 1: missing name"},
+		{"planet", `{"sections": [{"name": "PRODUCTION", "production": [{"orders": []}]}]}`, "production center 1: missing planet"},
+		{"empty entry", `{"sections": [{"name": "COMBAT", "orders": [{}]}]}`, "COMBAT: entry 1: missing command"},
+		{"species", `{"sections": [{"name": "PRE-DEPARTURE", "orders": [{"command": "Message", "text": ["hi"]}]}]}`, "PRE-DEPARTURE: entry 1: Message: invalid species name"},
+		{"ship", `{"sections": [{"name": "JUMPS", "orders": [{"command": "Jump", "x": 1, "y": 2, "z": 3}]}]}`, "JUMPS: entry 1: Jump: invalid ship name"},
+		{"jump orbit", `{"sections": [{"name": "JUMPS", "orders": [{"command": "Jump", "ship": "TR1 Alpha", "planet": "PL Home", "orbit": 3}]}]}`, "JUMPS: entry 1: Jump: orbit requires coordinates"},
+		{"yaml", "sections:\n  - name: COMBAT\n    orders:\n      - command: Attack\n", "COMBAT: entry 1: Attack: invalid species name"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Decode([]byte(tc.input))
+			if err == nil {
+				t.Fatalf("got no error, want %q", tc.want)
+			} else if !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("got %q, want %q", err, tc.want)
+			}
+		})
+	}
+
+	// the example from the Document comment is complete
+	example := `{"sections": [
+		{"name": "PRE-DEPARTURE", "orders": [
+			{"command": "Unload", "ship": "TR1 Hauler"},
+			{"comment": "a line with only a comment"},
+			{"command": "Message", "species": "SP Klingon", "text": ["Hello there"]}]},
+		{"name": "PRODUCTION", "production": [
+			{"planet": "PL Home", "orders": [
+				{"command": "Build", "count": 5, "item": "CU", "comment": "for the colony"},
+				{"command": "Research", "amount": 100, "tech": "GV"}]}]}]}`
+	if _, err := Decode([]byte(example)); err != nil {
+		t.Errorf("example: %v", err)
+	}
+}
@@ -0,0 +1,208 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package orders
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Change is a difference between the default orders and the orders submitted by a player.
+type Change struct {
+	Kind      string `json:"kind"` // "added", "removed", or "changed"
+	Section   string `json:"section"`
+	Center    string `json:"center,omitempty"`    // production center, set only in the PRODUCTION section
+	Default   string `json:"default,omitempty"`   // canonical text of the default order
+	Submitted string `json:"submitted,omitempty"` // canonical text of the submitted order
+	Line      int    `json:"line,omitempty"`      // line of the submitted order
+}
+
+func (c *Change) String() string {
+	switch c.Kind {
+	case "added":
+		return fmt.Sprintf("+ %d: %s", c.Line, c.Submitted)
+	case "removed":
+		return fmt.Sprintf("- %s", c.Default)
+	}
+	return fmt.Sprintf("~ %d: %s (default %s)", c.Line, c.Submitted, c.Default)
+}
+
+// Diff returns the orders that were added, removed, or changed in the submitted
+// orders when compared with the default orders, section by section.
+// The PRODUCTION section is compared one production center at a time,
+// so a missing PRODUCTION block shows up as the removal of all of its orders.
+//
+// Orders are compared by their canonical text. An order is changed when
+// the command and the ship, planet, species, or item that it applies to
+// are the same but the rest of the order is not. Removed and changed orders
+// are returned in the order of the default orders, followed by added orders.
+//
+// If names is not nil, names are compared with the spelling from the game data.
+func Diff(defaults, submitted *Orders, names *Names) []*Change {
+	f := &formatter{}
+	if names != nil {
+		f.ships, f.planets, f.species = upperNames(names.Ships), upperNames(names.Planets), upperNames(names.Species)
+	}
+
+	var list []*Change
+	for _, name := range sectionNames {
+		base, mine := f.groups(defaults.Section(name)), f.groups(submitted.Section(name))
+		var centers []string
+		for _, g := range base {
+			centers = append(centers, g.center)
+		}
+		for _, g := range mine {
+			if findGroup(base, g.center) == nil {
+				centers = append(centers, g.center)
+			}
+		}
+		for _, center := range centers {
+			for _, c := range compareGroups(findGroup(base, center), findGroup(mine, center)) {
+				c.Section = name
+				if name == "PRODUCTION" {
+					c.Center = center
+				}
+				list = append(list, c)
+			}
+		}
+	}
+	return list
+}
+
+// diffOrder is an order with the text used to compare it.
+type diffOrder struct {
+	key     string // command and the name the order applies to
+	text    string // canonical text of the order
+	line    int
+	matched bool
+}
+
+// diffGroup is the orders of a section, or of a production center in the PRODUCTION section.
+type diffGroup struct {
+	center string // name of the production center, without the PL abbreviation
+	orders []*diffOrder
+}
+
+// groups returns the orders of a section, grouped by production center.
+// Orders in the PRODUCTION section before the first PRODUCTION order are
+// in a group with no center. Other sections have a single group.
+func (f *formatter) groups(section *Section) []*diffGroup {
+	if section == nil {
+		return nil
+	}
+	var list []*diffGroup
+	g := &diffGroup{}
+	for _, order := range section.Orders {
+		if p, ok := order.(*Production); ok && len(p.Errs()) == 0 {
+			if len(g.orders) != 0 {
+				list = append(list, g)
+			}
+			center := stripAbbr(p.Planet)
+			if s := knownName(PLANET_ID, center, f.planets); s != "" {
+				center = s
+			}
+			g = &diffGroup{center: center}
+		}
+		text, ok := f.order(order)
+		if !ok {
+			text = strings.Join(strings.Fields(order.Input()), " ")
+		}
+		g.orders = append(g.orders, &diffOrder{key: diffKey(order), text: text, line: order.LineNo()})
+	}
+	if len(g.orders) != 0 {
+		list = append(list, g)
+	}
+	return list
+}
+
+// findGroup returns the group for the production center, or nil if there is none.
+func findGroup(list []*diffGroup, center string) *diffGroup {
+	for _, g := range list {
+		if strings.EqualFold(g.center, center) {
+			return g
+		}
+	}
+	return nil
+}
+
+// compareGroups returns the changes between two groups of orders.
+// Either group may be nil.
+func compareGroups(base, mine *diffGroup) []*Change {
+	if base == nil {
+		base = &diffGroup{}
+	}
+	if mine == nil {
+		mine = &diffGroup{}
+	}
+
+	// orders with the same text are unchanged, and the rest are changed if the keys match
+	pair := func(same func(a, b *diffOrder) bool) map[*diffOrder]*diffOrder {
+		pairs := make(map[*diffOrder]*diffOrder)
+		for _, o := range mine.orders {
+			if o.matched {
+				continue
+			}
+			for _, d := range base.orders {
+				if !d.matched && same(d, o) {
+					d.matched, o.matched, pairs[d] = true, true, o
+					break
+				}
+			}
+		}
+		return pairs
+	}
+	pair(func(a, b *diffOrder) bool { return strings.EqualFold(a.text, b.text) })
+	changed := pair(func(a, b *diffOrder) bool { return a.key == b.key })
+
+	var list []*Change
+	for _, d := range base.orders {
+		if o, ok := changed[d]; ok {
+			list = append(list, &Change{Kind: "changed", Default: d.text, Submitted: o.text, Line: o.line})
+		} else if !d.matched {
+			list = append(list, &Change{Kind: "removed", Default: d.text})
+		}
+	}
+	for _, o := range mine.orders {
+		if !o.matched {
+			list = append(list, &Change{Kind: "added", Submitted: o.text, Line: o.line})
+		}
+	}
+	return list
+}
+
+// diffKey returns the command of an order and the first name that it applies to.
+func diffKey(order Order) string {
+	key := strings.ToUpper(order.Command().String())
+	switch o := order.(type) {
+	case *Build:
+		if o.Item != "" {
+			return key + " " + strings.ToUpper(o.Item)
+		}
+	case *Recycle:
+		if o.Item != "" {
+			return key + " " + strings.ToUpper(o.Item)
+		}
+	}
+	for _, r := range references(order) {
+		if r.name != "" {
+			return key + " " + strings.ToUpper(r.name)
+		}
+	}
+	return key
+}
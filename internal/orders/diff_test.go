@@ -0,0 +1,55 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package orders
+
+import "testing"
+
+// TestDiff checks added, removed, and changed orders, and a missing PRODUCTION block.
+func TestDiff(t *testing.T) {
+	defaults := Parse([]byte("START PRE-DEPARTURE\nSCAN TR1 Seeker\nUNLOAD TR1 Alpha\nEND\n" +
+		"START JUMPS\nJUMP TR1 Seeker, 1 2 3\nEND\n" +
+		"START PRODUCTION\nPRODUCTION PL Home\nBUILD 10 IU\nRESEARCH 100 GV\nPRODUCTION PL Colony\nBUILD 5 AU\nEND\n"))
+	submitted := Parse([]byte("START PRE-DEPARTURE\nscan tr1 seeker\nEND\n" +
+		"START JUMPS\nJUMP TR1 Seeker, 4 5 6\nEND\n" +
+		"START PRODUCTION\nPRODUCTION PL Home\nBUILD 10 IU\nRESEARCH 100 GV\nBUILD 20 CU\nEND\n"))
+
+	want := []Change{
+		{Kind: "removed", Section: "PRE-DEPARTURE", Default: "Unload\tTR1 Alpha"},
+		{Kind: "changed", Section: "JUMPS", Default: "Jump\tTR1 Seeker\t1 2 3", Submitted: "Jump\tTR1 Seeker\t4 5 6", Line: 5},
+		{Kind: "added", Section: "PRODUCTION", Center: "Home", Submitted: "Build\t20 CU", Line: 11},
+		{Kind: "removed", Section: "PRODUCTION", Center: "Colony", Default: "Production\tPL Colony"},
+		{Kind: "removed", Section: "PRODUCTION", Center: "Colony", Default: "Build\t5 AU"},
+	}
+	got := Diff(defaults, submitted, nil)
+	if len(got) != len(want) {
+		for _, c := range got {
+			t.Logf("got %s", c)
+		}
+		t.Fatalf("got %d changes, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if *got[i] != want[i] {
+			t.Errorf("change %d: got %+v, want %+v", i, *got[i], want[i])
+		}
+	}
+
+	if changes := Diff(defaults, defaults, nil); len(changes) != 0 {
+		t.Errorf("same orders: got %v, want no changes", changes)
+	}
+}
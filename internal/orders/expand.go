@@ -0,0 +1,391 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package orders
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// The orders file may use a few directives to avoid writing near-identical
+// orders by hand. Expand replaces them with plain orders before the file is
+// parsed. Directives are recognized by their full word, in any case.
+//
+//	FLEET name s1, s2, ...            Define a group of ships and starbases
+//	FOR EACH ab IN FLEET name order   Repeat "order" for every ship of class "ab" in the fleet
+//	FOR EACH ab IN FLEET name         Repeat every line up to NEXT for every ship of class "ab" in the fleet
+//	NEXT                              End the lines repeated by FOR
+//	MACRO name                        Define a macro from every line up to MEND
+//	MEND                              End the definition of a macro
+//	CALL name a1, a2, ...             Insert the lines of a macro
+//
+// The class may be SHIP to select every ship in the fleet. A fleet name that
+// contains spaces must be quoted with ' or ".
+//
+// In the lines repeated by FOR, $SHIP is replaced with the name of the ship.
+// If a one-line FOR order does not use $SHIP, the ship is inserted as the first
+// argument of the order. In the lines of a macro, $1 through $9 are replaced with
+// the arguments of the CALL.
+//
+// For example,
+//
+//	FLEET 'Supply' TR1 Alpha, TR1 Beta, TR5 Gamma
+//	FOR EACH TR IN FLEET 'Supply' UNLOAD
+//
+// expands to
+//
+//	UNLOAD TR1 Alpha
+//	UNLOAD TR1 Beta
+//	UNLOAD TR5 Gamma
+//
+// The text of a message is never expanded.
+
+// Expansion is the result of expanding the directives in an orders file.
+type Expansion struct {
+	Text     []byte  // the orders file with every directive replaced by plain orders
+	Lines    []int   // line number in the original file of every line of Text
+	Errors   []error // errors in the directives, always *Error with the line number in the original file
+	Expanded bool    // true if the file used any directives
+}
+
+// maxExpandDepth limits how deeply macros may call each other.
+const maxExpandDepth = 8
+
+// srcLine is a line of input to the expander along with the line
+// number in the original file that it came from.
+type srcLine struct {
+	text   string
+	origin int
+}
+
+type expander struct {
+	x         *Expansion
+	lines     []string
+	fleets    map[string][]string // ship names, with class abbreviation, by upper-case fleet name
+	macros    map[string][]srcLine
+	inMessage bool
+}
+
+// Expand returns the orders file with every directive replaced by plain orders.
+// A file without directives is returned unchanged.
+func Expand(b []byte) *Expansion {
+	e := &expander{
+		x:      &Expansion{},
+		fleets: make(map[string][]string),
+		macros: make(map[string][]srcLine),
+	}
+	var input []srcLine
+	for n, text := range strings.Split(string(b), "\n") {
+		input = append(input, srcLine{text: text, origin: n + 1})
+	}
+	e.run(input, nil, 0)
+	if !e.x.Expanded {
+		e.x.Text = b
+	} else {
+		e.x.Text = []byte(strings.Join(e.lines, "\n"))
+	}
+	sort.SliceStable(e.x.Errors, func(i, j int) bool {
+		return e.x.Errors[i].(*Error).Line < e.x.Errors[j].(*Error).Line
+	})
+	return e.x
+}
+
+// OriginalLine returns the line number in the original file for a line of the expanded text.
+func (x *Expansion) OriginalLine(line int) int {
+	if line < 1 || line > len(x.Lines) {
+		return line
+	}
+	return x.Lines[line-1]
+}
+
+// Check parses the expanded orders and checks them like Orders.Check.
+// Errors in the directives are included, and every line number is
+// the line number in the original file.
+func (x *Expansion) Check(names *Names) []*Diagnostic {
+	var list []*Diagnostic
+	for _, err := range x.Errors {
+		e := err.(*Error)
+		list = append(list, &Diagnostic{Line: e.Line, Severity: "error", Message: e.Err.Error()})
+	}
+	for _, d := range Parse(x.Text).Check(names) {
+		d.Line = x.OriginalLine(d.Line)
+		list = append(list, d)
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Line < list[j].Line
+	})
+	return list
+}
+
+// errorf adds an error for a line of the original file.
+func (e *expander) errorf(line int, format string, a ...interface{}) {
+	e.x.Errors = append(e.x.Errors, &Error{Line: line, Err: fmt.Errorf(format, a...)})
+}
+
+// emit adds a line to the expanded text.
+func (e *expander) emit(text string, origin int) {
+	e.lines = append(e.lines, text)
+	e.x.Lines = append(e.x.Lines, origin)
+}
+
+var expandVariable = regexp.MustCompile(`\$(SHIP|[1-9])\b`)
+
+// run expands lines, replacing any variables with their values.
+// Variables are only replaced in lines that come from a FOR or a macro.
+func (e *expander) run(input []srcLine, vars map[string]string, depth int) {
+	for i := 0; i < len(input); i++ {
+		text, origin := input[i].text, input[i].origin
+		if vars != nil {
+			text = expandVariable.ReplaceAllStringFunc(text, func(s string) string {
+				if value, ok := vars[strings.ToUpper(s[1:])]; ok {
+					return value
+				}
+				e.errorf(origin, "%s is not defined here", s)
+				return s
+			})
+		}
+
+		// the text of a message is everything up to the ZZZ line
+		command, _ := splitLine([]byte(text))
+		if e.inMessage {
+			if verb, _ := CommandWord(command); verb == ZZZ && len(command) != 0 {
+				e.inMessage = false
+			}
+			e.emit(text, origin)
+			continue
+		}
+
+		word, rest := directive(command)
+		switch word {
+		case "FLEET":
+			e.x.Expanded = true
+			e.fleet(origin, rest)
+		case "MACRO":
+			e.x.Expanded = true
+			body, end := block(input[i+1:], "MEND")
+			if vars != nil {
+				e.errorf(origin, "MACRO may not be defined inside a FOR or a macro")
+			} else if name := strings.ToUpper(strings.TrimSpace(string(rest))); name == "" {
+				e.errorf(origin, "MACRO is missing its name")
+			} else {
+				e.macros[name] = body
+			}
+			if !end {
+				e.errorf(origin, "MACRO is missing MEND")
+			}
+			i += len(body) + 1
+		case "FOR":
+			e.x.Expanded = true
+			class, fleet, order, err := forHeader(rest)
+			var body []srcLine
+			if order != "" {
+				body = []srcLine{{text: order, origin: origin}}
+			} else {
+				var end bool
+				body, end = block(input[i+1:], "NEXT")
+				if !end {
+					e.errorf(origin, "FOR is missing NEXT")
+				}
+				i += len(body) + 1
+			}
+			if err != nil {
+				e.errorf(origin, "%v", err)
+				continue
+			}
+			ships, ok := e.fleets[strings.ToUpper(fleet)]
+			if !ok {
+				e.errorf(origin, "unknown fleet %q", fleet)
+				continue
+			}
+			for _, ship := range ships {
+				if !inClass(ship, class) {
+					continue
+				}
+				loop := map[string]string{"SHIP": ship}
+				for key, value := range vars {
+					if key != "SHIP" {
+						loop[key] = value
+					}
+				}
+				// the lines of a block keep their own line numbers; a one-line order has the FOR line's
+				var lines []srcLine
+				for _, l := range body {
+					text := l.text
+					if order != "" && !strings.Contains(strings.ToUpper(text), "$SHIP") {
+						text = insertShip(text, ship)
+					}
+					lines = append(lines, srcLine{text: text, origin: l.origin})
+				}
+				e.run(lines, loop, depth)
+			}
+		case "CALL":
+			e.x.Expanded = true
+			if depth >= maxExpandDepth {
+				e.errorf(origin, "macros are nested too deeply")
+				continue
+			}
+			fields := strings.SplitN(strings.TrimSpace(string(rest))+" ", " ", 2)
+			name := strings.ToUpper(fields[0])
+			body, ok := e.macros[name]
+			if !ok {
+				e.errorf(origin, "unknown macro %q", fields[0])
+				continue
+			}
+			call := make(map[string]string)
+			for key, value := range vars {
+				if key == "SHIP" {
+					call[key] = value
+				}
+			}
+			if args := strings.TrimSpace(fields[1]); args != "" {
+				for n, arg := range strings.Split(args, ",") {
+					call[fmt.Sprint(n+1)] = strings.TrimSpace(arg)
+				}
+			}
+			var lines []srcLine
+			for _, l := range body {
+				lines = append(lines, srcLine{text: l.text, origin: origin})
+			}
+			e.run(lines, call, depth+1)
+		case "MEND", "NEXT":
+			e.x.Expanded = true
+			e.errorf(origin, "%s found without %s", word, map[string]string{"MEND": "MACRO", "NEXT": "FOR"}[word])
+		default:
+			if verb, _ := CommandWord(command); verb == MESSAGE {
+				e.inMessage = true
+			}
+			e.emit(text, origin)
+		}
+	}
+}
+
+// fleet adds the ships in a FLEET directive to the fleet.
+func (e *expander) fleet(origin int, rest []byte) {
+	name, rest := quoted(rest)
+	if name == "" {
+		e.errorf(origin, "FLEET is missing its name")
+		return
+	}
+	key := strings.ToUpper(name)
+	a := &args{b: rest}
+	for !a.empty() {
+		ship, ok := a.ship(false)
+		if !ok {
+			e.errorf(origin, "invalid ship name in FLEET %q", name)
+			return
+		}
+		e.fleets[key] = append(e.fleets[key], ship)
+	}
+	if len(e.fleets[key]) == 0 {
+		e.errorf(origin, "FLEET %q has no ships", name)
+	}
+}
+
+// directive returns the upper-case first word of a command and the rest of the command.
+func directive(command []byte) (string, []byte) {
+	i := 0
+	for i < len(command) && command[i] != ' ' && command[i] != '\t' {
+		i++
+	}
+	return strings.ToUpper(string(command[:i])), command[i:]
+}
+
+// block returns the lines up to a line that starts with the word end.
+// Returns false if there is no such line.
+func block(input []srcLine, end string) ([]srcLine, bool) {
+	for i, l := range input {
+		command, _ := splitLine([]byte(l.text))
+		if word, _ := directive(command); word == end {
+			return input[:i], true
+		}
+	}
+	return input, false
+}
+
+// forHeader parses "EACH ab IN FLEET name [order]".
+func forHeader(rest []byte) (class, fleet, order string, err error) {
+	each, rest := directive(bytes.TrimSpace(rest))
+	class, rest = directive(bytes.TrimSpace(rest))
+	in, rest := directive(bytes.TrimSpace(rest))
+	if each != "EACH" || class == "" || in != "IN" {
+		return "", "", "", fmt.Errorf("FOR must look like FOR EACH ab IN FLEET name")
+	}
+	if word, after := directive(bytes.TrimSpace(rest)); word == "FLEET" {
+		rest = after
+	}
+	if fleet, rest = quoted(rest); fleet == "" {
+		return "", "", "", fmt.Errorf("FOR is missing the fleet name")
+	}
+	return class, fleet, strings.TrimSpace(string(rest)), nil
+}
+
+// quoted returns a name that is either quoted with ' or " or a single word,
+// and the rest of the input.
+func quoted(b []byte) (string, []byte) {
+	b = bytes.TrimSpace(b)
+	if len(b) != 0 && (b[0] == '\'' || b[0] == '"') {
+		if i := bytes.IndexByte(b[1:], b[0]); i != -1 {
+			return strings.TrimSpace(string(b[1 : i+1])), b[i+2:]
+		}
+		return "", b
+	}
+	i := 0
+	for i < len(b) && b[i] != ' ' && b[i] != '\t' && b[i] != ',' {
+		i++
+	}
+	return string(b[:i]), b[i:]
+}
+
+// inClass returns true if the ship belongs to the class from a FOR directive.
+// A class with a size, like TR1, must match exactly, ignoring a trailing S for sub-light ships.
+// A class without one, like TR, matches every size. The class SHIP matches every ship.
+func inClass(ship, class string) bool {
+	abbr := strings.ToUpper(strings.SplitN(ship, " ", 2)[0])
+	switch {
+	case class == "SHIP" || class == "SHIPS":
+		return true
+	case strings.IndexAny(class, "0123456789") != -1:
+		return abbr == class || abbr == class+"S"
+	}
+	return strings.HasPrefix(abbr, class)
+}
+
+// insertShip inserts the name of a ship as the first argument of an order.
+func insertShip(text, ship string) string {
+	var comment string
+	if i := strings.IndexByte(text, ';'); i != -1 {
+		text, comment = text[:i], text[i:]
+	}
+	text = strings.TrimSpace(text)
+	word, rest := text, ""
+	if i := strings.IndexAny(text, " \t"); i != -1 {
+		word, rest = text[:i], strings.TrimSpace(text[i:])
+	}
+	text = word + " " + ship
+	if rest != "" {
+		text += ", " + rest
+	}
+	if comment != "" {
+		text += " " + comment
+	}
+	return text
+}
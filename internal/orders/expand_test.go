@@ -0,0 +1,114 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package orders
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestExpand checks the text, line numbers, and errors from expanding directives.
+func TestExpand(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		input  string
+		text   string
+		lines  []int
+		errors []string
+	}{
+		{
+			name:  "one-line fleet loop",
+			input: "FLEET 'Supply' TR1 Alpha, TR1 Beta, TR5 Gamma\nSTART POST-ARRIVAL\nFOR EACH TR1 IN FLEET 'Supply' UNLOAD\nEND",
+			text:  "START POST-ARRIVAL\nUNLOAD TR1 Alpha\nUNLOAD TR1 Beta\nEND",
+			lines: []int{2, 3, 3, 4},
+		},
+		{
+			name:  "fleet loop block",
+			input: "FLEET Scouts TR1 Alpha, TR1 Beta\nSTART JUMPS\nFOR EACH SHIP IN FLEET Scouts\nJUMP $SHIP, 1 2 3\nVISITED ; $SHIP\nNEXT\nEND",
+			text:  "START JUMPS\nJUMP TR1 Alpha, 1 2 3\nVISITED ; TR1 Alpha\nJUMP TR1 Beta, 1 2 3\nVISITED ; TR1 Beta\nEND",
+			lines: []int{2, 4, 5, 4, 5, 7},
+		},
+		{
+			name:  "nested call",
+			input: "MACRO Inner\nBUILD $1 IU\nMEND\nMACRO Outer\nCALL Inner $1\nBUILD $2 AU\nMEND\nSTART PRODUCTION\nCALL Outer 10, 5\nEND",
+			text:  "START PRODUCTION\nBUILD 10 IU\nBUILD 5 AU\nEND",
+			lines: []int{8, 9, 9, 10},
+		},
+		{
+			name:   "depth limit",
+			input:  "MACRO Loop\nCALL Loop\nMEND\nCALL Loop",
+			text:   "",
+			lines:  nil,
+			errors: []string{"4: macros are nested too deeply"},
+		},
+		{
+			name:   "missing NEXT",
+			input:  "FLEET Scouts TR1 Alpha\nFOR EACH TR IN FLEET Scouts\nVISITED $SHIP",
+			text:   "VISITED TR1 Alpha",
+			lines:  []int{3},
+			errors: []string{"2: FOR is missing NEXT"},
+		},
+		{
+			name:   "missing MEND",
+			input:  "MACRO Build\nBUILD 10 IU\nSTART PRODUCTION",
+			text:   "",
+			errors: []string{"1: MACRO is missing MEND"},
+		},
+		{
+			name:   "NEXT and MEND without FOR and MACRO",
+			input:  "NEXT\nMEND",
+			text:   "",
+			errors: []string{"1: NEXT found without FOR", "2: MEND found without MACRO"},
+		},
+		{
+			name:  "directives in message text",
+			input: "FLEET Scouts TR1 Alpha\nSTART PRE-DEPARTURE\nMESSAGE SP Klingon\nFOR EACH TR IN FLEET Scouts\nCALL Missing\nNEXT\nZZZ\nEND",
+			text:  "START PRE-DEPARTURE\nMESSAGE SP Klingon\nFOR EACH TR IN FLEET Scouts\nCALL Missing\nNEXT\nZZZ\nEND",
+			lines: []int{2, 3, 4, 5, 6, 7, 8},
+		},
+	} {
+		x := Expand([]byte(tc.input))
+		if !x.Expanded {
+			t.Errorf("%s: not expanded", tc.name)
+			continue
+		}
+		if string(x.Text) != tc.text {
+			t.Errorf("%s: text: got %q, want %q", tc.name, x.Text, tc.text)
+		}
+		if fmt.Sprint(x.Lines) != fmt.Sprint(tc.lines) {
+			t.Errorf("%s: lines: got %v, want %v", tc.name, x.Lines, tc.lines)
+		}
+		var errors []string
+		for _, err := range x.Errors {
+			errors = append(errors, err.Error())
+		}
+		if strings.Join(errors, "\n") != strings.Join(tc.errors, "\n") {
+			t.Errorf("%s: errors: got %q, want %q", tc.name, errors, tc.errors)
+		}
+	}
+}
+
+// TestExpandUnchanged checks that a file without directives is returned unchanged.
+func TestExpandUnchanged(t *testing.T) {
+	input := "START PRODUCTION\nBUILD 10 IU\nEND\n"
+	if x := Expand([]byte(input)); x.Expanded || string(x.Text) != input || len(x.Errors) != 0 {
+		t.Errorf("got %+v, want the input unchanged", x)
+	}
+}
@@ -0,0 +1,435 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package orders
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Format returns the orders file in canonical form.
+// Commands are written with their full names, arguments are separated by tabs,
+// and every section is a START ... END block separated by a blank line.
+// Comments, blank lines, and the text of messages are kept.
+// Lines with errors are kept as entered so that nothing is lost.
+//
+// If names is not nil, names that match a known ship, planet, or species
+// when case is ignored are rewritten with the spelling from the game data.
+//
+// Formatting the result again returns the same result.
+func Format(b []byte, names *Names) []byte {
+	f := &formatter{}
+	if names != nil {
+		f.ships, f.planets, f.species = upperNames(names.Ships), upperNames(names.Planets), upperNames(names.Species)
+	}
+	for _, l := range scan(b) {
+		switch l.kind {
+		case headerLine, textLine:
+			f.println(l.text)
+		case blankLine:
+			f.blank = len(f.lines) != 0
+		case commentLine:
+			f.print(l.inSection, withComment("", l.comment))
+		case startLine:
+			f.blank = len(f.lines) != 0
+			f.print(false, withComment(strings.TrimSpace("START "+l.name), l.comment))
+			f.blank = false
+		case endLine:
+			f.blank = false
+			f.print(false, withComment("END", l.comment))
+		case orderLine:
+			if text, ok := f.order(l.order); ok {
+				f.print(true, withComment(text, l.comment))
+			} else {
+				f.print(true, l.text)
+			}
+		case rawLine:
+			f.print(l.inSection, l.text)
+		case zzzLine:
+			f.println(withComment("ZZZ", l.comment))
+		}
+	}
+	return []byte(strings.Join(f.lines, "\n") + "\n")
+}
+
+// kinds of lines returned by scan
+const (
+	headerLine  = iota // line of the mail header
+	blankLine          // empty line
+	commentLine        // line with only a comment
+	startLine          // START of a section
+	endLine            // END of a section, possibly added for a section that was missing one
+	orderLine          // order inside a section
+	rawLine            // line that must be kept as entered, like an order outside of a section
+	textLine           // line of message text
+	zzzLine            // ZZZ line that ends the text of a message
+)
+
+// line is a line of an orders file, classified the same way that Parse sees it.
+type line struct {
+	kind      int
+	text      string // line without trailing spaces; raw lines are trimmed of leading spaces, too
+	comment   string
+	name      string // name of the section for START lines
+	order     Order  // set only for order and raw lines that hold an order
+	inSection bool
+}
+
+// scan splits an orders file into classified lines.
+// A section that is missing its END is given one.
+func scan(b []byte) []*line {
+	var lines []*line
+	add := func(l *line) *line {
+		lines = append(lines, l)
+		return l
+	}
+
+	input := bytes.Split(b, []byte{'\n'})
+	// the newline at the end of the file does not start another line
+	if len(input) != 0 && len(input[len(input)-1]) == 0 {
+		input = input[:len(input)-1]
+	}
+	n := skipMailHeader(input)
+	for _, text := range input[:n] {
+		add(&line{kind: headerLine, text: string(bytes.TrimRight(text, " \t\r"))})
+	}
+
+	inSection, inMessage := false, false
+	for ; n < len(input); n++ {
+		text := bytes.TrimRight(input[n], " \t\r")
+		command, comment := splitLine(text)
+		l := add(&line{text: string(text), comment: string(comment), inSection: inSection})
+
+		// the text of a message is everything up to the ZZZ line
+		if inMessage {
+			if verb, _ := CommandWord(command); verb == ZZZ && len(command) != 0 {
+				l.kind, inMessage = zzzLine, false
+			} else {
+				l.kind = textLine
+			}
+			continue
+		}
+
+		if len(command) == 0 {
+			if len(comment) == 0 {
+				l.kind = blankLine
+			} else {
+				l.kind = commentLine
+			}
+			continue
+		}
+		l.kind, l.text = rawLine, string(bytes.TrimSpace(text))
+		verb, rest := CommandWord(command)
+
+		switch verb {
+		case START:
+			if inSection {
+				lines = append(lines[:len(lines)-1], &line{kind: endLine}, l)
+			}
+			l.kind, l.name, l.inSection, inSection = startLine, sectionName(rest), false, true
+			continue
+		case END:
+			if inSection {
+				l.kind, l.inSection, inSection = endLine, false, false
+			}
+			continue
+		case ZZZ:
+			continue
+		}
+
+		l.order = parseOrder(Common{Line: n + 1, Verb: verb, OriginalInput: string(command)}, &args{b: rest})
+		if _, ok := l.order.(*Message); ok {
+			inMessage = true
+		}
+		if inSection {
+			l.kind = orderLine
+		}
+	}
+	if inSection && !inMessage {
+		add(&line{kind: endLine})
+	}
+
+	return lines
+}
+
+// formatter collects the lines of a formatted orders file.
+type formatter struct {
+	lines                   []string
+	blank                   bool // true if a blank line is pending
+	open                    bool // true if the last message written has no ZZZ line
+	ships, planets, species map[string]string
+}
+
+// print adds a line, indented with a tab if it is inside a section.
+func (f *formatter) print(indent bool, line string) {
+	if f.blank {
+		f.lines, f.blank = append(f.lines, ""), false
+	}
+	if indent {
+		line = "\t" + line
+	}
+	f.lines = append(f.lines, line)
+}
+
+// println adds a line as is.
+func (f *formatter) println(line string) {
+	f.lines = append(f.lines, line)
+}
+
+// withComment returns the text followed by the comment, if there is one.
+func withComment(text, comment string) string {
+	if comment == "" {
+		return text
+	} else if text == "" {
+		return "; " + comment
+	}
+	return text + "\t; " + comment
+}
+
+// order returns the canonical text of an order.
+// Returns false if the order has errors.
+func (f *formatter) order(order Order) (string, bool) {
+	if len(order.Errs()) != 0 {
+		return "", false
+	}
+	var fields []string
+	add := func(a ...interface{}) {
+		for _, v := range a {
+			fields = append(fields, fmt.Sprint(v))
+		}
+	}
+	coords := func(x, y, z int) string {
+		return fmt.Sprintf("%d %d %d", x, y, z)
+	}
+	diplomacy := func(all bool, species string) {
+		if all {
+			add(0)
+		} else {
+			add(f.name(species))
+		}
+	}
+	opponent := func(all bool, species string, distorted int) {
+		if all {
+			add(0)
+		} else if distorted != 0 {
+			add(fmt.Sprintf("SP %d", distorted))
+		} else {
+			add(f.name(species))
+		}
+	}
+
+	switch o := order.(type) {
+	case *Ally:
+		diplomacy(o.All, o.Species)
+	case *Ambush:
+		add(o.Amount)
+	case *Attack:
+		opponent(o.All, o.Species, o.Distorted)
+	case *Auto, *Shipyard, *Summary:
+	case *Base:
+		if o.Count != 0 {
+			add(o.Count)
+		}
+		add(f.name(o.Source), f.name(o.Base))
+	case *Battle:
+		add(coords(o.X, o.Y, o.Z))
+	case *Build:
+		if o.Species != "" {
+			add(f.name(o.Species))
+		}
+		if o.Item != "" {
+			add(fmt.Sprintf("%d %s", o.Count, o.Item))
+			if o.Destination != "" {
+				add(f.name(o.Destination))
+			}
+		} else {
+			add(f.name(o.Ship))
+			if o.LimitSpecified {
+				add(o.Limit)
+			}
+		}
+	case *Deep:
+		add(f.name(o.Ship))
+	case *Destroy:
+		add(f.name(o.Ship))
+	case *Develop:
+		if o.LimitSpecified {
+			add(o.Limit)
+		}
+		if o.Planet != "" {
+			add(f.name(o.Planet))
+		}
+		if o.Ship != "" {
+			add(f.name(o.Ship))
+		}
+	case *Disband:
+		add(f.name(o.Planet))
+	case *Enemy:
+		diplomacy(o.All, o.Species)
+	case *Engage:
+		add(o.Option)
+		if o.Planet != 0 {
+			add(o.Planet)
+		}
+	case *Estimate:
+		add(f.name(o.Species))
+	case *Haven:
+		add(coords(o.X, o.Y, o.Z))
+	case *Hide:
+		if o.Ship != "" {
+			add(f.name(o.Ship))
+		}
+	case *Hijack:
+		opponent(o.All, o.Species, o.Distorted)
+	case *Install:
+		if o.Item != "" {
+			add(fmt.Sprintf("%d %s", o.Count, o.Item))
+		}
+		add(f.name(o.Planet))
+	case *Intercept:
+		add(o.Amount)
+	case *Jump:
+		add(f.name(o.Ship))
+		if o.CoordsSpecified && o.Orbit != 0 {
+			add(fmt.Sprintf("%s %d", coords(o.X, o.Y, o.Z), o.Orbit))
+		} else if o.CoordsSpecified {
+			add(coords(o.X, o.Y, o.Z))
+		} else {
+			add(f.name(o.Planet))
+		}
+		if o.Portal != "" {
+			add(f.name(o.Portal))
+		}
+	case *Land:
+		add(f.name(o.Ship))
+		if o.Orbit != 0 {
+			add(o.Orbit)
+		} else if o.Planet != "" {
+			add(f.name(o.Planet))
+		}
+	case *Message:
+		add(f.name(o.Species))
+	case *Move:
+		add(f.name(o.Ship), coords(o.X, o.Y, o.Z))
+	case *Name:
+		add(fmt.Sprintf("%s %d", coords(o.X, o.Y, o.Z), o.Orbit), f.name(o.Planet))
+	case *Neutral:
+		diplomacy(o.All, o.Species)
+	case *Orbit:
+		add(f.name(o.Ship))
+		if o.OrbitSpecified {
+			add(o.Orbit)
+		} else if o.PlanetSpecified {
+			add(f.name(o.Planet))
+		}
+	case *Production:
+		add(f.name(o.Planet))
+	case *Recycle:
+		if o.Item != "" {
+			add(fmt.Sprintf("%d %s", o.Count, o.Item))
+		} else {
+			add(f.name(o.Ship))
+		}
+	case *Repair:
+		if o.Ship == "" {
+			add(coords(o.X, o.Y, o.Z))
+			if o.Age != 0 {
+				add(o.Age)
+			}
+		} else {
+			add(f.name(o.Ship))
+			if o.Count != 0 {
+				add(o.Count)
+			}
+		}
+	case *Research:
+		add(fmt.Sprintf("%d %s", o.Amount, o.Tech))
+	case *Scan:
+		add(f.name(o.Ship))
+	case *Send:
+		add(o.Amount, f.name(o.Species))
+	case *Target:
+		add(o.Target)
+	case *Teach:
+		add(o.Tech)
+		if o.Level != 0 {
+			add(o.Level)
+		}
+		add(f.name(o.Species))
+	case *Tech:
+		if o.Limit != 0 {
+			add(o.Limit)
+		}
+		add(o.Tech)
+		if o.Level != 0 {
+			add(o.Level)
+		}
+		add(f.name(o.Species))
+	case *Telescope:
+		add(f.name(o.Base))
+	case *Terraform:
+		if o.Count != 0 {
+			add(o.Count)
+		}
+		add(f.name(o.Planet))
+	case *Transfer:
+		add(fmt.Sprintf("%d %s", o.Count, o.Item), f.name(o.Source), f.name(o.Destination))
+	case *Unload:
+		add(f.name(o.Ship))
+	case *Upgrade:
+		add(f.name(o.Ship))
+		if o.LimitSpecified {
+			add(o.Limit)
+		}
+	case *Visited:
+		add(coords(o.X, o.Y, o.Z))
+	case *Withdraw:
+		add(fmt.Sprintf("%d %d %d", o.TransportAge, o.WarshipAge, o.FleetPercent))
+	case *Wormhole:
+		add(f.name(o.Ship))
+		if o.Orbit != 0 {
+			add(o.Orbit)
+		}
+	default:
+		return "", false
+	}
+	return strings.Join(append([]string{order.Command().String()}, fields...), "\t"), true
+}
+
+// name returns a name with its class abbreviation.
+// If the name matches a known name when case is ignored, the known spelling is used.
+func (f *formatter) name(s string) string {
+	i := strings.IndexByte(s, ' ')
+	if i == -1 {
+		return s
+	}
+	abbr, name := s[:i], s[i+1:]
+	known := f.ships
+	switch abbr {
+	case "PL":
+		known = f.planets
+	case "SP":
+		known = f.species
+	}
+	if spelling, ok := known[strings.ToUpper(name)]; ok {
+		name = spelling
+	}
+	return abbr + " " + name
+}
@@ -0,0 +1,80 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package orders
+
+import "testing"
+
+// formatTests are orders files that are used to test Format and the conversions
+// between text and documents.
+var formatTests = []struct {
+	name  string
+	input string
+}{
+	{"empty", ""},
+	{"blank lines", "\n\n\n"},
+	{"no trailing newline", "START PRODUCTION\nBUILD 10 IU"},
+	{"unterminated message", "START PRE-DEPARTURE\nMESSAGE SP Klingon\nhello there\n"},
+	{"unterminated message with blank lines", "START PRE-DEPARTURE\nMESSAGE SP Klingon\nhello\n\nthere\n\n"},
+	{"message", "START PRE-DEPARTURE\nMESSAGE SP Klingon\nhello there\n\n  indented text\nZZZ\nEND\n"},
+	{"missing end", "START COMBAT\nATTACK SP Klingon\nSTART JUMP\nJUMP TR1 Seeker, 1 2 3\n"},
+	{"comments", "; orders for turn 3\n\nSTART PRODUCTION ; produce\n  ; a comment\n  BUILD 10 IU ; more\nEND\n"},
+	{"orders outside a section", "JUMP TR1 Seeker, 1 2 3\nSTART PRODUCTION\nEND\n\n\nBUILD 10 IU\n"},
+	{"mail header", "From gm@example.com\nSubject: orders\n\nSTART PRODUCTION\nEND\n"},
+	{"carriage returns", "START PRODUCTION\r\nBUILD 10 IU\r\nEND\r\n"},
+	{"errors", "START PRODUCTION\nBUILD\nNOT-AN-ORDER 1 2 3\nEND\n"},
+	{"production", "START PRODUCTION\nPRODUCTION PL Home\n\nBUILD 5 CU ; for the colony\n\n\nRESEARCH 100 GV\n\nEND\n"},
+	{"message with comments", "START PRE-DEPARTURE\nMESSAGE SP Klingon ; hello\nhello there\nZZZ ; end of message\nEND\n"},
+	{"message with errors", "START PRE-DEPARTURE\nMESSAGE\nhello there\nZZZ ; end\nEND\n"},
+	{"unterminated message with errors", "START PRE-DEPARTURE\nMESSAGE\nhello there\n"},
+	{"message outside a section", "MESSAGE SP Klingon\nhello\nZZZ\nSTART COMBAT\nEND\n"},
+	{"comments between sections", "START COMBAT\nEND\n; between\n\nSTART PRODUCTION\nEND\n; after\n"},
+	{"jumps", "START JUMPS\nJUMP TR1 Alpha, 10 10 10 3\nPJUMP TR1 Beta, 1 2 3 4, BAS Gate\nJUMP TR1 Gamma, 1 2 3\nJUMP TR1 Delta, PL Home\nEND\n"},
+}
+
+// TestFormat checks the canonical form of some orders.
+func TestFormat(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"jump into orbit", "start jumps\n  jump tr1 Alpha, 10 10 10 3\nend\n", "START JUMPS\n\tJump\tTR1 Alpha\t10 10 10 3\nEND\n"},
+		{"jump to deep space", "START JUMPS\nJUMP TR1 Alpha, 10 10 10\nEND\n", "START JUMPS\n\tJump\tTR1 Alpha\t10 10 10\nEND\n"},
+		{"portal jump into orbit", "START JUMPS\nPJUMP TR1 Alpha, 1 2 3 4, BAS Gate\nEND\n", "START JUMPS\n\tPjump\tTR1 Alpha\t1 2 3 4\tBAS Gate\nEND\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := string(Format([]byte(tc.input), nil)); got != tc.want {
+				t.Errorf("got\n%q\nwant\n%q", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestFormatIdempotent checks that formatting the output of Format again
+// returns the same output.
+func TestFormatIdempotent(t *testing.T) {
+	for _, tc := range formatTests {
+		t.Run(tc.name, func(t *testing.T) {
+			once := Format([]byte(tc.input), nil)
+			if twice := Format(once, nil); string(twice) != string(once) {
+				t.Errorf("Format(Format(x)) != Format(x)\ninput:\n%q\nonce:\n%q\ntwice:\n%q", tc.input, once, twice)
+			}
+		})
+	}
+}
@@ -16,24 +16,223 @@ You should have received a copy of the GNU Affero General Public License
 along with this program.  If not, see <https://www.gnu.org/licenses/>.
 ******************************************************************************/
 
+// Package orders parses an orders file into typed orders.
+//
+// It is also the lexer shared by every reader of orders. The engine and the
+// web applications read command words, class abbreviations, names, and
+// values with CommandWord, ClassAbbr, GetName, GetValue, and SkipWhitespace,
+// so they agree on which command a line holds and what its arguments are.
+// The engine reads the arguments with these functions as it executes each
+// phase; the typed orders from Parse are used to report, format, check, and
+// complete orders, not to execute them.
 package orders
 
-import "bytes"
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
 
-// Read returns a command and error.
-// A command is the command, parameters, and comments.
-// Some commands, like START, have children.
-// Some commands, like MESSAGE, will consume multiple lines.
-func Read(b []byte) error {
-	// split input into lines
+// Orders is the parsed orders file for a single species.
+type Orders struct {
+	Sections []*Section
+	Errors   []error // errors that are not tied to a single order, always *Error
+}
+
+// Section is a START ... END section of the orders file.
+type Section struct {
+	Line   int    // line number of the START command
+	Name   string // COMBAT, PRE-DEPARTURE, JUMPS, PRODUCTION, POST-ARRIVAL, or STRIKES
+	Orders []Order
+}
+
+var sectionNames = []string{"COMBAT", "PRE-DEPARTURE", "JUMPS", "PRODUCTION", "POST-ARRIVAL", "STRIKES"}
+
+// Parse returns the orders from an orders file.
+// It never fails; problems are reported in the Errors of the
+// orders and of the individual orders.
+func Parse(b []byte) *Orders {
+	o := &Orders{}
 	lines := bytes.Split(b, []byte{'\n'})
-	// trim leading and trailing whitespace from every line
-	for i := 0; i < len(lines); i++ {
-		lines[i] = bytes.TrimSpace(lines[i])
+
+	var section *Section
+	var message *Message
+	for n := skipMailHeader(lines); n < len(lines); n++ {
+		line, lineNo := bytes.TrimRight(lines[n], " \t\r"), n+1
+
+		command, _ := splitLine(line)
+		if len(command) == 0 && message == nil {
+			continue
+		}
+		verb, rest := CommandWord(command)
+
+		// the text of a message is everything up to the ZZZ line
+		if message != nil {
+			if verb == ZZZ {
+				message.Unterminated = false
+				message = nil
+			} else {
+				message.Text = append(message.Text, string(line))
+			}
+			continue
+		}
+
+		switch verb {
+		case START:
+			if section != nil {
+				o.errorf(lineNo, "START found before END of %s section", section.Name)
+			}
+			section = &Section{Line: lineNo, Name: sectionName(rest)}
+			if o.Section(section.Name) != nil {
+				o.errorf(lineNo, "duplicate %s section", section.Name)
+			}
+			o.Sections = append(o.Sections, section)
+			continue
+		case END:
+			if section == nil {
+				o.errorf(lineNo, "END found outside of a section")
+			}
+			section = nil
+			continue
+		case ZZZ:
+			o.errorf(lineNo, "ZZZ found without MESSAGE")
+			continue
+		}
+
+		order := parseOrder(Common{Line: lineNo, Verb: verb, OriginalInput: string(command)}, &args{b: rest})
+		if m, ok := order.(*Message); ok {
+			message = m
+		}
+		if section == nil {
+			o.errorf(lineNo, "%s order found outside of a section", verb)
+			continue
+		}
+		section.Orders = append(section.Orders, order)
+	}
+	if message != nil {
+		// the message text swallowed the rest of the file, so point at the first order it hid
+		for i, text := range message.Text {
+			command, _ := splitLine([]byte(text))
+			if verb, _ := CommandWord(command); verb == START || verb == END {
+				message.errorf("unterminated MESSAGE, add a ZZZ line before line %d", message.Line+1+i)
+				message = nil
+				break
+			}
+		}
+		if message != nil {
+			message.errorf("unterminated MESSAGE, add a ZZZ line after the message text")
+		}
+	}
+	if section != nil {
+		o.errorf(section.Line, "%s section is missing END", section.Name)
+	}
+
+	return o
+}
+
+// Error is an error found on a line of the orders file.
+type Error struct {
+	Line int
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d: %v", e.Line, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// AllErrors returns the errors for the orders and for every order, sorted by line number.
+// Every error in the list is an *Error.
+func (o *Orders) AllErrors() []error {
+	errs := append([]error{}, o.Errors...)
+	for _, section := range o.Sections {
+		for _, order := range section.Orders {
+			for _, err := range order.Errs() {
+				errs = append(errs, &Error{Line: order.LineNo(), Err: fmt.Errorf("%s: %w", order.Command(), err)})
+			}
+		}
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].(*Error).Line < errs[j].(*Error).Line
+	})
+	return errs
+}
+
+// NoOrders returns true if there are no orders in any section.
+func (o *Orders) NoOrders() bool {
+	if o == nil {
+		return true
+	}
+	for _, section := range o.Sections {
+		if len(section.Orders) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Section returns the first section with the given name, or nil if there is none.
+// Like the engine, only the first three letters of the name are compared.
+func (o *Orders) Section(name string) *Section {
+	if o == nil {
+		return nil
+	}
+	key := sectionKey(name)
+	for _, section := range o.Sections {
+		if sectionKey(section.Name) == key {
+			return section
+		}
 	}
 	return nil
 }
 
+// errorf adds an error for the orders file.
+func (o *Orders) errorf(line int, format string, a ...interface{}) {
+	o.Errors = append(o.Errors, &Error{Line: line, Err: fmt.Errorf(format, a...)})
+}
+
+// sectionKey returns the first three letters of a section name in upper case.
+func sectionKey(name string) string {
+	if name = strings.ToUpper(strings.TrimSpace(name)); len(name) > 3 {
+		name = name[:3]
+	}
+	return name
+}
+
+// sectionName returns the name of the section from the arguments of a START command.
+func sectionName(rest []byte) string {
+	fields := strings.Fields(string(rest))
+	if len(fields) == 0 {
+		return ""
+	}
+	key := sectionKey(fields[0])
+	for _, name := range sectionNames {
+		if key == name[:3] {
+			return name
+		}
+	}
+	return strings.ToUpper(fields[0])
+}
+
+// skipMailHeader returns the index of the first line after any mail header.
+func skipMailHeader(lines [][]byte) int {
+	n := 0
+	for n < len(lines) && len(bytes.TrimSpace(lines[n])) == 0 {
+		n++
+	}
+	if n == len(lines) || !bytes.HasPrefix(lines[n], []byte("From ")) {
+		return 0
+	}
+	for n < len(lines) && len(bytes.TrimSpace(lines[n])) != 0 {
+		n++
+	}
+	return n
+}
+
 // bdup wastes memory and cycles by needlessly making copies of slices.
 func bdup(src []byte) (dst []byte) {
 	if src == nil {
@@ -0,0 +1,460 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package orders
+
+import "fmt"
+
+// errorf adds an error to the order.
+func (c *Common) errorf(format string, a ...interface{}) {
+	c.Errors = append(c.Errors, fmt.Errorf(format, a...))
+}
+
+// parseOrder returns the typed order for a command.
+// The caller sets the line number, command, and original input in c.
+func parseOrder(c Common, a *args) Order {
+	switch c.Verb {
+	case ALLY:
+		o := &Ally{Common: c}
+		o.All, o.Species = parseDiplomacy(&o.Common, a)
+		return o
+	case AMBUSH:
+		o := &Ambush{Common: c}
+		o.Amount = parseAmount(&o.Common, a)
+		return o
+	case ATTACK:
+		o := &Attack{Common: c}
+		o.All, o.Species, o.Distorted = parseOpponent(&o.Common, a)
+		return o
+	case AUTO:
+		return &Auto{Common: c}
+	case BASE:
+		return parseBase(c, a)
+	case BATTLE:
+		o := &Battle{Common: c}
+		o.X, o.Y, o.Z = parseCoords(&o.Common, a)
+		return o
+	case BUILD, CONTINUE, IBUILD, ICONTINUE:
+		return parseBuild(c, a)
+	case DEEP:
+		o := &Deep{Common: c}
+		o.Ship = parseShip(&o.Common, a, false)
+		return o
+	case DESTROY:
+		o := &Destroy{Common: c}
+		o.Ship = parseShip(&o.Common, a, false)
+		return o
+	case DEVELOP:
+		return parseDevelop(c, a)
+	case DISBAND:
+		o := &Disband{Common: c}
+		o.Planet = parsePlanet(&o.Common, a)
+		return o
+	case ENEMY:
+		o := &Enemy{Common: c}
+		o.All, o.Species = parseDiplomacy(&o.Common, a)
+		return o
+	case ENGAGE:
+		o := &Engage{Common: c}
+		var ok bool
+		if o.Option, ok = a.value(); !ok || o.Option < 0 || o.Option > 7 {
+			o.errorf("invalid engagement option")
+		}
+		o.Planet, _ = a.value()
+		return o
+	case ESTIMATE:
+		o := &Estimate{Common: c}
+		o.Species = parseSpecies(&o.Common, a, false)
+		return o
+	case HAVEN:
+		o := &Haven{Common: c}
+		o.X, o.Y, o.Z = parseCoords(&o.Common, a)
+		return o
+	case HIDE:
+		o := &Hide{Common: c}
+		if !a.empty() {
+			o.Ship = parseShip(&o.Common, a, false)
+		}
+		return o
+	case HIJACK:
+		o := &Hijack{Common: c}
+		o.All, o.Species, o.Distorted = parseOpponent(&o.Common, a)
+		return o
+	case INSTALL:
+		return parseInstall(c, a)
+	case INTERCEPT:
+		o := &Intercept{Common: c}
+		o.Amount = parseAmount(&o.Common, a)
+		return o
+	case JUMP, PJUMP:
+		return parseJump(c, a)
+	case LAND:
+		o := &Land{Common: c}
+		o.Ship = parseShip(&o.Common, a, true)
+		if n, ok := a.value(); ok {
+			o.Orbit = n
+		} else if !a.empty() {
+			o.Planet = parsePlanet(&o.Common, a)
+		}
+		return o
+	case MESSAGE:
+		o := &Message{Common: c, Unterminated: true}
+		o.Species = parseSpecies(&o.Common, a, false)
+		return o
+	case MOVE:
+		o := &Move{Common: c}
+		o.Ship = parseShip(&o.Common, a, true)
+		o.X, o.Y, o.Z = parseCoords(&o.Common, a)
+		return o
+	case NAME:
+		o := &Name{Common: c}
+		o.X, o.Y, o.Z = parseCoords(&o.Common, a)
+		var ok bool
+		if o.Orbit, ok = a.value(); !ok || o.Orbit < 1 || o.Orbit > 9 {
+			o.errorf("invalid planet number")
+		}
+		o.Planet = parsePlanet(&o.Common, a)
+		return o
+	case NEUTRAL:
+		o := &Neutral{Common: c}
+		o.All, o.Species = parseDiplomacy(&o.Common, a)
+		return o
+	case ORBIT:
+		o := &Orbit{Common: c}
+		o.Ship = parseShip(&o.Common, a, true)
+		if n, ok := a.value(); ok {
+			o.Orbit, o.OrbitSpecified = n, true
+		} else if !a.empty() {
+			o.Planet, o.PlanetSpecified = parsePlanet(&o.Common, a), true
+		}
+		return o
+	case PRODUCTION:
+		o := &Production{Common: c}
+		o.Planet = parsePlanet(&o.Common, a)
+		return o
+	case RECYCLE:
+		o := &Recycle{Common: c}
+		if n, ok := a.value(); ok {
+			o.Count = n
+			o.Item = parseItem(&o.Common, a)
+		} else {
+			o.Ship = parseShip(&o.Common, a, false)
+		}
+		return o
+	case REPAIR:
+		return parseRepair(c, a)
+	case RESEARCH:
+		o := &Research{Common: c}
+		o.Amount = parseAmount(&o.Common, a)
+		o.Tech = parseTech(&o.Common, a)
+		return o
+	case SCAN:
+		o := &Scan{Common: c}
+		o.Ship = parseShip(&o.Common, a, false)
+		return o
+	case SEND:
+		o := &Send{Common: c}
+		o.Amount = parseAmount(&o.Common, a)
+		o.Species = parseSpecies(&o.Common, a, false)
+		return o
+	case SHIPYARD:
+		return &Shipyard{Common: c}
+	case SUMMARY:
+		return &Summary{Common: c}
+	case TARGET:
+		o := &Target{Common: c}
+		var ok bool
+		if o.Target, ok = a.value(); !ok || o.Target < 1 || o.Target > 4 {
+			o.errorf("invalid target type")
+		}
+		return o
+	case TEACH:
+		o := &Teach{Common: c}
+		o.Tech = parseTech(&o.Common, a)
+		o.Level, _ = a.value()
+		o.Species = parseSpecies(&o.Common, a, false)
+		return o
+	case TECH:
+		o := &Tech{Common: c}
+		o.Limit, _ = a.value()
+		o.Tech = parseTech(&o.Common, a)
+		o.Level, _ = a.value()
+		o.Species = parseSpecies(&o.Common, a, false)
+		return o
+	case TELESCOPE:
+		o := &Telescope{Common: c}
+		o.Base = parseShip(&o.Common, a, false)
+		return o
+	case TERRAFORM:
+		o := &Terraform{Common: c}
+		o.Count, _ = a.value()
+		o.Planet = parsePlanet(&o.Common, a)
+		return o
+	case TRANSFER:
+		return parseTransfer(c, a)
+	case UNLOAD:
+		o := &Unload{Common: c}
+		o.Ship = parseShip(&o.Common, a, false)
+		return o
+	case UPGRADE:
+		o := &Upgrade{Common: c}
+		o.Ship = parseShip(&o.Common, a, true)
+		o.Limit, o.LimitSpecified = a.value()
+		return o
+	case VISITED:
+		o := &Visited{Common: c}
+		o.X, o.Y, o.Z = parseCoords(&o.Common, a)
+		return o
+	case WITHDRAW:
+		o := &Withdraw{Common: c}
+		for _, p := range []*int{&o.TransportAge, &o.WarshipAge, &o.FleetPercent} {
+			var ok bool
+			if *p, ok = a.value(); !ok || *p < 0 || *p > 100 {
+				o.errorf("invalid or missing argument")
+				break
+			}
+		}
+		return o
+	case WORMHOLE:
+		o := &Wormhole{Common: c}
+		o.Ship = parseShip(&o.Common, a, true)
+		o.Orbit, _ = a.value()
+		return o
+	case RENAME, SURRENDER:
+		o := &Unknown{Common: c}
+		o.errorf("%s is not implemented", c.Verb)
+		return o
+	}
+	o := &Unknown{Common: c}
+	o.errorf("undefined command")
+	return o
+}
+
+// parseAmount reads a required, non-negative amount.
+func parseAmount(c *Common, a *args) int {
+	n, ok := a.value()
+	if !ok || n < 0 {
+		c.errorf("invalid or missing amount")
+	}
+	return n
+}
+
+// parseCoords reads the required coordinates of a sector.
+func parseCoords(c *Common, a *args) (x, y, z int) {
+	x, y, z, ok := a.coords()
+	if !ok {
+		c.errorf("invalid coordinates")
+	}
+	return x, y, z
+}
+
+// parseDiplomacy reads the target of an ALLY, ENEMY, or NEUTRAL order.
+// A number in place of the species name means all species.
+func parseDiplomacy(c *Common, a *args) (all bool, species string) {
+	if _, ok := a.value(); ok {
+		return true, ""
+	}
+	return false, parseSpecies(c, a, false)
+}
+
+// parseItem reads a required item class.
+func parseItem(c *Common, a *args) string {
+	item, ok := a.item()
+	if !ok {
+		c.errorf("invalid item class")
+	}
+	return item
+}
+
+// parseOpponent reads the target of an ATTACK or HIJACK order.
+func parseOpponent(c *Common, a *args) (all bool, species string, distorted int) {
+	if n, ok := a.value(); ok {
+		if n != 0 {
+			c.errorf("invalid species number")
+		}
+		return true, "", 0
+	}
+	saved := a.b
+	if kind, _ := a.abbr(); kind == SPECIES_ID {
+		if n, ok := a.value(); ok {
+			if n < 1 {
+				c.errorf("invalid species number")
+			}
+			return false, "", n
+		}
+	}
+	a.b = saved
+	return false, parseSpecies(c, a, false), 0
+}
+
+// parsePlanet reads a required planet name.
+func parsePlanet(c *Common, a *args) string {
+	planet, ok := a.planet(false)
+	if !ok {
+		c.errorf("invalid planet name")
+	}
+	return planet
+}
+
+// parseShip reads a required ship or starbase name.
+// Set more if the name may be followed by more arguments.
+func parseShip(c *Common, a *args, more bool) string {
+	ship, ok := a.ship(more)
+	if !ok {
+		c.errorf("invalid ship name")
+	}
+	return ship
+}
+
+// parseSpecies reads a required species name.
+// Set more if the name may be followed by more arguments.
+func parseSpecies(c *Common, a *args, more bool) string {
+	species, ok := a.species(more)
+	if !ok {
+		c.errorf("invalid species name")
+	}
+	return species
+}
+
+// parseTech reads a required technology abbreviation.
+func parseTech(c *Common, a *args) string {
+	tech, ok := a.tech()
+	if !ok {
+		c.errorf("invalid tech")
+	}
+	return tech
+}
+
+func parseBase(c Common, a *args) Order {
+	o := &Base{Common: c}
+	if n, ok := a.value(); ok {
+		if n < 0 {
+			o.errorf("invalid starbase unit count")
+		}
+		o.Count = n
+	}
+	var ok bool
+	if o.Source, ok = a.transferPoint(true); !ok {
+		o.errorf("invalid source location")
+	}
+	if o.Base, ok = a.ship(false); !ok || o.Base[:2] != "BA" {
+		o.errorf("invalid starbase name")
+	}
+	return o
+}
+
+func parseBuild(c Common, a *args) Order {
+	o := &Build{Common: c}
+	o.Continuation = c.Verb == CONTINUE || c.Verb == ICONTINUE
+	if c.Verb == IBUILD || c.Verb == ICONTINUE {
+		o.Species = parseSpecies(&o.Common, a, true)
+	}
+	if !o.Continuation {
+		if n, ok := a.value(); ok {
+			o.Count = n
+			if o.Item, ok = a.item(); !ok || o.Item == "RM" {
+				o.errorf("invalid item class")
+			}
+			if c.Verb == BUILD && !a.empty() {
+				if o.Destination, ok = a.transferPoint(false); !ok {
+					o.errorf("invalid destination")
+				}
+			}
+			return o
+		}
+	}
+	o.Ship = parseShip(&o.Common, a, true)
+	o.Limit, o.LimitSpecified = a.value()
+	return o
+}
+
+func parseDevelop(c Common, a *args) Order {
+	o := &Develop{Common: c}
+	o.Limit, o.LimitSpecified = a.value()
+	if a.empty() {
+		return o
+	}
+	var ok bool
+	if o.Planet, ok = a.planet(true); !ok {
+		o.errorf("invalid planet name")
+	}
+	if !a.empty() {
+		o.Ship = parseShip(&o.Common, a, false)
+	}
+	return o
+}
+
+func parseInstall(c Common, a *args) Order {
+	o := &Install{Common: c}
+	if n, ok := a.value(); ok {
+		if n < 0 {
+			o.errorf("invalid unit count")
+		}
+		o.Count = n
+		if o.Item, ok = a.item(); !ok || (o.Item != "IU" && o.Item != "AU") {
+			o.errorf("invalid item class")
+		}
+	}
+	o.Planet = parsePlanet(&o.Common, a)
+	return o
+}
+
+func parseJump(c Common, a *args) Order {
+	o := &Jump{Common: c}
+	o.Ship = parseShip(&o.Common, a, true)
+	var ok bool
+	if o.X, o.Y, o.Z, ok = a.coords(); ok {
+		o.CoordsSpecified = true
+		// the engine reads an optional planet number after the coordinates
+		o.Orbit, _ = a.value()
+	} else if o.Planet, ok = a.planet(c.Verb == PJUMP); !ok {
+		o.errorf("invalid destination")
+	}
+	if c.Verb == PJUMP {
+		if o.Portal, ok = a.ship(false); !ok {
+			o.errorf("invalid jump portal")
+		}
+	}
+	return o
+}
+
+func parseRepair(c Common, a *args) Order {
+	o := &Repair{Common: c}
+	var ok bool
+	if o.X, o.Y, o.Z, ok = a.coords(); ok {
+		o.Age, _ = a.value()
+		return o
+	}
+	o.Ship = parseShip(&o.Common, a, true)
+	o.Count, _ = a.value()
+	return o
+}
+
+func parseTransfer(c Common, a *args) Order {
+	o := &Transfer{Common: c}
+	o.Count = parseAmount(&o.Common, a)
+	o.Item = parseItem(&o.Common, a)
+	var ok bool
+	if o.Source, ok = a.transferPoint(true); !ok {
+		o.errorf("invalid source location")
+	}
+	if o.Destination, ok = a.transferPoint(false); !ok {
+		o.errorf("invalid destination")
+	}
+	return o
+}
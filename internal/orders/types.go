@@ -0,0 +1,554 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package orders
+
+// Order is implemented by every typed order.
+type Order interface {
+	Command() COMMAND
+	Input() string
+	LineNo() int
+	Errs() []error
+}
+
+// Common holds the fields shared by every order.
+type Common struct {
+	Line          int     `json:"-"` // line number of the order in the orders file
+	Verb          COMMAND `json:"-"` // command word used for the order
+	OriginalInput string  `json:"-"` // the order as entered, without the trailing comment
+	Errors        []error `json:"-"` // errors found while parsing the order
+}
+
+func (c *Common) Command() COMMAND {
+	return c.Verb
+}
+
+func (c *Common) Input() string {
+	return c.OriginalInput
+}
+
+func (c *Common) LineNo() int {
+	return c.Line
+}
+
+func (c *Common) Errs() []error {
+	return c.Errors
+}
+
+// Note: in the descriptions below
+//   ab      is a class abbreviation
+//   base    is the name of a starbase, including "BAS" abbreviation
+//   d       is the name of a ship, starbase, or planet, including class abbreviation
+//   loc     is a jump destination, either "x y z" or "PL name"
+//   n       is a whole number, 0 or more
+//   [n]     is an optional whole number, 1 or more
+//   name    is a name string, including any embedded spaces. May not start with a digit!
+//   p       is a planet number
+//   pl      is a planet name, including abbreviation "PL"
+//   s       is the name of a ship, starbase, or planet, including class abbreviation
+//   section is COMBAT, PRE-DEPARTURE, JUMPS, PRODUCTION, POST-ARRIVAL, or STRIKES
+//   ship    is the name of a ship, including class abbreviation
+//   sp      is a species name, including "SP" abbreviation
+//   tech    is a technology abbreviation: MI, MA, ML, GV, LS, or BI
+//   x y z   are the galactic coordinates of a sector
+//
+// Names are stored with their class abbreviation, for example "TR1 Hauler" or "PL Home".
+
+// Ally
+//
+//	ALLY sp   Declare species "sp" to be an ally
+//	ALLY n    Declare all species to be allies
+type Ally struct {
+	Common
+	All     bool   `json:"all,omitempty"`
+	Species string `json:"species,omitempty"`
+}
+
+// Ambush
+//
+//	AMBUSH n  Spend "n" in preparation for ambush
+type Ambush struct {
+	Common
+	Amount int `json:"amount,omitempty"`
+}
+
+// Attack
+//
+//	ATTACK sp    Attack opponent "sp"
+//	ATTACK SP n  Attack field-distorted species number "n"
+//	ATTACK 0     Attack all declared enemies
+type Attack struct {
+	Common
+	All       bool   `json:"all,omitempty"`
+	Species   string `json:"species,omitempty"`
+	Distorted int    `json:"distorted,omitempty"` // set only when attacking a field-distorted species
+}
+
+// Auto
+//
+//	AUTO  Automatically generate sensible orders for next turn
+type Auto struct {
+	Common
+}
+
+// Base
+//
+//	BASE n s, base  Build or increase size of starbase "base" using "n" starbase units from "s"
+//	BASE s, base    Use all available starbase units from "s"
+type Base struct {
+	Common
+	Count  int    `json:"count,omitempty"` // zero means all available units
+	Source string `json:"source,omitempty"`
+	Base   string `json:"base,omitempty"`
+}
+
+// Battle
+//
+//	BATTLE x y z  Start the combat orders for the battle at sector "x y z"
+type Battle struct {
+	Common
+	X int `json:"x"`
+	Y int `json:"y"`
+	Z int `json:"z"`
+}
+
+// Build is used for BUILD, CONTINUE, IBUILD, and ICONTINUE.
+//
+//	BUILD n ab [,d]   Build "n" items of class "ab", optionally transferring them to "d"
+//	BUILD ship        Build "ship"
+//	BUILD ship,n      Start building "ship", spend only "n"
+//	BUILD base,n      Start building starbase "base", spend "n"
+//	CONTINUE ship     Finish construction of "ship"
+//	CONTINUE ship,n   Continue construction on "ship", spend only "n"
+//	CONTINUE base,n   Increase size of starbase "base", spend "n"
+//	IBUILD sp,n ab    Build "n" items of class "ab" for species "sp"
+//	IBUILD sp,ship    Build "ship" for species "sp"
+//	IBUILD sp,base,n  Build starbase "base" for species "sp", spend "n"
+//	ICONTINUE sp,ship    Finish construction of "ship" for species "sp"
+//	ICONTINUE sp,base,n  Increase size of starbase "base" for species "sp", spend "n"
+type Build struct {
+	Common
+	Count          int    `json:"count,omitempty"`           // number of items to build
+	Item           string `json:"item,omitempty"`            // code of item to build
+	Destination    string `json:"destination,omitempty"`     // set only when items are transferred after building
+	Ship           string `json:"ship,omitempty"`            // name of ship or starbase to build
+	Limit          int    `json:"limit,omitempty"`           // maximum amount to spend this turn
+	LimitSpecified bool   `json:"limit_specified,omitempty"` // true if the player specified any amount, even zero
+	Species        string `json:"species,omitempty"`         // set only when building for another species
+	Continuation   bool   `json:"-"`                         // set only when continuing an existing build
+}
+
+// Deep
+//
+//	DEEP ship  Put "ship" into deep space
+type Deep struct {
+	Common
+	Ship string `json:"ship,omitempty"`
+}
+
+// Destroy
+//
+//	DESTROY ship  Destroy "ship"
+//	DESTROY base  Destroy starbase "base"
+type Destroy struct {
+	Common
+	Ship string `json:"ship,omitempty"`
+}
+
+// Develop
+//
+//	DEVELOP [n]           Build CUs, IUs, and AUs for producing planet but do not spend more than "n"
+//	DEVELOP [n] pl        Build CUs, IUs, and AUs for colony planet "pl" in same sector but do not spend more than "n"
+//	DEVELOP [n] pl, ship  Build CUs, IUs, and AUs for colony planet "pl" and load units onto "ship" but do not spend more than "n"
+type Develop struct {
+	Common
+	Limit          int    `json:"limit,omitempty"`
+	LimitSpecified bool   `json:"limit_specified,omitempty"`
+	Planet         string `json:"planet,omitempty"`
+	Ship           string `json:"ship,omitempty"`
+}
+
+// Disband
+//
+//	DISBAND pl  Disband colony "pl"
+type Disband struct {
+	Common
+	Planet string `json:"planet,omitempty"`
+}
+
+// Enemy
+//
+//	ENEMY sp  Declare species "sp" to be an enemy
+//	ENEMY n   Declare all species to be enemies
+type Enemy struct {
+	Common
+	All     bool   `json:"all,omitempty"`
+	Species string `json:"species,omitempty"`
+}
+
+// Engage
+//
+//	ENGAGE n [p]  Specify combat engagement option "n" and optional planet number "p"
+type Engage struct {
+	Common
+	Option int `json:"option,omitempty"`
+	Planet int `json:"planet,omitempty"` // zero if not specified
+}
+
+// Estimate
+//
+//	ESTIMATE sp  Estimate tech levels of species "sp"
+type Estimate struct {
+	Common
+	Species string `json:"species,omitempty"`
+}
+
+// Haven
+//
+//	HAVEN x y z  Set rendezvous point for ships that withdraw from combat
+type Haven struct {
+	Common
+	X int `json:"x"`
+	Y int `json:"y"`
+	Z int `json:"z"`
+}
+
+// Hide
+//
+//	HIDE       Actively hide this planet from alien observation
+//	HIDE ship  Keep "ship" out of combat unless you start to lose the battle
+type Hide struct {
+	Common
+	Ship string `json:"ship,omitempty"` // empty when hiding the planet
+}
+
+// Hijack
+//
+//	HIJACK sp    Hijack opponent "sp"
+//	HIJACK SP n  Hijack field-distorted species number "n"
+//	HIJACK 0     Hijack all declared enemies
+type Hijack struct {
+	Common
+	All       bool   `json:"all,omitempty"`
+	Species   string `json:"species,omitempty"`
+	Distorted int    `json:"distorted,omitempty"` // set only when hijacking a field-distorted species
+}
+
+// Install
+//
+//	INSTALL n ab pl  Install "n" IUs or AUs on planet "pl"
+//	INSTALL pl       Install all available IUs and AUs on planet "pl"
+type Install struct {
+	Common
+	Count  int    `json:"count,omitempty"` // zero means all available units
+	Item   string `json:"item,omitempty"`  // empty means both IUs and AUs
+	Planet string `json:"planet,omitempty"`
+}
+
+// Intercept
+//
+//	INTERCEPT n  Spend "n" in preparation for interception
+type Intercept struct {
+	Common
+	Amount int `json:"amount,omitempty"`
+}
+
+// Jump is used for JUMP and PJUMP.
+//
+//	JUMP ship,loc         Have "ship" jump to destination "loc"
+//	PJUMP ship,loc,base   Have "ship" jump to destination "loc" via jump portals on starbase "base"
+//
+// A location given as coordinates may be followed by the number of a planet
+// to orbit, as in "JUMP TR1 Alpha, 10 10 10 3".
+type Jump struct {
+	Common
+	Ship            string `json:"ship,omitempty"`
+	X               int    `json:"x,omitempty"`
+	Y               int    `json:"y,omitempty"`
+	Z               int    `json:"z,omitempty"`
+	Orbit           int    `json:"orbit,omitempty"`  // set only when the coordinates are followed by a planet number
+	Planet          string `json:"planet,omitempty"` // set only when the destination is a named planet
+	CoordsSpecified bool   `json:"-"`
+	Portal          string `json:"portal,omitempty"` // set only for PJUMP
+}
+
+// Land
+//
+//	LAND ship [,pl]  Have "ship" land on planet in same star system
+//	LAND ship, p     Have "ship" land on planet number "p" in same star system
+type Land struct {
+	Common
+	Ship   string `json:"ship,omitempty"`
+	Orbit  int    `json:"orbit,omitempty"`  // zero if not specified
+	Planet string `json:"planet,omitempty"` // empty if not specified
+}
+
+// Message
+//
+//	MESSAGE sp  Send a message to species "sp"; the text ends with a ZZZ line
+type Message struct {
+	Common
+	Species      string   `json:"species,omitempty"`
+	Text         []string `json:"text,omitempty"`
+	Unterminated bool     `json:"-"` // set only on error when parsing
+}
+
+// Move
+//
+//	MOVE ship, x y z  Move "ship" up to one parsec
+//	MOVE base, x y z  Tow starbase "base" up to one parsec
+type Move struct {
+	Common
+	Ship string `json:"ship,omitempty"`
+	X    int    `json:"x"`
+	Y    int    `json:"y"`
+	Z    int    `json:"z"`
+}
+
+// Name
+//
+//	NAME x y z p PL name  Give "name" to planet "p" at location "x y z"
+type Name struct {
+	Common
+	X      int    `json:"x"`
+	Y      int    `json:"y"`
+	Z      int    `json:"z"`
+	Orbit  int    `json:"orbit,omitempty"`
+	Planet string `json:"planet,omitempty"` // name for planet, includes the PL code
+}
+
+// Neutral
+//
+//	NEUTRAL sp  Declare neutrality towards species "sp"
+//	NEUTRAL n   Declare neutrality towards all species
+type Neutral struct {
+	Common
+	All     bool   `json:"all,omitempty"`
+	Species string `json:"species,omitempty"`
+}
+
+// Orbit
+//
+//	ORBIT ship,pl  Have "ship" orbit planet in same star system
+//	ORBIT ship,p   Have "ship" orbit planet number "p" in same star system
+//	ORBIT ship     Have "ship" orbit the planet it is already at
+//
+// The engine does not accept coordinates, so Parse never sets X, Y, Z, or
+// CoordsSpecified. They are used by the ORBIT command in cms.
+type Orbit struct {
+	Common
+	Ship            string `json:"ship,omitempty"`
+	X, Y, Z         int    `json:"-"`
+	Orbit           int    `json:"orbit,omitempty"`
+	Planet          string `json:"planet,omitempty"` // name for planet, includes the PL code
+	CoordsSpecified bool   `json:"-"`
+	OrbitSpecified  bool   `json:"-"`
+	PlanetSpecified bool   `json:"-"`
+}
+
+// Production
+//
+//	PRODUCTION pl  Start production on planet "pl"
+type Production struct {
+	Common
+	Planet string `json:"planet,omitempty"`
+}
+
+// Recycle
+//
+//	RECYCLE n ab  Recycle "n" items of class "ab"
+//	RECYCLE ship  Recycle "ship"
+//	RECYCLE base  Recycle starbase "base"
+type Recycle struct {
+	Common
+	Count int    `json:"count,omitempty"`
+	Item  string `json:"item,omitempty"`
+	Ship  string `json:"ship,omitempty"`
+}
+
+// Repair
+//
+//	REPAIR ship,n       Repair "ship" using "n" onboard damage repair units
+//	REPAIR base,n       Repair "base" using "n" onboard damage repair units
+//	REPAIR x y z [age]  Repair as many ships/starbases as possible in sector x y z,
+//	                    pooling damage repair units but do not reduce age below "age"
+type Repair struct {
+	Common
+	Ship  string `json:"ship,omitempty"`  // empty when pooling repairs in a sector
+	Count int    `json:"count,omitempty"` // zero means all available units
+	X     int    `json:"x,omitempty"`
+	Y     int    `json:"y,omitempty"`
+	Z     int    `json:"z,omitempty"`
+	Age   int    `json:"age,omitempty"`
+}
+
+// Research
+//
+//	RESEARCH n tech  Spend "n" on research in technology "tech"
+type Research struct {
+	Common
+	Amount int    `json:"amount,omitempty"`
+	Tech   string `json:"tech,omitempty"`
+}
+
+// Scan
+//
+//	SCAN ship  Have "ship" do a scan of its current location
+type Scan struct {
+	Common
+	Ship string `json:"ship,omitempty"`
+}
+
+// Send
+//
+//	SEND n sp  Send "n" economic units to species "sp"
+type Send struct {
+	Common
+	Amount  int    `json:"amount,omitempty"`
+	Species string `json:"species,omitempty"`
+}
+
+// Shipyard
+//
+//	SHIPYARD  Increase shipyard capacity by one
+type Shipyard struct {
+	Common
+}
+
+// Summary
+//
+//	SUMMARY  Provide only a brief summary of combat results
+type Summary struct {
+	Common
+}
+
+// Target
+//
+//	TARGET n  Concentrate fire on target type "n" during combat
+type Target struct {
+	Common
+	Target int `json:"target,omitempty"`
+}
+
+// Teach
+//
+//	TEACH tech [n] sp  Transfer knowledge of technology "tech" to species "sp" to maximum tech level "n"
+type Teach struct {
+	Common
+	Tech    string `json:"tech,omitempty"`
+	Level   int    `json:"level,omitempty"` // zero if not specified
+	Species string `json:"species,omitempty"`
+}
+
+// Tech
+//
+//	TECH [n] tech [n] sp  Acquire technology "tech" from species "sp",
+//	                      spending at most the first "n" to a maximum tech level of the second "n"
+type Tech struct {
+	Common
+	Limit   int    `json:"limit,omitempty"` // zero if not specified
+	Tech    string `json:"tech,omitempty"`
+	Level   int    `json:"level,omitempty"` // zero if not specified
+	Species string `json:"species,omitempty"`
+}
+
+// Telescope
+//
+//	TELESCOPE base  Operate gravitic telescope on starbase "base"
+type Telescope struct {
+	Common
+	Base string `json:"base,omitempty"`
+}
+
+// Terraform
+//
+//	TERRAFORM [n] pl  Terraform planet "pl" using "n" TPs
+type Terraform struct {
+	Common
+	Count  int    `json:"count,omitempty"` // zero means all available plants
+	Planet string `json:"planet,omitempty"`
+}
+
+// Transfer
+//
+//	TRANSFER n ab s,d  Transfer "n" items of class "ab" from "s" to "d"
+type Transfer struct {
+	Common
+	Count       int    `json:"count,omitempty"` // zero means all available units
+	Item        string `json:"item,omitempty"`
+	Source      string `json:"source,omitempty"`
+	Destination string `json:"destination,omitempty"`
+}
+
+// Unload
+//
+//	UNLOAD ship  Transfer all CUs, IUs, and AUs from "ship" or
+//	UNLOAD base  starbase "base" to the planet it is at and install as many IUs and AUs as possible
+type Unload struct {
+	Common
+	Ship string `json:"ship,omitempty"` // ship or starbase to unload
+}
+
+// Upgrade
+//
+//	UPGRADE ship    Upgrade "ship" to age zero
+//	UPGRADE base    Upgrade starbase "base" to age zero
+//	UPGRADE ship,n  Upgrade "ship", spend "n"
+//	UPGRADE base,n  Upgrade starbase "base", spend "n"
+type Upgrade struct {
+	Common
+	Ship           string `json:"ship,omitempty"`            // ship or starbase to upgrade
+	Limit          int    `json:"limit,omitempty"`           // maximum number of units to spend
+	LimitSpecified bool   `json:"limit_specified,omitempty"` // true if user gave any value for the limit, even zero
+}
+
+// Visited
+//
+//	VISITED x y z  Mark a star system as having been visited, even if you have not actually been there
+type Visited struct {
+	Common
+	X int `json:"x"`
+	Y int `json:"y"`
+	Z int `json:"z"`
+}
+
+// Withdraw
+//
+//	WITHDRAW n1 n2 n3  Set conditions for withdrawing from combat
+type Withdraw struct {
+	Common
+	TransportAge int `json:"transport_age,omitempty"` // withdraw transports at or above this age
+	WarshipAge   int `json:"warship_age,omitempty"`   // withdraw warships at or above this age
+	FleetPercent int `json:"fleet_percent,omitempty"` // withdraw the fleet after losing this percentage
+}
+
+// Wormhole
+//
+//	WORMHOLE ship [,p]  Have "ship" jump to opposite end of wormhole and orbit planet "p" on arrival
+//	WORMHOLE base [,p]  Have starbase "base" jump to opposite end of wormhole and orbit planet "p" on arrival
+type Wormhole struct {
+	Common
+	Ship  string `json:"ship,omitempty"`
+	Orbit int    `json:"orbit,omitempty"` // zero if not specified
+}
+
+// Unknown holds an order that could not be recognized
+// or a command that the engine does not implement.
+type Unknown struct {
+	Common
+}
@@ -323,13 +323,33 @@ func (db *DB) LoadGalaxy(setupJson string, initGalaxy, forcePurge bool) error {
 			continue
 		}
 		log.Printf("[load] staging galaxy %d %q root %q (%d)\n", setup.Id, setup.Name, t.Root, i)
-		if err := db.stageGalaxy(setup, filepath.Join(setup.Root, setup.Files[i].Root), ctx); err != nil {
+		root := filepath.Join(setup.Root, setup.Files[i].Root)
+		log.Printf("[stage] galaxy %d %q: reading galaxy file\n", setup.Id, setup.Name)
+		galaxy, err := dat32.ReadGalaxy(filepath.Join(root, "galaxy.dat"), setup.Endian)
+		if err != nil {
+			return err
+		}
+		log.Printf("[stage] galaxy %d %q: reading stars file\n", setup.Id, setup.Name)
+		stars, err := dat32.ReadStars(filepath.Join(root, "stars.dat"), setup.Endian)
+		if err != nil {
+			return err
+		}
+		log.Printf("[stage] galaxy %d %q: reading planets file\n", setup.Id, setup.Name)
+		planets, err := dat32.ReadPlanets(filepath.Join(root, "planets.dat"), setup.Endian)
+		if err != nil {
+			return err
+		}
+		if err := db.stageGalaxy(setup, galaxy, ctx); err != nil {
 			return err
-		} else if err = db.stageSystems(setup, filepath.Join(setup.Root, setup.Files[i].Root), ctx); err != nil {
+		} else if err = db.stageSystems(setup, stars, planets, ctx); err != nil {
 			return err
 		}
 		for no := 1; no <= setup.NumSpecies; no++ {
-			if err = db.stageSpecies(setup, filepath.Join(setup.Root, setup.Files[i].Root), no, ctx); err != nil {
+			log.Printf("[stage] galaxy %d %q: reading species %2d file\n", setup.Id, setup.Name, no)
+			sp, err := dat32.ReadSpecies(filepath.Join(root, fmt.Sprintf("sp%02d.dat", no)), no, setup.Endian)
+			if err != nil {
+				return err
+			} else if err = db.stageSpecies(setup, sp, ctx); err != nil {
 				return err
 			}
 		}
@@ -500,8 +520,8 @@ func (db *DB) initGalaxy(gs *GalaxySetup, root string, forcePurge bool, ctx cont
 					homeWorldId,
 					sp.TechKnowledge[0], sp.TechKnowledge[1], sp.TechKnowledge[2], sp.TechKnowledge[3], sp.TechKnowledge[4], sp.TechKnowledge[5],
 					gs.GasCode(sp.RequiredGas), sp.RequiredGasMin, sp.RequiredGasMax,
-					gs.GasCode(sp.NeutralGas[0]), gs.GasCode(sp.NeutralGas[1]), gs.GasCode(sp.NeutralGas[2]), gs.GasCode(sp.NeutralGas[3]), gs.GasCode(sp.NeutralGas[4]), gs.GasCode(sp.NeutralGas[5]),
-					gs.GasCode(sp.PoisonGas[0]), gs.GasCode(sp.PoisonGas[1]), gs.GasCode(sp.PoisonGas[2]), gs.GasCode(sp.PoisonGas[3]), gs.GasCode(sp.PoisonGas[4]), gs.GasCode(sp.PoisonGas[5]),
+					gs.GasCode(nthGas(sp.NeutralGas, 0)), gs.GasCode(nthGas(sp.NeutralGas, 1)), gs.GasCode(nthGas(sp.NeutralGas, 2)), gs.GasCode(nthGas(sp.NeutralGas, 3)), gs.GasCode(nthGas(sp.NeutralGas, 4)), gs.GasCode(nthGas(sp.NeutralGas, 5)),
+					gs.GasCode(nthGas(sp.PoisonGas, 0)), gs.GasCode(nthGas(sp.PoisonGas, 1)), gs.GasCode(nthGas(sp.PoisonGas, 2)), gs.GasCode(nthGas(sp.PoisonGas, 3)), gs.GasCode(nthGas(sp.PoisonGas, 4)), gs.GasCode(nthGas(sp.PoisonGas, 5)),
 					sp.Id, sp.NamplaBase[0].PlanetIndex,
 					accountId)
 			}
@@ -524,12 +544,7 @@ func (db *DB) initGalaxy(gs *GalaxySetup, root string, forcePurge bool, ctx cont
 	return nil
 }
 
-func (db *DB) stageGalaxy(gs *GalaxySetup, root string, ctx context.Context) error {
-	log.Printf("[stage] galaxy %d %q: reading galaxy file\n", gs.Id, gs.Name)
-	galaxy, err := dat32.ReadGalaxy(filepath.Join(root, "galaxy.dat"), gs.Endian)
-	if err != nil {
-		return err
-	}
+func (db *DB) stageGalaxy(gs *GalaxySetup, galaxy *dat32.Galaxy, ctx context.Context) error {
 	tx, err := db.pool.Begin(ctx)
 	if err != nil {
 		return err
@@ -554,13 +569,8 @@ func (db *DB) stageGalaxy(gs *GalaxySetup, root string, ctx context.Context) err
 	return nil
 }
 
-func (db *DB) stageSpecies(gs *GalaxySetup, root string, no int, ctx context.Context) error {
-	log.Printf("[stage] galaxy %d %q: reading species %2d file\n", gs.Id, gs.Name, no)
-	sp, err := dat32.ReadSpecies(filepath.Join(root, fmt.Sprintf("sp%02d.dat", no)), no, gs.Endian)
-	if err != nil {
-		return err
-	}
-
+func (db *DB) stageSpecies(gs *GalaxySetup, sp *dat32.Species, ctx context.Context) error {
+	no := sp.Id
 	tx, err := db.pool.Begin(ctx)
 	if err != nil {
 		return err
@@ -584,8 +594,8 @@ func (db *DB) stageSpecies(gs *GalaxySetup, root string, no int, ctx context.Con
 	b.Queue("delete from stage_species_homeworld where galaxy_no = $1 and species_no = $2", gs.Id, no)
 	b.Queue("insert into stage_species_homeworld (galaxy_no, species_no, homeworld_no, hp_original_base, neutral_gas_code_1, neutral_gas_code_2, neutral_gas_code_3, neutral_gas_code_4, neutral_gas_code_5, neutral_gas_code_6, poison_gas_code_1, poison_gas_code_2, poison_gas_code_3, poison_gas_code_4, poison_gas_code_5, poison_gas_code_6, required_gas_code, required_gas_min, required_gas_max) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)",
 		gs.Id, sp.Id, homeworldNo, sp.HPOriginalBase,
-		gs.GasCode(sp.NeutralGas[0]), gs.GasCode(sp.NeutralGas[1]), gs.GasCode(sp.NeutralGas[2]), gs.GasCode(sp.NeutralGas[3]), gs.GasCode(sp.NeutralGas[4]), gs.GasCode(sp.NeutralGas[5]),
-		gs.GasCode(sp.PoisonGas[0]), gs.GasCode(sp.PoisonGas[1]), gs.GasCode(sp.PoisonGas[2]), gs.GasCode(sp.PoisonGas[3]), gs.GasCode(sp.PoisonGas[4]), gs.GasCode(sp.PoisonGas[5]),
+		gs.GasCode(nthGas(sp.NeutralGas, 0)), gs.GasCode(nthGas(sp.NeutralGas, 1)), gs.GasCode(nthGas(sp.NeutralGas, 2)), gs.GasCode(nthGas(sp.NeutralGas, 3)), gs.GasCode(nthGas(sp.NeutralGas, 4)), gs.GasCode(nthGas(sp.NeutralGas, 5)),
+		gs.GasCode(nthGas(sp.PoisonGas, 0)), gs.GasCode(nthGas(sp.PoisonGas, 1)), gs.GasCode(nthGas(sp.PoisonGas, 2)), gs.GasCode(nthGas(sp.PoisonGas, 3)), gs.GasCode(nthGas(sp.PoisonGas, 4)), gs.GasCode(nthGas(sp.PoisonGas, 5)),
 		gs.GasCode(sp.RequiredGas), sp.RequiredGasMin, sp.RequiredGasMax)
 	b.Queue("delete from stage_species_relations where galaxy_no = $1 and species_no = $2", gs.Id, no)
 	for _, alienNo := range sp.Ally {
@@ -853,17 +863,7 @@ func (db *DB) stageSpecies(gs *GalaxySetup, root string, no int, ctx context.Con
 	return nil
 }
 
-func (db *DB) stageSystems(gs *GalaxySetup, root string, ctx context.Context) error {
-	log.Printf("[stage] galaxy %d %q: reading stars file\n", gs.Id, gs.Name)
-	stars, err := dat32.ReadStars(filepath.Join(root, "stars.dat"), gs.Endian)
-	if err != nil {
-		return err
-	}
-	log.Printf("[stage] galaxy %d %q: reading planets file\n", gs.Id, gs.Name)
-	planets, err := dat32.ReadPlanets(filepath.Join(root, "planets.dat"), gs.Endian)
-	if err != nil {
-		return err
-	}
+func (db *DB) stageSystems(gs *GalaxySetup, stars *dat32.Stars, planets *dat32.Planets, ctx context.Context) error {
 	tx, err := db.pool.Begin(ctx)
 	if err != nil {
 		return err
@@ -900,3 +900,11 @@ func (db *DB) stageSystems(gs *GalaxySetup, root string, ctx context.Context) er
 
 	return nil
 }
+
+// nthGas returns the n-th gas from a compacted list, or 0 if the list is shorter.
+func nthGas(gases []int, n int) int {
+	if n < len(gases) {
+		return gases[n]
+	}
+	return 0
+}
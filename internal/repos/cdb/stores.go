@@ -0,0 +1,99 @@
+/*******************************************************************************
+Far Horizons Engine
+Copyright (C) 2022  Michael D Henderson
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+******************************************************************************/
+
+package cdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"github.com/mdhender/fhcms/cms/store/jsondb"
+	"github.com/mdhender/fhcms/internal/convert"
+	"log"
+)
+
+// StageStore loads a store into the staging tables for an existing galaxy.
+// It also saves a copy of the store so that ReadStore can return it without loss.
+func (db *DB) StageStore(ctx context.Context, name string, ds *jsondb.Store) error {
+	gs := &GalaxySetup{Name: name}
+	if err := db.pool.QueryRow(ctx, "select id, num_species from galaxies where upper(name) = upper($1)", name).Scan(&gs.Id, &gs.NumSpecies); err != nil {
+		log.Printf("[stage] galaxy %q: %+v\n", name, err)
+		return fmt.Errorf("no such galaxy")
+	}
+
+	galaxy, stars, planets, species, _ := convert.ToDat32Records(ds)
+	log.Printf("[stage] galaxy %d %q: staging turn %d\n", gs.Id, gs.Name, galaxy.TurnNumber)
+	if err := db.stageGalaxy(gs, galaxy, ctx); err != nil {
+		return err
+	} else if err = db.stageSystems(gs, stars, planets, ctx); err != nil {
+		return err
+	}
+	for _, sp := range species {
+		if err := db.stageSpecies(gs, sp, ctx); err != nil {
+			return err
+		}
+	}
+
+	b, err := json.Marshal(ds)
+	if err != nil {
+		return err
+	}
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		_ = tx.Rollback(ctx)
+	}(tx, ctx)
+	if _, err = tx.Exec(ctx, `CREATE TABLE IF NOT EXISTS stage_stores(
+		galaxy_no   INTEGER     NOT NULL,
+		turn_number INTEGER     NOT NULL,
+		semver      TEXT        NOT NULL,
+		store       JSONB       NOT NULL,
+		created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		CONSTRAINT pk_stage_stores PRIMARY KEY (galaxy_no, turn_number))`); err != nil {
+		return err
+	} else if _, err = tx.Exec(ctx, "delete from stage_stores where galaxy_no = $1 and turn_number = $2", gs.Id, galaxy.TurnNumber); err != nil {
+		return err
+	} else if _, err = tx.Exec(ctx, "insert into stage_stores (galaxy_no, turn_number, semver, store) values ($1, $2, $3, $4)", gs.Id, galaxy.TurnNumber, ds.Version, b); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// ReadStore returns the store saved by StageStore for a turn.
+// If turn is zero, it returns the store for the latest turn.
+func (db *DB) ReadStore(ctx context.Context, name string, turn int) (*jsondb.Store, error) {
+	var b []byte
+	if err := db.pool.QueryRow(ctx, `select s.store
+		from stage_stores s, galaxies g
+		where g.id = s.galaxy_no and upper(g.name) = upper($1) and ($2 = 0 or s.turn_number = $2)
+		order by s.turn_number desc
+		limit 1`, name, turn).Scan(&b); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("no store for galaxy %q turn %d", name, turn)
+		}
+		return nil, err
+	}
+	var ds jsondb.Store
+	if err := json.Unmarshal(b, &ds); err != nil {
+		return nil, err
+	}
+	return &ds, nil
+}